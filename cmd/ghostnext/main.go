@@ -2,6 +2,8 @@ package main
 
 import (
 	"os"
+
+	configCmd "github.com/chronicleprotocol/oracle-suite/pkg/config/cmd"
 )
 
 func main() {
@@ -10,6 +12,7 @@ func main() {
 
 	rootCmd.AddCommand(
 		NewRunCmd(&opts),
+		configCmd.NewConfigCmd(&opts.Config, &opts.ConfigFilePath),
 	)
 
 	if err := rootCmd.Execute(); err != nil {
@@ -40,6 +40,10 @@ func NewAgentCmd(opts *options) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			services.Reloader, err = config.NewReloader(opts.ConfigFilePath, services.Reload, services.Logger)
+			if err != nil {
+				return err
+			}
 			if err = services.Start(ctx); err != nil {
 				return err
 			}
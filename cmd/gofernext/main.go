@@ -6,6 +6,7 @@ import (
 	"os"
 
 	suite "github.com/chronicleprotocol/oracle-suite"
+	configCmd "github.com/chronicleprotocol/oracle-suite/pkg/config/cmd"
 	"github.com/chronicleprotocol/oracle-suite/pkg/datapoint"
 )
 
@@ -21,6 +22,7 @@ func main() {
 	rootCmd.AddCommand(
 		NewModelsCmd(&opts),
 		NewDataCmd(&opts),
+		configCmd.NewConfigCmd(&opts.Config, &opts.ConfigFilePath),
 	)
 
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,197 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/defiweb/go-eth/hexutil"
+	"github.com/spf13/cobra"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store/archive"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+func NewEventsCmd(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Export and import events stored by the agent",
+	}
+
+	cmd.AddCommand(
+		NewEventsExportCmd(opts),
+		NewEventsImportCmd(opts),
+	)
+
+	return cmd
+}
+
+func NewEventsExportCmd(opts *options) *cobra.Command {
+	var (
+		typ    string
+		index  string
+		signer string
+		from   string
+		to     string
+		limit  int
+		format string
+		output string
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Args:  cobra.ExactArgs(0),
+		Short: "Export events from the configured storage",
+		Long: `Export events from the configured storage to a file or to the standard output.
+
+If the storage does not support range queries (e.g. Redis), the type and index must be specified.`,
+		RunE: func(_ *cobra.Command, _ []string) (err error) {
+			if err := config.LoadFiles(&opts.Config, opts.ConfigFilePath); err != nil {
+				return err
+			}
+			q := store.Query{Type: typ, Limit: limit}
+			if index != "" {
+				if q.Index, err = hexutil.HexToBytes(index); err != nil {
+					return fmt.Errorf("invalid index: %w", err)
+				}
+			}
+			if signer != "" {
+				if q.Signer, err = hexutil.HexToBytes(signer); err != nil {
+					return fmt.Errorf("invalid signer: %w", err)
+				}
+			}
+			if q.From, err = parseTime(from); err != nil {
+				return fmt.Errorf("invalid from date: %w", err)
+			}
+			if q.To, err = parseTime(to); err != nil {
+				return fmt.Errorf("invalid to date: %w", err)
+			}
+			services, err := opts.Config.ArchiveServices(opts.Logger(), false)
+			if err != nil {
+				return err
+			}
+			out := os.Stdout
+			if output != "" {
+				if out, err = os.Create(output); err != nil {
+					return err
+				}
+				defer func() {
+					if cErr := out.Close(); err == nil {
+						err = cErr
+					}
+				}()
+			}
+			w, err := archive.NewWriter(out, archive.Format(format))
+			if err != nil {
+				return err
+			}
+			n, err := archive.Export(context.Background(), services.Storage, q, w)
+			if err != nil {
+				return err
+			}
+			services.Logger.WithField("count", n).Info("Events exported")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&typ, "type", "", "event type")
+	cmd.Flags().StringVar(&index, "index", "", "hex encoded event index")
+	cmd.Flags().StringVar(&signer, "signer", "", "hex encoded signer address")
+	cmd.Flags().StringVar(&from, "from", "", "export events not older than the given date (RFC3339 or UNIX timestamp)")
+	cmd.Flags().StringVar(&to, "to", "", "export events older than the given date (RFC3339 or UNIX timestamp)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "maximum number of exported events")
+	cmd.Flags().StringVar(&format, "format", string(archive.FormatJSON), "output format (jsonl|protobuf)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output file (default stdout)")
+	return cmd
+}
+
+func NewEventsImportCmd(opts *options) *cobra.Command {
+	var (
+		format      string
+		rebroadcast bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Import events to the configured storage",
+		Long: `Import events from a file or from the standard input to the configured storage.
+
+Signatures of imported events are verified and every signature is stored under the address of its signer. If the --rebroadcast flag is set, events are
+also broadcast over the configured transport.`,
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			if err := config.LoadFiles(&opts.Config, opts.ConfigFilePath); err != nil {
+				return err
+			}
+			in := os.Stdin
+			if len(args) == 1 {
+				if in, err = os.Open(args[0]); err != nil {
+					return err
+				}
+				defer in.Close()
+			}
+			r, err := archive.NewReader(in, archive.Format(format))
+			if err != nil {
+				return err
+			}
+			ctx, ctxCancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			services, err := opts.Config.ArchiveServices(opts.Logger(), rebroadcast)
+			if err != nil {
+				return err
+			}
+			if err = services.Start(ctx); err != nil {
+				return err
+			}
+			defer func() {
+				ctxCancel()
+				if sErr := <-services.Wait(); err == nil { // Ignore sErr if another error has already occurred.
+					err = sErr
+				}
+			}()
+			var broadcast func(evt *messages.Event) error
+			if rebroadcast {
+				broadcast = func(evt *messages.Event) error {
+					return services.Transport.Broadcast(messages.EventV1MessageName, evt)
+				}
+			}
+			n, err := archive.Import(ctx, services.Storage, r, broadcast)
+			if err != nil {
+				return err
+			}
+			services.Logger.WithField("count", n).Info("Events imported")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", string(archive.FormatJSON), "input format (jsonl|protobuf)")
+	cmd.Flags().BoolVar(&rebroadcast, "rebroadcast", false, "broadcast imported events over the configured transport")
+	return cmd
+}
+
+// parseTime parses a date given as RFC3339 string or as a UNIX timestamp.
+// An empty string returns a zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -17,6 +17,8 @@ package main
 
 import (
 	"os"
+
+	configCmd "github.com/chronicleprotocol/oracle-suite/pkg/config/cmd"
 )
 
 func main() {
@@ -25,6 +27,8 @@ func main() {
 
 	rootCmd.AddCommand(
 		NewRunCmd(&opts),
+		NewEventsCmd(&opts),
+		configCmd.NewConfigCmd(&opts.Config, &opts.ConfigFilePath),
 	)
 
 	if err := rootCmd.Execute(); err != nil {
@@ -24,6 +24,7 @@ import (
 
 type options struct {
 	Listen             string
+	AdminListen        string
 	EnableCORS         bool
 	GracefulTimeoutSec int
 	TotalTimeoutSec    int
@@ -50,6 +51,12 @@ func NewRootCommand(opts *options) *cobra.Command {
 		"127.0.0.1:8545",
 		"listen address",
 	)
+	rootCmd.PersistentFlags().StringVar(
+		&opts.AdminListen,
+		"admin-listen",
+		"",
+		"listen address of the admin server with metrics and health endpoints, disabled if empty",
+	)
 	rootCmd.PersistentFlags().BoolVarP(
 		&opts.EnableCORS,
 		"enable-cors",
@@ -25,6 +25,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/admin"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver/middleware"
 	"github.com/chronicleprotocol/oracle-suite/pkg/rpcsplitter"
@@ -77,6 +79,24 @@ func NewRunCmd(opts *options) *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("unable to start the HTTP server: %w", err)
 			}
+			registry := health.NewRegistry()
+			registry.Register("rpc_splitter").Ready()
+
+			if opts.AdminListen != "" {
+				adminSrv := admin.NewServer(admin.Config{
+					ListenAddr: opts.AdminListen,
+					Health:     registry,
+					Logger:     log,
+				})
+				if err := adminSrv.Start(ctx); err != nil {
+					return fmt.Errorf("unable to start the admin server: %w", err)
+				}
+				defer func() {
+					if err := <-adminSrv.Wait(); err != nil {
+						log.WithError(err).Error("Error while closing admin server")
+					}
+				}()
+			}
 
 			defer func() {
 				err := <-srv.Wait()
@@ -39,6 +39,10 @@ func NewRunCmd(opts *options) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			services.Reloader, err = config.NewReloader(opts.ConfigFilePath, services.Reload, services.Logger)
+			if err != nil {
+				return err
+			}
 			if err = services.Start(ctx); err != nil {
 				return err
 			}
@@ -19,13 +19,14 @@ import (
 	"github.com/spf13/cobra"
 
 	suite "github.com/chronicleprotocol/oracle-suite"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/spirebootstrap"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/logrus/flag"
 )
 
 type options struct {
 	flag.LoggerFlag
 	ConfigFilePath []string
-	Config         Config
+	Config         spirebootstrap.Config
 }
 
 func NewRootCommand(opts *options) *cobra.Command {
@@ -18,6 +18,7 @@ package main
 import (
 	"github.com/spf13/cobra"
 
+	configCmd "github.com/chronicleprotocol/oracle-suite/pkg/config/cmd"
 	"github.com/chronicleprotocol/oracle-suite/pkg/config/spire"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/logrus/flag"
 )
@@ -53,6 +54,7 @@ func NewRootCommand(opts *options) *cobra.Command {
 		NewStreamCmd(opts),
 		NewPullCmd(opts),
 		NewPushCmd(opts),
+		configCmd.NewConfigCmd(&opts.Config, &opts.ConfigFilePath),
 	)
 
 	return rootCmd
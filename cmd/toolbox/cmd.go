@@ -19,13 +19,25 @@ import (
 	"github.com/spf13/cobra"
 
 	suite "github.com/chronicleprotocol/oracle-suite"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	configCmd "github.com/chronicleprotocol/oracle-suite/pkg/config/cmd"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/toolbox"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/logrus/flag"
 )
 
 type options struct {
 	flag.LoggerFlag
 	ConfigFilePath []string
-	Config         Config
+	Config         toolbox.Config
+}
+
+// services loads the config files and returns the services configured in
+// them.
+func (o *options) services() (*toolbox.Services, error) {
+	if err := config.LoadFiles(&o.Config, o.ConfigFilePath); err != nil {
+		return nil, err
+	}
+	return o.Config.Services(o.Logger())
 }
 
 func NewRootCommand() *cobra.Command {
@@ -48,9 +60,11 @@ func NewRootCommand() *cobra.Command {
 	)
 
 	rootCmd.AddCommand(
+		NewAuditCmd(),
 		NewMedianCmd(&opts),
 		NewPriceCmd(&opts),
 		NewSignerCmd(&opts),
+		configCmd.NewConfigCmd(&opts.Config, &opts.ConfigFilePath),
 	)
 
 	return rootCmd
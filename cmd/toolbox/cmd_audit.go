@@ -0,0 +1,107 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/audit"
+)
+
+func NewAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Args:  cobra.ExactArgs(0),
+		Short: "commands related to the audit log of signatures",
+		Long:  ``,
+	}
+
+	cmd.AddCommand(
+		NewAuditVerifyCmd(),
+	)
+
+	return cmd
+}
+
+func NewAuditVerifyCmd() *cobra.Command {
+	var noRotated bool
+
+	cmd := &cobra.Command{
+		Use:   "verify path...",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "verifies the sequence, the hash chain and the signatures in audit log files",
+		Long: `Verifies the sequence, the hash chain and the signatures in audit log files.
+
+Rotated files of every given file are verified before the file itself, from
+the oldest to the newest, unless the --no-rotated flag is used. Files given
+as separate arguments must be listed in the order in which they were written.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			var files []string
+			for _, path := range args {
+				if !noRotated {
+					rotated, err := audit.RotatedFiles(path)
+					if err != nil {
+						return err
+					}
+					files = append(files, rotated...)
+				}
+				files = append(files, path)
+			}
+
+			v := audit.NewVerifier()
+			for _, path := range files {
+				if err := verifyAuditFile(v, path); err != nil {
+					return err
+				}
+			}
+			if v.Entries == 0 {
+				return errors.New("no entries found")
+			}
+
+			out := c.OutOrStdout()
+			fmt.Fprintf(out, "Verified %d entries (seq %d to %d) in %d file(s)\n", v.Entries, v.FirstSeq, v.LastSeq, len(files))
+			if v.FirstSeq > 1 {
+				fmt.Fprintf(out, "Entries before seq %d are not available, e.g. rotated files were removed\n", v.FirstSeq)
+			}
+			if !v.Chained {
+				fmt.Fprintln(out, "Warning: some entries are not hash-chained, removed or modified entries cannot be detected")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(
+		&noRotated,
+		"no-rotated",
+		false,
+		"do not verify rotated files",
+	)
+
+	return cmd
+}
+
+func verifyAuditFile(v *audit.Verifier, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return v.Verify(f, path)
+}
@@ -62,7 +62,7 @@ func NewMedianAgeCmd(opts *options) *cobra.Command {
 		Short: "returns the age value (last update time)",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -96,7 +96,7 @@ func NewMedianBarCmd(opts *options) *cobra.Command {
 		Short: "returns the bar value (required quorum)",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -129,7 +129,7 @@ func NewMedianWatCmd(opts *options) *cobra.Command {
 		Short: "returns the wat value (asset name)",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -162,7 +162,7 @@ func NewMedianValCmd(opts *options) *cobra.Command {
 		Short: "returns the val value (asset price)",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -195,7 +195,7 @@ func NewMedianFeedsCmd(opts *options) *cobra.Command {
 		Short: "returns list of feeds which are allowed to send prices",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -230,7 +230,7 @@ func NewMedianPokeCmd(opts *options) *cobra.Command {
 		Short: "directly invokes poke method",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -280,7 +280,7 @@ func NewMedianLiftCmd(opts *options) *cobra.Command {
 		Short: "adds given addresses to the feeds list",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -318,7 +318,7 @@ func NewMedianDropCmd(opts *options) *cobra.Command {
 		Short: "removes given addresses from the feeds list",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -356,7 +356,7 @@ func NewMedianSetBarCmd(opts *options) *cobra.Command {
 		Short: "sets bar variable (quorum)",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -47,7 +47,7 @@ func NewPriceSignCmd(opts *options) *cobra.Command {
 		Short: "signs given JSON price message and returns JSON with VRS fields",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -61,7 +61,7 @@ func NewSignerSignCmd(opts *options, signerOpts *signerOptions) *cobra.Command {
 		Short: "signs given input (stdin is used if input argument is empty)",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, args []string) error {
-			srv, err := PrepareServices(opts)
+			srv, err := opts.services()
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,149 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package admin provides an HTTP server that exposes administrative
+// endpoints shared by all commands: Prometheus metrics, health probes,
+// configuration reload and endpoints of other components, like the LibP2P
+// admin API.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/metrics"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
+)
+
+const (
+	MetricsPath   = "/metrics"
+	LivenessPath  = "/health/live"
+	ReadinessPath = "/health/ready"
+	ReloadPath    = "/reload"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Config is the configuration for the admin HTTP server.
+type Config struct {
+	// ListenAddr is the address on which the server listens.
+	ListenAddr string
+
+	// MetricsPath is the path under which metrics are exposed. If empty,
+	// "/metrics" is used.
+	MetricsPath string
+
+	// Health is the registry used by the health endpoints. If nil, the
+	// health endpoints do not report any components.
+	Health *health.Registry
+
+	// Reload reloads the configuration and applies it to running services.
+	// It is called by the reload endpoint. If nil, or if it returns
+	// reload.ErrNotSupported, the endpoint responds with 501.
+	Reload func(ctx context.Context) error
+
+	// Handlers are additional handlers mounted under the given path
+	// prefixes. The prefix is stripped from the request path before the
+	// request is passed to the handler, so the "/libp2p" prefix serves
+	// the "/peers" endpoint of a handler as "/libp2p/peers".
+	Handlers map[string]http.Handler
+
+	// Logger is used to log errors that occur while serving requests.
+	// If nil, null logger will be used.
+	Logger log.Logger
+}
+
+// NewServer returns an HTTP server that exposes the following endpoints:
+//
+//   - GET /metrics - metrics in the Prometheus text format, the path can be
+//     changed using Config.MetricsPath.
+//   - GET /health/live - liveness probe, responds with 503 if any component
+//     is down.
+//   - GET /health/ready - readiness probe, responds with 503 if any
+//     component is down or not ready.
+//   - POST /reload - reloads the configuration, responds with 501 if the
+//     command does not support reloading and with 422 if the configuration
+//     could not be applied.
+//
+// Health endpoints respond with a JSON document containing statuses of all
+// components. Handlers from Config.Handlers are served under their path
+// prefixes.
+func NewServer(cfg Config) *httpserver.HTTPServer {
+	if cfg.MetricsPath == "" {
+		cfg.MetricsPath = MetricsPath
+	}
+	if cfg.Health == nil {
+		cfg.Health = health.NewRegistry()
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = null.New()
+	}
+	mux := http.NewServeMux()
+	mux.Handle(cfg.MetricsPath, metrics.Handler(cfg.Logger))
+	mux.Handle(LivenessPath, health.LivenessHandler(cfg.Health))
+	mux.Handle(ReadinessPath, health.ReadinessHandler(cfg.Health))
+	mux.Handle(ReloadPath, reloadHandler(cfg.Reload))
+	for prefix, handler := range cfg.Handlers {
+		prefix = "/" + strings.Trim(prefix, "/")
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, handler))
+	}
+	return httpserver.New(&http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           mux,
+		IdleTimeout:       defaultTimeout,
+		ReadTimeout:       defaultTimeout,
+		WriteTimeout:      defaultTimeout,
+		ReadHeaderTimeout: defaultTimeout,
+	})
+}
+
+// reloadResponse is the response returned by the reload endpoint.
+type reloadResponse struct {
+	Reloaded bool   `json:"reloaded"`
+	Error    string `json:"error,omitempty"`
+}
+
+func reloadHandler(fn func(ctx context.Context) error) http.Handler {
+	if fn == nil {
+		fn = func(context.Context) error { return reload.ErrNotSupported }
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.Header().Set("Allow", http.MethodPost)
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		res := reloadResponse{Reloaded: true}
+		status := http.StatusOK
+		if err := fn(r.Context()); err != nil {
+			res = reloadResponse{Error: err.Error()}
+			status = http.StatusUnprocessableEntity
+			if errors.Is(err, reload.ErrNotSupported) {
+				status = http.StatusNotImplemented
+			}
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(status)
+		_ = json.NewEncoder(rw).Encode(res)
+	})
+}
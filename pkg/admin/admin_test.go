@@ -0,0 +1,141 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
+)
+
+func TestServer(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+
+	registry := health.NewRegistry()
+	registry.Register("a").Ready()
+	registry.Register("b")
+
+	srv := NewServer(Config{
+		ListenAddr:  "127.0.0.1:0",
+		MetricsPath: "/custom/metrics",
+		Health:      registry,
+		Handlers: map[string]http.Handler{
+			"/libp2p": http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				_, _ = rw.Write([]byte(r.URL.Path))
+			}),
+		},
+	})
+	require.NoError(t, srv.Start(ctx))
+
+	get := func(path string) (int, string) {
+		res, err := http.Get("http://" + srv.Addr().String() + path)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(body)
+	}
+
+	// Metrics are served under the configured path.
+	status, body := get("/custom/metrics")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "go_goroutines")
+	status, _ = get(MetricsPath)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// Health endpoints use the injected registry.
+	status, _ = get(LivenessPath)
+	assert.Equal(t, http.StatusOK, status)
+	status, body = get(ReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	var res health.Response
+	require.NoError(t, json.Unmarshal([]byte(body), &res))
+	require.Len(t, res.Components, 2)
+	assert.Equal(t, "b", res.Components[1].Name)
+
+	// Additional handlers are served under their prefixes.
+	status, body = get("/libp2p/peers")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "/peers", body)
+}
+
+func TestReloadHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		reload   func(ctx context.Context) error
+		status   int
+		response reloadResponse
+	}{
+		{
+			name:     "reloaded",
+			method:   http.MethodPost,
+			reload:   func(context.Context) error { return nil },
+			status:   http.StatusOK,
+			response: reloadResponse{Reloaded: true},
+		},
+		{
+			name:     "invalid-config",
+			method:   http.MethodPost,
+			reload:   func(context.Context) error { return errors.New("invalid config") },
+			status:   http.StatusUnprocessableEntity,
+			response: reloadResponse{Error: "invalid config"},
+		},
+		{
+			name:     "not-supported",
+			method:   http.MethodPost,
+			reload:   func(context.Context) error { return reload.ErrNotSupported },
+			status:   http.StatusNotImplemented,
+			response: reloadResponse{Error: reload.ErrNotSupported.Error()},
+		},
+		{
+			name:     "nil",
+			method:   http.MethodPost,
+			status:   http.StatusNotImplemented,
+			response: reloadResponse{Error: reload.ErrNotSupported.Error()},
+		},
+		{
+			name:   "get",
+			method: http.MethodGet,
+			reload: func(context.Context) error { panic("unexpected call") },
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			reloadHandler(tt.reload).ServeHTTP(rec, httptest.NewRequest(tt.method, ReloadPath, nil))
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.method != http.MethodPost {
+				return
+			}
+			var res reloadResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, tt.response, res)
+		})
+	}
+}
@@ -0,0 +1,397 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package audit provides an append-only audit log of signatures produced by
+// the application. Entries are written as JSON lines and may be linked in
+// a hash chain, so that removed or modified entries can be detected.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+)
+
+const LoggerTag = "AUDIT"
+
+// Types of signed payloads.
+const (
+	TypePrice     = "price"
+	TypeDataPoint = "datapoint"
+	TypeEvent     = "event"
+)
+
+// rotatedTimeFormat is used in names of rotated files. It sorts in the same
+// order as the time it represents.
+const rotatedTimeFormat = "20060102T150405.000000000Z"
+
+// Entry is a single record of the audit log.
+type Entry struct {
+	// Seq is the sequence number of the entry. It is incremented by one for
+	// every entry, also across rotated files.
+	Seq uint64 `json:"seq"`
+
+	// Time is the time at which the signature was produced.
+	Time time.Time `json:"time"`
+
+	// Type is the type of the signed payload, e.g. "price".
+	Type string `json:"type"`
+
+	// Signer is the address of the key that produced the signature.
+	Signer types.Address `json:"signer"`
+
+	// Hash is the hash of the signed payload, e.g. the price hash.
+	Hash types.Hash `json:"hash"`
+
+	// Digest is the hash that was actually signed. For messages, it is the
+	// hash of the payload with the Ethereum message prefix.
+	Digest types.Hash `json:"digest"`
+
+	// Signature is the produced signature.
+	Signature types.Signature `json:"signature"`
+
+	// PrevHash is the EntryHash of the previous entry. It is empty for the
+	// first entry of the chain and if the hash chain is disabled.
+	PrevHash string `json:"prev_hash,omitempty"`
+
+	// EntryHash is the SHA-256 hash of the JSON encoded entry without the
+	// EntryHash field. It is empty if the hash chain is disabled.
+	EntryHash string `json:"entry_hash,omitempty"`
+}
+
+// Config is the configuration for the Log.
+type Config struct {
+	// Path is the path to the audit log file.
+	Path string
+
+	// MaxSize is the maximum size of the log file in bytes. If the next
+	// entry would exceed the size, the file is rotated. If zero, the file
+	// is never rotated.
+	MaxSize int64
+
+	// MaxFiles is the maximum number of rotated files to keep. If zero, all
+	// rotated files are kept.
+	MaxFiles int
+
+	// HashChain enables the hash chain of entries. If the last entry of an
+	// existing log is hash-chained, the chain is continued regardless of
+	// this option, so the log remains verifiable.
+	HashChain bool
+
+	// Logger is used to log rotations. If nil, null logger is used.
+	Logger log.Logger
+}
+
+// Log is an append-only audit log. Rotated files are renamed to include the
+// time of rotation, e.g. "audit.jsonl" is rotated to
+// "audit-20230101T000000.000000000Z.jsonl".
+type Log struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	waitCh chan error
+	file   *os.File
+	size   int64
+	seq    uint64
+	prev   string
+	closed bool
+	now    func() time.Time
+
+	path      string
+	maxSize   int64
+	maxFiles  int
+	hashChain bool
+	log       log.Logger
+}
+
+// New opens the audit log file, creating it if necessary. If the file, or
+// the most recent rotated file, contains entries, the sequence and the hash
+// chain are continued from the last entry. The hash chain is continued even
+// if it is disabled in the config, because entries that are not
+// hash-chained cannot follow chained ones.
+func New(cfg Config) (*Log, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("audit log path must not be empty")
+	}
+	if cfg.MaxSize < 0 {
+		return nil, errors.New("max size must not be negative")
+	}
+	if cfg.MaxFiles < 0 {
+		return nil, errors.New("max files must not be negative")
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = null.New()
+	}
+	l := &Log{
+		waitCh:    make(chan error),
+		now:       time.Now,
+		path:      cfg.Path,
+		maxSize:   cfg.MaxSize,
+		maxFiles:  cfg.MaxFiles,
+		hashChain: cfg.HashChain,
+		log:       cfg.Logger.WithField("tag", LoggerTag),
+	}
+	if err := l.resume(); err != nil {
+		return nil, err
+	}
+	if !l.hashChain && l.prev != "" {
+		l.log.Warn("The audit log is hash-chained, the hash chain is continued")
+		l.hashChain = true
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Start implements the supervisor.Service interface.
+func (l *Log) Start(ctx context.Context) error {
+	if l.ctx != nil {
+		return errors.New("service can be started only once")
+	}
+	if ctx == nil {
+		return errors.New("context must not be nil")
+	}
+	l.log.Debug("Starting")
+	l.ctx = ctx
+	go l.contextCancelHandler()
+	return nil
+}
+
+// Wait implements the supervisor.Service interface.
+func (l *Log) Wait() <-chan error {
+	return l.waitCh
+}
+
+// Record appends an entry to the log. The Seq, PrevHash and EntryHash
+// fields are set by the Log. The entry is synced to the disk before the
+// method returns.
+func (l *Log) Record(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errors.New("audit log is closed")
+	}
+	entry.Seq = l.seq + 1
+	entry.Time = entry.Time.UTC()
+	entry.PrevHash = ""
+	entry.EntryHash = ""
+	if l.hashChain {
+		entry.PrevHash = l.prev
+		h, err := hashEntry(entry)
+		if err != nil {
+			return err
+		}
+		entry.EntryHash = h
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("unable to write audit log entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("unable to sync audit log: %w", err)
+	}
+	l.seq = entry.Seq
+	l.prev = entry.EntryHash
+	return nil
+}
+
+// Close closes the log file. Entries cannot be recorded after the log is
+// closed.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.file.Close()
+}
+
+// open opens the log file for appending.
+func (l *Log) open() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to open audit log: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to open audit log: %w", err)
+	}
+	l.file = f
+	l.size = fi.Size()
+	return nil
+}
+
+// rotate renames the current file and opens a new one.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("unable to close audit log: %w", err)
+	}
+	rotated := rotatedPath(l.path, l.now())
+	if err := os.Rename(l.path, rotated); err != nil {
+		return fmt.Errorf("unable to rotate audit log: %w", err)
+	}
+	l.log.WithField("file", rotated).Info("Audit log rotated")
+	if err := l.open(); err != nil {
+		return err
+	}
+	return l.prune()
+}
+
+// prune removes the oldest rotated files that exceed the MaxFiles limit.
+func (l *Log) prune() error {
+	if l.maxFiles == 0 {
+		return nil
+	}
+	files, err := RotatedFiles(l.path)
+	if err != nil {
+		return err
+	}
+	for len(files) > l.maxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return fmt.Errorf("unable to remove rotated audit log: %w", err)
+		}
+		l.log.WithField("file", files[0]).Info("Rotated audit log removed")
+		files = files[1:]
+	}
+	return nil
+}
+
+// resume reads the last entry from the log file, or from the most recent
+// rotated file if the log file is empty.
+func (l *Log) resume() error {
+	files, err := RotatedFiles(l.path)
+	if err != nil {
+		return err
+	}
+	files = append(files, l.path)
+	for i := len(files) - 1; i >= 0; i-- {
+		last, err := lastEntry(files[i])
+		if err != nil {
+			return err
+		}
+		if last != nil {
+			l.seq = last.Seq
+			l.prev = last.EntryHash
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *Log) contextCancelHandler() {
+	defer func() { close(l.waitCh) }()
+	defer l.log.Debug("Stopped")
+	<-l.ctx.Done()
+	if err := l.Close(); err != nil {
+		l.waitCh <- err
+	}
+}
+
+// RotatedFiles returns the rotated files of the log file at the given path,
+// from the oldest to the newest.
+func RotatedFiles(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	files, err := filepath.Glob(strings.TrimSuffix(path, ext) + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func rotatedPath(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + t.UTC().Format(rotatedTimeFormat) + ext
+}
+
+// lastEntry returns the last entry of the given file or nil if the file
+// does not exist or is empty.
+func lastEntry(path string) (*Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read audit log: %w", err)
+	}
+	defer f.Close()
+	var last []byte
+	s := newScanner(f)
+	for s.Scan() {
+		if len(s.Bytes()) > 0 {
+			last = append(last[:0], s.Bytes()...)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read audit log: %w", err)
+	}
+	if last == nil {
+		return nil, nil
+	}
+	var e Entry
+	if err := json.Unmarshal(last, &e); err != nil {
+		return nil, fmt.Errorf("unable to parse the last entry of %s: %w", path, err)
+	}
+	return &e, nil
+}
+
+// hashEntry returns the hash of the entry, ignoring the EntryHash field.
+func hashEntry(e Entry) (string, error) {
+	e.EntryHash = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+func newScanner(r io.Reader) *bufio.Scanner {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), 1024*1024)
+	return s
+}
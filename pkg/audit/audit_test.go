@@ -0,0 +1,289 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHash = types.MustHashFromHex("0x2d3e4a5b6c7d8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f6071", types.PadNone)
+
+func newTestLog(t *testing.T, cfg Config) *Log {
+	l, err := New(cfg)
+	require.NoError(t, err)
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time {
+		ts = ts.Add(time.Second)
+		return ts
+	}
+	return l
+}
+
+func verifyFiles(t *testing.T, files ...string) (*Verifier, error) {
+	v := NewVerifier()
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		require.NoError(t, err)
+		if err := v.Verify(bytes.NewReader(b), f); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+func TestKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l := newTestLog(t, Config{Path: path, HashChain: true})
+	priv := wallet.NewRandomKey()
+	key := NewKey(priv, l, TypePrice)
+
+	sig, err := key.SignMessage(testHash.Bytes())
+	require.NoError(t, err)
+	_, err = key.SignMessage([]byte("not a hash"))
+	require.NoError(t, err)
+	_, err = key.SignHash(testHash)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 3)
+
+	e, err := lastEntry(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), e.Seq)
+	assert.Equal(t, testHash, e.Hash)
+	assert.Equal(t, testHash, e.Digest)
+
+	v, err := verifyFiles(t, path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, v.Entries)
+	assert.Equal(t, uint64(1), v.FirstSeq)
+	assert.Equal(t, uint64(3), v.LastSeq)
+	assert.True(t, v.Chained)
+
+	// The recorded signature can be verified against the payload hash.
+	addr, err := crypto.ECRecoverer.RecoverMessage(testHash.Bytes(), *sig)
+	require.NoError(t, err)
+	assert.Equal(t, priv.Address(), *addr)
+	assert.Contains(t, lines[0], testHash.String())
+
+	// Signing fails if the signature cannot be recorded.
+	_, err = key.SignMessage(testHash.Bytes())
+	assert.Error(t, err)
+
+	// The wrapped key is accessible, so key sets can be rotated.
+	assert.Same(t, priv, key.(*Key).Unwrap())
+
+	// Without a log, the key is not wrapped.
+	assert.Same(t, priv, NewKey(priv, nil, TypePrice))
+}
+
+// rotatedKey simulates a key set whose active key is rotated right after
+// signing, so the address no longer matches the signing key.
+type rotatedKey struct {
+	wallet.Key
+	next wallet.Key
+}
+
+func (k *rotatedKey) Address() types.Address {
+	return k.next.Address()
+}
+
+func TestKey_RotatedAddress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l := newTestLog(t, Config{Path: path, HashChain: true})
+	priv := wallet.NewRandomKey()
+	key := NewKey(&rotatedKey{Key: priv, next: wallet.NewRandomKey()}, l, TypePrice)
+
+	_, err := key.SignHash(testHash)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	e, err := lastEntry(path)
+	require.NoError(t, err)
+	assert.Equal(t, priv.Address(), e.Signer)
+	_, err = verifyFiles(t, path)
+	require.NoError(t, err)
+}
+
+func TestLog_HashChainDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l := newTestLog(t, Config{Path: path, HashChain: true})
+	_, err := NewKey(wallet.NewRandomKey(), l, TypePrice).SignHash(testHash)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	// The chain is continued even though it is disabled, so the log
+	// remains verifiable.
+	l = newTestLog(t, Config{Path: path})
+	_, err = NewKey(wallet.NewRandomKey(), l, TypePrice).SignHash(testHash)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	v, err := verifyFiles(t, path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v.Entries)
+	assert.True(t, v.Chained)
+
+	// A log that was never chained stays unchained.
+	path = filepath.Join(t.TempDir(), "audit.jsonl")
+	l = newTestLog(t, Config{Path: path})
+	_, err = NewKey(wallet.NewRandomKey(), l, TypePrice).SignHash(testHash)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	l = newTestLog(t, Config{Path: path})
+	_, err = NewKey(wallet.NewRandomKey(), l, TypePrice).SignHash(testHash)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	v, err = verifyFiles(t, path)
+	require.NoError(t, err)
+	assert.False(t, v.Chained)
+}
+
+func TestVerifier_Tampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+		error  string
+	}{
+		{
+			name: "modified",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"type":"price"`, `"type":"event"`, 1)
+				return lines
+			},
+			error: "entry 2 hash mismatch",
+		},
+		{
+			name: "removed",
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+			error: "expected sequence number 2, got 3",
+		},
+		{
+			name: "reordered",
+			tamper: func(lines []string) []string {
+				lines[1], lines[2] = lines[2], lines[1]
+				return lines
+			},
+			error: "expected sequence number 2, got 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			l := newTestLog(t, Config{Path: path, HashChain: true})
+			key := NewKey(wallet.NewRandomKey(), l, TypePrice)
+			for i := 0; i < 3; i++ {
+				_, err := key.SignHash(testHash)
+				require.NoError(t, err)
+			}
+			require.NoError(t, l.Close())
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			lines := tt.tamper(strings.Split(strings.TrimSpace(string(b)), "\n"))
+			require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+
+			_, err = verifyFiles(t, path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.error)
+		})
+	}
+}
+
+func TestVerifier_ForgedSignature(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l := newTestLog(t, Config{Path: path})
+	other := wallet.NewRandomKey()
+	sig, err := other.SignHash(testHash)
+	require.NoError(t, err)
+	require.NoError(t, l.Record(Entry{
+		Type:      TypePrice,
+		Signer:    wallet.NewRandomKey().Address(),
+		Hash:      testHash,
+		Digest:    testHash,
+		Signature: *sig,
+	}))
+	require.NoError(t, l.Close())
+
+	_, err = verifyFiles(t, path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signature was produced by")
+}
+
+func TestLog_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	cfg := Config{Path: path, MaxSize: 1000, MaxFiles: 2, HashChain: true}
+	l := newTestLog(t, cfg)
+	key := NewKey(wallet.NewRandomKey(), l, TypeEvent)
+	for i := 0; i < 10; i++ {
+		_, err := key.SignHash(testHash)
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Close())
+
+	rotated, err := RotatedFiles(path)
+	require.NoError(t, err)
+	require.Len(t, rotated, 2)
+	for _, f := range append(rotated, path) {
+		fi, err := os.Stat(f)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, fi.Size(), cfg.MaxSize)
+	}
+
+	// Pruned files are missing, so the chain starts at a later entry.
+	v, err := verifyFiles(t, append(rotated, path)...)
+	require.NoError(t, err)
+	assert.Greater(t, v.FirstSeq, uint64(1))
+	assert.Equal(t, uint64(10), v.LastSeq)
+
+	// The sequence and the chain are continued after reopening.
+	l = newTestLog(t, cfg)
+	l.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+	key = NewKey(wallet.NewRandomKey(), l, TypeEvent)
+	_, err = key.SignHash(testHash)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	rotated, err = RotatedFiles(path)
+	require.NoError(t, err)
+	v, err = verifyFiles(t, append(rotated, path)...)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(11), v.LastSeq)
+}
+
+func TestLog_Service(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := newTestLog(t, Config{Path: filepath.Join(t.TempDir(), "audit.jsonl")})
+	require.NoError(t, l.Start(ctx))
+	cancel()
+	assert.NoError(t, <-l.Wait())
+	assert.Error(t, l.Record(Entry{}))
+}
@@ -0,0 +1,99 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"fmt"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+)
+
+// Key wraps a wallet.Key and records every message and hash signature in
+// the audit log. Transaction signatures are not recorded.
+//
+// If the signature cannot be recorded, the signing fails, so no signature
+// leaves the application without a record.
+type Key struct {
+	wallet.Key
+	log *Log
+	typ string
+}
+
+// NewKey returns a key that records signatures of the given payload type in
+// the given log. If the log is nil, the key is returned unchanged.
+func NewKey(key wallet.Key, log *Log, typ string) wallet.Key {
+	if log == nil || key == nil {
+		return key
+	}
+	return &Key{Key: key, log: log, typ: typ}
+}
+
+// Unwrap returns the wrapped key. It allows to find a key set behind the
+// key, see keyset.FromKey.
+func (k *Key) Unwrap() wallet.Key {
+	return k.Key
+}
+
+// SignHash implements the wallet.Key interface.
+func (k *Key) SignHash(hash types.Hash) (*types.Signature, error) {
+	sig, err := k.Key.SignHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.record(hash, hash, *sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// SignMessage implements the wallet.Key interface.
+//
+// Signers in this module sign 32-byte hashes of payloads. Such data is
+// recorded as the payload hash, other data is hashed first.
+func (k *Key) SignMessage(data []byte) (*types.Signature, error) {
+	sig, err := k.Key.SignMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	hash := crypto.Keccak256(data)
+	if len(data) == types.HashLength {
+		hash = types.MustHashFromBytes(data, types.PadNone)
+	}
+	if err := k.record(hash, crypto.Keccak256(crypto.AddMessagePrefix(data)), *sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// record appends the signature to the log. The signer is recovered from the
+// signature rather than read from the key, because the address of a key set
+// may change between signing and recording.
+func (k *Key) record(hash, digest types.Hash, sig types.Signature) error {
+	signer, err := crypto.ECRecoverer.RecoverHash(digest, sig)
+	if err != nil {
+		return fmt.Errorf("unable to recover the signer: %w", err)
+	}
+	return k.log.Record(Entry{
+		Time:      k.log.now(),
+		Type:      k.typ,
+		Signer:    *signer,
+		Hash:      hash,
+		Digest:    digest,
+		Signature: sig,
+	})
+}
@@ -0,0 +1,113 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/defiweb/go-eth/crypto"
+)
+
+// Verifier verifies audit log files. It checks that entries have consecutive
+// sequence numbers, that the hash chain is intact and that every signature
+// was produced by the recorded signer.
+//
+// Files must be verified in the order in which they were written, i.e.
+// rotated files from the oldest to the newest, followed by the current file.
+type Verifier struct {
+	// Entries is the number of verified entries.
+	Entries int
+
+	// FirstSeq and LastSeq are the sequence numbers of the first and the
+	// last verified entry.
+	FirstSeq uint64
+	LastSeq  uint64
+
+	// Chained is true if all verified entries are hash-chained.
+	Chained bool
+
+	prev string
+}
+
+// NewVerifier returns a new Verifier.
+func NewVerifier() *Verifier {
+	return &Verifier{Chained: true}
+}
+
+// Verify verifies entries read from r. The name is used in error messages.
+func (v *Verifier) Verify(r io.Reader, name string) error {
+	s := newScanner(r)
+	line := 0
+	for s.Scan() {
+		line++
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+		if err := v.verifyEntry(s.Bytes()); err != nil {
+			return fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func (v *Verifier) verifyEntry(b []byte) error {
+	var e Entry
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&e); err != nil {
+		return fmt.Errorf("invalid entry: %w", err)
+	}
+	if v.Entries > 0 && e.Seq != v.LastSeq+1 {
+		return fmt.Errorf("expected sequence number %d, got %d", v.LastSeq+1, e.Seq)
+	}
+	if e.EntryHash == "" {
+		if v.Entries > 0 && v.prev != "" {
+			return fmt.Errorf("entry %d is not hash-chained", e.Seq)
+		}
+		v.Chained = false
+	} else {
+		h, err := hashEntry(e)
+		if err != nil {
+			return err
+		}
+		if h != e.EntryHash {
+			return fmt.Errorf("entry %d hash mismatch: the entry was modified", e.Seq)
+		}
+		if v.Entries > 0 && e.PrevHash != v.prev {
+			return fmt.Errorf("entry %d does not link to the previous entry: the chain is broken", e.Seq)
+		}
+	}
+	addr, err := crypto.ECRecoverer.RecoverHash(e.Digest, e.Signature)
+	if err != nil {
+		return fmt.Errorf("entry %d has an invalid signature: %w", e.Seq, err)
+	}
+	if *addr != e.Signer {
+		return fmt.Errorf("entry %d signature was produced by %s, not by %s", e.Seq, addr, e.Signer)
+	}
+	if v.Entries == 0 {
+		v.FirstSeq = e.Seq
+	}
+	v.Entries++
+	v.LastSeq = e.Seq
+	v.prev = e.EntryHash
+	return nil
+}
@@ -0,0 +1,124 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/audit"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+)
+
+const megabyte = 1024 * 1024
+
+type Dependencies struct {
+	Logger log.Logger
+}
+
+// Config is the configuration for the audit log, which records every
+// signature produced by the application.
+type Config struct {
+	// Path is the path to the audit log file. Rotated files are stored in
+	// the same directory.
+	Path string `hcl:"path"`
+
+	// MaxSize is the maximum size of the audit log file in megabytes. If
+	// the size is exceeded, the file is rotated. If zero, the file is never
+	// rotated.
+	MaxSize int64 `hcl:"max_size,optional"`
+
+	// MaxFiles is the maximum number of rotated files to keep. If zero, all
+	// rotated files are kept.
+	MaxFiles int `hcl:"max_files,optional"`
+
+	// HashChain enables the hash chain of entries, which allows detecting
+	// removed or modified entries using the "toolbox audit verify" command.
+	// Once enabled, the chain is continued in existing log files even if
+	// the option is disabled later.
+	HashChain bool `hcl:"hash_chain,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+
+	// Configured service:
+	log *audit.Log
+}
+
+// AuditLog returns the audit log of signatures. If the audit block is not
+// configured, it returns nil.
+func (c *Config) AuditLog(d Dependencies) (*audit.Log, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if c.log != nil {
+		return c.log, nil
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	l, err := audit.New(audit.Config{
+		Path:      c.Path,
+		MaxSize:   c.MaxSize * megabyte,
+		MaxFiles:  c.MaxFiles,
+		HashChain: c.HashChain,
+		Logger:    d.Logger,
+	})
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Runtime error",
+			Detail:   fmt.Sprintf("Failed to open the audit log: %v", err),
+			Subject:  c.Range.Ptr(),
+		}
+	}
+	c.log = l
+	return l, nil
+}
+
+// Validate checks the audit log configuration without opening the file.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Path == "" {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Audit log path must not be empty",
+			Subject:  c.Content.Attributes["path"].Range.Ptr(),
+		}
+	}
+	if c.MaxSize < 0 {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Audit log max size must not be negative",
+			Subject:  c.Content.Attributes["max_size"].Range.Ptr(),
+		}
+	}
+	if c.MaxFiles < 0 {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Audit log max files must not be negative",
+			Subject:  c.Content.Attributes["max_files"].Range.Ptr(),
+		}
+	}
+	return nil
+}
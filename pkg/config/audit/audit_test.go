@@ -0,0 +1,87 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+)
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		test func(*testing.T, *Config)
+	}{
+		{
+			name: "valid",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "/var/log/oracle/audit.jsonl", cfg.Path)
+				assert.Equal(t, int64(100), cfg.MaxSize)
+				assert.Equal(t, 10, cfg.MaxFiles)
+				assert.True(t, cfg.HashChain)
+				assert.NoError(t, cfg.Validate())
+			},
+		},
+		{
+			name: "audit log",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				cfg.Path = filepath.Join(t.TempDir(), "audit.jsonl")
+				l, err := cfg.AuditLog(Dependencies{Logger: null.New()})
+				require.NoError(t, err)
+				require.NotNil(t, l)
+				defer l.Close()
+				l2, err := cfg.AuditLog(Dependencies{Logger: null.New()})
+				require.NoError(t, err)
+				assert.Same(t, l, l2)
+				assert.FileExists(t, cfg.Path)
+			},
+		},
+		{
+			name: "invalid",
+			path: "invalid.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				assert.ErrorContains(t, cfg.Validate(), "max size must not be negative")
+				_, err := cfg.AuditLog(Dependencies{Logger: null.New()})
+				assert.Error(t, err)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cfg Config
+			err := config.LoadFiles(&cfg, []string{"./testdata/" + test.path})
+			require.NoError(t, err)
+			test.test(t, &cfg)
+		})
+	}
+}
+
+func TestConfig_Nil(t *testing.T) {
+	var cfg *Config
+	l, err := cfg.AuditLog(Dependencies{Logger: null.New()})
+	require.NoError(t, err)
+	assert.Nil(t, l)
+	assert.NoError(t, cfg.Validate())
+}
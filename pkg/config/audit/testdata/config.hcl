@@ -0,0 +1,4 @@
+path       = "/var/log/oracle/audit.jsonl"
+max_size   = 100
+max_files  = 10
+hash_chain = true
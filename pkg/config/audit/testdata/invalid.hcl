@@ -0,0 +1,2 @@
+path     = "/var/log/oracle/audit.jsonl"
+max_size = -1
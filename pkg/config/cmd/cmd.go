@@ -0,0 +1,110 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+)
+
+// NewConfigCmd returns the "config" command with the "validate" and "render"
+// subcommands. The cfg argument must be a pointer to the application config
+// struct and paths a pointer to the list of config files, usually bound to
+// the --config flag.
+func NewConfigCmd(cfg any, paths *[]string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Args:  cobra.ExactArgs(0),
+		Short: "Commands related to the configuration files",
+		Long:  ``,
+	}
+	cmd.AddCommand(
+		newValidateCmd(cfg, paths),
+		newRenderCmd(cfg, paths),
+	)
+	return cmd
+}
+
+func newValidateCmd(cfg any, paths *[]string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Args:  cobra.ExactArgs(0),
+		Short: "Decode the configuration and check it for errors",
+		Long:  ``,
+		RunE: func(c *cobra.Command, _ []string) error {
+			err := config.ValidateFiles(cfg, *paths)
+			if err == nil {
+				fmt.Fprintln(c.OutOrStdout(), "Configuration is valid")
+				return nil
+			}
+			var diags hcl.Diagnostics
+			if !errors.As(config.JoinErrors(err), &diags) {
+				return err
+			}
+			w := hcl.NewDiagnosticTextWriter(c.ErrOrStderr(), sourceFiles(diags), 0, false)
+			if err := w.WriteDiagnostics(diags); err != nil {
+				return err
+			}
+			return fmt.Errorf("configuration is invalid: %d error(s)", len(diags.Errs()))
+		},
+	}
+}
+
+// sourceFiles reads files referenced by diagnostics, so that the source code
+// snippets can be printed along with errors.
+func sourceFiles(diags hcl.Diagnostics) map[string]*hcl.File {
+	files := map[string]*hcl.File{}
+	for _, d := range diags {
+		if d.Subject == nil || d.Subject.Filename == "" {
+			continue
+		}
+		if _, ok := files[d.Subject.Filename]; ok {
+			continue
+		}
+		b, err := os.ReadFile(d.Subject.Filename)
+		if err != nil {
+			continue
+		}
+		files[d.Subject.Filename] = &hcl.File{Bytes: b}
+	}
+	return files
+}
+
+func newRenderCmd(cfg any, paths *[]string) *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "render",
+		Args:  cobra.ExactArgs(0),
+		Short: "Print the effective configuration after includes and variables are expanded",
+		Long:  ``,
+		RunE: func(c *cobra.Command, _ []string) error {
+			b, err := config.RenderFiles(cfg, *paths, format)
+			if err != nil {
+				return err
+			}
+			_, err = c.OutOrStdout().Write(b)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", config.RenderHCL, "output format (hcl|json)")
+	return cmd
+}
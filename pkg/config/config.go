@@ -30,10 +30,15 @@ import (
 
 	utilHCL "github.com/chronicleprotocol/oracle-suite/pkg/util/hcl"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/hcl/ext/include"
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/hcl/ext/secrets"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/hcl/ext/variables"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/hcl/funcs"
 )
 
+// secretResolver resolves secrets for the "secret" function and redacts them
+// from the rendered configuration and logs.
+var secretResolver = secrets.NewResolver()
+
 var hclContext = &hcl.EvalContext{
 	Variables: map[string]cty.Value{
 		"env": getEnvVars(),
@@ -56,32 +61,50 @@ var hclContext = &hcl.EvalContext{
 		"tonumber": funcs.MakeToFunc(cty.Number),
 		"toset":    funcs.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
 		"tostring": funcs.MakeToFunc(cty.String),
+		"secret":   secretResolver.Func(),
 	},
 }
 
+// RedactSecrets replaces secrets resolved by the "secret" function in the
+// given string.
+func RedactSecrets(s string) string {
+	return secretResolver.Redact(s)
+}
+
 // LoadFiles loads the given paths into the given config, merging contents of
 // multiple HCL files specified by the "include" attribute using glob patterns,
 // and expanding dynamic blocks before decoding the HCL content.
 func LoadFiles(config any, paths []string) error {
+	body, err := loadBody(paths)
+	if err != nil {
+		return err
+	}
+	if diags := utilHCL.Decode(hclContext, body, config); diags.HasErrors() {
+		return diags
+	}
+	return nil
+}
+
+// loadBody parses the given paths, merges included files, evaluates
+// variables and returns the body with dynamic blocks expanded, ready to be
+// decoded.
+func loadBody(paths []string) (hcl.Body, error) {
 	var body hcl.Body
 	var diags hcl.Diagnostics
 	wd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
 	}
 	if body, diags = utilHCL.ParseFiles(paths, nil); diags.HasErrors() {
-		return diags
+		return nil, diags
 	}
 	if body, diags = include.Include(hclContext, body, wd, 10); diags.HasErrors() {
-		return diags
+		return nil, diags
 	}
 	if body, diags = variables.Variables(hclContext, body); diags.HasErrors() {
-		return diags
-	}
-	if diags = utilHCL.Decode(hclContext, dynblock.Expand(body, hclContext), config); diags.HasErrors() {
-		return diags
+		return nil, diags
 	}
-	return nil
+	return dynblock.Expand(body, hclContext), nil
 }
 
 // getEnvVars retrieves environment variables from the system and returns
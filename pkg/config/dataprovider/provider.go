@@ -12,7 +12,9 @@ import (
 	"github.com/chronicleprotocol/oracle-suite/pkg/datapoint/origin"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/sliceutil"
 )
 
@@ -20,6 +22,9 @@ type Dependencies struct {
 	HTTPClient *http.Client
 	Clients    ethereum.ClientRegistry
 	Logger     log.Logger
+
+	// Health is an optional registry in which origins report their status.
+	Health *health.Registry
 }
 
 type Config struct {
@@ -47,7 +52,23 @@ func (c *Config) ConfigureDataProvider(d Dependencies) (datapoint.Provider, erro
 	}
 
 	// Configure data provider:
-	return graph.NewProvider(models, graph.NewUpdater(origins, d.Logger)), nil
+	updater := graph.NewUpdater(origins, d.Logger)
+	updater.SetHealth(d.Health)
+	return graph.NewProvider(models, updater), nil
+}
+
+// Validate checks that all origins can be configured and that data models
+// reference only known origins and do not contain cycles.
+func (c *Config) Validate(d Dependencies) error {
+	if d.Logger == nil {
+		d.Logger = null.New()
+	}
+	origins, err := c.configureOrigins(d)
+	if err != nil {
+		return err
+	}
+	_, err = c.configureDataModels(origins)
+	return err
 }
 
 func (c *Config) configureOrigins(d Dependencies) (map[string]origin.Origin, error) {
@@ -0,0 +1,202 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package docs
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	utilHCL "github.com/chronicleprotocol/oracle-suite/pkg/util/hcl"
+)
+
+// comments provides doc comments of struct types and fields declared in
+// the module.
+type comments struct {
+	moduleDir  string
+	modulePath string
+	pkgs       map[string]map[string]*typeComments // Package path -> type name -> comments.
+}
+
+type typeComments struct {
+	doc    string
+	fields map[string]string
+}
+
+func newComments(moduleDir string) (*comments, error) {
+	modulePath, err := readModulePath(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	return &comments{
+		moduleDir:  moduleDir,
+		modulePath: modulePath,
+		pkgs:       map[string]map[string]*typeComments{},
+	}, nil
+}
+
+// typ returns the doc comment of the given struct type decoded from the
+// block with the given HCL name. If the comment starts with the Go name of
+// the type, the name is replaced with the HCL name.
+func (c *comments) typ(t reflect.Type, name string) string {
+	t = derefType(t)
+	tc := c.lookup(t)
+	if tc == nil {
+		return ""
+	}
+	doc := tc.doc
+	if rest, ok := strings.CutPrefix(doc, t.Name()+" "); ok {
+		doc = "`" + name + "` " + rest
+	}
+	return hclNames(doc, t, "")
+}
+
+// field returns the doc comment of the given field of the given struct
+// type. If the comment starts with the Go name of the field, the name is
+// replaced with the HCL name. Go names of other fields of the struct are
+// replaced as well.
+func (c *comments) field(t reflect.Type, f utilHCL.SchemaField) string {
+	// Promoted fields are declared in embedded structs.
+	for _, i := range f.Field.Index[:len(f.Field.Index)-1] {
+		t = derefType(t.Field(i).Type)
+	}
+	tc := c.lookup(t)
+	if tc == nil {
+		return ""
+	}
+	doc := tc.fields[f.Field.Name]
+	if rest, ok := strings.CutPrefix(doc, f.Field.Name+" "); ok {
+		doc = "`" + f.Name + "` " + rest
+	}
+	return hclNames(doc, t, f.Field.Name)
+}
+
+// goIdentifier matches identifiers made of several words, like KeystorePath
+// or CacheTTL, which do not occur in regular text.
+var goIdentifier = regexp.MustCompile(`\b[A-Z][a-z0-9]+[A-Z][A-Za-z0-9]*\b`)
+
+// hclNames replaces Go names of fields of the given struct type that occur
+// in the comment with their HCL names. Only names matched by goIdentifier
+// are replaced, so single words like "Feeds" are left as they are. The name
+// of the documented field is skipped, because it often matches the name of
+// the thing it configures, like StatsD.
+func hclNames(doc string, t reflect.Type, self string) string {
+	schema, diags := utilHCL.StructSchema(t)
+	if diags.HasErrors() {
+		return doc
+	}
+	names := map[string]string{}
+	for _, fields := range [][]utilHCL.SchemaField{schema.Labels, schema.Attributes, schema.Blocks} {
+		for _, f := range fields {
+			names[f.Field.Name] = f.Name
+		}
+	}
+	return goIdentifier.ReplaceAllStringFunc(doc, func(s string) string {
+		if name, ok := names[s]; ok && s != self {
+			return "`" + name + "`"
+		}
+		return s
+	})
+}
+
+func (c *comments) lookup(t reflect.Type) *typeComments {
+	if t.PkgPath() == "" || t.Name() == "" {
+		return nil
+	}
+	types, ok := c.pkgs[t.PkgPath()]
+	if !ok {
+		types = c.parse(t.PkgPath())
+		c.pkgs[t.PkgPath()] = types
+	}
+	return types[t.Name()]
+}
+
+// parse reads doc comments of all struct types in the given package. It
+// returns nil for packages outside the module.
+func (c *comments) parse(pkgPath string) map[string]*typeComments {
+	rel, ok := strings.CutPrefix(pkgPath, c.modulePath)
+	if !ok {
+		return nil
+	}
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, filepath.Join(c.moduleDir, rel), func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+	types := map[string]*typeComments{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					doc := ts.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					tc := &typeComments{doc: text(doc), fields: map[string]string{}}
+					for _, field := range st.Fields.List {
+						fieldDoc := field.Doc
+						if fieldDoc == nil {
+							fieldDoc = field.Comment
+						}
+						for _, name := range field.Names {
+							tc.fields[name.Name] = text(fieldDoc)
+						}
+					}
+					types[ts.Name.Name] = tc
+				}
+			}
+		}
+	}
+	return types
+}
+
+// text returns the comment text as a single paragraph.
+func text(g *ast.CommentGroup) string {
+	return strings.Join(strings.Fields(g.Text()), " ")
+}
+
+func readModulePath(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if p, ok := strings.CutPrefix(strings.TrimSpace(s.Text()), "module "); ok {
+			return strings.TrimSpace(p), nil
+		}
+	}
+	return "", fmt.Errorf("module path not found in %s", path)
+}
@@ -0,0 +1,206 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package docs generates a JSON Schema and a Markdown reference for
+// application configs from the structs to which the configuration files are
+// decoded.
+package docs
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	utilHCL "github.com/chronicleprotocol/oracle-suite/pkg/util/hcl"
+)
+
+// Generator generates documentation for config structs. Descriptions are
+// taken from doc comments of struct types and fields, which are read from
+// the source code of the module.
+type Generator struct {
+	comments *comments
+}
+
+// NewGenerator returns a new Generator that reads the source code of the
+// module located in the given directory.
+func NewGenerator(moduleDir string) (*Generator, error) {
+	c, err := newComments(moduleDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{comments: c}, nil
+}
+
+// block is a node of the config tree built from the config structs.
+type block struct {
+	name        string
+	path        string
+	description string
+	labels      []string
+	optional    bool
+	multiple    bool
+	remain      bool
+	typ         reflect.Type
+	attributes  []attribute
+	blocks      []*block
+	recursive   *block // Set if the block has the same type as one of its parents.
+}
+
+type attribute struct {
+	name        string
+	description string
+	typ         valueType
+	optional    bool
+}
+
+// valueType describes the type of attribute value.
+type valueType struct {
+	kind string     // One of: string, number, integer, bool, list, map, any.
+	elem *valueType // Element type of lists and maps.
+}
+
+// String returns the type in the HCL type constraint syntax.
+func (t valueType) String() string {
+	switch t.kind {
+	case "list", "map":
+		return fmt.Sprintf("%s(%s)", t.kind, t.elem)
+	case "integer":
+		return "number"
+	}
+	return t.kind
+}
+
+// tree builds the config tree for the given config struct.
+func (g *Generator) tree(config any) (*block, error) {
+	root := &block{typ: reflect.TypeOf(config)}
+	if err := g.fill(root, nil); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (g *Generator) fill(b *block, parents []*block) error {
+	for _, p := range parents {
+		if p.typ == b.typ {
+			b.recursive = p
+			return nil
+		}
+	}
+	schema, diags := utilHCL.StructSchema(b.typ)
+	if diags.HasErrors() {
+		return diags
+	}
+	typ := derefType(b.typ)
+	b.remain = schema.Remain
+	for _, l := range schema.Labels {
+		b.labels = append(b.labels, l.Name)
+	}
+	for _, a := range schema.Attributes {
+		b.attributes = append(b.attributes, attribute{
+			name:        a.Name,
+			description: g.comments.field(typ, a),
+			typ:         typeOf(a.Type),
+			optional:    a.Optional,
+		})
+	}
+	for _, s := range schema.Blocks {
+		path := s.Name
+		if b.path != "" {
+			path = b.path + "." + s.Name
+		}
+		desc := g.comments.field(typ, s)
+		if desc == "" {
+			desc = g.comments.typ(s.Type, s.Name)
+		}
+		child := &block{
+			name:        s.Name,
+			path:        path,
+			description: desc,
+			optional:    s.Optional,
+			multiple:    s.Multiple,
+			typ:         s.Type,
+		}
+		if err := g.fill(child, append(parents, b)); err != nil {
+			return err
+		}
+		b.blocks = append(b.blocks, child)
+	}
+	return nil
+}
+
+var (
+	textUnmarshalerTy = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	unmarshalerTy     = reflect.TypeOf((*utilHCL.Unmarshaler)(nil)).Elem()
+	ctyValueTy        = reflect.TypeOf(cty.Value{})
+	bigIntTy          = reflect.TypeOf(big.Int{})
+	bigFloatTy        = reflect.TypeOf(big.Float{})
+)
+
+// knownTypes maps types that implement the Unmarshaler interface to the
+// type of value they accept, because it cannot be inferred from the type.
+var knownTypes = map[reflect.Type]valueType{
+	reflect.TypeOf(config.URL{}): {kind: "string"},
+}
+
+// typeOf returns the type of attribute value that can be decoded into the
+// given Go type.
+func typeOf(t reflect.Type) valueType {
+	t = derefType(t)
+	ptr := reflect.PtrTo(t)
+	if vt, ok := knownTypes[t]; ok {
+		return vt
+	}
+	switch {
+	case t == ctyValueTy:
+		return valueType{kind: "any"}
+	case t == bigIntTy:
+		return valueType{kind: "integer"}
+	case t == bigFloatTy:
+		return valueType{kind: "number"}
+	case ptr.Implements(unmarshalerTy) && t.Kind() != reflect.String:
+		return valueType{kind: "any"}
+	case ptr.Implements(textUnmarshalerTy):
+		return valueType{kind: "string"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return valueType{kind: "string"}
+	case reflect.Bool:
+		return valueType{kind: "bool"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return valueType{kind: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return valueType{kind: "number"}
+	case reflect.Slice, reflect.Array:
+		elem := typeOf(t.Elem())
+		return valueType{kind: "list", elem: &elem}
+	case reflect.Map:
+		elem := typeOf(t.Elem())
+		return valueType{kind: "map", elem: &elem}
+	}
+	return valueType{kind: "any"}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
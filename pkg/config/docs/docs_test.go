@@ -0,0 +1,151 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package docs
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/ghost"
+	ghostnext "github.com/chronicleprotocol/oracle-suite/pkg/config/ghostnext"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/gofer"
+	gofernext "github.com/chronicleprotocol/oracle-suite/pkg/config/gofernext"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/lair"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/leeloo"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/spectre"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/spire"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/spirebootstrap"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config/toolbox"
+	utilHCL "github.com/chronicleprotocol/oracle-suite/pkg/util/hcl"
+)
+
+var update = flag.Bool("update", false, "update the generated config documentation")
+
+const (
+	moduleDir = "../../.."
+	docsDir   = "../../../docs/config"
+)
+
+var apps = []struct {
+	name   string
+	config any
+}{
+	{name: "ghost", config: &ghost.Config{}},
+	{name: "ghostnext", config: &ghostnext.Config{}},
+	{name: "gofer", config: &gofer.Config{}},
+	{name: "gofernext", config: &gofernext.Config{}},
+	{name: "lair", config: &lair.Config{}},
+	{name: "leeloo", config: &leeloo.Config{}},
+	{name: "spectre", config: &spectre.Config{}},
+	{name: "spire", config: &spire.Config{}},
+	{name: "spire-bootstrap", config: &spirebootstrap.Config{}},
+	{name: "toolbox", config: &toolbox.Config{}},
+}
+
+// TestGenerated fails if the generated config documentation is stale. To
+// update it, run: go test ./pkg/config/docs -update
+func TestGenerated(t *testing.T) {
+	g, err := NewGenerator(moduleDir)
+	require.NoError(t, err)
+	if *update {
+		require.NoError(t, os.MkdirAll(docsDir, 0755))
+	}
+	for _, app := range apps {
+		t.Run(app.name, func(t *testing.T) {
+			schema, err := g.JSONSchema(app.name, app.config)
+			require.NoError(t, err)
+			markdown, err := g.Markdown(app.name, app.config)
+			require.NoError(t, err)
+			for path, got := range map[string][]byte{
+				filepath.Join(docsDir, app.name+".schema.json"): schema,
+				filepath.Join(docsDir, app.name+".md"):          markdown,
+			} {
+				if *update {
+					require.NoError(t, os.WriteFile(path, got, 0644)) //nolint:gosec
+					continue
+				}
+				want, err := os.ReadFile(path)
+				require.NoError(t, err, "run: go test ./pkg/config/docs -update")
+				assert.Equal(t, string(want), string(got), "%s is stale, run: go test ./pkg/config/docs -update", path)
+			}
+		})
+	}
+}
+
+// TestGenerated_GoNames fails if descriptions in the generated config
+// documentation start with Go names of types or fields, or mention names
+// of Go types, instead of using HCL names.
+func TestGenerated_GoNames(t *testing.T) {
+	g, err := NewGenerator(moduleDir)
+	require.NoError(t, err)
+	for _, app := range apps {
+		t.Run(app.name, func(t *testing.T) {
+			root, err := g.tree(app.config)
+			require.NoError(t, err)
+			typeNames := map[string]bool{}
+			collectTypeNames(root, typeNames)
+			var check func(b *block)
+			check = func(b *block) {
+				if b.recursive != nil {
+					return
+				}
+				schema, diags := utilHCL.StructSchema(b.typ)
+				require.False(t, diags.HasErrors())
+				fieldNames := map[string]bool{}
+				for _, f := range append(schema.Attributes, schema.Blocks...) {
+					fieldNames[f.Field.Name] = true
+				}
+				descriptions := map[string]string{}
+				for _, a := range b.attributes {
+					descriptions[a.name] = a.description
+				}
+				for _, c := range b.blocks {
+					descriptions[c.path] = c.description
+					check(c)
+				}
+				for name, desc := range descriptions {
+					first, _, _ := strings.Cut(desc, " ")
+					assert.False(t, fieldNames[first] || typeNames[first], "description of %s starts with a Go name: %s", name, desc)
+					for _, word := range strings.FieldsFunc(desc, func(r rune) bool {
+						return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+					}) {
+						assert.False(t, typeNames[word], "description of %s mentions the Go type %s: %s", name, word, desc)
+					}
+				}
+			}
+			check(root)
+		})
+	}
+}
+
+// collectTypeNames collects names of struct types of the block and its
+// descendants. Lower case names, like "attestation", are skipped, because
+// they cannot be told apart from regular words.
+func collectTypeNames(b *block, names map[string]bool) {
+	if name := derefType(b.typ).Name(); name != "" && name != strings.ToLower(name) {
+		names[name] = true
+	}
+	for _, c := range b.blocks {
+		collectTypeNames(c, names)
+	}
+}
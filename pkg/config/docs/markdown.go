@@ -0,0 +1,117 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Markdown returns a Markdown reference of the configuration that is decoded
+// into the given config struct. Every block is described in a separate
+// section, in the order in which blocks are declared in the config structs.
+func (g *Generator) Markdown(name string, config any) ([]byte, error) {
+	root, err := g.tree(config)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<!-- Code generated by the pkg/config/docs package. DO NOT EDIT. -->\n\n")
+	fmt.Fprintf(&buf, "# %s configuration reference\n\n", name)
+	fmt.Fprintf(&buf, "This reference is generated from the structs to which the configuration is decoded. ")
+	fmt.Fprintf(&buf, "A JSON Schema of the configuration is available in the `%s.schema.json` file.\n", name)
+	if len(root.attributes) > 0 {
+		buf.WriteString("\n")
+		writeAttributes(&buf, root.attributes)
+	}
+	for _, b := range root.blocks {
+		writeBlock(&buf, b)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeBlock(buf *bytes.Buffer, b *block) {
+	fmt.Fprintf(buf, "\n## `%s`\n\n", b.path)
+	if b.description != "" {
+		fmt.Fprintf(buf, "%s\n\n", b.description)
+	}
+	var props []string
+	switch {
+	case b.multiple:
+		props = append(props, "Can be repeated.")
+	case b.optional:
+		props = append(props, "Optional.")
+	default:
+		props = append(props, "Required.")
+	}
+	labels := b.labels
+	if b.recursive != nil {
+		labels = b.recursive.labels
+	}
+	if len(labels) > 0 {
+		props = append(props, fmt.Sprintf("Labels: %s.", quoteList(labels)))
+	}
+	fmt.Fprintf(buf, "%s\n", strings.Join(props, " "))
+	if b.recursive != nil {
+		fmt.Fprintf(buf, "\nThe block has the same structure as the `%s` block.\n", b.recursive.path)
+		return
+	}
+	if b.remain {
+		fmt.Fprintf(buf, "\nThe block accepts additional attributes and blocks that are not listed here.\n")
+	}
+	if len(b.attributes) > 0 {
+		buf.WriteString("\n")
+		writeAttributes(buf, b.attributes)
+	}
+	if len(b.blocks) > 0 {
+		names := make([]string, len(b.blocks))
+		for i, c := range b.blocks {
+			names[i] = c.path
+		}
+		fmt.Fprintf(buf, "\nNested blocks: %s.\n", quoteList(names))
+	}
+	for _, c := range b.blocks {
+		writeBlock(buf, c)
+	}
+}
+
+func writeAttributes(buf *bytes.Buffer, attrs []attribute) {
+	buf.WriteString("| Attribute | Type | Required | Description |\n")
+	buf.WriteString("|-----------|------|----------|-------------|\n")
+	for _, a := range attrs {
+		required := "yes"
+		if a.optional {
+			required = "no"
+		}
+		fmt.Fprintf(
+			buf,
+			"| `%s` | `%s` | %s | %s |\n",
+			a.name,
+			a.typ,
+			required,
+			strings.ReplaceAll(a.description, "|", `\|`),
+		)
+	}
+}
+
+func quoteList(s []string) string {
+	q := make([]string, len(s))
+	for i, v := range s {
+		q[i] = "`" + v + "`"
+	}
+	return strings.Join(q, ", ")
+}
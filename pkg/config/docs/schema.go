@@ -0,0 +1,136 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema of the configuration that is decoded into
+// the given config struct. The schema describes the HCL JSON syntax, in
+// which labeled blocks are objects keyed by labels and repeated blocks may
+// be given as arrays, as in the output of the "config render" command.
+func (g *Generator) JSONSchema(name string, config any) ([]byte, error) {
+	root, err := g.tree(config)
+	if err != nil {
+		return nil, err
+	}
+	targets := map[*block]bool{}
+	collectRecursive(root, targets)
+	defs := map[string]any{}
+	schema := bodySchema(root, defs, targets)
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = fmt.Sprintf("%s configuration", name)
+	if len(defs) > 0 {
+		schema["$defs"] = defs
+	}
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// collectRecursive collects blocks that are referenced by recursive blocks.
+// These blocks are added to schema definitions, so they can be referenced.
+func collectRecursive(b *block, targets map[*block]bool) {
+	if b.recursive != nil {
+		targets[b.recursive] = true
+	}
+	for _, c := range b.blocks {
+		collectRecursive(c, targets)
+	}
+}
+
+func bodySchema(b *block, defs map[string]any, targets map[*block]bool) map[string]any {
+	if b.recursive != nil {
+		return map[string]any{"$ref": "#/$defs/" + b.recursive.path}
+	}
+	props := map[string]any{}
+	required := []string{}
+	for _, a := range b.attributes {
+		s := valueSchema(a.typ)
+		if a.description != "" {
+			s["description"] = a.description
+		}
+		props[a.name] = s
+		if !a.optional {
+			required = append(required, a.name)
+		}
+	}
+	for _, c := range b.blocks {
+		props[c.name] = blockSchema(c, defs, targets)
+		if !c.optional && !c.multiple {
+			required = append(required, c.name)
+		}
+	}
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": b.remain,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	if targets[b] {
+		defs[b.path] = s
+		return map[string]any{"$ref": "#/$defs/" + b.path}
+	}
+	return s
+}
+
+// blockSchema returns the schema of a block value. For labeled blocks, the
+// body is nested in objects keyed by labels.
+func blockSchema(b *block, defs map[string]any, targets map[*block]bool) map[string]any {
+	s := bodySchema(b, defs, targets)
+	labels := b.labels
+	if b.recursive != nil {
+		labels = b.recursive.labels
+	}
+	for i := len(labels) - 1; i >= 0; i-- {
+		s = map[string]any{
+			"type":                 "object",
+			"description":          fmt.Sprintf("Blocks keyed by %s.", labels[i]),
+			"additionalProperties": s,
+		}
+	}
+	if b.multiple || len(labels) > 0 {
+		s = map[string]any{
+			"anyOf": []any{s, map[string]any{"type": "array", "items": s}},
+		}
+	}
+	if b.description != "" {
+		s["description"] = b.description
+	}
+	return s
+}
+
+func valueSchema(t valueType) map[string]any {
+	switch t.kind {
+	case "string", "number", "integer":
+		return map[string]any{"type": t.kind}
+	case "bool":
+		return map[string]any{"type": "boolean"}
+	case "list":
+		return map[string]any{"type": "array", "items": valueSchema(*t.elem)}
+	case "map":
+		return map[string]any{"type": "object", "additionalProperties": valueSchema(*t.elem)}
+	}
+	return map[string]any{}
+}
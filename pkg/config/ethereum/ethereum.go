@@ -16,6 +16,7 @@
 package ethereum
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -30,6 +31,8 @@ import (
 	"github.com/hashicorp/hcl/v2"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/remotesigner"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/rpcsplitter"
 )
@@ -38,6 +41,7 @@ const (
 	splitterVirtualHost    = "rpc-splitter"
 	defaultTotalTimeout    = 10
 	defaultGracefulTimeout = 1
+	defaultKeySetOverlap   = 600
 )
 
 type (
@@ -58,6 +62,9 @@ type Config struct {
 	// RandKeys is a list of random keys.
 	RandKeys []string `hcl:"rand_keys,optional"`
 
+	// KeySets is a list of key sets that allow to rotate keys at runtime.
+	KeySets []ConfigKeySet `hcl:"key_set,block"`
+
 	// Clients is a list of Ethereum clients.
 	Clients []ConfigClient `hcl:"client,block"`
 
@@ -79,20 +86,80 @@ type ConfigKey struct {
 	// Address is the address of the key in hex format.
 	Address types.Address `hcl:"address"`
 
-	// KeystorePath is the path to the keystore directory.
-	KeystorePath string `hcl:"keystore_path"`
+	// KeystorePath is the path to the keystore directory. It cannot be set
+	// together with RemoteSigner, but one of them must be set.
+	KeystorePath string `hcl:"keystore_path,optional"`
 
 	// PassphraseFile is the path to the file containing the passphrase for the
 	// key. If empty, then the passphrase is not provided.
 	PassphraseFile string `hcl:"passphrase_file,optional"`
 
+	// Passphrase is the passphrase for the key. It is meant to be used with
+	// the secret function. It cannot be set together with PassphraseFile.
+	Passphrase string `hcl:"passphrase,optional"`
+
+	// RemoteSigner is the configuration of a remote signer that holds
+	// the key. It cannot be set together with KeystorePath, but one of them
+	// must be set.
+	RemoteSigner *ConfigRemoteSigner `hcl:"remote_signer,block,optional"`
+
 	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
 	Content hcl.BodyContent `hcl:",content"`
 
 	// Configured key:
 	key wallet.Key
 }
 
+// ConfigKeySet contains the configuration for a set of Ethereum keys, one
+// of which is used for signing. The active key can be changed at runtime.
+type ConfigKeySet struct {
+	// Name is the unique name of the key set that can be referenced by
+	// other services in the same way as a regular key.
+	Name string `hcl:"name,label"`
+
+	// Keys is a list of names of keys that belong to the set. Keys held by
+	// remote signers cannot be mixed with local keys.
+	Keys []string `hcl:"keys"`
+
+	// ActiveKey is the name of the key that is used for signing on startup.
+	ActiveKey string `hcl:"active_key"`
+
+	// Overlap is the time, in seconds, during which signatures made by
+	// the previously active key are still accepted after a rotation. If
+	// zero, the default overlap of 10 minutes is used.
+	Overlap uint32 `hcl:"overlap,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+// ConfigRemoteSigner contains the configuration for a remote signer.
+type ConfigRemoteSigner struct {
+	// URL is the base URL of a remote signer that implements the Web3Signer
+	// Eth1 signing API.
+	URL config.URL `hcl:"url"`
+
+	// Identifier identifies the key in the signing endpoint path. Web3Signer
+	// identifies keys by their public keys. If empty, the address of the
+	// key is used.
+	Identifier string `hcl:"identifier,optional"`
+
+	// AuthTokenFile is the path to the file containing a token that is sent
+	// to the remote signer as a bearer token. If empty, then the token is
+	// not sent.
+	AuthTokenFile string `hcl:"auth_token_file,optional"`
+
+	// Timeout is the timeout for signing requests, in seconds. If zero,
+	// the default timeout of 10 seconds is used.
+	Timeout uint32 `hcl:"timeout,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
 // ConfigClient contains the configuration for an Ethereum client.
 type ConfigClient struct {
 	// Name is the unique name of the client that can be referenced by other
@@ -115,8 +182,8 @@ type ConfigClient struct {
 
 	// MaxBlocksBehind is the maximum number of blocks behind the node with the
 	// highest block number can be. RPC-Splitter will use the lowest block number
-	// from all nodes that is not more than MaxBlocksBehind behind the highest
-	// block number.
+	// from all nodes that is not more than this number of blocks behind the
+	// highest block number.
 	MaxBlocksBehind uint64 `hcl:"max_blocks_behind,optional"`
 
 	// Key configuration:
@@ -147,6 +214,26 @@ func (c *Config) KeyRegistry(d Dependencies) (KeyRegistry, error) {
 	return c.keys, nil
 }
 
+// Validate checks the configuration of keys, key sets and clients without
+// opening keystores, so passphrases are not needed. It returns registries
+// that can be used to validate the configuration of other services. Keys
+// from keystores in the returned registry have the configured address but
+// cannot sign.
+func (c *Config) Validate(d Dependencies) (KeyRegistry, ClientRegistry, error) {
+	if c == nil {
+		return nil, nil, nil
+	}
+	keys, err := c.keyRegistry(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	clients, err := c.clientRegistry(d.Logger, keys, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys, clients, nil
+}
+
 // ClientRegistry returns the list of configured Ethereum clients.
 func (c *Config) ClientRegistry(d Dependencies) (ClientRegistry, error) {
 	if c == nil {
@@ -158,6 +245,67 @@ func (c *Config) ClientRegistry(d Dependencies) (ClientRegistry, error) {
 	return c.clients, nil
 }
 
+// KeySetPaths returns configuration paths of the active keys of the key
+// sets, which can be changed at runtime using the ReloadKeySets method. The
+// prefix is the path of the ethereum block.
+func (c *Config) KeySetPaths(prefix string) []string {
+	if c == nil {
+		return nil
+	}
+	paths := make([]string, len(c.KeySets))
+	for i, setCfg := range c.KeySets {
+		paths[i] = prefix + ".key_set." + setCfg.Name + ".active_key"
+	}
+	return paths
+}
+
+// ReloadKeySets verifies that the active keys from the next configuration
+// belong to the running key sets and returns a function that rotates the
+// key sets to them. The previously active keys are accepted for the overlap
+// window of the key sets. The KeyRegistry method must be called first.
+func (c *Config) ReloadKeySets(next *Config) (func(), error) {
+	if c == nil || !c.prepared {
+		return nil, errors.New("Ethereum keys are not configured")
+	}
+	rotations := make(map[*keyset.KeySet]types.Address, len(next.KeySets))
+	for _, setCfg := range next.KeySets {
+		ks, ok := c.keys[setCfg.Name].(*keyset.KeySet)
+		if !ok {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Runtime error",
+				Detail:   fmt.Sprintf("Key set %q is not running", setCfg.Name),
+				Subject:  setCfg.Range.Ptr(),
+			}
+		}
+		key, ok := c.keys[setCfg.ActiveKey]
+		if !ok {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Ethereum key %q is not configured", setCfg.ActiveKey),
+				Subject:  setCfg.Content.Attributes["active_key"].Range.Ptr(),
+			}
+		}
+		if !ks.Has(key.Address()) {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Runtime error",
+				Detail:   fmt.Sprintf("Failed to rotate key set %q: key %s is not in the key set", setCfg.Name, key.Address()),
+				Subject:  setCfg.Content.Attributes["active_key"].Range.Ptr(),
+			}
+		}
+		rotations[ks] = key.Address()
+	}
+	return func() {
+		for ks, addr := range rotations {
+			// Rotate fails only if the key is not in the set, which is
+			// checked above.
+			_ = ks.Rotate(addr, 0)
+		}
+	}, nil
+}
+
 func (c *Config) prepare(d Dependencies) error {
 	if c.prepared {
 		return nil
@@ -173,20 +321,39 @@ func (c *Config) prepare(d Dependencies) error {
 }
 
 func (c *Config) prepareKeys() error {
+	keys, err := c.keyRegistry(true)
+	if err != nil {
+		return err
+	}
+	c.keys = keys
+	return nil
+}
+
+// keyRegistry creates the configured keys and key sets. If open is false,
+// keystores are not opened and keys from them are replaced by stubs.
+func (c *Config) keyRegistry(open bool) (KeyRegistry, error) {
 	// Keys from the keystore.
-	c.keys = make(map[string]wallet.Key)
+	keys := make(map[string]wallet.Key)
 	for _, keyCfg := range c.Keys {
-		key, err := keyCfg.Key()
+		var (
+			key wallet.Key
+			err error
+		)
+		if open {
+			key, err = keyCfg.Key()
+		} else {
+			key, err = keyCfg.stub()
+		}
 		if err != nil {
-			return err
+			return nil, err
 		}
-		c.keys[keyCfg.Name] = key
+		keys[keyCfg.Name] = key
 	}
 
 	// Random keys.
 	for _, name := range c.RandKeys {
 		if len(name) == 0 {
-			return &hcl.Diagnostic{
+			return nil, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Validation error",
 				Detail:   "Random key name must not be empty",
@@ -194,44 +361,148 @@ func (c *Config) prepareKeys() error {
 			}
 		}
 		if !nameRegexp.MatchString(name) {
-			return &hcl.Diagnostic{
+			return nil, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Validation error",
 				Detail:   "Random key name must contain only alphanumeric characters and underscores",
 				Subject:  c.Content.Attributes["rand_keys"].Range.Ptr(),
 			}
 		}
-		if _, ok := c.keys[name]; ok {
-			return &hcl.Diagnostic{
+		if _, ok := keys[name]; ok {
+			return nil, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Validation error",
 				Detail:   fmt.Sprintf("Key with name %q already exists", name),
 				Subject:  c.Content.Attributes["rand_keys"].Range.Ptr(),
 			}
 		}
-		c.keys[name] = wallet.NewRandomKey()
+		keys[name] = wallet.NewRandomKey()
 	}
-	return nil
+
+	// Key sets.
+	for _, setCfg := range c.KeySets {
+		if _, ok := keys[setCfg.Name]; ok {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Key with name %q already exists", setCfg.Name),
+				Subject:  setCfg.Range.Ptr(),
+			}
+		}
+		ks, err := setCfg.KeySet(keys)
+		if err != nil {
+			return nil, err
+		}
+		keys[setCfg.Name] = ks
+	}
+	return keys, nil
+}
+
+// KeySet returns a key set that consists of the keys from the given
+// registry.
+func (c *ConfigKeySet) KeySet(keys KeyRegistry) (*keyset.KeySet, error) {
+	var (
+		set    []wallet.Key
+		active types.Address
+		remote int
+	)
+	for _, name := range c.Keys {
+		key, ok := keys[name]
+		if !ok {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Ethereum key %q is not configured", name),
+				Subject:  c.Content.Attributes["keys"].Range.Ptr(),
+			}
+		}
+		if _, ok := key.(*keyset.KeySet); ok {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Key set cannot contain another key set %q", name),
+				Subject:  c.Content.Attributes["keys"].Range.Ptr(),
+			}
+		}
+		if _, ok := key.(*remotesigner.Key); ok {
+			remote++
+		}
+		if name == c.ActiveKey {
+			active = key.Address()
+		}
+		set = append(set, key)
+	}
+	// Remote signers cannot sign raw hashes, so a key set that mixes them
+	// with local keys would stop supporting SignHash after a rotation.
+	if remote > 0 && remote < len(set) {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Key set cannot mix keys held by remote signers with local keys",
+			Subject:  c.Content.Attributes["keys"].Range.Ptr(),
+		}
+	}
+	if active == types.ZeroAddress {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Active key %q is not one of the keys in the key set", c.ActiveKey),
+			Subject:  c.Content.Attributes["active_key"].Range.Ptr(),
+		}
+	}
+	overlap := c.Overlap
+	if overlap == 0 {
+		overlap = defaultKeySetOverlap
+	}
+	ks, err := keyset.New(set, active, time.Duration(overlap)*time.Second)
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Runtime error",
+			Detail:   fmt.Sprintf("Failed to create key set: %v", err),
+			Subject:  c.Range.Ptr(),
+		}
+	}
+	return ks, nil
 }
 
 func (c *Config) prepareClients(logger log.Logger) error {
-	c.clients = make(map[string]rpc.RPC)
+	clients, err := c.clientRegistry(logger, c.keys, true)
+	if err != nil {
+		return err
+	}
+	c.clients = clients
+	return nil
+}
+
+// clientRegistry creates the configured clients using the given keys. If
+// cache is false, clients are created even if they were created before.
+func (c *Config) clientRegistry(logger log.Logger, keys KeyRegistry, cache bool) (ClientRegistry, error) {
+	clients := make(map[string]rpc.RPC)
 	for _, clientCfg := range c.Clients {
-		if _, ok := c.clients[clientCfg.Name]; ok {
-			return &hcl.Diagnostic{
+		if _, ok := clients[clientCfg.Name]; ok {
+			return nil, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Validation error",
 				Detail:   fmt.Sprintf("Client with name %q already exists", clientCfg.Name),
 				Subject:  clientCfg.Range.Ptr(),
 			}
 		}
-		client, err := clientCfg.Client(logger, c.keys)
+		var (
+			client rpc.RPC
+			err    error
+		)
+		if cache {
+			client, err = clientCfg.Client(logger, keys)
+		} else {
+			client, err = clientCfg.newClient(logger, keys)
+		}
 		if err != nil {
-			return err
+			return nil, err
 		}
-		c.clients[clientCfg.Name] = client
+		clients[clientCfg.Name] = client
 	}
-	return nil
+	return clients, nil
 }
 
 // Key returns the configured Ethereum key.
@@ -242,10 +513,63 @@ func (c *ConfigKey) Key() (wallet.Key, error) {
 	if c.key != nil {
 		return c.key, nil
 	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
 
-	// Validate the key configuration.
-	if len(c.Name) == 0 {
+	// Create key.
+	if c.RemoteSigner != nil {
+		key, err := c.RemoteSigner.key(c.Address)
+		if err != nil {
+			return nil, err
+		}
+		c.key = key
+		return key, nil
+	}
+	passphrase, err := c.passphrase()
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Failed to read Ethereum key passphrase: %v", err),
+			Subject:  c.Content.Attributes["passphrase_file"].Range.Ptr(),
+		}
+	}
+	key, err := wallet.NewKeyFromDirectory(c.KeystorePath, passphrase, c.Address)
+	if err != nil {
 		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Failed to read Ethereum key: %v", err),
+			Subject:  c.Content.Attributes["keystore_path"].Range.Ptr(),
+		}
+	}
+
+	c.key = key
+	return key, nil
+}
+
+// stub validates the key configuration and returns a key that has the
+// configured address but cannot sign. Keystores are not opened. Keys held
+// by remote signers are returned as they are, because creating them does
+// not require secrets.
+func (c *ConfigKey) stub() (wallet.Key, error) {
+	if c == nil {
+		return nil, fmt.Errorf("ethereum config: key is not configured")
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	if c.RemoteSigner != nil {
+		return c.RemoteSigner.key(c.Address)
+	}
+	return stubKey{address: c.Address}, nil
+}
+
+// validate checks the key configuration.
+func (c *ConfigKey) validate() error {
+	if len(c.Name) == 0 {
+		return &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Validation error",
 			Detail:   "Ethereum key name is required",
@@ -253,35 +577,64 @@ func (c *ConfigKey) Key() (wallet.Key, error) {
 		}
 	}
 	if !nameRegexp.MatchString(c.Name) {
-		return nil, &hcl.Diagnostic{
+		return &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Validation error",
 			Detail:   "Ethereum key name must contain only alphanumeric characters and underscores",
 			Subject:  c.Content.Attributes["name"].Range.Ptr(),
 		}
 	}
-
-	// Create key.
-	passphrase, err := readAccountPassphrase(c.PassphraseFile)
-	if err != nil {
-		return nil, &hcl.Diagnostic{
+	hasKeystore := c.KeystorePath != ""
+	hasRemoteSigner := c.RemoteSigner != nil
+	if hasKeystore == hasRemoteSigner {
+		return &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Validation error",
-			Detail:   fmt.Sprintf("Failed to read Ethereum key passphrase: %v", err),
-			Subject:  c.Content.Attributes["passphrase_file"].Range.Ptr(),
+			Detail:   "Exactly one of keystore_path or remote_signer must be set",
+			Subject:  c.Range.Ptr(),
 		}
 	}
-	key, err := wallet.NewKeyFromDirectory(c.KeystorePath, passphrase, c.Address)
+	if c.Passphrase != "" && c.PassphraseFile != "" {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Only one of passphrase or passphrase_file can be set",
+			Subject:  c.Range.Ptr(),
+		}
+	}
+	return nil
+}
+
+// key returns a key that delegates signing to the remote signer.
+func (c *ConfigRemoteSigner) key(address types.Address) (wallet.Key, error) {
+	var token string
+	if c.AuthTokenFile != "" {
+		b, err := os.ReadFile(c.AuthTokenFile)
+		if err != nil {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Failed to read remote signer auth token: %v", err),
+				Subject:  c.Content.Attributes["auth_token_file"].Range.Ptr(),
+			}
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	key, err := remotesigner.New(remotesigner.Config{
+		URL:        c.URL.String(),
+		Address:    address,
+		Identifier: c.Identifier,
+		AuthToken:  token,
+		Timeout:    time.Second * time.Duration(c.Timeout),
+	})
 	if err != nil {
 		return nil, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Validation error",
-			Detail:   fmt.Sprintf("Failed to read Ethereum key: %v", err),
-			Subject:  c.Content.Attributes["keystore_path"].Range.Ptr(),
+			Detail:   fmt.Sprintf("Failed to create remote signer: %v", err),
+			Subject:  c.Range.Ptr(),
 		}
 	}
-
-	c.key = key
 	return key, nil
 }
 
@@ -293,7 +646,17 @@ func (c *ConfigClient) Client(logger log.Logger, keys KeyRegistry) (rpc.RPC, err
 	if c.client != nil {
 		return c.client, nil
 	}
+	client, err := c.newClient(logger, keys)
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+	return client, nil
+}
 
+// newClient validates the client configuration and creates a new RPC
+// client.
+func (c *ConfigClient) newClient(logger log.Logger, keys KeyRegistry) (rpc.RPC, error) {
 	// Validate the client configuration.
 	if len(c.Name) == 0 {
 		return nil, &hcl.Diagnostic{
@@ -376,8 +739,6 @@ func (c *ConfigClient) Client(logger log.Logger, keys KeyRegistry) (rpc.RPC, err
 			Subject:  c.Range.Ptr(),
 		}
 	}
-
-	c.client = client
 	return client, nil
 }
 
@@ -420,6 +781,15 @@ func (c *ConfigClient) transport(logger log.Logger) (transport.Transport, error)
 	return rpcTransport, nil
 }
 
+// passphrase returns the passphrase from the passphrase attribute or, if it
+// is not set, from the passphrase file.
+func (c *ConfigKey) passphrase() (string, error) {
+	if c.Passphrase != "" {
+		return c.Passphrase, nil
+	}
+	return readAccountPassphrase(c.PassphraseFile)
+}
+
 func readAccountPassphrase(path string) (string, error) {
 	if path == "" {
 		return "", nil
@@ -439,3 +809,41 @@ func minimumRequiredResponses(endpoints int) int {
 }
 
 var nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// errStubKey is returned by stubKey methods that require a private key.
+var errStubKey = errors.New("key was not opened during validation")
+
+// stubKey stands in for a key from a keystore during validation.
+type stubKey struct {
+	address types.Address
+}
+
+// Address implements the wallet.Key interface.
+func (k stubKey) Address() types.Address {
+	return k.address
+}
+
+// SignHash implements the wallet.Key interface.
+func (k stubKey) SignHash(types.Hash) (*types.Signature, error) {
+	return nil, errStubKey
+}
+
+// SignMessage implements the wallet.Key interface.
+func (k stubKey) SignMessage([]byte) (*types.Signature, error) {
+	return nil, errStubKey
+}
+
+// SignTransaction implements the wallet.Key interface.
+func (k stubKey) SignTransaction(*types.Transaction) error {
+	return errStubKey
+}
+
+// VerifyHash implements the wallet.Key interface.
+func (k stubKey) VerifyHash(types.Hash, types.Signature) bool {
+	return false
+}
+
+// VerifyMessage implements the wallet.Key interface.
+func (k stubKey) VerifyMessage([]byte, types.Signature) bool {
+	return false
+}
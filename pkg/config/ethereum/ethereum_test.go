@@ -7,6 +7,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/remotesigner"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 )
 
@@ -30,6 +32,18 @@ func TestConfig(t *testing.T) {
 				assert.Equal(t, "./testdata/keystore", cfg.Keys[1].KeystorePath)
 				assert.Equal(t, "./testdata/keystore/passphrase", cfg.Keys[1].PassphraseFile)
 
+				assert.Equal(t, "key3", cfg.Keys[2].Name)
+				assert.Equal(t, "0x1234567890123456789012345678901234567890", cfg.Keys[2].Address.String())
+				assert.Equal(t, "http://localhost:9000", cfg.Keys[2].RemoteSigner.URL.String())
+				assert.Equal(t, "0x04abcdef", cfg.Keys[2].RemoteSigner.Identifier)
+				assert.Equal(t, "./testdata/keystore/passphrase", cfg.Keys[2].RemoteSigner.AuthTokenFile)
+				assert.Equal(t, uint32(5), cfg.Keys[2].RemoteSigner.Timeout)
+
+				assert.Equal(t, "key_set1", cfg.KeySets[0].Name)
+				assert.Equal(t, []string{"key1", "key2"}, cfg.KeySets[0].Keys)
+				assert.Equal(t, "key2", cfg.KeySets[0].ActiveKey)
+				assert.Equal(t, uint32(600), cfg.KeySets[0].Overlap)
+
 				assert.Equal(t, "client1", cfg.Clients[0].Name)
 				assert.Equal(t, "https://rpc1.example", cfg.Clients[0].RPCURLs[0].String())
 				assert.Equal(t, uint64(1), cfg.Clients[0].ChainID)
@@ -51,10 +65,15 @@ func TestConfig(t *testing.T) {
 				keys, diags := cfg.KeyRegistry(Dependencies{Logger: null.New()})
 				require.NoError(t, diags)
 
-				require.Len(t, keys, 3)
+				require.Len(t, keys, 5)
 				assert.NotNil(t, keys["rand_key"])
 				assert.Equal(t, "0xd18d7f6d9e349d1d6bf33702192019f166a7201e", keys["key1"].Address().String())
 				assert.Equal(t, "0x2d800d93b065ce011af83f316cef9f0d005b0aa4", keys["key2"].Address().String())
+				assert.IsType(t, &remotesigner.Key{}, keys["key3"])
+				assert.Equal(t, "0x1234567890123456789012345678901234567890", keys["key3"].Address().String())
+				require.IsType(t, &keyset.KeySet{}, keys["key_set1"])
+				assert.Equal(t, "0x2d800d93b065ce011af83f316cef9f0d005b0aa4", keys["key_set1"].Address().String())
+				assert.Len(t, keys["key_set1"].(*keyset.KeySet).Keys(), 2)
 			},
 		},
 		{
@@ -69,6 +88,69 @@ func TestConfig(t *testing.T) {
 				assert.NotNil(t, clients["client2"])
 			},
 		},
+		{
+			name: "passphrase",
+			path: "passphrase.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "test123", cfg.Keys[0].Passphrase)
+
+				keys, diags := cfg.KeyRegistry(Dependencies{Logger: null.New()})
+				require.NoError(t, diags)
+				assert.Equal(t, "0x2d800d93b065ce011af83f316cef9f0d005b0aa4", keys["key"].Address().String())
+			},
+		},
+		{
+			name: "key set with remote and local keys",
+			path: "mixed_key_set.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				_, diags := cfg.KeyRegistry(Dependencies{Logger: null.New()})
+				assert.ErrorContains(t, diags, "cannot mix keys held by remote signers with local keys")
+			},
+		},
+		{
+			name: "reload key sets",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				keys, diags := cfg.KeyRegistry(Dependencies{Logger: null.New()})
+				require.NoError(t, diags)
+				assert.Equal(t, []string{"ethereum.key_set.key_set1.active_key"}, cfg.KeySetPaths("ethereum"))
+
+				var next Config
+				require.NoError(t, config.LoadFiles(&next, []string{"./testdata/config.hcl"}))
+				next.KeySets[0].ActiveKey = "key1"
+				commit, err := cfg.ReloadKeySets(&next)
+				require.NoError(t, err)
+
+				ks := keys["key_set1"].(*keyset.KeySet)
+				assert.Equal(t, "0x2d800d93b065ce011af83f316cef9f0d005b0aa4", ks.Active().Address().String())
+				commit()
+				assert.Equal(t, "0xd18d7f6d9e349d1d6bf33702192019f166a7201e", ks.Active().Address().String())
+				retiring, _ := ks.Retiring()
+				require.NotNil(t, retiring)
+				assert.Equal(t, "0x2d800d93b065ce011af83f316cef9f0d005b0aa4", retiring.Address().String())
+
+				next.KeySets[0].ActiveKey = "key3"
+				_, err = cfg.ReloadKeySets(&next)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "validate without opening keys",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				cfg.Keys[1].PassphraseFile = "./testdata/keystore/missing"
+				keys, clients, err := cfg.Validate(Dependencies{Logger: null.New()})
+				require.NoError(t, err)
+				assert.Len(t, clients, 2)
+				assert.Equal(t, "0x2d800d93b065ce011af83f316cef9f0d005b0aa4", keys["key2"].Address().String())
+				_, err = keys["key2"].SignMessage([]byte("foo"))
+				assert.Error(t, err)
+
+				// Keys are opened only when the registry is requested:
+				_, err = cfg.KeyRegistry(Dependencies{Logger: null.New()})
+				assert.Error(t, err)
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -13,6 +13,25 @@ key "key2" {
   passphrase_file = "./testdata/keystore/passphrase"
 }
 
+# Remote signer
+key "key3" {
+  address = "0x1234567890123456789012345678901234567890"
+
+  remote_signer {
+    url             = "http://localhost:9000"
+    identifier      = "0x04abcdef"
+    auth_token_file = "./testdata/keystore/passphrase"
+    timeout         = 5
+  }
+}
+
+# Key set
+key_set "key_set1" {
+  keys       = ["key1", "key2"]
+  active_key = "key2"
+  overlap    = 600
+}
+
 # Without optionals
 client "client1" {
   rpc_urls     = ["https://rpc1.example"]
@@ -0,0 +1,17 @@
+key "local" {
+  address       = "0xd18d7f6d9e349d1d6bf33702192019f166a7201e"
+  keystore_path = "./testdata/keystore"
+}
+
+key "remote" {
+  address = "0x1234567890123456789012345678901234567890"
+
+  remote_signer {
+    url = "http://localhost:9000"
+  }
+}
+
+key_set "key_set" {
+  keys       = ["local", "remote"]
+  active_key = "local"
+}
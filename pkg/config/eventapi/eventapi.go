@@ -23,18 +23,25 @@ import (
 	"github.com/defiweb/go-eth/types"
 	"github.com/hashicorp/hcl/v2"
 
+	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/api"
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/store/redis"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store/sql"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
 )
 
 const week uint32 = 3600 * 24 * 7
 
+// defaultSignerCacheTTL is the default time in seconds for which signers
+// read from the TeleportOracleAuth contract are cached.
+const defaultSignerCacheTTL uint32 = 300
+
 type Dependencies struct {
 	EventStore *store.EventStore
+	Clients    ethereumConfig.ClientRegistry
 	Transport  transport.Transport
 	Logger     log.Logger
 }
@@ -49,14 +56,27 @@ type Config struct {
 	// ListenAddr is the address on which the event API will listen.
 	ListenAddr string `hcl:"listen_addr"`
 
+	// EventTypes is a list of additional event types to store, e.g. types
+	// of events produced by evm_log listeners. Teleport events are always
+	// stored.
+	EventTypes []string `hcl:"event_types,optional"`
+
 	// Memory is the configuration for the in-memory storage. Cannot be
-	// used together with storage_redis configuration.
+	// used together with other storage configurations.
 	Memory *storageMemory `hcl:"storage_memory,block,optional"`
 
 	// Redis is the configuration for the Redis storage. Cannot be used
-	// together with storage_memory configuration.
+	// together with other storage configurations.
 	Redis *storageRedis `hcl:"storage_redis,block,optional"`
 
+	// SQL is the configuration for the SQL storage. Cannot be used
+	// together with other storage configurations.
+	SQL *storageSQL `hcl:"storage_sql,block,optional"`
+
+	// Attestation is the configuration for the attestation endpoint. If
+	// not specified, the endpoint is disabled.
+	Attestation *attestation `hcl:"attestation,block,optional"`
+
 	// HCL fields:
 	Range   hcl.Range       `hcl:",range"`
 	Content hcl.BodyContent `hcl:",content"`
@@ -72,6 +92,57 @@ type storageMemory struct {
 	TTL uint32 `hcl:"ttl,optional"`
 }
 
+type attestation struct {
+	// Quorum is the minimum number of signatures required. Optional if
+	// the oracle_auth_addr is specified, in which case the threshold is read
+	// from the contract.
+	Quorum int `hcl:"quorum,optional"`
+
+	// Signers is a static list of authorized signers. Cannot be used
+	// together with oracle_auth_addr.
+	Signers []types.Address `hcl:"signers,optional"`
+
+	// EthereumClient is the name of the Ethereum client used to read
+	// authorized signers from the TeleportOracleAuth contract.
+	EthereumClient string `hcl:"ethereum_client,optional"`
+
+	// OracleAuthAddr is the address of the TeleportOracleAuth contract.
+	// Cannot be used together with signers.
+	OracleAuthAddr types.Address `hcl:"oracle_auth_addr,optional"`
+
+	// CacheTTL specifies how long, in seconds, signers read from the
+	// contract are cached. Defaults to 300.
+	CacheTTL uint32 `hcl:"cache_ttl,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type storageSQL struct {
+	// Driver is the database driver, either "sqlite3" or "postgres".
+	Driver string `hcl:"driver"`
+
+	// DSN is the data source name, e.g. a path to the SQLite database file
+	// or a PostgreSQL connection string.
+	DSN string `hcl:"dsn"`
+
+	// TTL is the time to live for the events in the SQL storage in seconds.
+	// Defaults to 604800 (one week).
+	TTL uint32 `hcl:"ttl,optional"`
+
+	// TypeTTL overrides the TTL for specific event types. Keys are event
+	// types and values are TTLs in seconds.
+	TypeTTL map[string]uint32 `hcl:"type_ttl,optional"`
+
+	// CleanupInterval specifies how often, in seconds, expired events are
+	// removed from the database. Defaults to 60.
+	CleanupInterval uint32 `hcl:"cleanup_interval,optional"`
+
+	// HCL fields:
+	Range hcl.Range `hcl:",range"`
+}
+
 type storageRedis struct {
 	// TTL is the time to live for the events in the Redis storage in seconds.
 	// Defaults to 604800 (one week).
@@ -128,10 +199,15 @@ func (c *Config) EventAPI(d Dependencies) (*api.EventAPI, error) {
 	if c.eventAPI != nil {
 		return c.eventAPI, nil
 	}
+	signerPolicy, err := c.Attestation.signerPolicy(d)
+	if err != nil {
+		return nil, err
+	}
 	eventAPI, err := api.New(api.Config{
-		EventStore: d.EventStore,
-		Address:    c.ListenAddr,
-		Logger:     d.Logger,
+		EventStore:   d.EventStore,
+		Address:      c.ListenAddr,
+		SignerPolicy: signerPolicy,
+		Logger:       d.Logger,
 	})
 	if err != nil {
 		return nil, err
@@ -140,15 +216,62 @@ func (c *Config) EventAPI(d Dependencies) (*api.EventAPI, error) {
 	return eventAPI, nil
 }
 
+func (c *attestation) signerPolicy(d Dependencies) (api.SignerPolicy, error) {
+	if c == nil {
+		return nil, nil
+	}
+	_, hasSigners := c.Content.Attributes["signers"]
+	_, hasOracleAuth := c.Content.Attributes["oracle_auth_addr"]
+	if hasSigners == hasOracleAuth {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   `Exactly one of "signers" or "oracle_auth_addr" must be set`,
+			Subject:  c.Range.Ptr(),
+		}}
+	}
+	if c.Quorum < 0 || (hasSigners && c.Quorum == 0) {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Quorum must be greater than zero",
+			Subject:  c.Range.Ptr(),
+		}}
+	}
+	if hasSigners {
+		return api.NewStaticSignerPolicy(c.Signers, c.Quorum), nil
+	}
+	client, ok := d.Clients[c.EthereumClient]
+	if !ok {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Ethereum client %q is not configured", c.EthereumClient),
+			Subject:  c.Range.Ptr(),
+		}}
+	}
+	cacheTTL := defaultSignerCacheTTL
+	if c.CacheTTL > 0 {
+		cacheTTL = c.CacheTTL
+	}
+	return api.NewOracleAuthSignerPolicy(client, c.OracleAuthAddr, c.Quorum, time.Second*time.Duration(cacheTTL)), nil
+}
+
 func (c *Config) Storage() (store.Storage, error) {
 	if c.storage != nil {
 		return c.storage, nil
 	}
-	if c.Memory != nil && c.Redis != nil {
+	storages := 0
+	for _, configured := range []bool{c.Memory != nil, c.Redis != nil, c.SQL != nil} {
+		if configured {
+			storages++
+		}
+	}
+	if storages > 1 {
 		return nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  "Validation error",
-			Detail:   `"storage_memory" and "storage_redis" storage types are mutually exclusive`,
+			Detail:   `"storage_memory", "storage_redis" and "storage_sql" storage types are mutually exclusive`,
 			Subject:  c.Range.Ptr(),
 		}}
 	}
@@ -199,11 +322,37 @@ func (c *Config) Storage() (store.Storage, error) {
 		}
 		c.storage = r
 		return c.storage, nil
+	case c.SQL != nil:
+		ttl := week
+		if c.SQL.TTL > 0 {
+			ttl = c.SQL.TTL
+		}
+		typeTTL := make(map[string]time.Duration, len(c.SQL.TypeTTL))
+		for typ, t := range c.SQL.TypeTTL {
+			typeTTL[typ] = time.Second * time.Duration(t)
+		}
+		s, err := sql.New(sql.Config{
+			Driver:          c.SQL.Driver,
+			DSN:             c.SQL.DSN,
+			TTL:             time.Second * time.Duration(ttl),
+			TypeTTL:         typeTTL,
+			CleanupInterval: time.Second * time.Duration(c.SQL.CleanupInterval),
+		})
+		if err != nil {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Runtime error",
+				Detail:   fmt.Sprintf(`Unable to create an SQL storage: %s`, err),
+				Subject:  c.SQL.Range.Ptr(),
+			}
+		}
+		c.storage = s
+		return c.storage, nil
 	default:
 		return nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  "Validation error",
-			Detail:   `One of "storage_memory" or "storage_redis" storage types must be specified`,
+			Detail:   `One of "storage_memory", "storage_redis" or "storage_sql" storage types must be specified`,
 			Subject:  c.Range.Ptr(),
 		}}
 	}
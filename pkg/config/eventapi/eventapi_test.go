@@ -25,6 +25,7 @@ func TestConfig(t *testing.T) {
 			path: "config.hcl",
 			test: func(t *testing.T, cfg *Config) {
 				assert.Equal(t, "0.0.0.0:8000", cfg.ListenAddr)
+				assert.Equal(t, []string{"transfer"}, cfg.EventTypes)
 
 				assert.NotNil(t, cfg.Memory)
 				assert.Equal(t, uint32(86400), cfg.Memory.TTL)
@@ -43,6 +44,19 @@ func TestConfig(t *testing.T) {
 				assert.Equal(t, "./tls_root_ca.pem", cfg.Redis.TLSRootCAFile)
 				assert.Equal(t, false, cfg.Redis.Cluster)
 				assert.Equal(t, []string{"localhost:7000", "localhost:7001"}, cfg.Redis.ClusterAddrs)
+
+				assert.NotNil(t, cfg.SQL)
+				assert.Equal(t, "sqlite3", cfg.SQL.Driver)
+				assert.Equal(t, "/tmp/lair.db", cfg.SQL.DSN)
+				assert.Equal(t, uint32(86400), cfg.SQL.TTL)
+				assert.Equal(t, map[string]uint32{"teleport_evm": 3600}, cfg.SQL.TypeTTL)
+				assert.Equal(t, uint32(30), cfg.SQL.CleanupInterval)
+
+				assert.NotNil(t, cfg.Attestation)
+				assert.Equal(t, 2, cfg.Attestation.Quorum)
+				assert.Equal(t, "client", cfg.Attestation.EthereumClient)
+				assert.Equal(t, "0x1234567890123456789012345678901234567890", cfg.Attestation.OracleAuthAddr.String())
+				assert.Equal(t, uint32(60), cfg.Attestation.CacheTTL)
 			},
 		},
 		{
@@ -1,4 +1,5 @@
 listen_addr = "0.0.0.0:8000"
+event_types = ["transfer"]
 
 # Storage memory
 storage_memory {
@@ -21,3 +22,19 @@ storage_redis {
   cluster          = false
   cluster_addrs    = ["localhost:7000", "localhost:7001"]
 }
+
+attestation {
+  quorum           = 2
+  ethereum_client  = "client"
+  oracle_auth_addr = "0x1234567890123456789012345678901234567890"
+  cache_ttl        = 60
+}
+
+# Storage SQL
+storage_sql {
+  driver           = "sqlite3"
+  dsn              = "/tmp/lair.db"
+  ttl              = 86400
+  type_ttl         = { teleport_evm = 3600 }
+  cleanup_interval = 30
+}
@@ -4,3 +4,8 @@ listen_addr = "0.0.0.0:8000"
 storage_memory {
   ttl = 86400
 }
+
+attestation {
+  quorum  = 2
+  signers = ["0x1234567890123456789012345678901234567890", "0x2345678901234567890123456789012345678901"]
+}
@@ -21,12 +21,17 @@ import (
 	"time"
 
 	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/audit"
 	"github.com/chronicleprotocol/oracle-suite/pkg/config"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/geth"
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/checkpoint"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/evmlog"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/opstack"
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/replayer"
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/teleportevm"
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/teleportstarknet"
@@ -40,6 +45,9 @@ type Dependencies struct {
 	Clients   ethereumConfig.ClientRegistry
 	Transport transport.Transport
 	Logger    log.Logger
+
+	// AuditLog records signed events. It may be nil.
+	AuditLog *audit.Log
 }
 
 type Config struct {
@@ -52,6 +60,14 @@ type Config struct {
 	// TeleportStarknet is a list of Teleport listeners for Starknet.
 	TeleportStarknet []teleportStarknetListener `hcl:"teleport_starknet,block"`
 
+	// EVMLog is a list of listeners for arbitrary events on EVM-compatible
+	// chains.
+	EVMLog []evmLogListener `hcl:"evm_log,block"`
+
+	// OPWithdrawal is a list of listeners for withdrawals on OP Stack
+	// rollups.
+	OPWithdrawal []opWithdrawalListener `hcl:"op_withdrawal,block"`
+
 	// HCL fields:
 	Range   hcl.Range       `hcl:",range"`
 	Content hcl.BodyContent `hcl:",content"`
@@ -91,15 +107,25 @@ type teleportEVMListener struct {
 	// to.
 	ContractAddrs []types.Address `hcl:"contract_addrs"`
 
+	// CheckpointFile is a path to a file in which the last processed block
+	// of each contract is persisted. If set, the event listener resumes from
+	// the checkpoints after a restart instead of prefetching past events.
+	// If empty, checkpoints are kept in memory only.
+	CheckpointFile string `hcl:"checkpoint_file,optional"`
+
 	// HCL fields:
 	Range   hcl.Range       `hcl:",range"`
 	Content hcl.BodyContent `hcl:",content"`
 }
 
 type teleportStarknetListener struct {
-	// Sequencer is the name of the Starknet sequencer to use for listening
-	// to events.
-	Sequencer config.URL `hcl:"sequencer"`
+	// Sequencer is the URL of the Starknet feeder gateway to use for
+	// listening to events. Either Sequencer or RPC must be set.
+	Sequencer config.URL `hcl:"sequencer,optional"`
+
+	// RPC is the URL of the Starknet JSON-RPC endpoint to use for listening
+	// to events. Either Sequencer or RPC must be set.
+	RPC config.URL `hcl:"rpc,optional"`
 
 	// Interval specifies how often, in seconds, the event listener should
 	// check for new events.
@@ -124,6 +150,119 @@ type teleportStarknetListener struct {
 	Content hcl.BodyContent `hcl:",content"`
 }
 
+type evmLogListener struct {
+	// EventType is the type of produced event messages.
+	EventType string `hcl:"event_type,label"`
+
+	// EthereumClient is the name of the Ethereum client to use for
+	// listening to events.
+	EthereumClient string `hcl:"ethereum_client"`
+
+	// EthereumKey is the name of the key to use for signing events. If
+	// empty, the key from the ethereum_key attribute of the event publisher
+	// is used.
+	EthereumKey string `hcl:"ethereum_key,optional"`
+
+	// ABI is the event ABI, either as a human-readable signature or as
+	// a JSON fragment.
+	ABI string `hcl:"abi"`
+
+	// IndexFields is a list of indexed event arguments whose topics are
+	// used as the event index. If empty, the transaction hash is used.
+	IndexFields []string `hcl:"index_fields,optional"`
+
+	// DataFields is a list of event arguments to include in the event data.
+	// At least one field is required.
+	DataFields []string `hcl:"data_fields"`
+
+	// Interval specifies how often, in seconds, the event listener should
+	// check for new events.
+	Interval uint32 `hcl:"interval"`
+
+	// PrefetchPeriod specifies how far, in seconds, the event listener should
+	// check for new  events during the initial synchronization.
+	PrefetchPeriod uint64 `hcl:"prefetch_period"`
+
+	// BlockConfirmations is the number of blocks to wait before
+	// considering a block final.
+	BlockConfirmations uint64 `hcl:"block_confirmations"`
+
+	// BlockLimit is the maximum range of blocks to fetch in a single
+	// filter log request.
+	BlockLimit uint64 `hcl:"block_limit"`
+
+	// ReplayAfter specifies after which time, in seconds, the event listener
+	// should replay events.
+	ReplayAfter []uint64 `hcl:"replay_after,optional"`
+
+	// ContractAddrs is a list of contract addresses to listen to.
+	ContractAddrs []types.Address `hcl:"contract_addrs"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type opWithdrawalListener struct {
+	// EventType is the type of produced event messages.
+	EventType string `hcl:"event_type,label"`
+
+	// L2EthereumClient is the name of the Ethereum client connected to the
+	// L2 chain on which withdrawals are initiated.
+	L2EthereumClient string `hcl:"l2_ethereum_client"`
+
+	// L1EthereumClient is the name of the Ethereum client connected to the
+	// L1 chain on which output roots are proposed.
+	L1EthereumClient string `hcl:"l1_ethereum_client"`
+
+	// EthereumKey is the name of the key to use for signing events. If
+	// empty, the key from the ethereum_key attribute of the event publisher
+	// is used.
+	EthereumKey string `hcl:"ethereum_key,optional"`
+
+	// MessagePasserAddr is the address of the L2ToL1MessagePasser contract
+	// on L2. If empty, the predeploy address is used.
+	MessagePasserAddr types.Address `hcl:"message_passer_addr,optional"`
+
+	// OutputOracleAddr is the address of the L2OutputOracle contract on L1.
+	OutputOracleAddr types.Address `hcl:"output_oracle_addr"`
+
+	// Interval specifies how often, in seconds, the event listener should
+	// check for new withdrawals and output proposals.
+	Interval uint32 `hcl:"interval"`
+
+	// PrefetchPeriod specifies how far, in seconds, the event listener should
+	// check for new withdrawals during the initial synchronization.
+	PrefetchPeriod uint64 `hcl:"prefetch_period"`
+
+	// BlockConfirmations is the number of L2 blocks to wait before
+	// considering a block final.
+	BlockConfirmations uint64 `hcl:"block_confirmations"`
+
+	// L1BlockConfirmations is the number of L1 blocks to wait before
+	// using an output proposal.
+	L1BlockConfirmations uint64 `hcl:"l1_block_confirmations,optional"`
+
+	// BlockLimit is the maximum range of blocks to fetch in a single
+	// filter log request.
+	BlockLimit uint64 `hcl:"block_limit"`
+
+	// ReplayAfter specifies after which time, in seconds, the event listener
+	// should replay events.
+	ReplayAfter []uint64 `hcl:"replay_after,optional"`
+
+	// CheckpointFile is a path to a file in which the block from which the
+	// event listener should resume is persisted. The checkpoint is never
+	// newer than the oldest withdrawal waiting for an output proposal, so
+	// such withdrawals are not lost after a restart. If empty, pending
+	// withdrawals are kept in memory only.
+	CheckpointFile string `hcl:"checkpoint_file,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
 func (c *Config) EventPublisher(d Dependencies) (*publisher.EventPublisher, error) {
 	if c.eventPublisher != nil {
 		return c.eventPublisher, nil
@@ -135,6 +274,12 @@ func (c *Config) EventPublisher(d Dependencies) (*publisher.EventPublisher, erro
 	if err := c.teleportStarknet(&eventProviders, d); err != nil {
 		return nil, err
 	}
+	if err := c.evmLog(&eventProviders, d); err != nil {
+		return nil, err
+	}
+	if err := c.opWithdrawal(&eventProviders, d); err != nil {
+		return nil, err
+	}
 	key, ok := d.Keys[c.EthereumKey]
 	if !ok {
 		return nil, &hcl.Diagnostic{
@@ -144,10 +289,21 @@ func (c *Config) EventPublisher(d Dependencies) (*publisher.EventPublisher, erro
 			Subject:  c.Content.Attributes["ethereum_key"].Range.Ptr(),
 		}
 	}
+	key = audit.NewKey(key, d.AuditLog, audit.TypeEvent)
 	signer := []publisher.EventSigner{teleportevm.NewSigner(key, []string{
 		teleportevm.TeleportEventType,
 		teleportstarknet.TeleportEventType,
 	})}
+	evmLogSigners, err := c.evmLogSigners(d)
+	if err != nil {
+		return nil, err
+	}
+	signer = append(signer, evmLogSigners...)
+	opWithdrawalSigners, err := c.opWithdrawalSigners(d)
+	if err != nil {
+		return nil, err
+	}
+	signer = append(signer, opWithdrawalSigners...)
 	eventPublisher, err := publisher.New(publisher.Config{
 		Providers: eventProviders,
 		Signers:   signer,
@@ -198,6 +354,18 @@ func (c *Config) teleportEVM(eps *[]publisher.EventProvider, d Dependencies) err
 		for i, r := range cfg.ReplayAfter {
 			replayAfter[i] = time.Second * time.Duration(r)
 		}
+		var checkpoints checkpoint.Store = checkpoint.NewMemoryStore()
+		if cfg.CheckpointFile != "" {
+			checkpoints, err = checkpoint.NewFileStore(cfg.CheckpointFile)
+			if err != nil {
+				return &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Runtime error",
+					Detail:   fmt.Sprintf("Failed to load the checkpoint file: %v", err),
+					Subject:  cfg.Content.Attributes["checkpoint_file"].Range.Ptr(),
+				}
+			}
+		}
 		var eventProvider publisher.EventProvider
 		eventProvider, err = teleportevm.New(teleportevm.Config{
 			Client:             geth.NewClient(client), //nolint:staticcheck // deprecated ethereum.Client
@@ -206,6 +374,7 @@ func (c *Config) teleportEVM(eps *[]publisher.EventProvider, d Dependencies) err
 			PrefetchPeriod:     time.Second * time.Duration(cfg.PrefetchPeriod),
 			BlockLimit:         cfg.BlockLimit,
 			BlockConfirmations: cfg.BlockConfirmations,
+			Checkpoints:        checkpoints,
 			Logger:             d.Logger,
 		})
 		if err != nil {
@@ -255,13 +424,29 @@ func (c *Config) teleportStarknet(eps *[]publisher.EventProvider, d Dependencies
 				Subject:  cfg.Content.Attributes["contract_addrs"].Range.Ptr(),
 			}}
 		}
+		_, hasSequencer := cfg.Content.Attributes["sequencer"]
+		_, hasRPC := cfg.Content.Attributes["rpc"]
+		if hasSequencer == hasRPC {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Summary:  "Validation error",
+				Detail:   "Exactly one of sequencer or rpc must be set",
+				Severity: hcl.DiagError,
+				Subject:  cfg.Range.Ptr(),
+			}}
+		}
+		var sequencer teleportstarknet.Sequencer
+		if hasRPC {
+			sequencer = starknetClient.NewRPC(cfg.RPC.String(), http.Client{})
+		} else {
+			sequencer = starknetClient.NewSequencer(cfg.Sequencer.String(), http.Client{})
+		}
 		replayAfter := make([]time.Duration, len(cfg.ReplayAfter))
 		for i, r := range cfg.ReplayAfter {
 			replayAfter[i] = time.Duration(r)
 		}
 		var eventProvider publisher.EventProvider
 		eventProvider, err = teleportstarknet.New(teleportstarknet.Config{
-			Sequencer:      starknetClient.NewSequencer(cfg.Sequencer.String(), http.Client{}),
+			Sequencer:      sequencer,
 			Addresses:      cfg.ContractAddrs,
 			Interval:       time.Second * time.Duration(cfg.Interval),
 			PrefetchPeriod: time.Second * time.Duration(cfg.PrefetchPeriod),
@@ -294,3 +479,232 @@ func (c *Config) teleportStarknet(eps *[]publisher.EventProvider, d Dependencies
 	}
 	return nil
 }
+
+func (c *Config) evmLog(eps *[]publisher.EventProvider, d Dependencies) error {
+	for _, cfg := range c.EVMLog {
+		if cfg.Interval == 0 {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Summary:  "Validation error",
+				Detail:   "Interval cannot be zero",
+				Severity: hcl.DiagError,
+				Subject:  cfg.Content.Attributes["interval"].Range.Ptr(),
+			}}
+		}
+		if len(cfg.ContractAddrs) == 0 {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Summary:  "Validation error",
+				Detail:   "Contract addresses cannot be empty",
+				Severity: hcl.DiagError,
+				Subject:  cfg.Content.Attributes["contract_addrs"].Range.Ptr(),
+			}}
+		}
+		if len(cfg.DataFields) == 0 {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Summary:  "Validation error",
+				Detail:   "Data fields cannot be empty",
+				Severity: hcl.DiagError,
+				Subject:  cfg.Content.Attributes["data_fields"].Range.Ptr(),
+			}}
+		}
+		client, ok := d.Clients[cfg.EthereumClient]
+		if !ok {
+			return &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Ethereum client %q is not configured", cfg.EthereumClient),
+				Subject:  cfg.Content.Attributes["ethereum_client"].Range.Ptr(),
+			}
+		}
+		event, err := evmlog.ParseEvent(cfg.ABI)
+		if err != nil {
+			return &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Invalid event ABI: %v", err),
+				Subject:  cfg.Content.Attributes["abi"].Range.Ptr(),
+			}
+		}
+		replayAfter := make([]time.Duration, len(cfg.ReplayAfter))
+		for i, r := range cfg.ReplayAfter {
+			replayAfter[i] = time.Second * time.Duration(r)
+		}
+		var eventProvider publisher.EventProvider
+		eventProvider, err = evmlog.New(evmlog.Config{
+			Client:             client,
+			Addresses:          cfg.ContractAddrs,
+			Event:              event,
+			EventType:          cfg.EventType,
+			IndexFields:        cfg.IndexFields,
+			DataFields:         cfg.DataFields,
+			Interval:           time.Second * time.Duration(cfg.Interval),
+			PrefetchPeriod:     time.Second * time.Duration(cfg.PrefetchPeriod),
+			BlockLimit:         cfg.BlockLimit,
+			BlockConfirmations: cfg.BlockConfirmations,
+			Logger:             d.Logger,
+		})
+		if err != nil {
+			return &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Runtime error",
+				Detail:   fmt.Sprintf("Failed to create the EVM Event Provider for %s: %v", cfg.EventType, err),
+				Subject:  cfg.Range.Ptr(),
+			}
+		}
+		if len(cfg.ReplayAfter) > 0 {
+			eventProvider, err = replayer.New(replayer.Config{
+				EventProvider: eventProvider,
+				Interval:      time.Minute,
+				ReplayAfter:   replayAfter,
+			})
+			if err != nil {
+				return &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Runtime error",
+					Detail:   fmt.Sprintf("Failed to create the EVM Event Provider for %s: %v", cfg.EventType, err),
+					Subject:  cfg.Range.Ptr(),
+				}
+			}
+		}
+		*eps = append(*eps, eventProvider)
+	}
+	return nil
+}
+
+func (c *Config) opWithdrawal(eps *[]publisher.EventProvider, d Dependencies) error {
+	for _, cfg := range c.OPWithdrawal {
+		if cfg.Interval == 0 {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Summary:  "Validation error",
+				Detail:   "Interval cannot be zero",
+				Severity: hcl.DiagError,
+				Subject:  cfg.Content.Attributes["interval"].Range.Ptr(),
+			}}
+		}
+		l2Client, ok := d.Clients[cfg.L2EthereumClient]
+		if !ok {
+			return &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Ethereum client %q is not configured", cfg.L2EthereumClient),
+				Subject:  cfg.Content.Attributes["l2_ethereum_client"].Range.Ptr(),
+			}
+		}
+		l1Client, ok := d.Clients[cfg.L1EthereumClient]
+		if !ok {
+			return &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Ethereum client %q is not configured", cfg.L1EthereumClient),
+				Subject:  cfg.Content.Attributes["l1_ethereum_client"].Range.Ptr(),
+			}
+		}
+		replayAfter := make([]time.Duration, len(cfg.ReplayAfter))
+		for i, r := range cfg.ReplayAfter {
+			replayAfter[i] = time.Second * time.Duration(r)
+		}
+		var checkpoints checkpoint.Store
+		if cfg.CheckpointFile != "" {
+			fileStore, err := checkpoint.NewFileStore(cfg.CheckpointFile)
+			if err != nil {
+				return &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Runtime error",
+					Detail:   fmt.Sprintf("Failed to load the checkpoint file: %v", err),
+					Subject:  cfg.Content.Attributes["checkpoint_file"].Range.Ptr(),
+				}
+			}
+			checkpoints = fileStore
+		}
+		var eventProvider publisher.EventProvider
+		eventProvider, err := opstack.New(opstack.Config{
+			L2Client:             l2Client,
+			L1Client:             l1Client,
+			MessagePasser:        cfg.MessagePasserAddr,
+			OutputOracle:         cfg.OutputOracleAddr,
+			EventType:            cfg.EventType,
+			Interval:             time.Second * time.Duration(cfg.Interval),
+			PrefetchPeriod:       time.Second * time.Duration(cfg.PrefetchPeriod),
+			BlockLimit:           cfg.BlockLimit,
+			BlockConfirmations:   cfg.BlockConfirmations,
+			L1BlockConfirmations: cfg.L1BlockConfirmations,
+			Checkpoints:          checkpoints,
+			Logger:               d.Logger,
+		})
+		if err != nil {
+			return &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Runtime error",
+				Detail:   fmt.Sprintf("Failed to create the OP Stack Event Provider for %s: %v", cfg.EventType, err),
+				Subject:  cfg.Range.Ptr(),
+			}
+		}
+		if len(cfg.ReplayAfter) > 0 {
+			eventProvider, err = replayer.New(replayer.Config{
+				EventProvider: eventProvider,
+				Interval:      time.Minute,
+				ReplayAfter:   replayAfter,
+			})
+			if err != nil {
+				return &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Runtime error",
+					Detail:   fmt.Sprintf("Failed to create the OP Stack Event Provider for %s: %v", cfg.EventType, err),
+					Subject:  cfg.Range.Ptr(),
+				}
+			}
+		}
+		*eps = append(*eps, eventProvider)
+	}
+	return nil
+}
+
+// evmLogSigners returns signers for events produced by evm_log listeners.
+// Events are signed using the key specified in the listener, or the default
+// key if not specified.
+func (c *Config) evmLogSigners(d Dependencies) ([]publisher.EventSigner, error) {
+	var signers []publisher.EventSigner
+	for _, cfg := range c.EVMLog {
+		key, err := c.listenerKey(d, cfg.EthereumKey, cfg.Content)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, teleportevm.NewSigner(audit.NewKey(key, d.AuditLog, audit.TypeEvent), []string{cfg.EventType}))
+	}
+	return signers, nil
+}
+
+// opWithdrawalSigners returns signers for events produced by op_withdrawal
+// listeners. Events are signed using the key specified in the listener, or
+// the default key if not specified.
+func (c *Config) opWithdrawalSigners(d Dependencies) ([]publisher.EventSigner, error) {
+	var signers []publisher.EventSigner
+	for _, cfg := range c.OPWithdrawal {
+		key, err := c.listenerKey(d, cfg.EthereumKey, cfg.Content)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, opstack.NewSigner(audit.NewKey(key, d.AuditLog, audit.TypeEvent), []string{cfg.EventType}))
+	}
+	return signers, nil
+}
+
+// listenerKey returns the key with the given name, or the default key if
+// the name is empty.
+func (c *Config) listenerKey(d Dependencies, keyName string, content hcl.BodyContent) (wallet.Key, error) {
+	subject := c.Content.Attributes["ethereum_key"].Range.Ptr()
+	if keyName != "" {
+		subject = content.Attributes["ethereum_key"].Range.Ptr()
+	} else {
+		keyName = c.EthereumKey
+	}
+	key, ok := d.Keys[keyName]
+	if !ok {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Ethereum key %q is not configured", keyName),
+			Subject:  subject,
+		}
+	}
+	return key, nil
+}
@@ -3,6 +3,7 @@ package eventpublisher
 import (
 	"testing"
 
+	"github.com/defiweb/go-eth/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -33,6 +34,7 @@ func TestConfig(t *testing.T) {
 				assert.Equal(t, []uint64{600, 1200}, cfg.TeleportEVM[0].ReplayAfter)
 				assert.Equal(t, "0x1234567890123456789012345678901234567890", cfg.TeleportEVM[0].ContractAddrs[0].String())
 				assert.Equal(t, "0x2345678901234567890123456789012345678901", cfg.TeleportEVM[0].ContractAddrs[1].String())
+				assert.Equal(t, "/tmp/checkpoints.json", cfg.TeleportEVM[0].CheckpointFile)
 
 				assert.Equal(t, "http://localhost:8080", cfg.TeleportStarknet[0].Sequencer.String())
 				assert.Equal(t, uint32(60), cfg.TeleportStarknet[0].Interval)
@@ -40,6 +42,33 @@ func TestConfig(t *testing.T) {
 				assert.Equal(t, []uint32{600, 1200}, cfg.TeleportStarknet[0].ReplayAfter)
 				assert.Equal(t, "3456789012345678901234567890123456789012", cfg.TeleportStarknet[0].ContractAddrs[0].Text(16))
 				assert.Equal(t, "4567890123456789012345678901234567890123", cfg.TeleportStarknet[0].ContractAddrs[1].Text(16))
+				assert.Equal(t, "http://localhost:9545/rpc/v0.4", cfg.TeleportStarknet[1].RPC.String())
+
+				assert.Equal(t, "transfer", cfg.EVMLog[0].EventType)
+				assert.Equal(t, "client", cfg.EVMLog[0].EthereumClient)
+				assert.Equal(t, "key", cfg.EVMLog[0].EthereumKey)
+				assert.Equal(t, "event Transfer(address indexed from, address indexed to, uint256 value)", cfg.EVMLog[0].ABI)
+				assert.Equal(t, []string{"to"}, cfg.EVMLog[0].IndexFields)
+				assert.Equal(t, []string{"from", "value"}, cfg.EVMLog[0].DataFields)
+				assert.Equal(t, uint32(60), cfg.EVMLog[0].Interval)
+				assert.Equal(t, uint64(120), cfg.EVMLog[0].PrefetchPeriod)
+				assert.Equal(t, uint64(3), cfg.EVMLog[0].BlockConfirmations)
+				assert.Equal(t, uint64(100), cfg.EVMLog[0].BlockLimit)
+				assert.Equal(t, []uint64{600}, cfg.EVMLog[0].ReplayAfter)
+				assert.Equal(t, "0x5678901234567890123456789012345678901234", cfg.EVMLog[0].ContractAddrs[0].String())
+
+				assert.Equal(t, "op_withdrawal_base", cfg.OPWithdrawal[0].EventType)
+				assert.Equal(t, "client", cfg.OPWithdrawal[0].L2EthereumClient)
+				assert.Equal(t, "client", cfg.OPWithdrawal[0].L1EthereumClient)
+				assert.Equal(t, types.ZeroAddress, cfg.OPWithdrawal[0].MessagePasserAddr)
+				assert.Equal(t, "0x56315b90c40730925ec5485cf004d835058518a0", cfg.OPWithdrawal[0].OutputOracleAddr.String())
+				assert.Equal(t, uint32(60), cfg.OPWithdrawal[0].Interval)
+				assert.Equal(t, uint64(120), cfg.OPWithdrawal[0].PrefetchPeriod)
+				assert.Equal(t, uint64(10), cfg.OPWithdrawal[0].BlockConfirmations)
+				assert.Equal(t, uint64(3), cfg.OPWithdrawal[0].L1BlockConfirmations)
+				assert.Equal(t, uint64(100), cfg.OPWithdrawal[0].BlockLimit)
+				assert.Equal(t, []uint64{600}, cfg.OPWithdrawal[0].ReplayAfter)
+				assert.Equal(t, "/tmp/op_checkpoints.json", cfg.OPWithdrawal[0].CheckpointFile)
 			},
 		},
 		{
@@ -8,6 +8,7 @@ teleport_evm {
   block_limit         = 100
   replay_after        = [600, 1200]
   contract_addrs      = ["0x1234567890123456789012345678901234567890", "0x2345678901234567890123456789012345678901"]
+  checkpoint_file     = "/tmp/checkpoints.json"
 }
 
 teleport_starknet {
@@ -17,3 +18,38 @@ teleport_starknet {
   replay_after    = [600, 1200]
   contract_addrs  = ["0x3456789012345678901234567890123456789012", "0x4567890123456789012345678901234567890123"]
 }
+
+teleport_starknet {
+  rpc             = "http://localhost:9545/rpc/v0.4"
+  interval        = 60
+  prefetch_period = 120
+  replay_after    = []
+  contract_addrs  = ["0x3456789012345678901234567890123456789012"]
+}
+
+evm_log "transfer" {
+  ethereum_client     = "client"
+  ethereum_key        = "key"
+  abi                 = "event Transfer(address indexed from, address indexed to, uint256 value)"
+  index_fields        = ["to"]
+  data_fields         = ["from", "value"]
+  interval            = 60
+  prefetch_period     = 120
+  block_confirmations = 3
+  block_limit         = 100
+  replay_after        = [600]
+  contract_addrs      = ["0x5678901234567890123456789012345678901234"]
+}
+
+op_withdrawal "op_withdrawal_base" {
+  l2_ethereum_client     = "client"
+  l1_ethereum_client     = "client"
+  output_oracle_addr     = "0x56315b90c40730925ec5485cf004d835058518a0"
+  interval               = 60
+  prefetch_period        = 120
+  block_confirmations    = 10
+  l1_block_confirmations = 3
+  block_limit            = 100
+  replay_after           = [600]
+  checkpoint_file        = "/tmp/op_checkpoints.json"
+}
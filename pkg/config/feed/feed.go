@@ -16,13 +16,17 @@
 package feed
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/defiweb/go-eth/wallet"
 	"github.com/hashicorp/hcl/v2"
 
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/audit"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/feed"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider"
@@ -54,12 +58,54 @@ type Dependencies struct {
 	PriceProvider provider.Provider
 	Transport     transport.Transport
 	Logger        log.Logger
+
+	// AuditLog records signed prices. It may be nil.
+	AuditLog *audit.Log
+
+	// Health is an optional registry in which the feed reports its status.
+	Health *health.Registry
 }
 
 func (c *Config) Feed(d Dependencies) (*feed.Feed, error) {
 	if c.feed != nil {
 		return c.feed, nil
 	}
+	ethereumKey, err := c.ethereumKey(d)
+	if err != nil {
+		return nil, err
+	}
+	cfg := feed.Config{
+		PriceProvider: d.PriceProvider,
+		Signer:        audit.NewKey(ethereumKey, d.AuditLog, audit.TypePrice),
+		Transport:     d.Transport,
+		Logger:        d.Logger,
+		Health:        d.Health,
+		Interval:      timeutil.NewTicker(time.Second * time.Duration(c.Interval)),
+		Pairs:         c.pairs(),
+	}
+	feed, err := feed.New(cfg)
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Runtime error",
+			Detail:   fmt.Sprintf("Failed to create the ConfigureFeed service: %v", err),
+			Subject:  c.Range.Ptr(),
+		}
+	}
+	c.feed = feed
+	return feed, nil
+}
+
+// Validate checks the feed configuration, including the reference to the
+// Ethereum key, without creating the feed.
+func (c *Config) Validate(d Dependencies) error {
+	_, err := c.ethereumKey(d)
+	return err
+}
+
+// ethereumKey validates the configuration and returns the Ethereum key used
+// to sign prices.
+func (c *Config) ethereumKey(d Dependencies) (wallet.Key, error) {
 	if c.Interval == 0 {
 		return nil, hcl.Diagnostics{&hcl.Diagnostic{
 			Summary:  "Validation error",
@@ -68,7 +114,7 @@ func (c *Config) Feed(d Dependencies) (*feed.Feed, error) {
 			Subject:  c.Content.Attributes["interval"].Range.Ptr(),
 		}}
 	}
-	ethereumKey, ok := d.KeysRegistry[c.EthereumKey]
+	key, ok := d.KeysRegistry[c.EthereumKey]
 	if !ok {
 		return nil, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -77,27 +123,32 @@ func (c *Config) Feed(d Dependencies) (*feed.Feed, error) {
 			Subject:  c.Content.Attributes["ethereum_key"].Range.Ptr(),
 		}
 	}
-	pairs := make([]string, len(c.Pairs))
-	for i, p := range c.Pairs {
-		pairs[i] = p.String()
-	}
-	cfg := feed.Config{
-		PriceProvider: d.PriceProvider,
-		Signer:        ethereumKey,
-		Transport:     d.Transport,
-		Logger:        d.Logger,
-		Interval:      timeutil.NewTicker(time.Second * time.Duration(c.Interval)),
-		Pairs:         pairs,
+	return key, nil
+}
+
+// ReloadPairs validates the pairs from the next configuration and returns a
+// function that replaces the pairs of the running feed with them. The Feed
+// method must be called first.
+func (c *Config) ReloadPairs(next *Config) (func(), error) {
+	if c.feed == nil {
+		return nil, errors.New("feed is not configured")
 	}
-	feed, err := feed.New(cfg)
+	pairs, err := provider.NewPairs(next.pairs()...)
 	if err != nil {
 		return nil, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
-			Summary:  "Runtime error",
-			Detail:   fmt.Sprintf("Failed to create the ConfigureFeed service: %v", err),
-			Subject:  c.Range.Ptr(),
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Invalid pairs: %v", err),
+			Subject:  next.Content.Attributes["pairs"].Range.Ptr(),
 		}
 	}
-	c.feed = feed
-	return feed, nil
+	return func() { c.feed.SetPairs(pairs) }, nil
+}
+
+func (c *Config) pairs() []string {
+	pairs := make([]string, len(c.Pairs))
+	for i, p := range c.Pairs {
+		pairs[i] = p.String()
+	}
+	return pairs
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/wallet"
 	"github.com/hashicorp/hcl/v2"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/datapoint"
@@ -12,7 +13,9 @@ import (
 
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	"github.com/chronicleprotocol/oracle-suite/pkg/feed"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/audit"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/timeutil"
@@ -40,35 +43,30 @@ type Dependencies struct {
 	DataProvider datapoint.Provider
 	Transport    transport.Transport
 	Logger       log.Logger
+
+	// AuditLog records signed data points. It may be nil.
+	AuditLog *audit.Log
+
+	// Health is an optional registry in which the feed reports its status.
+	Health *health.Registry
 }
 
 func (c *Config) ConfigureFeed(d Dependencies) (*feed.Feed, error) {
 	if c.feed != nil {
 		return c.feed, nil
 	}
-	if c.Interval == 0 {
-		return nil, hcl.Diagnostics{&hcl.Diagnostic{
-			Summary:  "Validation error",
-			Detail:   "Interval cannot be zero",
-			Severity: hcl.DiagError,
-			Subject:  c.Content.Attributes["interval"].Range.Ptr(),
-		}}
-	}
-	ethereumKey, ok := d.KeysRegistry[c.EthereumKey]
-	if !ok {
-		return nil, &hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Validation error",
-			Detail:   fmt.Sprintf("Ethereum key %q is not configured", c.EthereumKey),
-			Subject:  c.Content.Attributes["ethereum_key"].Range.Ptr(),
-		}
+	ethereumKey, err := c.ethereumKey(d)
+	if err != nil {
+		return nil, err
 	}
+	ethereumKey = audit.NewKey(ethereumKey, d.AuditLog, audit.TypeDataPoint)
 	cfg := feed.Config{
 		DataModels:   c.DataModels,
 		DataProvider: d.DataProvider,
 		Signers:      []datapoint.Signer{signer.NewTick(ethereumKey, crypto.ECRecoverer)},
 		Transport:    d.Transport,
 		Logger:       d.Logger,
+		Health:       d.Health,
 		Interval:     timeutil.NewTicker(time.Second * time.Duration(c.Interval)),
 	}
 	feedService, err := feed.New(cfg)
@@ -83,3 +81,33 @@ func (c *Config) ConfigureFeed(d Dependencies) (*feed.Feed, error) {
 	c.feed = feedService
 	return feedService, nil
 }
+
+// Validate checks the feed configuration, including the reference to the
+// Ethereum key, without creating the feed.
+func (c *Config) Validate(d Dependencies) error {
+	_, err := c.ethereumKey(d)
+	return err
+}
+
+// ethereumKey validates the configuration and returns the Ethereum key used
+// to sign data points.
+func (c *Config) ethereumKey(d Dependencies) (wallet.Key, error) {
+	if c.Interval == 0 {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Summary:  "Validation error",
+			Detail:   "Interval cannot be zero",
+			Severity: hcl.DiagError,
+			Subject:  c.Content.Attributes["interval"].Range.Ptr(),
+		}}
+	}
+	key, ok := d.KeysRegistry[c.EthereumKey]
+	if !ok {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Ethereum key %q is not configured", c.EthereumKey),
+			Subject:  c.Content.Attributes["ethereum_key"].Range.Ptr(),
+		}
+	}
+	return key, nil
+}
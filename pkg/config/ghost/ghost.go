@@ -22,13 +22,21 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/audit"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	auditConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/audit"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	feedConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/feed"
 	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
 	priceproviderConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/priceprovider"
 	transportConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/feed"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing/otlp"
 
 	pkgSupervisor "github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
 	"github.com/chronicleprotocol/oracle-suite/pkg/sysmon"
@@ -43,6 +51,7 @@ type Config struct {
 	Ethereum  ethereumConfig.Config      `hcl:"ethereum,block"`
 	Transport transportConfig.Config     `hcl:"transport,block"`
 	Logger    *loggerConfig.Config       `hcl:"logger,block,optional"`
+	Audit     *auditConfig.Config        `hcl:"audit,block,optional"`
 
 	// HCL fields:
 	Remain  hcl.Body        `hcl:",remain"` // To ignore unknown blocks.
@@ -54,7 +63,16 @@ type Services struct {
 	Feed      *feed.Feed
 	Transport pkgTransport.Transport
 	Logger    log.Logger
+	Tracing   *otlp.Exporter
+	Admin     *httpserver.HTTPServer
+	Audit     *audit.Log
+	Reloader  *reload.Reloader
 
+	config     *Config
+	current    *Config
+	health     *health.Registry
+	baseLogger log.Logger
+	noRPC      bool
 	supervisor *pkgSupervisor.Supervisor
 }
 
@@ -64,10 +82,25 @@ func (s *Services) Start(ctx context.Context) error {
 		return fmt.Errorf("services already started")
 	}
 	s.supervisor = pkgSupervisor.New(s.Logger)
-	s.supervisor.Watch(s.Transport, s.Feed, sysmon.New(time.Minute, s.Logger))
+	s.supervisor.SetHealth(s.health)
+	s.supervisor.WatchService(s.config.Transport.Services()...)
+	s.supervisor.WatchService(pkgSupervisor.ServiceConfig{Service: s.Feed, DependsOn: []string{transportConfig.ServiceName}})
+	s.supervisor.Watch(sysmon.New(time.Minute, s.Logger))
 	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
 		s.supervisor.Watch(l)
 	}
+	if s.Tracing != nil {
+		s.supervisor.Watch(s.Tracing)
+	}
+	if s.Admin != nil {
+		s.supervisor.Watch(s.Admin)
+	}
+	if s.Audit != nil {
+		s.supervisor.Watch(s.Audit)
+	}
+	if s.Reloader != nil {
+		s.supervisor.Watch(s.Reloader)
+	}
 	return s.supervisor.Start(ctx)
 }
 
@@ -76,6 +109,83 @@ func (s *Services) Wait() <-chan error {
 	return s.supervisor.Wait()
 }
 
+// reload reloads the configuration using the Reloader. It is called by the
+// reload endpoint of the admin server.
+func (s *Services) reload(_ context.Context) error {
+	if s.Reloader == nil {
+		return reload.ErrNotSupported
+	}
+	return s.Reloader.Reload()
+}
+
+// Reload applies the next configuration to running services. Only changes of
+// the log level, transport address books, LibP2P feeds, active keys of key
+// sets, price models, origins and pairs are applied. If the next configuration contains other changes, none of the
+// changes are applied and an error is returned.
+func (s *Services) Reload(next *Config) error {
+	clients, err := s.config.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: s.Logger})
+	if err != nil {
+		return err
+	}
+	err = config.Reload(s.current, next, s.Logger,
+		config.ReloadRule{
+			Paths: []string{"logger.level"},
+			Prepare: func() (func(), error) {
+				return s.config.Logger.ReloadLevel(next.Logger, loggerConfig.Dependencies{
+					AppName:    "ghost",
+					BaseLogger: s.baseLogger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.AddressBookPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadAddressBook(&next.Transport, transportConfig.Dependencies{
+					Clients: clients,
+					Logger:  s.Logger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.FeedPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadFeeds(&next.Transport)
+			},
+		},
+		config.ReloadRule{
+			Paths: s.config.Ethereum.KeySetPaths("ethereum"),
+			Prepare: func() (func(), error) {
+				return s.config.Ethereum.ReloadKeySets(&next.Ethereum)
+			},
+		},
+		config.ReloadRule{
+			Paths: []string{"gofer"},
+			Prepare: func() (func(), error) {
+				provider, err := next.Gofer.PriceProvider(priceproviderConfig.Dependencies{
+					Clients: clients,
+					Logger:  s.Logger,
+					Health:  s.health,
+				}, s.noRPC)
+				if err != nil {
+					return nil, err
+				}
+				return func() { s.Feed.SetPriceProvider(provider) }, nil
+			},
+		},
+		config.ReloadRule{
+			Paths: []string{"ghost.pairs"},
+			Prepare: func() (func(), error) {
+				return s.config.Ghost.ReloadPairs(&next.Ghost)
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	s.current = next
+	return nil
+}
+
 // Services returns the services configured for Lair.
 func (c *Config) Services(baseLogger log.Logger, noRPC bool) (*Services, error) {
 	logger, err := c.Logger.Logger(loggerConfig.Dependencies{
@@ -85,6 +195,17 @@ func (c *Config) Services(baseLogger log.Logger, noRPC bool) (*Services, error)
 	if err != nil {
 		return nil, err
 	}
+	traceExporter, err := c.Logger.TraceExporter(loggerConfig.Dependencies{
+		AppName:    "ghost",
+		BaseLogger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	auditLog, err := c.Audit.AuditLog(auditConfig.Dependencies{Logger: logger})
+	if err != nil {
+		return nil, err
+	}
 	keys, err := c.Ethereum.KeyRegistry(ethereumConfig.Dependencies{Logger: logger})
 	if err != nil {
 		return nil, err
@@ -93,6 +214,7 @@ func (c *Config) Services(baseLogger log.Logger, noRPC bool) (*Services, error)
 	if err != nil {
 		return nil, err
 	}
+	registry := health.NewRegistry()
 	transport, err := c.Transport.Transport(transportConfig.Dependencies{
 		Keys:    keys,
 		Clients: clients,
@@ -101,6 +223,7 @@ func (c *Config) Services(baseLogger log.Logger, noRPC bool) (*Services, error)
 			messages.PriceV1MessageName: (*messages.Price)(nil),
 		},
 		Logger: logger,
+		Health: registry,
 	})
 	if err != nil {
 		return nil, err
@@ -108,6 +231,7 @@ func (c *Config) Services(baseLogger log.Logger, noRPC bool) (*Services, error)
 	gofer, err := c.Gofer.PriceProvider(priceproviderConfig.Dependencies{
 		Clients: clients,
 		Logger:  logger,
+		Health:  registry,
 	}, noRPC)
 	if err != nil {
 		return nil, err
@@ -117,13 +241,47 @@ func (c *Config) Services(baseLogger log.Logger, noRPC bool) (*Services, error)
 		PriceProvider: gofer,
 		Transport:     transport,
 		Logger:        logger,
+		AuditLog:      auditLog,
+		Health:        registry,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &Services{
-		Feed:      ghost,
-		Transport: transport,
-		Logger:    logger,
-	}, nil
+	services := &Services{
+		Feed:       ghost,
+		Transport:  transport,
+		Logger:     logger,
+		Tracing:    traceExporter,
+		Audit:      auditLog,
+		config:     c,
+		current:    c,
+		health:     registry,
+		baseLogger: baseLogger,
+		noRPC:      noRPC,
+	}
+	services.Admin, err = c.Logger.AdminServer(loggerConfig.AdminDependencies{
+		Logger:   logger,
+		Health:   registry,
+		Handlers: c.Transport.AdminHandlers(),
+		Reload:   services.reload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// Validate checks the Ghost configuration for semantic errors without
+// starting any services.
+func (c *Config) Validate() error {
+	keys, clients, err := c.Ethereum.Validate(ethereumConfig.Dependencies{Logger: null.New()})
+	if err != nil {
+		return err
+	}
+	return config.JoinErrors(
+		c.Transport.Validate(transportConfig.Dependencies{Keys: keys, Clients: clients}),
+		c.Gofer.Validate(priceproviderConfig.Dependencies{Clients: clients}),
+		c.Ghost.Validate(feedConfig.Dependencies{KeysRegistry: keys}),
+		c.Audit.Validate(),
+	)
 }
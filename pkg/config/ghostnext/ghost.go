@@ -3,17 +3,26 @@ package ghostnext
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/audit"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	auditConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/audit"
 	dataproviderConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/dataprovider"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	feedConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/feednext"
 	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
 	transportConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/feed"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing/otlp"
 
 	pkgSupervisor "github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
 	"github.com/chronicleprotocol/oracle-suite/pkg/sysmon"
@@ -28,6 +37,7 @@ type Config struct {
 	Ethereum  ethereumConfig.Config     `hcl:"ethereum,block"`
 	Transport transportConfig.Config    `hcl:"transport,block"`
 	Logger    *loggerConfig.Config      `hcl:"logger,block,optional"`
+	Audit     *auditConfig.Config       `hcl:"audit,block,optional"`
 
 	// HCL fields:
 	Remain  hcl.Body        `hcl:",remain"` // To ignore unknown blocks.
@@ -39,7 +49,15 @@ type Services struct {
 	Feed      *feed.Feed
 	Transport pkgTransport.Transport
 	Logger    log.Logger
+	Tracing   *otlp.Exporter
+	Admin     *httpserver.HTTPServer
+	Audit     *audit.Log
+	Reloader  *reload.Reloader
 
+	config     *Config
+	current    *Config
+	health     *health.Registry
+	baseLogger log.Logger
 	supervisor *pkgSupervisor.Supervisor
 }
 
@@ -49,10 +67,25 @@ func (s *Services) Start(ctx context.Context) error {
 		return fmt.Errorf("services already started")
 	}
 	s.supervisor = pkgSupervisor.New(s.Logger)
-	s.supervisor.Watch(s.Transport, s.Feed, sysmon.New(time.Minute, s.Logger))
+	s.supervisor.SetHealth(s.health)
+	s.supervisor.WatchService(s.config.Transport.Services()...)
+	s.supervisor.WatchService(pkgSupervisor.ServiceConfig{Service: s.Feed, DependsOn: []string{transportConfig.ServiceName}})
+	s.supervisor.Watch(sysmon.New(time.Minute, s.Logger))
 	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
 		s.supervisor.Watch(l)
 	}
+	if s.Tracing != nil {
+		s.supervisor.Watch(s.Tracing)
+	}
+	if s.Admin != nil {
+		s.supervisor.Watch(s.Admin)
+	}
+	if s.Audit != nil {
+		s.supervisor.Watch(s.Audit)
+	}
+	if s.Reloader != nil {
+		s.supervisor.Watch(s.Reloader)
+	}
 	return s.supervisor.Start(ctx)
 }
 
@@ -61,6 +94,84 @@ func (s *Services) Wait() <-chan error {
 	return s.supervisor.Wait()
 }
 
+// reload reloads the configuration using the Reloader. It is called by the
+// reload endpoint of the admin server.
+func (s *Services) reload(_ context.Context) error {
+	if s.Reloader == nil {
+		return reload.ErrNotSupported
+	}
+	return s.Reloader.Reload()
+}
+
+// Reload applies the next configuration to running services. Only changes of
+// the log level, transport address books, LibP2P feeds, active keys of key
+// sets, data models and origins are applied.
+// If the next configuration contains other changes, none of the changes are
+// applied and an error is returned.
+func (s *Services) Reload(next *Config) error {
+	clients, err := s.config.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: s.Logger})
+	if err != nil {
+		return err
+	}
+	err = config.Reload(s.current, next, s.Logger,
+		config.ReloadRule{
+			Paths: []string{"logger.level"},
+			Prepare: func() (func(), error) {
+				return s.config.Logger.ReloadLevel(next.Logger, loggerConfig.Dependencies{
+					AppName:    "ghost",
+					BaseLogger: s.baseLogger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.AddressBookPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadAddressBook(&next.Transport, transportConfig.Dependencies{
+					Clients: clients,
+					Logger:  s.Logger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.FeedPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadFeeds(&next.Transport)
+			},
+		},
+		config.ReloadRule{
+			Paths: s.config.Ethereum.KeySetPaths("ethereum"),
+			Prepare: func() (func(), error) {
+				return s.config.Ethereum.ReloadKeySets(&next.Ethereum)
+			},
+		},
+		config.ReloadRule{
+			Paths: []string{"gofernext"},
+			Prepare: func() (func(), error) {
+				provider, err := next.Gofer.ConfigureDataProvider(dataproviderConfig.Dependencies{
+					Clients: clients,
+					Logger:  s.Logger,
+					Health:  s.health,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return func() { s.Feed.SetDataProvider(provider) }, nil
+			},
+		},
+		config.ReloadRule{
+			Paths: []string{"ghostnext.data_models"},
+			Prepare: func() (func(), error) {
+				return func() { s.Feed.SetDataModels(next.Ghost.DataModels) }, nil
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	s.current = next
+	return nil
+}
+
 // Services returns the services configured for Lair.
 func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	logger, err := c.Logger.Logger(loggerConfig.Dependencies{
@@ -70,6 +181,17 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	if err != nil {
 		return nil, err
 	}
+	traceExporter, err := c.Logger.TraceExporter(loggerConfig.Dependencies{
+		AppName:    "ghost",
+		BaseLogger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	auditLog, err := c.Audit.AuditLog(auditConfig.Dependencies{Logger: logger})
+	if err != nil {
+		return nil, err
+	}
 	keys, err := c.Ethereum.KeyRegistry(ethereumConfig.Dependencies{Logger: logger})
 	if err != nil {
 		return nil, err
@@ -78,6 +200,7 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	if err != nil {
 		return nil, err
 	}
+	registry := health.NewRegistry()
 	transport, err := c.Transport.Transport(transportConfig.Dependencies{
 		Keys:    keys,
 		Clients: clients,
@@ -85,6 +208,7 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 			messages.DataPointV1MessageName: (*messages.DataPoint)(nil),
 		},
 		Logger: logger,
+		Health: registry,
 	})
 	if err != nil {
 		return nil, err
@@ -92,6 +216,7 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	dataProvider, err := c.Gofer.ConfigureDataProvider(dataproviderConfig.Dependencies{
 		Clients: clients,
 		Logger:  logger,
+		Health:  registry,
 	})
 	if err != nil {
 		return nil, err
@@ -101,13 +226,46 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 		DataProvider: dataProvider,
 		Transport:    transport,
 		Logger:       logger,
+		AuditLog:     auditLog,
+		Health:       registry,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &Services{
-		Feed:      feedService,
-		Transport: transport,
-		Logger:    logger,
-	}, nil
+	services := &Services{
+		Feed:       feedService,
+		Transport:  transport,
+		Logger:     logger,
+		Tracing:    traceExporter,
+		Audit:      auditLog,
+		config:     c,
+		current:    c,
+		health:     registry,
+		baseLogger: baseLogger,
+	}
+	services.Admin, err = c.Logger.AdminServer(loggerConfig.AdminDependencies{
+		Logger:   logger,
+		Health:   registry,
+		Handlers: c.Transport.AdminHandlers(),
+		Reload:   services.reload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// Validate checks the Ghost configuration for semantic errors without
+// starting any services.
+func (c *Config) Validate() error {
+	keys, clients, err := c.Ethereum.Validate(ethereumConfig.Dependencies{Logger: null.New()})
+	if err != nil {
+		return err
+	}
+	return config.JoinErrors(
+		c.Transport.Validate(transportConfig.Dependencies{Keys: keys, Clients: clients}),
+		c.Gofer.Validate(dataproviderConfig.Dependencies{HTTPClient: http.DefaultClient, Clients: clients}),
+		c.Ghost.Validate(feedConfig.Dependencies{KeysRegistry: keys}),
+		c.Audit.Validate(),
+	)
 }
@@ -7,18 +7,35 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
 	priceProviderConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/priceprovider"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider"
+	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider/graph"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider/marshal"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider/rpc"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
 	"github.com/chronicleprotocol/oracle-suite/pkg/sysmon"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing/otlp"
 
 	pkgSupervisor "github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
 )
 
+const (
+	// priceProviderServiceName is the name of the service that updates
+	// prices from origins.
+	priceProviderServiceName = "price_provider"
+
+	// priceProviderMaxRetries is the number of consecutive restarts of
+	// failed price updates after which all services are stopped.
+	priceProviderMaxRetries = 5
+)
+
 // Config is the configuration for Gofer.
 type Config struct {
 	Gofer    priceProviderConfig.Config `hcl:"gofer,block"`
@@ -45,7 +62,14 @@ type AgentServices struct {
 	PriceProvider provider.Provider
 	Agent         *rpc.Agent
 	Logger        log.Logger
+	Tracing       *otlp.Exporter
+	Admin         *httpserver.HTTPServer
+	Reloader      *reload.Reloader
 
+	config     *Config
+	current    *Config
+	health     *health.Registry
+	baseLogger log.Logger
 	supervisor *pkgSupervisor.Supervisor
 }
 
@@ -75,13 +99,43 @@ func (s *AgentServices) Start(ctx context.Context) error {
 		return fmt.Errorf("services already started")
 	}
 	s.supervisor = pkgSupervisor.New(s.Logger)
-	s.supervisor.Watch(s.Agent, sysmon.New(time.Minute, s.Logger))
-	if p, ok := s.PriceProvider.(pkgSupervisor.Service); ok {
-		s.supervisor.Watch(p)
+	s.supervisor.SetHealth(s.health)
+	switch p := s.PriceProvider.(type) {
+	case *graph.AsyncProvider:
+		// Prices from origins are updated by workers that are restarted
+		// if they fail. The agent keeps using the first instance to read
+		// prices from the graphs updated by the restarted workers.
+		s.supervisor.WatchService(pkgSupervisor.ServiceConfig{
+			Service:       p,
+			Name:          priceProviderServiceName,
+			RestartPolicy: pkgSupervisor.RestartOnFailure,
+			MaxRetries:    priceProviderMaxRetries,
+			Factory: func() (pkgSupervisor.Service, error) {
+				return p.Clone(), nil
+			},
+		})
+		s.supervisor.WatchService(pkgSupervisor.ServiceConfig{
+			Service:   s.Agent,
+			DependsOn: []string{priceProviderServiceName},
+		})
+	case pkgSupervisor.Service:
+		s.supervisor.Watch(s.Agent, p)
+	default:
+		s.supervisor.Watch(s.Agent)
 	}
+	s.supervisor.Watch(sysmon.New(time.Minute, s.Logger))
 	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
 		s.supervisor.Watch(l)
 	}
+	if s.Tracing != nil {
+		s.supervisor.Watch(s.Tracing)
+	}
+	if s.Admin != nil {
+		s.supervisor.Watch(s.Admin)
+	}
+	if s.Reloader != nil {
+		s.supervisor.Watch(s.Reloader)
+	}
 	return s.supervisor.Start(ctx)
 }
 
@@ -90,6 +144,37 @@ func (s *AgentServices) Wait() <-chan error {
 	return s.supervisor.Wait()
 }
 
+// reload reloads the configuration using the Reloader. It is called by the
+// reload endpoint of the admin server.
+func (s *AgentServices) reload(_ context.Context) error {
+	if s.Reloader == nil {
+		return reload.ErrNotSupported
+	}
+	return s.Reloader.Reload()
+}
+
+// Reload applies the next configuration to running services. Only changes
+// of the log level are applied. If the next configuration contains other
+// changes, none of the changes are applied and an error is returned.
+func (s *AgentServices) Reload(next *Config) error {
+	err := config.Reload(s.current, next, s.Logger,
+		config.ReloadRule{
+			Paths: []string{"logger.level"},
+			Prepare: func() (func(), error) {
+				return s.config.Logger.ReloadLevel(next.Logger, loggerConfig.Dependencies{
+					AppName:    "gofer",
+					BaseLogger: s.baseLogger,
+				})
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	s.current = next
+	return nil
+}
+
 // ClientServices returns the services configured for Gofer.
 func (c *Config) ClientServices(
 	ctx context.Context,
@@ -149,13 +234,22 @@ func (c *Config) AgentServices(baseLogger log.Logger) (*AgentServices, error) {
 	if err != nil {
 		return nil, err
 	}
+	traceExporter, err := c.Logger.TraceExporter(loggerConfig.Dependencies{
+		AppName:    "gofer",
+		BaseLogger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
 	clients, err := c.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: baseLogger})
 	if err != nil {
 		return nil, err
 	}
+	registry := health.NewRegistry()
 	priceProvider, err := c.Gofer.AsyncPriceProvider(priceProviderConfig.AsyncDependencies{
 		Clients: clients,
 		Logger:  baseLogger,
+		Health:  registry,
 	})
 	if err != nil {
 		return nil, err
@@ -167,9 +261,33 @@ func (c *Config) AgentServices(baseLogger log.Logger) (*AgentServices, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &AgentServices{
+	services := &AgentServices{
 		PriceProvider: priceProvider,
 		Agent:         agent,
 		Logger:        logger,
-	}, nil
+		Tracing:       traceExporter,
+		config:        c,
+		current:       c,
+		health:        registry,
+		baseLogger:    baseLogger,
+	}
+	services.Admin, err = c.Logger.AdminServer(loggerConfig.AdminDependencies{
+		Logger: logger,
+		Health: registry,
+		Reload: services.reload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// Validate checks the Gofer configuration for semantic errors without
+// starting any services.
+func (c *Config) Validate() error {
+	_, clients, err := c.Ethereum.Validate(ethereumConfig.Dependencies{Logger: null.New()})
+	if err != nil {
+		return err
+	}
+	return c.Gofer.Validate(priceProviderConfig.Dependencies{Clients: clients})
 }
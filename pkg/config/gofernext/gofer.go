@@ -12,6 +12,7 @@ import (
 	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
 	"github.com/chronicleprotocol/oracle-suite/pkg/datapoint"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	pkgSupervisor "github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
 )
 
@@ -80,3 +81,13 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 		Logger:       logger,
 	}, nil
 }
+
+// Validate checks the Gofer configuration for semantic errors without
+// starting any services.
+func (c *Config) Validate() error {
+	_, clients, err := c.Ethereum.Validate(ethereumConfig.Dependencies{Logger: null.New()})
+	if err != nil {
+		return err
+	}
+	return c.Gofer.Validate(dataproviderConfig.Dependencies{HTTPClient: http.DefaultClient, Clients: clients})
+}
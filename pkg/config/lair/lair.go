@@ -7,6 +7,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	eventAPIConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/eventapi"
 	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
@@ -15,9 +16,14 @@ import (
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/teleportevm"
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/teleportstarknet"
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
 	pkgSupervisor "github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
 	"github.com/chronicleprotocol/oracle-suite/pkg/sysmon"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing/otlp"
 	pkgTransport "github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
 )
@@ -40,7 +46,14 @@ type Services struct {
 	EventStore *store.EventStore
 	EventAPI   *api.EventAPI
 	Logger     log.Logger
+	Tracing    *otlp.Exporter
+	Admin      *httpserver.HTTPServer
+	Reloader   *reload.Reloader
 
+	config     *Config
+	current    *Config
+	health     *health.Registry
+	baseLogger log.Logger
 	supervisor *pkgSupervisor.Supervisor
 }
 
@@ -50,10 +63,22 @@ func (s *Services) Start(ctx context.Context) error {
 		return fmt.Errorf("services already started")
 	}
 	s.supervisor = pkgSupervisor.New(s.Logger)
-	s.supervisor.Watch(s.Transport, s.EventStore, s.EventAPI, sysmon.New(time.Minute, s.Logger))
+	s.supervisor.SetHealth(s.health)
+	s.supervisor.WatchService(s.config.Transport.Services()...)
+	s.supervisor.WatchService(pkgSupervisor.ServiceConfig{Service: s.EventStore, DependsOn: []string{transportConfig.ServiceName}})
+	s.supervisor.Watch(s.EventAPI, sysmon.New(time.Minute, s.Logger))
 	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
 		s.supervisor.Watch(l)
 	}
+	if s.Tracing != nil {
+		s.supervisor.Watch(s.Tracing)
+	}
+	if s.Admin != nil {
+		s.supervisor.Watch(s.Admin)
+	}
+	if s.Reloader != nil {
+		s.supervisor.Watch(s.Reloader)
+	}
 	return s.supervisor.Start(ctx)
 }
 
@@ -62,6 +87,63 @@ func (s *Services) Wait() <-chan error {
 	return s.supervisor.Wait()
 }
 
+// reload reloads the configuration using the Reloader. It is called by the
+// reload endpoint of the admin server.
+func (s *Services) reload(_ context.Context) error {
+	if s.Reloader == nil {
+		return reload.ErrNotSupported
+	}
+	return s.Reloader.Reload()
+}
+
+// Reload applies the next configuration to running services. Only changes of
+// the log level, transport address books, LibP2P feeds and active keys of key
+// sets are applied. If the next configuration contains other changes, none of
+// the changes are applied and an error is returned.
+func (s *Services) Reload(next *Config) error {
+	clients, err := s.config.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: s.Logger})
+	if err != nil {
+		return err
+	}
+	err = config.Reload(s.current, next, s.Logger,
+		config.ReloadRule{
+			Paths: []string{"logger.level"},
+			Prepare: func() (func(), error) {
+				return s.config.Logger.ReloadLevel(next.Logger, loggerConfig.Dependencies{
+					AppName:    "lair",
+					BaseLogger: s.baseLogger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.AddressBookPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadAddressBook(&next.Transport, transportConfig.Dependencies{
+					Clients: clients,
+					Logger:  s.Logger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.FeedPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadFeeds(&next.Transport)
+			},
+		},
+		config.ReloadRule{
+			Paths: s.config.Ethereum.KeySetPaths("ethereum"),
+			Prepare: func() (func(), error) {
+				return s.config.Ethereum.ReloadKeySets(next.Ethereum)
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	s.current = next
+	return nil
+}
+
 // Services returns the services configured for Lair.
 func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	logger, err := c.Logger.Logger(loggerConfig.Dependencies{
@@ -71,6 +153,13 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	if err != nil {
 		return nil, err
 	}
+	traceExporter, err := c.Logger.TraceExporter(loggerConfig.Dependencies{
+		AppName:    "lair",
+		BaseLogger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
 	keys, err := c.Ethereum.KeyRegistry(ethereumConfig.Dependencies{Logger: logger})
 	if err != nil {
 		return nil, err
@@ -79,6 +168,7 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	if err != nil {
 		return nil, err
 	}
+	registry := health.NewRegistry()
 	transport, err := c.Transport.Transport(transportConfig.Dependencies{
 		Clients: clients,
 		Keys:    keys,
@@ -86,6 +176,7 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 		Messages: map[string]pkgTransport.Message{
 			messages.EventV1MessageName: (*messages.Event)(nil),
 		},
+		Health: registry,
 	})
 	if err != nil {
 		return nil, err
@@ -95,10 +186,13 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 		return nil, err
 	}
 	eventStore, err := store.New(store.Config{
-		EventTypes: []string{teleportevm.TeleportEventType, teleportstarknet.TeleportEventType},
-		Storage:    storage,
-		Transport:  transport,
-		Logger:     logger,
+		EventTypes: append(
+			[]string{teleportevm.TeleportEventType, teleportstarknet.TeleportEventType},
+			c.EventAPI.EventTypes...,
+		),
+		Storage:   storage,
+		Transport: transport,
+		Logger:    logger,
 	})
 	if err != nil {
 		return nil, &hcl.Diagnostic{
@@ -110,6 +204,7 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	}
 	eventAPI, err := c.EventAPI.EventAPI(eventAPIConfig.Dependencies{
 		EventStore: eventStore,
+		Clients:    clients,
 		Transport:  transport,
 		Logger:     logger,
 	})
@@ -121,10 +216,109 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 			Subject:  c.EventAPI.Range.Ptr(),
 		}
 	}
-	return &Services{
+	services := &Services{
 		Transport:  transport,
 		EventStore: eventStore,
 		EventAPI:   eventAPI,
 		Logger:     logger,
-	}, nil
+		Tracing:    traceExporter,
+		config:     c,
+		current:    c,
+		health:     registry,
+		baseLogger: baseLogger,
+	}
+	services.Admin, err = c.Logger.AdminServer(loggerConfig.AdminDependencies{
+		Logger:   logger,
+		Health:   registry,
+		Handlers: c.Transport.AdminHandlers(),
+		Reload:   services.reload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// ArchiveServices are the services used to export and import events.
+type ArchiveServices struct {
+	Storage   store.Storage
+	Transport pkgTransport.Transport // Transport is nil if not requested.
+	Logger    log.Logger
+
+	config     *Config
+	supervisor *pkgSupervisor.Supervisor
+}
+
+// Start implements the supervisor.Service interface.
+func (s *ArchiveServices) Start(ctx context.Context) error {
+	if s.supervisor != nil {
+		return fmt.Errorf("services already started")
+	}
+	s.supervisor = pkgSupervisor.New(s.Logger)
+	if s.Transport != nil {
+		s.supervisor.WatchService(s.config.Transport.Services()...)
+	}
+	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
+		s.supervisor.Watch(l)
+	}
+	return s.supervisor.Start(ctx)
+}
+
+// Wait implements the supervisor.Service interface.
+func (s *ArchiveServices) Wait() <-chan error {
+	return s.supervisor.Wait()
+}
+
+// ArchiveServices returns the services used to export and import events.
+// The transport is configured only if withTransport is true.
+func (c *Config) ArchiveServices(baseLogger log.Logger, withTransport bool) (*ArchiveServices, error) {
+	logger, err := c.Logger.Logger(loggerConfig.Dependencies{
+		AppName:    "lair",
+		BaseLogger: baseLogger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	storage, err := c.EventAPI.Storage()
+	if err != nil {
+		return nil, err
+	}
+	services := &ArchiveServices{
+		Storage: storage,
+		Logger:  logger,
+		config:  c,
+	}
+	if !withTransport {
+		return services, nil
+	}
+	keys, err := c.Ethereum.KeyRegistry(ethereumConfig.Dependencies{Logger: logger})
+	if err != nil {
+		return nil, err
+	}
+	clients, err := c.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: logger})
+	if err != nil {
+		return nil, err
+	}
+	services.Transport, err = c.Transport.Transport(transportConfig.Dependencies{
+		Clients: clients,
+		Keys:    keys,
+		Logger:  logger,
+		Messages: map[string]pkgTransport.Message{
+			messages.EventV1MessageName: (*messages.Event)(nil),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// Validate checks the Lair configuration for semantic errors without
+// starting any services.
+func (c *Config) Validate() error {
+	keys, clients, err := c.Ethereum.Validate(ethereumConfig.Dependencies{Logger: null.New()})
+	if err != nil {
+		return err
+	}
+	return c.Transport.Validate(transportConfig.Dependencies{Keys: keys, Clients: clients})
 }
@@ -33,7 +33,14 @@ func TestConfig(t *testing.T) {
 			test: func(t *testing.T, cfg *Config) {
 				_, err := cfg.Services(null.New())
 				require.Error(t, err)
-				require.Contains(t, err.Error(), `multiple-storages.hcl:1,1-5: Validation error; "storage_memory" and "storage_redis" storage types are mutually exclusive`)
+				require.Contains(t, err.Error(), `multiple-storages.hcl:1,1-5: Validation error; "storage_memory", "storage_redis" and "storage_sql" storage types are mutually exclusive`)
+			},
+		},
+		{
+			name: "validate",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				require.NoError(t, cfg.Validate())
 			},
 		},
 	}
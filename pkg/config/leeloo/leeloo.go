@@ -7,14 +7,22 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/audit"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	auditConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/audit"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	leelooConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/eventpublisher"
 	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
 	transportConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
 	pkgSupervisor "github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
 	"github.com/chronicleprotocol/oracle-suite/pkg/sysmon"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing/otlp"
 	pkgTransport "github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
 )
@@ -25,6 +33,7 @@ type Config struct {
 	Ethereum  ethereumConfig.Config  `hcl:"ethereum,block"`
 	Transport transportConfig.Config `hcl:"transport,block"`
 	Logger    *loggerConfig.Config   `hcl:"logger,block,optional"`
+	Audit     *auditConfig.Config    `hcl:"audit,block,optional"`
 
 	// HCL fields:
 	Remain  hcl.Body        `hcl:",remain"` // To ignore unknown blocks.
@@ -36,7 +45,15 @@ type Services struct {
 	Transport      pkgTransport.Transport
 	EventPublisher *publisher.EventPublisher
 	Logger         log.Logger
+	Tracing        *otlp.Exporter
+	Admin          *httpserver.HTTPServer
+	Audit          *audit.Log
+	Reloader       *reload.Reloader
 
+	config     *Config
+	current    *Config
+	health     *health.Registry
+	baseLogger log.Logger
 	supervisor *pkgSupervisor.Supervisor
 }
 
@@ -46,14 +63,29 @@ func (s *Services) Start(ctx context.Context) error {
 		return fmt.Errorf("services already started")
 	}
 	s.supervisor = pkgSupervisor.New(s.Logger)
-	s.supervisor.Watch(
-		s.Transport,
-		pkgSupervisor.NewDelayed(s.EventPublisher, 10*time.Second),
-		sysmon.New(time.Minute, s.Logger),
-	)
+	s.supervisor.SetHealth(s.health)
+	s.supervisor.WatchService(s.config.Transport.Services()...)
+	s.supervisor.WatchService(pkgSupervisor.ServiceConfig{
+		Service:   pkgSupervisor.NewDelayed(s.EventPublisher, 10*time.Second),
+		Name:      "event_publisher",
+		DependsOn: []string{transportConfig.ServiceName},
+	})
+	s.supervisor.Watch(sysmon.New(time.Minute, s.Logger))
 	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
 		s.supervisor.Watch(l)
 	}
+	if s.Tracing != nil {
+		s.supervisor.Watch(s.Tracing)
+	}
+	if s.Admin != nil {
+		s.supervisor.Watch(s.Admin)
+	}
+	if s.Audit != nil {
+		s.supervisor.Watch(s.Audit)
+	}
+	if s.Reloader != nil {
+		s.supervisor.Watch(s.Reloader)
+	}
 	return s.supervisor.Start(ctx)
 }
 
@@ -62,6 +94,63 @@ func (s *Services) Wait() <-chan error {
 	return s.supervisor.Wait()
 }
 
+// reload reloads the configuration using the Reloader. It is called by the
+// reload endpoint of the admin server.
+func (s *Services) reload(_ context.Context) error {
+	if s.Reloader == nil {
+		return reload.ErrNotSupported
+	}
+	return s.Reloader.Reload()
+}
+
+// Reload applies the next configuration to running services. Only changes of
+// the log level, transport address books, LibP2P feeds and active keys of key
+// sets are applied. If the next configuration contains other changes, none of
+// the changes are applied and an error is returned.
+func (s *Services) Reload(next *Config) error {
+	clients, err := s.config.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: s.Logger})
+	if err != nil {
+		return err
+	}
+	err = config.Reload(s.current, next, s.Logger,
+		config.ReloadRule{
+			Paths: []string{"logger.level"},
+			Prepare: func() (func(), error) {
+				return s.config.Logger.ReloadLevel(next.Logger, loggerConfig.Dependencies{
+					AppName:    "leeloo",
+					BaseLogger: s.baseLogger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.AddressBookPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadAddressBook(&next.Transport, transportConfig.Dependencies{
+					Clients: clients,
+					Logger:  s.Logger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.FeedPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadFeeds(&next.Transport)
+			},
+		},
+		config.ReloadRule{
+			Paths: s.config.Ethereum.KeySetPaths("ethereum"),
+			Prepare: func() (func(), error) {
+				return s.config.Ethereum.ReloadKeySets(&next.Ethereum)
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	s.current = next
+	return nil
+}
+
 // Services returns the services configured for Leeloo.
 func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	logger, err := c.Logger.Logger(loggerConfig.Dependencies{
@@ -71,6 +160,17 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	if err != nil {
 		return nil, err
 	}
+	traceExporter, err := c.Logger.TraceExporter(loggerConfig.Dependencies{
+		AppName:    "leeloo",
+		BaseLogger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	auditLog, err := c.Audit.AuditLog(auditConfig.Dependencies{Logger: logger})
+	if err != nil {
+		return nil, err
+	}
 	keys, err := c.Ethereum.KeyRegistry(ethereumConfig.Dependencies{Logger: logger})
 	if err != nil {
 		return nil, err
@@ -79,6 +179,7 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	if err != nil {
 		return nil, err
 	}
+	registry := health.NewRegistry()
 	transport, err := c.Transport.Transport(transportConfig.Dependencies{
 		Keys:    keys,
 		Clients: clients,
@@ -86,6 +187,7 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 			messages.EventV1MessageName: (*messages.Event)(nil),
 		},
 		Logger: logger,
+		Health: registry,
 	})
 	if err != nil {
 		return nil, err
@@ -95,6 +197,7 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 		Clients:   clients,
 		Transport: transport,
 		Logger:    logger,
+		AuditLog:  auditLog,
 	})
 	if err != nil {
 		return nil, &hcl.Diagnostic{
@@ -104,9 +207,49 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 			Subject:  c.Leeloo.Range.Ptr(),
 		}
 	}
-	return &Services{
+	services := &Services{
 		Transport:      transport,
 		EventPublisher: eventPublisher,
 		Logger:         logger,
-	}, nil
+		Tracing:        traceExporter,
+		Audit:          auditLog,
+		config:         c,
+		current:        c,
+		health:         registry,
+		baseLogger:     baseLogger,
+	}
+	services.Admin, err = c.Logger.AdminServer(loggerConfig.AdminDependencies{
+		Logger:   logger,
+		Health:   registry,
+		Handlers: c.Transport.AdminHandlers(),
+		Reload:   services.reload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// Validate checks the Leeloo configuration for semantic errors without
+// starting any services.
+func (c *Config) Validate() error {
+	keys, clients, err := c.Ethereum.Validate(ethereumConfig.Dependencies{Logger: null.New()})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if _, ok := keys[c.Leeloo.EthereumKey]; !ok {
+		errs = append(errs, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Ethereum key %q is not configured", c.Leeloo.EthereumKey),
+			Subject:  c.Leeloo.Content.Attributes["ethereum_key"].Range.Ptr(),
+		})
+	}
+	errs = append(
+		errs,
+		c.Transport.Validate(transportConfig.Dependencies{Keys: keys, Clients: clients}),
+		c.Audit.Validate(),
+	)
+	return config.JoinErrors(errs...)
 }
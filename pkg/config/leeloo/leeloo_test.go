@@ -1,11 +1,13 @@
 package leeloo
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	auditConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/audit"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 )
 
@@ -26,6 +28,24 @@ func TestConfig(t *testing.T) {
 				require.NotNil(t, services.Logger)
 			},
 		},
+		{
+			name: "audit",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				cfg.Audit = &auditConfig.Config{Path: filepath.Join(t.TempDir(), "audit.jsonl")}
+				services, err := cfg.Services(null.New())
+				require.NoError(t, err)
+				require.NotNil(t, services.Audit)
+				require.NoError(t, services.Audit.Close())
+			},
+		},
+		{
+			name: "validate",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				require.NoError(t, cfg.Validate())
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -16,20 +16,32 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"net/http"
 	"regexp"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 
 	suite "github.com/chronicleprotocol/oracle-suite"
+	"github.com/chronicleprotocol/oracle-suite/pkg/admin"
 	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/chain"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/grafana"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric/openmetrics"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric/prometheus"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric/statsd"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/redact"
+	pkgMetrics "github.com/chronicleprotocol/oracle-suite/pkg/metrics"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing/otlp"
 )
 
 type Dependencies struct {
@@ -37,16 +49,78 @@ type Dependencies struct {
 	BaseLogger log.Logger
 }
 
+type AdminDependencies struct {
+	Logger log.Logger
+
+	// Health is the registry used by the health endpoints.
+	Health *health.Registry
+
+	// Reload reloads the configuration, it is used by the reload endpoint.
+	// If nil, the endpoint responds that reloading is not supported.
+	Reload func(ctx context.Context) error
+
+	// Handlers are additional handlers served by the admin server, mapped
+	// to their path prefixes.
+	Handlers map[string]http.Handler
+}
+
 type Config struct {
+	// Level is the log level. If set, it overrides the level set using the
+	// command line flags. It can be changed without restarting the app.
+	Level string `hcl:"level,optional"`
+
 	// Grafana is a configuration for a Grafana logger.
 	Grafana *grafanaLogger `hcl:"grafana,block,optional"`
 
+	// LogMetrics is a configuration for a logger that extracts metrics
+	// from logs and pushes them to configured sinks.
+	LogMetrics *logMetricsLogger `hcl:"log_metrics,block,optional"`
+
+	// Tracing is a configuration for the OTLP trace exporter.
+	Tracing *tracingConfig `hcl:"tracing,block,optional"`
+
+	// Admin is a configuration for the admin HTTP server that exposes
+	// metrics, health, reload and LibP2P admin endpoints.
+	Admin *adminConfig `hcl:"admin,block,optional"`
+
 	// HCL fields:
 	Range   hcl.Range       `hcl:",range"`
 	Content hcl.BodyContent `hcl:",content"`
 
-	// Configured service:
-	logger log.Logger
+	// Configured services:
+	logger        log.Logger
+	baseLevel     log.Level
+	traceExporter *otlp.Exporter
+	adminServer   *httpserver.HTTPServer
+}
+
+type adminConfig struct {
+	// ListenAddr is the address on which the admin server listens.
+	ListenAddr string `hcl:"listen_addr"`
+
+	// MetricsPath is the path of the Prometheus metrics endpoint. If empty,
+	// "/metrics" is used.
+	MetricsPath string `hcl:"metrics_path,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type tracingConfig struct {
+	// Endpoint is the URL of the OTLP/HTTP traces endpoint.
+	Endpoint config.URL `hcl:"endpoint"`
+
+	// Headers are additional HTTP headers sent to the endpoint.
+	Headers map[string]string `hcl:"headers,optional"`
+
+	// Interval is a time interval in seconds between sending batches of
+	// spans. If zero, 5 seconds is used.
+	Interval int `hcl:"interval,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
 }
 
 type grafanaLogger struct {
@@ -60,14 +134,84 @@ type grafanaLogger struct {
 	APIKey string `hcl:"api_key"`
 
 	// Metrics is a list of metrics to send to Grafana.
-	Metrics []grafanaMetric `hcl:"metric,block"`
+	Metrics []logMetric `hcl:"metric,block"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type logMetricsLogger struct {
+	// Interval is a time interval in seconds between pushing metrics to
+	// sinks.
+	Interval int `hcl:"interval"`
+
+	// Metrics is a list of metrics to extract from logs.
+	Metrics []logMetric `hcl:"metric,block"`
+
+	// Grafana is a configuration for the Grafana sink.
+	Grafana *grafanaSink `hcl:"grafana,block,optional"`
+
+	// Prometheus is a configuration for the Prometheus sink.
+	Prometheus *prometheusSink `hcl:"prometheus,block,optional"`
+
+	// StatsD is a configuration for the StatsD sink.
+	StatsD *statsdSink `hcl:"statsd,block,optional"`
+
+	// OpenMetrics is a configuration for the OpenMetrics text file sink.
+	OpenMetrics *openMetricsSink `hcl:"openmetrics,block,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type grafanaSink struct {
+	// Endpoint is a Graphite endpoint.
+	Endpoint config.URL `hcl:"endpoint"`
+
+	// APIKey is a Graphite API key.
+	APIKey string `hcl:"api_key"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type prometheusSink struct {
+	// Type is the type of Prometheus metrics, "gauge" or "counter". If
+	// empty, "gauge" is used. Metrics are exposed using the metrics
+	// endpoint of the admin server.
+	Type string `hcl:"type,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type statsdSink struct {
+	// Address is the UDP address of a StatsD server.
+	Address string `hcl:"address"`
+
+	// Prefix is a prefix added to all metric names.
+	Prefix string `hcl:"prefix,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type openMetricsSink struct {
+	// Path is the path to a file to which metrics are written in the
+	// OpenMetrics text format.
+	Path string `hcl:"path"`
 
 	// HCL fields:
 	Range   hcl.Range       `hcl:",range"`
 	Content hcl.BodyContent `hcl:",content"`
 }
 
-type grafanaMetric struct {
+type logMetric struct {
 	// MatchMessage is a regular expression to match a log message.
 	MatchMessage string `hcl:"match_message"`
 
@@ -106,11 +250,19 @@ type grafanaMetric struct {
 
 func (c *Config) Logger(d Dependencies) (log.Logger, error) {
 	if c == nil {
-		return d.BaseLogger, nil
+		return redact.New(d.BaseLogger, config.RedactSecrets), nil
 	}
 	if c.logger != nil {
 		return c.logger, nil
 	}
+	c.baseLevel = d.BaseLogger.Level()
+	if c.Level != "" {
+		setLevel, err := c.prepareLevel(d.BaseLogger)
+		if err != nil {
+			return nil, err
+		}
+		setLevel()
+	}
 	loggers := []log.Logger{d.BaseLogger}
 	if c.Grafana != nil {
 		logger, err := c.grafanaLogger(d)
@@ -119,11 +271,18 @@ func (c *Config) Logger(d Dependencies) (log.Logger, error) {
 		}
 		loggers = append(loggers, logger)
 	}
+	if c.LogMetrics != nil {
+		logger, err := c.logMetricsLogger(d)
+		if err != nil {
+			return nil, err
+		}
+		loggers = append(loggers, logger)
+	}
 	logger := chain.New(loggers...)
 	if len(loggers) == 1 {
 		logger = loggers[0]
 	}
-	logger = logger.
+	logger = redact.New(logger, config.RedactSecrets).
 		WithFields(log.Fields{
 			"x-appName":    d.AppName,
 			"x-appVersion": suite.Version,
@@ -136,11 +295,263 @@ func (c *Config) Logger(d Dependencies) (log.Logger, error) {
 	return logger, nil
 }
 
+// ReloadLevel validates the log level from the next configuration and
+// returns a function that sets it on the base logger. If the next
+// configuration does not set the level, the function restores the level
+// that the base logger had before the configuration was applied. The Logger
+// method must be called first.
+func (c *Config) ReloadLevel(next *Config, d Dependencies) (func(), error) {
+	if next.Level == "" {
+		return func() {
+			if ls, ok := d.BaseLogger.(log.LevelSetter); ok {
+				ls.SetLevel(c.baseLevel)
+			}
+		}, nil
+	}
+	return next.prepareLevel(d.BaseLogger)
+}
+
+// prepareLevel validates the level attribute and returns a function that
+// sets the log level of the given logger.
+func (c *Config) prepareLevel(logger log.Logger) (func(), error) {
+	lvl, err := log.ParseLevel(c.Level)
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Invalid log level: %v", err),
+			Subject:  c.Content.Attributes["level"].Range.Ptr(),
+		}
+	}
+	ls, ok := logger.(log.LevelSetter)
+	if !ok {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Runtime error",
+			Detail:   "The logger does not support changing the log level",
+			Subject:  c.Content.Attributes["level"].Range.Ptr(),
+		}
+	}
+	return func() { ls.SetLevel(lvl) }, nil
+}
+
+// AdminServer returns an HTTP server that exposes metrics, liveness,
+// readiness and reload endpoints, and the handlers from the dependencies.
+// If the admin block is not configured, it returns nil.
+func (c *Config) AdminServer(d AdminDependencies) (*httpserver.HTTPServer, error) {
+	if c == nil || c.Admin == nil {
+		return nil, nil
+	}
+	if c.adminServer != nil {
+		return c.adminServer, nil
+	}
+	if len(c.Admin.ListenAddr) == 0 {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Admin listen address must not be empty",
+			Subject:  c.Admin.Content.Attributes["listen_addr"].Range.Ptr(),
+		}
+	}
+	if len(c.Admin.MetricsPath) > 0 && !strings.HasPrefix(c.Admin.MetricsPath, "/") {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Metrics path must start with a slash",
+			Subject:  c.Admin.Content.Attributes["metrics_path"].Range.Ptr(),
+		}
+	}
+	c.adminServer = admin.NewServer(admin.Config{
+		ListenAddr:  c.Admin.ListenAddr,
+		MetricsPath: c.Admin.MetricsPath,
+		Health:      d.Health,
+		Reload:      d.Reload,
+		Handlers:    d.Handlers,
+		Logger:      d.Logger,
+	})
+	return c.adminServer, nil
+}
+
+// TraceExporter returns the OTLP trace exporter. It returns nil if tracing
+// is not configured.
+func (c *Config) TraceExporter(d Dependencies) (*otlp.Exporter, error) {
+	if c == nil || c.Tracing == nil {
+		return nil, nil
+	}
+	if c.traceExporter != nil {
+		return c.traceExporter, nil
+	}
+	if c.Tracing.Interval < 0 {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Tracing interval must not be negative",
+			Subject:  c.Tracing.Content.Attributes["interval"].Range.Ptr(),
+		}
+	}
+	exporter, err := otlp.New(otlp.Config{
+		Endpoint:    c.Tracing.Endpoint.String(),
+		ServiceName: d.AppName,
+		Headers:     c.Tracing.Headers,
+		Interval:    time.Duration(c.Tracing.Interval) * time.Second,
+		Logger:      d.BaseLogger,
+	})
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Failed to create the trace exporter: %s", err),
+			Subject:  c.Tracing.Content.Attributes["endpoint"].Range.Ptr(),
+		}
+	}
+	c.traceExporter = exporter
+	return c.traceExporter, nil
+}
+
 func (c *Config) grafanaLogger(d Dependencies) (log.Logger, error) {
+	metrics, err := logMetrics(c.Grafana.Metrics)
+	if err != nil {
+		return nil, err
+	}
+	sink, err := grafana.New(grafana.Config{
+		GraphiteEndpoint: c.Grafana.Endpoint.String(),
+		GraphiteAPIKey:   c.Grafana.APIKey,
+		HTTPClient:       http.DefaultClient,
+	})
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Runtime error",
+			Detail:   fmt.Sprintf("Failed to create the Grafana logger: %s", err),
+			Subject:  c.Range.Ptr(),
+		}
+	}
+	logger, err := metric.New(d.BaseLogger.Level(), metric.Config{
+		Metrics:  metrics,
+		Interval: interval(c.Grafana.Interval),
+		Sinks:    []metric.Sink{sink},
+		Logger:   d.BaseLogger,
+	})
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Runtime error",
+			Detail:   fmt.Sprintf("Failed to create the Grafana logger: %s", err),
+			Subject:  c.Range.Ptr(),
+		}
+	}
+	return logger, nil
+}
+
+//nolint:funlen
+func (c *Config) logMetricsLogger(d Dependencies) (log.Logger, error) {
+	metrics, err := logMetrics(c.LogMetrics.Metrics)
+	if err != nil {
+		return nil, err
+	}
+	var sinks []metric.Sink
+	if cfg := c.LogMetrics.Grafana; cfg != nil {
+		sink, err := grafana.New(grafana.Config{
+			GraphiteEndpoint: cfg.Endpoint.String(),
+			GraphiteAPIKey:   cfg.APIKey,
+			HTTPClient:       http.DefaultClient,
+		})
+		if err != nil {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Runtime error",
+				Detail:   fmt.Sprintf("Failed to create the Grafana sink: %s", err),
+				Subject:  cfg.Range.Ptr(),
+			}
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg := c.LogMetrics.Prometheus; cfg != nil {
+		var kind prometheus.Kind
+		switch strings.ToLower(cfg.Type) {
+		case "gauge", "":
+			kind = prometheus.Gauge
+		case "counter":
+			kind = prometheus.Counter
+		default:
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Invalid value for type: %s. Possible values are: gauge, counter", cfg.Type),
+				Subject:  cfg.Content.Attributes["type"].NameRange.Ptr(),
+			}
+		}
+		sink, err := prometheus.New(prometheus.Config{
+			Registerer: pkgMetrics.Registry,
+			Kind:       kind,
+		})
+		if err != nil {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Runtime error",
+				Detail:   fmt.Sprintf("Failed to create the Prometheus sink: %s", err),
+				Subject:  cfg.Range.Ptr(),
+			}
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg := c.LogMetrics.StatsD; cfg != nil {
+		sink, err := statsd.New(statsd.Config{
+			Address: cfg.Address,
+			Prefix:  cfg.Prefix,
+		})
+		if err != nil {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Failed to create the StatsD sink: %s", err),
+				Subject:  cfg.Content.Attributes["address"].Range.Ptr(),
+			}
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg := c.LogMetrics.OpenMetrics; cfg != nil {
+		sink, err := openmetrics.New(openmetrics.Config{Path: cfg.Path})
+		if err != nil {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Failed to create the OpenMetrics sink: %s", err),
+				Subject:  cfg.Content.Attributes["path"].Range.Ptr(),
+			}
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 0 {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "At least one sink must be configured",
+			Subject:  c.LogMetrics.Range.Ptr(),
+		}
+	}
+	logger, err := metric.New(d.BaseLogger.Level(), metric.Config{
+		Metrics:  metrics,
+		Interval: interval(c.LogMetrics.Interval),
+		Sinks:    sinks,
+		Logger:   d.BaseLogger,
+	})
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Runtime error",
+			Detail:   fmt.Sprintf("Failed to create the log metrics logger: %s", err),
+			Subject:  c.LogMetrics.Range.Ptr(),
+		}
+	}
+	return logger, nil
+}
+
+func logMetrics(cfgs []logMetric) ([]metric.Metric, error) {
 	var err error
-	var metrics []grafana.Metric
-	for _, cfg := range c.Grafana.Metrics {
-		metric := grafana.Metric{
+	var metrics []metric.Metric
+	for _, cfg := range cfgs {
+		m := metric.Metric{
 			Value:         cfg.Value,
 			Name:          cfg.Name,
 			Tags:          cfg.Tags,
@@ -148,7 +559,7 @@ func (c *Config) grafanaLogger(d Dependencies) (log.Logger, error) {
 		}
 
 		// Compile the regular expression for a message:
-		metric.MatchMessage, err = regexp.Compile(cfg.MatchMessage)
+		m.MatchMessage, err = regexp.Compile(cfg.MatchMessage)
 		if err != nil {
 			return nil, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -159,7 +570,7 @@ func (c *Config) grafanaLogger(d Dependencies) (log.Logger, error) {
 		}
 
 		// Compile regular expressions for log fields:
-		metric.MatchFields = map[string]*regexp.Regexp{}
+		m.MatchFields = map[string]*regexp.Regexp{}
 		for f, p := range cfg.MatchFields {
 			rx, err := regexp.Compile(p)
 			if err != nil {
@@ -170,19 +581,19 @@ func (c *Config) grafanaLogger(d Dependencies) (log.Logger, error) {
 					Subject:  cfg.Content.Attributes["match_fields"].NameRange.Ptr(),
 				}
 			}
-			metric.MatchFields[f] = rx
+			m.MatchFields[f] = rx
 		}
 
 		// On duplicate:
 		switch strings.ToLower(cfg.OnDuplicate) {
 		case "sum":
-			metric.OnDuplicate = grafana.Sum
+			m.OnDuplicate = metric.Sum
 		case "min":
-			metric.OnDuplicate = grafana.Min
+			m.OnDuplicate = metric.Min
 		case "max":
-			metric.OnDuplicate = grafana.Max
+			m.OnDuplicate = metric.Max
 		case "replace", "":
-			metric.OnDuplicate = grafana.Replace
+			m.OnDuplicate = metric.Replace
 		default:
 			return nil, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -195,29 +606,16 @@ func (c *Config) grafanaLogger(d Dependencies) (log.Logger, error) {
 			}
 		}
 
-		metrics = append(metrics, metric)
-	}
-	interval := c.Grafana.Interval
-	if interval < 1 {
-		interval = 1
+		metrics = append(metrics, m)
 	}
-	logger, err := grafana.New(d.BaseLogger.Level(), grafana.Config{
-		Metrics:          metrics,
-		Interval:         uint(interval),
-		GraphiteEndpoint: c.Grafana.Endpoint.String(),
-		GraphiteAPIKey:   c.Grafana.APIKey,
-		HTTPClient:       http.DefaultClient,
-		Logger:           d.BaseLogger,
-	})
-	if err != nil {
-		return nil, &hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Runtime error",
-			Detail:   fmt.Sprintf("Failed to create the Grafana logger: %s", err),
-			Subject:  c.Range.Ptr(),
-		}
+	return metrics, nil
+}
+
+func interval(i int) uint {
+	if i < 1 {
+		return 1
 	}
-	return logger, nil
+	return uint(i)
 }
 
 func scalingFunc(sf float64) func(v float64) float64 {
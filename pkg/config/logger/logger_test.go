@@ -3,10 +3,14 @@ package logger
 import (
 	"testing"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	logrusLogger "github.com/chronicleprotocol/oracle-suite/pkg/log/logrus"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 )
 
@@ -34,6 +38,58 @@ func TestConfig(t *testing.T) {
 				assert.Equal(t, "example.message", metric.Name)
 				assert.Equal(t, map[string][]string{"environment": {"production"}}, metric.Tags)
 				assert.Equal(t, "sum", metric.OnDuplicate)
+
+				require.NotNil(t, cfg.LogMetrics)
+				assert.Equal(t, 10, cfg.LogMetrics.Interval)
+				require.Len(t, cfg.LogMetrics.Metrics, 1)
+				assert.Equal(t, "Price updated", cfg.LogMetrics.Metrics[0].MatchMessage)
+				assert.Equal(t, "price", cfg.LogMetrics.Metrics[0].Value)
+				assert.Equal(t, "price.%{pair}", cfg.LogMetrics.Metrics[0].Name)
+				assert.Equal(t, "max", cfg.LogMetrics.Metrics[0].OnDuplicate)
+				assert.Nil(t, cfg.LogMetrics.Grafana)
+				require.NotNil(t, cfg.LogMetrics.Prometheus)
+				assert.Equal(t, "gauge", cfg.LogMetrics.Prometheus.Type)
+				require.NotNil(t, cfg.LogMetrics.StatsD)
+				assert.Equal(t, "127.0.0.1:8125", cfg.LogMetrics.StatsD.Address)
+				assert.Equal(t, "oracle", cfg.LogMetrics.StatsD.Prefix)
+				require.NotNil(t, cfg.LogMetrics.OpenMetrics)
+				assert.Equal(t, "/tmp/oracle-metrics.prom", cfg.LogMetrics.OpenMetrics.Path)
+
+				require.NotNil(t, cfg.Tracing)
+				assert.Equal(t, "http://localhost:4318/v1/traces", cfg.Tracing.Endpoint.String())
+				assert.Equal(t, map[string]string{"Authorization": "Bearer token"}, cfg.Tracing.Headers)
+				assert.Equal(t, 10, cfg.Tracing.Interval)
+
+				require.NotNil(t, cfg.Admin)
+				assert.Equal(t, "127.0.0.1:9100", cfg.Admin.ListenAddr)
+				assert.Equal(t, "/metrics", cfg.Admin.MetricsPath)
+			},
+		},
+		{
+			name: "reload level",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				base := logrus.New()
+				base.SetLevel(logrus.WarnLevel)
+				d := Dependencies{
+					AppName:    "app",
+					BaseLogger: logrusLogger.New(base),
+				}
+				_, err := cfg.Logger(d)
+				require.NoError(t, err)
+
+				commit, err := cfg.ReloadLevel(&Config{Level: "debug"}, d)
+				require.NoError(t, err)
+				assert.Equal(t, log.Warn, d.BaseLogger.Level())
+				commit()
+				assert.Equal(t, log.Debug, d.BaseLogger.Level())
+				assert.Equal(t, logrus.DebugLevel, base.Level)
+
+				commit, err = cfg.ReloadLevel(&Config{}, d)
+				require.NoError(t, err)
+				commit()
+				assert.Equal(t, log.Warn, d.BaseLogger.Level())
+				assert.Equal(t, logrus.WarnLevel, base.Level)
 			},
 		},
 		{
@@ -48,6 +104,30 @@ func TestConfig(t *testing.T) {
 				assert.NotNil(t, service)
 			},
 		},
+		{
+			name: "trace exporter",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				exporter, err := cfg.TraceExporter(Dependencies{
+					AppName:    "app",
+					BaseLogger: null.New(),
+				})
+				require.NoError(t, err)
+				assert.NotNil(t, exporter)
+			},
+		},
+		{
+			name: "admin server",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				srv, err := cfg.AdminServer(AdminDependencies{
+					Logger: null.New(),
+					Health: health.NewRegistry(),
+				})
+				require.NoError(t, err)
+				assert.NotNil(t, srv)
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
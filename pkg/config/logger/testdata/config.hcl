@@ -17,3 +17,42 @@ grafana {
     on_duplicate = "sum"
   }
 }
+
+log_metrics {
+  interval = 10
+
+  metric {
+    match_message = "Price updated"
+    match_fields  = {}
+    value         = "price"
+    name          = "price.%%{pair}"
+    tags          = {}
+    on_duplicate  = "max"
+  }
+
+  prometheus {
+    type = "gauge"
+  }
+
+  statsd {
+    address = "127.0.0.1:8125"
+    prefix  = "oracle"
+  }
+
+  openmetrics {
+    path = "/tmp/oracle-metrics.prom"
+  }
+}
+
+tracing {
+  endpoint = "http://localhost:4318/v1/traces"
+  headers  = {
+    Authorization = "Bearer token"
+  }
+  interval = 10
+}
+
+admin {
+  listen_addr  = "127.0.0.1:9100"
+  metrics_path = "/metrics"
+}
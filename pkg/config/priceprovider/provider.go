@@ -17,16 +17,20 @@ package priceprovider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/defiweb/go-eth/types"
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	utilHCL "github.com/chronicleprotocol/oracle-suite/pkg/util/hcl"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/query"
 
@@ -42,11 +46,17 @@ import (
 type Dependencies struct {
 	Clients ethereumConfig.ClientRegistry
 	Logger  log.Logger
+
+	// Health is an optional registry in which origins report their status.
+	Health *health.Registry
 }
 
 type AsyncDependencies struct {
 	Clients ethereumConfig.ClientRegistry
 	Logger  log.Logger
+
+	// Health is an optional registry in which origins report their status.
+	Health *health.Registry
 }
 
 type AgentDependencies struct {
@@ -170,7 +180,7 @@ func (c *Config) AsyncPriceProvider(d AsyncDependencies) (provider.Provider, err
 	if err != nil {
 		return nil, err
 	}
-	originSet, err := c.buildOrigins(d.Clients)
+	originSet, err := c.buildOrigins(d.Clients, d.Health)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +209,7 @@ func (c *Config) PriceProvider(d Dependencies, noRPC bool) (provider.Provider, e
 		if err != nil {
 			return nil, err
 		}
-		originSet, err := c.buildOrigins(d.Clients)
+		originSet, err := c.buildOrigins(d.Clients, d.Health)
 		if err != nil {
 			return nil, err
 		}
@@ -264,10 +274,49 @@ func (c *Config) PriceHook(d HookDependencies) (provider.PriceHook, error) {
 	return priceHook, nil
 }
 
-func (c *Config) buildOrigins(clients ethereumConfig.ClientRegistry) (*origins.Set, error) {
+// Validate checks the configuration of origins and price models, including
+// references to unknown origins and pairs, cycles in price models and
+// contract addresses in origin parameters, without creating the price
+// provider.
+func (c *Config) Validate(d Dependencies) error {
+	if _, err := c.buildGraphs(); err != nil {
+		return err
+	}
+	var errs []error
+	known := origins.DefaultOriginSet(validationWorkerPool{}).Handlers()
+	for _, origin := range c.Origins {
+		handler, err := NewHandler(origin.Type, validationWorkerPool{}, d.Clients, origin.Params)
+		if err != nil || handler == nil {
+			errs = append(errs, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Failed to create handler for origin %s: %v", origin.Origin, err),
+				Subject:  origin.Range.Ptr(),
+			})
+		}
+		errs = append(errs, origin.validateContracts())
+		known[origin.Origin] = handler
+	}
+	for _, model := range c.PriceModels {
+		errs = append(errs, validateSourceOrigins(model, known))
+	}
+	return config.JoinErrors(errs...)
+}
+
+// validationWorkerPool is used by handlers created during validation. Such
+// handlers are never used to fetch prices, so queries always fail.
+type validationWorkerPool struct{}
+
+// Query implements the query.WorkerPool interface.
+func (validationWorkerPool) Query(*query.HTTPRequest) *query.HTTPResponse {
+	return &query.HTTPResponse{Error: errors.New("queries are not allowed during validation")}
+}
+
+func (c *Config) buildOrigins(clients ethereumConfig.ClientRegistry, h *health.Registry) (*origins.Set, error) {
 	const defaultWorkerCount = 10
 	wp := query.NewHTTPWorkerPool(defaultWorkerCount)
 	originSet := origins.DefaultOriginSet(wp)
+	originSet.SetHealth(h)
 	for _, origin := range c.Origins {
 		handler, err := NewHandler(origin.Type, wp, clients, origin.Params)
 		if err != nil || handler == nil {
@@ -438,6 +487,48 @@ func (c *Config) detectCycle(graphs map[provider.Pair]nodes.Node) error {
 	return nil
 }
 
+// validateContracts checks if contract addresses in the origin parameters
+// are valid Ethereum addresses.
+func (c *configOrigin) validateContracts() error {
+	contracts := parseParamsContracts(c.Params)
+	pairs := make([]string, 0, len(contracts))
+	for pair := range contracts {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+	for _, pair := range pairs {
+		if _, err := types.AddressFromHex(contracts[pair]); err != nil {
+			return &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Invalid contract address for %s in origin %s: %v", pair, c.Origin, err),
+				Subject:  c.Content.Attributes["params"].Range.Ptr(),
+			}
+		}
+	}
+	return nil
+}
+
+// validateSourceOrigins checks if origin nodes of the given source refer to
+// known origins.
+func validateSourceOrigins(source configSource, known map[string]origins.Handler) error {
+	var errs []error
+	if source.Origin != nil && source.Origin.Origin != "." {
+		if _, ok := known[source.Origin.Origin]; !ok {
+			errs = append(errs, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   fmt.Sprintf("Unknown origin %s referenced by %s", source.Origin.Origin, source.Pair),
+				Subject:  source.Range.Ptr(),
+			})
+		}
+	}
+	for _, child := range source.Sources {
+		errs = append(errs, validateSourceOrigins(child, known))
+	}
+	return config.JoinErrors(errs...)
+}
+
 func sortGraphs(graphs map[provider.Pair]nodes.Node) []provider.Pair {
 	var ps []provider.Pair
 	for p := range graphs {
@@ -3,6 +3,7 @@ package priceprovider
 import (
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/mocks"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider"
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/query"
 )
 
 func TestConfig(t *testing.T) {
@@ -76,6 +78,20 @@ func TestConfig(t *testing.T) {
 				assert.NotNil(t, priceProvider)
 			},
 		},
+		{
+			name: "validate",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				var diags hcl.Diagnostics
+				require.ErrorAs(t, cfg.Validate(Dependencies{}), &diags)
+				require.Len(t, diags, 4)
+				assert.Contains(t, diags[0].Detail, "Failed to create handler for origin origin")
+				assert.Contains(t, diags[1].Detail, "Unknown origin origin1 referenced by AAA/BBB")
+
+				// Handlers created during validation must not make requests:
+				assert.Error(t, validationWorkerPool{}.Query(&query.HTTPRequest{URL: "http://localhost"}).Error)
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
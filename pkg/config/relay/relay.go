@@ -16,6 +16,7 @@
 package relay
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -25,6 +26,8 @@ import (
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/geth"
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	medianGeth "github.com/chronicleprotocol/oracle-suite/pkg/price/median/geth"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/relayer"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/store"
@@ -38,11 +41,22 @@ type Dependencies struct {
 	Clients    ethereumConfig.ClientRegistry
 	PriceStore *store.PriceStore
 	Logger     log.Logger
+
+	// Health is an optional registry in which the relayer reports its
+	// status.
+	Health *health.Registry
 }
 
 type PriceStoreDependencies struct {
 	Transport transport.Transport
 	Logger    log.Logger
+
+	// Feeds is the allowlist of feeds from which prices are accepted.
+	Feeds *keyset.Allowlist
+
+	// Health is an optional registry in which the price store reports its
+	// status.
+	Health *health.Registry
 }
 
 type Config struct {
@@ -89,19 +103,85 @@ func (c *Config) Relay(d Dependencies) (*relayer.Relayer, error) {
 	if c.relayer != nil {
 		return c.relayer, nil
 	}
+	if err := c.validateInterval(); err != nil {
+		return nil, err
+	}
+	pairs, err := c.pairs(d)
+	if err != nil {
+		return nil, err
+	}
+	cfg := relayer.Config{
+		PokeTicker: timeutil.NewTicker(time.Second * time.Duration(c.Interval)),
+		PriceStore: d.PriceStore,
+		Pairs:      pairs,
+		Logger:     d.Logger,
+		Health:     d.Health,
+	}
+	rel, err := relayer.New(cfg)
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Relay error",
+			Detail:   fmt.Sprintf("Failed to create the Relay service: %v", err),
+			Subject:  &c.Range,
+		}
+	}
+	c.relayer = rel
+	return rel, nil
+}
+
+// Validate checks the relay configuration, including references to Ethereum
+// clients and contract addresses, without creating the relayer.
+func (c *Config) Validate(d Dependencies) error {
+	if err := c.validateInterval(); err != nil {
+		return err
+	}
+	_, err := c.pairs(d)
+	return err
+}
+
+func (c *Config) validateInterval() error {
 	if c.Interval == 0 {
-		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+		return hcl.Diagnostics{&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Validation error",
 			Detail:   "Interval must be greater than 0",
 			Subject:  c.Content.Attributes["interval"].Range.Ptr(),
 		}}
 	}
-	cfg := relayer.Config{
-		PokeTicker: timeutil.NewTicker(time.Second * time.Duration(c.Interval)),
-		PriceStore: d.PriceStore,
-		Logger:     d.Logger,
+	return nil
+}
+
+// ReloadPairs prepares the pairs from the next configuration and returns a
+// function that replaces the pairs of the running relayer and price store
+// with them. The Relay and PriceStore methods must be called first.
+func (c *Config) ReloadPairs(next *Config, d Dependencies) (func(), error) {
+	if c.relayer == nil || c.priceStore == nil {
+		return nil, errors.New("relay is not configured")
 	}
+	pairs, err := next.pairs(d)
+	if err != nil {
+		return nil, err
+	}
+	setPairs, err := c.relayer.PreparePairs(pairs)
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Runtime error",
+			Detail:   fmt.Sprintf("Failed to update relay pairs: %v", err),
+			Subject:  &next.Range,
+		}
+	}
+	return func() {
+		// Update the price store first, so prices for new pairs are
+		// collected before the relayer starts to use them.
+		c.priceStore.SetPairs(next.pairNames())
+		setPairs()
+	}, nil
+}
+
+func (c *Config) pairs(d Dependencies) ([]*relayer.Pair, error) {
+	var pairs []*relayer.Pair
 	for _, pair := range c.Median {
 		if pair.Expiration == 0 {
 			return nil, hcl.Diagnostics{&hcl.Diagnostic{
@@ -120,8 +200,16 @@ func (c *Config) Relay(d Dependencies) (*relayer.Relayer, error) {
 				Subject:  pair.Content.Attributes["ethereum_client"].Range.Ptr(),
 			}
 		}
+		if pair.ContractAddr == types.ZeroAddress {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   "Contract address must not be zero",
+				Subject:  pair.Content.Attributes["contract_addr"].Range.Ptr(),
+			}
+		}
 		ethClient := geth.NewClient(rpcClient) //nolint:staticcheck // deprecated ethereum.Client
-		cfg.Pairs = append(cfg.Pairs, &relayer.Pair{
+		pairs = append(pairs, &relayer.Pair{
 			AssetPair:                 pair.Pair,
 			Spread:                    pair.Spread,
 			Expiration:                time.Second * time.Duration(pair.Expiration),
@@ -129,32 +217,28 @@ func (c *Config) Relay(d Dependencies) (*relayer.Relayer, error) {
 			FeedAddressesUpdateTicker: timeutil.NewTicker(time.Minute * 60),
 		})
 	}
-	rel, err := relayer.New(cfg)
-	if err != nil {
-		return nil, &hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Relay error",
-			Detail:   fmt.Sprintf("Failed to create the Relay service: %v", err),
-			Subject:  &c.Range,
-		}
+	return pairs, nil
+}
+
+func (c *Config) pairNames() []string {
+	var pairs []string
+	for _, pair := range c.Median {
+		pairs = append(pairs, pair.Pair)
 	}
-	c.relayer = rel
-	return rel, nil
+	return pairs
 }
 
 func (c *Config) PriceStore(d PriceStoreDependencies) (*store.PriceStore, error) {
 	if c.priceStore != nil {
 		return c.priceStore, nil
 	}
-	var pairs []string
-	for _, pair := range c.Median {
-		pairs = append(pairs, pair.Pair)
-	}
 	cfg := store.Config{
 		Storage:   store.NewMemoryStorage(),
 		Transport: d.Transport,
-		Pairs:     pairs,
+		Pairs:     c.pairNames(),
+		Allowlist: d.Feeds,
 		Logger:    d.Logger,
+		Health:    d.Health,
 	}
 	priceStore, err := store.New(cfg)
 	if err != nil {
@@ -0,0 +1,352 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
+)
+
+// ReloadRule describes a part of the configuration that can be changed
+// without restarting the process.
+type ReloadRule struct {
+	// Paths is a list of configuration paths handled by the rule, e.g.
+	// "logger.level". The rule handles changes of the given paths and all
+	// nested fields.
+	Paths []string
+
+	// Prepare validates the changes and prepares everything that may fail
+	// without modifying running services. It returns a function that
+	// applies the prepared changes. The returned function must not fail.
+	Prepare func() (func(), error)
+}
+
+// Reload compares the current and the next configuration and applies the
+// changes using the given rules. Each rule is used at most once.
+//
+// All matched rules are prepared before any of them is applied, and then
+// the prepared changes are applied in the order of the rules. If any of the
+// changes is not handled by the rules, or any of the rules fails to prepare
+// the changes, no changes are applied and an error is returned.
+func Reload(current, next any, logger log.Logger, rules ...ReloadRule) error {
+	changes := Diff(current, next)
+	if len(changes) == 0 {
+		logger.Info("Configuration unchanged")
+		return nil
+	}
+	var (
+		unsafe  []string
+		matched = make([][]string, len(rules))
+	)
+	for _, change := range changes {
+		found := false
+		for i, rule := range rules {
+			if matchPaths(rule.Paths, change) {
+				matched[i] = append(matched[i], change)
+				found = true
+				break
+			}
+		}
+		if !found {
+			unsafe = append(unsafe, change)
+		}
+	}
+	if len(unsafe) > 0 {
+		return fmt.Errorf("changes require restart: %s", strings.Join(unsafe, ", "))
+	}
+	commits := make([]func(), len(rules))
+	for i, rule := range rules {
+		if len(matched[i]) == 0 {
+			continue
+		}
+		commit, err := rule.Prepare()
+		if err != nil {
+			return fmt.Errorf("unable to apply changes of %s: %w", strings.Join(matched[i], ", "), err)
+		}
+		commits[i] = commit
+	}
+	for i, commit := range commits {
+		if len(matched[i]) == 0 {
+			continue
+		}
+		if commit != nil {
+			commit()
+		}
+		logger.
+			WithField("changes", matched[i]).
+			Info("Configuration changes applied")
+	}
+	return nil
+}
+
+// NewReloader returns a service that loads the configuration from the given
+// files when the process receives the SIGHUP signal or when the reload is
+// triggered using the admin API, and passes it to the apply function.
+func NewReloader[T any](paths []string, apply func(*T) error, logger log.Logger) (*reload.Reloader, error) {
+	return reload.New(reload.Config{
+		Reload: func() error {
+			var cfg T
+			if err := LoadFiles(&cfg, paths); err != nil {
+				return err
+			}
+			return apply(&cfg)
+		},
+		Logger: logger,
+	})
+}
+
+// Diff compares two configuration structs of the same type and returns
+// sorted paths of the fields that differ. Paths consist of HCL attribute
+// and block names, e.g. "transport.webapi.static_address_book.addresses".
+// Labeled blocks are identified by their labels, e.g.
+// "gofernext.origin.binance". Source ranges and raw HCL bodies are ignored.
+func Diff(a, b any) []string {
+	d := differ{paths: map[string]struct{}{}}
+	d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+	paths := make([]string, 0, len(d.paths))
+	for p := range d.paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+var (
+	ctyValueType   = reflect.TypeOf(cty.Value{})
+	hclRangeType   = reflect.TypeOf(hcl.Range{})
+	hclContentType = reflect.TypeOf(hcl.BodyContent{})
+	hclBodyType    = reflect.TypeOf((*hcl.Body)(nil)).Elem()
+	hclExprType    = reflect.TypeOf((*hcl.Expression)(nil)).Elem()
+)
+
+type differ struct {
+	paths map[string]struct{}
+}
+
+func (d *differ) add(path string) {
+	if path == "" {
+		path = "."
+	}
+	d.paths[path] = struct{}{}
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.add(path)
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		d.add(path)
+		return
+	}
+	switch a.Type() {
+	case hclRangeType, hclContentType, hclBodyType, hclExprType:
+		return
+	case ctyValueType:
+		if !a.Interface().(cty.Value).RawEquals(b.Interface().(cty.Value)) {
+			d.add(path)
+		}
+		return
+	}
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path)
+			}
+			return
+		}
+		d.diff(path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		d.diffStruct(path, a, b)
+	case reflect.Slice, reflect.Array:
+		d.diffSlice(path, a, b)
+	case reflect.Map:
+		d.diffMap(path, a, b)
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			d.add(path)
+		}
+	}
+}
+
+func (d *differ) diffStruct(path string, a, b reflect.Value) {
+	t := a.Type()
+	exported := false
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fa, fb := a.Field(i), b.Field(i)
+		if !f.IsExported() {
+			// Fields promoted from unexported embedded structs are
+			// decoded like fields of the parent struct.
+			if !f.Anonymous || f.Type.Kind() != reflect.Struct || !fa.CanAddr() || !fb.CanAddr() {
+				continue
+			}
+			fa, fb = accessible(fa), accessible(fb)
+		}
+		exported = true
+		name, kind := hclTag(f)
+		switch kind {
+		case "range", "content", "remain", "body":
+			continue
+		}
+		p := path
+		if name != "" {
+			p = joinPath(path, name)
+		}
+		if kind == "label" {
+			// Labels identify blocks, so a changed label means that the
+			// whole block was replaced.
+			p = path
+		}
+		d.diff(p, fa, fb)
+	}
+	if !exported && !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		// Structs without exported fields, like big.Int, are compared
+		// as a whole.
+		d.add(path)
+	}
+}
+
+func (d *differ) diffSlice(path string, a, b reflect.Value) {
+	la, okA := blockLabels(a)
+	lb, okB := blockLabels(b)
+	if okA && okB {
+		for label, i := range la {
+			j, ok := lb[label]
+			if !ok {
+				d.add(joinPath(path, label))
+				continue
+			}
+			d.diff(joinPath(path, label), a.Index(i), b.Index(j))
+		}
+		for label := range lb {
+			if _, ok := la[label]; !ok {
+				d.add(joinPath(path, label))
+			}
+		}
+		return
+	}
+	if a.Len() != b.Len() {
+		d.add(path)
+		return
+	}
+	for i := 0; i < a.Len(); i++ {
+		d.diff(path, a.Index(i), b.Index(i))
+	}
+}
+
+func (d *differ) diffMap(path string, a, b reflect.Value) {
+	for _, k := range a.MapKeys() {
+		p := joinPath(path, fmt.Sprint(k.Interface()))
+		bv := b.MapIndex(k)
+		if !bv.IsValid() {
+			d.add(p)
+			continue
+		}
+		d.diff(p, a.MapIndex(k), bv)
+	}
+	for _, k := range b.MapKeys() {
+		if !a.MapIndex(k).IsValid() {
+			d.add(joinPath(path, fmt.Sprint(k.Interface())))
+		}
+	}
+}
+
+// blockLabels returns a map of block labels to indices of the elements of
+// the given slice. It returns false if elements are not labeled blocks or
+// labels are not unique.
+func blockLabels(v reflect.Value) (map[string]int, bool) {
+	labels := make(map[string]int, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		label, ok := blockLabel(v.Index(i))
+		if !ok {
+			return nil, false
+		}
+		if _, dup := labels[label]; dup {
+			return nil, false
+		}
+		labels[label] = i
+	}
+	return labels, v.Len() > 0
+}
+
+// blockLabel returns the labels of a block joined with a dot.
+func blockLabel(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return "", false
+	}
+	var labels []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+		if _, kind := hclTag(f); kind == "label" {
+			labels = append(labels, v.Field(i).String())
+		}
+	}
+	return strings.Join(labels, "."), len(labels) > 0
+}
+
+// accessible returns a value that refers to the same memory as v, but can be
+// used with the Interface method even though v was obtained through an
+// unexported field. The v value must be addressable.
+func accessible(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// matchPaths reports whether the path is equal to or nested in one of the
+// given paths.
+func matchPaths(paths []string, path string) bool {
+	for _, p := range paths {
+		if path == p || strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func hclTag(f reflect.StructField) (name, kind string) {
+	tag := f.Tag.Get("hcl")
+	name, kind, _ = strings.Cut(tag, ",")
+	return name, kind
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,204 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+)
+
+type reloadTestConfig struct {
+	Level   string             `hcl:"level,optional"`
+	Pairs   []string           `hcl:"pairs,optional"`
+	Tags    map[string]string  `hcl:"tags,optional"`
+	Origins []reloadTestOrigin `hcl:"origin,block"`
+	Nested  *reloadTestNested  `hcl:"nested,block"`
+	Range   hcl.Range          `hcl:",range"`
+	Content hcl.BodyContent    `hcl:",content"`
+}
+
+type reloadTestOrigin struct {
+	Name string   `hcl:",label"`
+	URL  string   `hcl:"url"`
+	Body hcl.Body `hcl:",remain"`
+}
+
+type reloadTestNested struct {
+	Value int       `hcl:"value"`
+	Range hcl.Range `hcl:",range"`
+}
+
+func loadReloadTestConfig(t *testing.T, src string) *reloadTestConfig {
+	path := filepath.Join(t.TempDir(), "config.hcl")
+	require.NoError(t, os.WriteFile(path, []byte(src), 0600))
+	var cfg reloadTestConfig
+	require.NoError(t, LoadFiles(&cfg, []string{path}))
+	return &cfg
+}
+
+const reloadTestSrc = `
+level = "info"
+pairs = ["ETH/USD", "BTC/USD"]
+tags  = { a = "1" }
+
+origin "binance" {
+  url = "https://binance.com"
+}
+
+origin "kraken" {
+  url = "https://kraken.com"
+}
+
+nested {
+  value = 1
+}
+`
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		next string
+		want []string
+	}{
+		{
+			name: "unchanged",
+			next: "\n\n" + reloadTestSrc,
+			want: []string{},
+		},
+		{
+			name: "attribute",
+			next: `
+level = "debug"
+pairs = ["ETH/USD", "BTC/USD"]
+tags  = { a = "1" }
+origin "binance" { url = "https://binance.com" }
+origin "kraken" { url = "https://kraken.com" }
+nested { value = 1 }
+`,
+			want: []string{"level"},
+		},
+		{
+			name: "list and map",
+			next: `
+level = "info"
+pairs = ["ETH/USD"]
+tags  = { a = "2", b = "1" }
+origin "binance" { url = "https://binance.com" }
+origin "kraken" { url = "https://kraken.com" }
+nested { value = 1 }
+`,
+			want: []string{"pairs", "tags.a", "tags.b"},
+		},
+		{
+			name: "labeled blocks",
+			next: `
+level = "info"
+pairs = ["ETH/USD", "BTC/USD"]
+tags  = { a = "1" }
+origin "kraken" { url = "https://api.kraken.com" }
+origin "binance" { url = "https://binance.com" }
+origin "coinbase" { url = "https://coinbase.com" }
+nested { value = 1 }
+`,
+			want: []string{"origin.coinbase", "origin.kraken.url"},
+		},
+		{
+			name: "nested block",
+			next: `
+level = "info"
+pairs = ["ETH/USD", "BTC/USD"]
+tags  = { a = "1" }
+origin "binance" { url = "https://binance.com" }
+origin "kraken" { url = "https://kraken.com" }
+nested { value = 2 }
+`,
+			want: []string{"nested.value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := loadReloadTestConfig(t, reloadTestSrc)
+			next := loadReloadTestConfig(t, tt.next)
+			assert.Equal(t, tt.want, Diff(current, next))
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	t.Run("apply", func(t *testing.T) {
+		current := loadReloadTestConfig(t, reloadTestSrc)
+		next := *current
+		next.Level = "debug"
+		next.Pairs = []string{"ETH/USD"}
+		var calls []string
+		rule := func(path string) ReloadRule {
+			return ReloadRule{Paths: []string{path}, Prepare: func() (func(), error) {
+				calls = append(calls, "prepare "+path)
+				return func() { calls = append(calls, "apply "+path) }, nil
+			}}
+		}
+		err := Reload(current, &next, null.New(), rule("level"), rule("pairs"), rule("origin"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"prepare level", "prepare pairs", "apply level", "apply pairs"}, calls)
+	})
+	t.Run("unsafe", func(t *testing.T) {
+		current := loadReloadTestConfig(t, reloadTestSrc)
+		next := *current
+		next.Level = "debug"
+		next.Nested = &reloadTestNested{Value: 2}
+		err := Reload(current, &next, null.New(),
+			ReloadRule{Paths: []string{"level"}, Prepare: func() (func(), error) {
+				t.Fatal("rule must not be prepared")
+				return nil, nil
+			}},
+		)
+		require.EqualError(t, err, "changes require restart: nested.value")
+	})
+	t.Run("apply error", func(t *testing.T) {
+		current := loadReloadTestConfig(t, reloadTestSrc)
+		next := *current
+		next.Level = "debug"
+		err := Reload(current, &next, null.New(),
+			ReloadRule{Paths: []string{"level"}, Prepare: func() (func(), error) {
+				return nil, errors.New("invalid level")
+			}},
+		)
+		require.EqualError(t, err, "unable to apply changes of level: invalid level")
+	})
+	t.Run("second rule error", func(t *testing.T) {
+		current := loadReloadTestConfig(t, reloadTestSrc)
+		next := *current
+		next.Level = "debug"
+		next.Pairs = []string{"ETH/USD"}
+		err := Reload(current, &next, null.New(),
+			ReloadRule{Paths: []string{"level"}, Prepare: func() (func(), error) {
+				return func() { t.Fatal("rule must not be applied") }, nil
+			}},
+			ReloadRule{Paths: []string{"pairs"}, Prepare: func() (func(), error) {
+				return nil, errors.New("invalid pair")
+			}},
+		)
+		require.EqualError(t, err, "unable to apply changes of pairs: invalid pair")
+	})
+}
@@ -0,0 +1,296 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyJSON "github.com/zclconf/go-cty/cty/json"
+
+	utilHCL "github.com/chronicleprotocol/oracle-suite/pkg/util/hcl"
+)
+
+// Formats supported by the RenderFiles function.
+const (
+	RenderHCL  = "hcl"
+	RenderJSON = "json"
+)
+
+// RenderFiles loads the given paths into the given config in the same way as
+// LoadFiles and returns the effective configuration in the given format.
+// In the returned configuration, included files are merged, variables,
+// environment variables and functions are evaluated and dynamic blocks are
+// expanded. Secrets resolved by the "secret" function are redacted. Only
+// attributes and blocks that were decoded into the config are rendered.
+func RenderFiles(config any, paths []string, format string) ([]byte, error) {
+	if format != RenderHCL && format != RenderJSON {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	body, err := loadBody(paths)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]int{}
+	rec := &recordingBody{
+		body:  body,
+		node:  &renderNode{attrs: map[string]*hcl.Attribute{}, files: files},
+		files: files,
+	}
+	for _, path := range paths {
+		rec.fileRank(path)
+	}
+	if diags := utilHCL.Decode(hclContext, rec, config); diags.HasErrors() {
+		return nil, diags
+	}
+	items, diags := rec.node.render()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if format == RenderJSON {
+		b, err := json.MarshalIndent(jsonBody(items), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	}
+	f := hclwrite.NewEmptyFile()
+	hclBody(f.Body(), items)
+	return hclwrite.Format(f.Bytes()), nil
+}
+
+// renderItem is an evaluated attribute or an expanded block.
+type renderItem struct {
+	name   string
+	labels []string
+	value  cty.Value    // Attribute value.
+	body   []renderItem // Block body.
+	block  bool
+	file   int // Position of the source file, used for sorting.
+	offset int // Position in the source file, used for sorting.
+}
+
+// renderNode holds the attributes and blocks of a body that were read by
+// the decoder.
+type renderNode struct {
+	attrs  map[string]*hcl.Attribute
+	blocks []*hcl.Block
+	nodes  []*renderNode
+	files  map[string]int
+}
+
+// recordingBody wraps the body that is passed to the decoder and records the
+// content read from it, so that exactly the decoded configuration can be
+// rendered. Bodies of nested blocks are wrapped as well.
+type recordingBody struct {
+	body  hcl.Body
+	node  *renderNode
+	files map[string]int
+}
+
+// Content implements the hcl.Body interface.
+func (b *recordingBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	content, diags := b.body.Content(schema)
+	b.record(content)
+	return content, diags
+}
+
+// PartialContent implements the hcl.Body interface.
+func (b *recordingBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	content, remain, diags := b.body.PartialContent(schema)
+	b.record(content)
+	if remain != nil {
+		remain = &recordingBody{body: remain, node: b.node, files: b.files}
+	}
+	return content, remain, diags
+}
+
+// JustAttributes implements the hcl.Body interface.
+func (b *recordingBody) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	attrs, diags := b.body.JustAttributes()
+	b.record(&hcl.BodyContent{Attributes: attrs})
+	return attrs, diags
+}
+
+// MissingItemRange implements the hcl.Body interface.
+func (b *recordingBody) MissingItemRange() hcl.Range {
+	return b.body.MissingItemRange()
+}
+
+// record adds the attributes and blocks of the content to the node and
+// wraps bodies of the blocks.
+func (b *recordingBody) record(content *hcl.BodyContent) {
+	if content == nil {
+		return
+	}
+	for i, block := range content.Blocks {
+		b.fileRank(block.DefRange.Filename)
+		node := &renderNode{attrs: map[string]*hcl.Attribute{}, files: b.files}
+		wrapped := *block
+		wrapped.Body = &recordingBody{body: block.Body, node: node, files: b.files}
+		content.Blocks[i] = &wrapped
+		b.node.blocks = append(b.node.blocks, &wrapped)
+		b.node.nodes = append(b.node.nodes, node)
+	}
+	names := make([]string, 0, len(content.Attributes))
+	for name := range content.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attr := content.Attributes[name]
+		b.fileRank(attr.Range.Filename)
+		b.node.attrs[name] = attr
+	}
+}
+
+// fileRank returns the position of the file in the order in which files
+// were read.
+func (b *recordingBody) fileRank(filename string) int {
+	if rank, ok := b.files[filename]; ok {
+		return rank
+	}
+	b.files[filename] = len(b.files)
+	return b.files[filename]
+}
+
+// render evaluates the recorded attributes and renders the recorded blocks.
+func (n *renderNode) render() ([]renderItem, hcl.Diagnostics) {
+	var items []renderItem
+	for name, attr := range n.attrs {
+		value, diags := attr.Expr.Value(hclContext)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if !value.IsWhollyKnown() {
+			return nil, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to render configuration",
+				Detail:   fmt.Sprintf("The value of the %s attribute is not known.", name),
+				Subject:  attr.Range.Ptr(),
+			}}
+		}
+		items = append(items, renderItem{
+			name:   name,
+			value:  secretResolver.RedactValue(value),
+			file:   n.files[attr.Range.Filename],
+			offset: attr.Range.Start.Byte,
+		})
+	}
+	for i, block := range n.blocks {
+		body, diags := n.nodes[i].render()
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		items = append(items, renderItem{
+			name:   block.Type,
+			labels: block.Labels,
+			body:   body,
+			block:  true,
+			file:   n.files[block.DefRange.Filename],
+			offset: block.DefRange.Start.Byte,
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].file != items[j].file {
+			return items[i].file < items[j].file
+		}
+		return items[i].offset < items[j].offset
+	})
+	return items, nil
+}
+
+func hclBody(body *hclwrite.Body, items []renderItem) {
+	for i, item := range items {
+		if !item.block {
+			body.SetAttributeValue(item.name, item.value)
+			continue
+		}
+		if i > 0 {
+			body.AppendNewline()
+		}
+		hclBody(body.AppendNewBlock(item.name, item.labels).Body(), item.body)
+	}
+}
+
+// jsonObject is a JSON object that preserves the order of fields.
+type jsonObject []jsonField
+
+type jsonField struct {
+	key   string
+	value any
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonBody converts rendered items to an object that follows the HCL JSON
+// syntax. Blocks of the same type are grouped in an array. Labels are
+// represented as nested objects.
+func jsonBody(items []renderItem) jsonObject {
+	var (
+		obj    jsonObject
+		blocks = map[string]int{}
+	)
+	for _, item := range items {
+		if !item.block {
+			obj = append(obj, jsonField{key: item.name, value: ctyJSON.SimpleJSONValue{Value: item.value}})
+			continue
+		}
+		var value any = jsonBody(item.body)
+		for i := len(item.labels) - 1; i >= 0; i-- {
+			value = jsonObject{{key: item.labels[i], value: value}}
+		}
+		idx, ok := blocks[item.name]
+		if !ok {
+			blocks[item.name] = len(obj)
+			obj = append(obj, jsonField{key: item.name, value: value})
+			continue
+		}
+		if list, ok := obj[idx].value.([]any); ok {
+			obj[idx].value = append(list, value)
+		} else {
+			obj[idx].value = []any{obj[idx].value, value}
+		}
+	}
+	return obj
+}
@@ -0,0 +1,193 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	utilHCL "github.com/chronicleprotocol/oracle-suite/pkg/util/hcl"
+)
+
+type renderTestConfig struct {
+	Name    string             `hcl:"name"`
+	Origins []renderTestOrigin `hcl:"origin,block"`
+	Remain  hcl.Body           `hcl:",remain"`
+}
+
+type renderTestOrigin struct {
+	Name string `hcl:",label"`
+	URL  string `hcl:"url"`
+}
+
+type renderTestTypedConfig struct {
+	Nodes []*renderTestNode `hcl:"node,block"`
+}
+
+type renderTestNode struct {
+	Type   string   `hcl:",label"`
+	Remain hcl.Body `hcl:",remain"`
+	Value  any
+}
+
+func (n *renderTestNode) PostDecodeBlock(ctx *hcl.EvalContext, _ *hcl.BodySchema, _ *hcl.Block, _ *hcl.BodyContent) hcl.Diagnostics {
+	switch n.Type {
+	case "number":
+		n.Value = &struct {
+			Number int `hcl:"number"`
+		}{}
+	case "text":
+		n.Value = &struct {
+			Text string `hcl:"text"`
+		}{}
+	}
+	return utilHCL.Decode(ctx, n.Remain, n.Value)
+}
+
+func TestRenderFiles(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "config.hcl")
+	require.NoError(t, os.WriteFile(main, []byte(`
+include = ["`+filepath.Join(dir, "origins.hcl")+`"]
+
+variables {
+  host = "example.com"
+}
+
+name = "test-${var.host}"
+
+unknown {
+  foo = "bar"
+}
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "origins.hcl"), []byte(`
+dynamic "origin" {
+  for_each = ["a", "b"]
+  labels   = [origin.value]
+  content {
+    url = "https://${origin.value}.${var.host}"
+  }
+}
+`), 0600))
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: RenderHCL,
+			want: `name = "test-example.com"
+
+origin "a" {
+  url = "https://a.example.com"
+}
+
+origin "b" {
+  url = "https://b.example.com"
+}
+`,
+		},
+		{
+			format: RenderJSON,
+			want: `{
+  "name": "test-example.com",
+  "origin": [
+    {
+      "a": {
+        "url": "https://a.example.com"
+      }
+    },
+    {
+      "b": {
+        "url": "https://b.example.com"
+      }
+    }
+  ]
+}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var cfg renderTestConfig
+			b, err := RenderFiles(&cfg, []string{main}, test.format)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, string(b))
+			assert.Len(t, cfg.Origins, 2)
+		})
+	}
+}
+
+func TestRenderFiles_Remain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.hcl")
+	require.NoError(t, os.WriteFile(path, []byte(`
+node "number" {
+  number = 1 + 1
+}
+
+node "text" {
+  text = "foo"
+}
+`), 0600))
+
+	var cfg renderTestTypedConfig
+	b, err := RenderFiles(&cfg, []string{path}, RenderHCL)
+	require.NoError(t, err)
+	assert.Equal(t, `node "number" {
+  number = 2
+}
+
+node "text" {
+  text = "foo"
+}
+`, string(b))
+}
+
+func TestRenderFiles_Secrets(t *testing.T) {
+	t.Setenv("RENDER_TEST_SECRET", "secret-value")
+	path := filepath.Join(t.TempDir(), "config.hcl")
+	require.NoError(t, os.WriteFile(path, []byte(`name = secret("env:RENDER_TEST_SECRET")`), 0600))
+
+	var cfg renderTestConfig
+	b, err := RenderFiles(&cfg, []string{path}, RenderHCL)
+	require.NoError(t, err)
+	assert.Equal(t, "name = \"(redacted)\"\n", string(b))
+	assert.Equal(t, "secret-value", cfg.Name)
+	assert.Equal(t, "key=(redacted)", RedactSecrets("key=secret-value"))
+}
+
+func TestJoinErrors(t *testing.T) {
+	assert.NoError(t, JoinErrors(nil, nil))
+
+	err := JoinErrors(
+		nil,
+		&hcl.Diagnostic{Severity: hcl.DiagError, Summary: "a"},
+		hcl.Diagnostics{{Severity: hcl.DiagError, Summary: "b"}},
+		os.ErrNotExist,
+	)
+	var diags hcl.Diagnostics
+	require.ErrorAs(t, err, &diags)
+	require.Len(t, diags, 3)
+	assert.Equal(t, "a", diags[0].Summary)
+	assert.Equal(t, "b", diags[1].Summary)
+	assert.Equal(t, "Validation error", diags[2].Summary)
+	assert.Equal(t, os.ErrNotExist.Error(), diags[2].Detail)
+}
@@ -7,15 +7,22 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
 	relayConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/relay"
 	transportConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/relayer"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
 	pkgSupervisor "github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
 	"github.com/chronicleprotocol/oracle-suite/pkg/sysmon"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing/otlp"
 	pkgTransport "github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
 )
@@ -38,7 +45,15 @@ type Services struct {
 	PriceStore *store.PriceStore
 	Transport  pkgTransport.Transport
 	Logger     log.Logger
+	Tracing    *otlp.Exporter
+	Admin      *httpserver.HTTPServer
+	Reloader   *reload.Reloader
 
+	config     *Config
+	current    *Config
+	feeds      *keyset.Allowlist
+	health     *health.Registry
+	baseLogger log.Logger
 	supervisor *pkgSupervisor.Supervisor
 }
 
@@ -48,10 +63,22 @@ func (s *Services) Start(ctx context.Context) error {
 		return fmt.Errorf("services already started")
 	}
 	s.supervisor = pkgSupervisor.New(s.Logger)
-	s.supervisor.Watch(s.Transport, s.PriceStore, s.Relay, sysmon.New(time.Minute, s.Logger))
+	s.supervisor.SetHealth(s.health)
+	s.supervisor.WatchService(s.config.Transport.Services()...)
+	s.supervisor.WatchService(pkgSupervisor.ServiceConfig{Service: s.PriceStore, DependsOn: []string{transportConfig.ServiceName}})
+	s.supervisor.Watch(s.Relay, sysmon.New(time.Minute, s.Logger))
 	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
 		s.supervisor.Watch(l)
 	}
+	if s.Tracing != nil {
+		s.supervisor.Watch(s.Tracing)
+	}
+	if s.Admin != nil {
+		s.supervisor.Watch(s.Admin)
+	}
+	if s.Reloader != nil {
+		s.supervisor.Watch(s.Reloader)
+	}
 	return s.supervisor.Start(ctx)
 }
 
@@ -60,6 +87,74 @@ func (s *Services) Wait() <-chan error {
 	return s.supervisor.Wait()
 }
 
+// reload reloads the configuration using the Reloader. It is called by the
+// reload endpoint of the admin server.
+func (s *Services) reload(_ context.Context) error {
+	if s.Reloader == nil {
+		return reload.ErrNotSupported
+	}
+	return s.Reloader.Reload()
+}
+
+// Reload applies the next configuration to running services. Only changes of
+// the log level, transport address books, LibP2P feeds, active keys of key
+// sets and median relayer pairs are applied.
+// If the next configuration contains other changes, none of the changes are
+// applied and an error is returned.
+func (s *Services) Reload(next *Config) error {
+	clients, err := s.config.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: s.Logger})
+	if err != nil {
+		return err
+	}
+	err = config.Reload(s.current, next, s.Logger,
+		config.ReloadRule{
+			Paths: []string{"logger.level"},
+			Prepare: func() (func(), error) {
+				return s.config.Logger.ReloadLevel(next.Logger, loggerConfig.Dependencies{
+					AppName:    "spectre",
+					BaseLogger: s.baseLogger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.AddressBookPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadAddressBook(&next.Transport, transportConfig.Dependencies{
+					Clients: clients,
+					Logger:  s.Logger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.FeedPaths("transport"),
+			Prepare: func() (func(), error) {
+				return func() { s.feeds.Update(next.Transport.FeedAddresses(), 0) }, nil
+			},
+		},
+		config.ReloadRule{
+			Paths: s.config.Ethereum.KeySetPaths("ethereum"),
+			Prepare: func() (func(), error) {
+				return s.config.Ethereum.ReloadKeySets(&next.Ethereum)
+			},
+		},
+		config.ReloadRule{
+			Paths: []string{"spectre.median"},
+			Prepare: func() (func(), error) {
+				return s.config.Spectre.ReloadPairs(&next.Spectre, relayConfig.Dependencies{
+					Clients:    clients,
+					PriceStore: s.PriceStore,
+					Logger:     s.Logger,
+				})
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	s.current = next
+	return nil
+}
+
 // Services returns the services configured for Spectre.
 func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	logger, err := c.Logger.Logger(loggerConfig.Dependencies{
@@ -69,6 +164,13 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	if err != nil {
 		return nil, err
 	}
+	traceExporter, err := c.Logger.TraceExporter(loggerConfig.Dependencies{
+		AppName:    "spectre",
+		BaseLogger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
 	keys, err := c.Ethereum.KeyRegistry(ethereumConfig.Dependencies{Logger: logger})
 	if err != nil {
 		return nil, err
@@ -77,6 +179,11 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Spectre does not have its own list of feeds, so the price store
+	// shares the allowlist with the transport and accepts prices from the
+	// feeds of all transports, including feeds added using the admin API.
+	feeds := keyset.NewAllowlist(c.Transport.FeedAddresses())
+	registry := health.NewRegistry()
 	transport, err := c.Transport.Transport(transportConfig.Dependencies{
 		Keys:    keys,
 		Clients: clients,
@@ -85,6 +192,8 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 			messages.PriceV1MessageName: (*messages.Price)(nil),
 		},
 		Logger: logger,
+		Feeds:  feeds,
+		Health: registry,
 	})
 	if err != nil {
 		return nil, err
@@ -92,6 +201,8 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 	priceStore, err := c.Spectre.PriceStore(relayConfig.PriceStoreDependencies{
 		Transport: transport,
 		Logger:    logger,
+		Feeds:     feeds,
+		Health:    registry,
 	})
 	if err != nil {
 		return nil, err
@@ -100,14 +211,44 @@ func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
 		Clients:    clients,
 		PriceStore: priceStore,
 		Logger:     logger,
+		Health:     registry,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &Services{
+	services := &Services{
 		Relay:      relay,
 		PriceStore: priceStore,
 		Transport:  transport,
 		Logger:     logger,
-	}, nil
+		Tracing:    traceExporter,
+		config:     c,
+		current:    c,
+		feeds:      feeds,
+		health:     registry,
+		baseLogger: baseLogger,
+	}
+	services.Admin, err = c.Logger.AdminServer(loggerConfig.AdminDependencies{
+		Logger:   logger,
+		Health:   registry,
+		Handlers: c.Transport.AdminHandlers(),
+		Reload:   services.reload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// Validate checks the Spectre configuration for semantic errors without
+// starting any services.
+func (c *Config) Validate() error {
+	keys, clients, err := c.Ethereum.Validate(ethereumConfig.Dependencies{Logger: null.New()})
+	if err != nil {
+		return err
+	}
+	return config.JoinErrors(
+		c.Transport.Validate(transportConfig.Dependencies{Keys: keys, Clients: clients}),
+		c.Spectre.Validate(relayConfig.Dependencies{Clients: clients}),
+	)
 }
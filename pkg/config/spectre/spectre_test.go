@@ -27,6 +27,13 @@ func TestConfig(t *testing.T) {
 				require.NotNil(t, services.Logger)
 			},
 		},
+		{
+			name: "validate",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				require.NoError(t, cfg.Validate())
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -23,14 +23,20 @@ import (
 	"github.com/defiweb/go-eth/types"
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
 	transportConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/reload"
 	"github.com/chronicleprotocol/oracle-suite/pkg/spire"
 	pkgSupervisor "github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
 	"github.com/chronicleprotocol/oracle-suite/pkg/sysmon"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing/otlp"
 	pkgTransport "github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
 )
@@ -57,7 +63,8 @@ type ConfigSpire struct {
 	// Pairs is a list of pairs to store in the price store.
 	Pairs []string `hcl:"pairs"`
 
-	// Pairs is a list of pairs to store in the price store.
+	// Feeds is a list of feeds from which prices are accepted by the price
+	// store.
 	Feeds []types.Address `hcl:"feeds"`
 
 	// EthereumKey is a name of an Ethereum key to use for signing
@@ -88,7 +95,14 @@ type AgentServices struct {
 	Transport  pkgTransport.Transport
 	PriceStore *store.PriceStore
 	Logger     log.Logger
-
+	Tracing    *otlp.Exporter
+	Admin      *httpserver.HTTPServer
+	Reloader   *reload.Reloader
+
+	config     *Config
+	current    *Config
+	health     *health.Registry
+	baseLogger log.Logger
 	supervisor *pkgSupervisor.Supervisor
 }
 
@@ -97,6 +111,7 @@ type StreamServices struct {
 	Transport pkgTransport.Transport
 	Logger    log.Logger
 
+	config     *Config
 	supervisor *pkgSupervisor.Supervisor
 }
 
@@ -124,10 +139,25 @@ func (s *AgentServices) Start(ctx context.Context) error {
 		return fmt.Errorf("services already started")
 	}
 	s.supervisor = pkgSupervisor.New(s.Logger)
-	s.supervisor.Watch(s.Transport, s.PriceStore, s.SpireAgent, sysmon.New(time.Minute, s.Logger))
+	s.supervisor.SetHealth(s.health)
+	s.supervisor.WatchService(s.config.Transport.Services()...)
+	s.supervisor.WatchService(
+		pkgSupervisor.ServiceConfig{Service: s.PriceStore, DependsOn: []string{transportConfig.ServiceName}},
+		pkgSupervisor.ServiceConfig{Service: s.SpireAgent, DependsOn: []string{transportConfig.ServiceName}},
+	)
+	s.supervisor.Watch(sysmon.New(time.Minute, s.Logger))
 	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
 		s.supervisor.Watch(l)
 	}
+	if s.Tracing != nil {
+		s.supervisor.Watch(s.Tracing)
+	}
+	if s.Admin != nil {
+		s.supervisor.Watch(s.Admin)
+	}
+	if s.Reloader != nil {
+		s.supervisor.Watch(s.Reloader)
+	}
 	return s.supervisor.Start(ctx)
 }
 
@@ -136,13 +166,84 @@ func (s *AgentServices) Wait() <-chan error {
 	return s.supervisor.Wait()
 }
 
+// reload reloads the configuration using the Reloader. It is called by the
+// reload endpoint of the admin server.
+func (s *AgentServices) reload(_ context.Context) error {
+	if s.Reloader == nil {
+		return reload.ErrNotSupported
+	}
+	return s.Reloader.Reload()
+}
+
+// Reload applies the next configuration to running services. Only changes of
+// the log level, transport address books, LibP2P feeds, active keys of key
+// sets, pairs and feeds are applied. If the next
+// configuration contains other changes, none of the changes are applied and an
+// error is returned.
+func (s *AgentServices) Reload(next *Config) error {
+	clients, err := s.config.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: s.Logger})
+	if err != nil {
+		return err
+	}
+	err = config.Reload(s.current, next, s.Logger,
+		config.ReloadRule{
+			Paths: []string{"logger.level"},
+			Prepare: func() (func(), error) {
+				return s.config.Logger.ReloadLevel(next.Logger, loggerConfig.Dependencies{
+					AppName:    "spire",
+					BaseLogger: s.baseLogger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.AddressBookPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadAddressBook(&next.Transport, transportConfig.Dependencies{
+					Clients: clients,
+					Logger:  s.Logger,
+				})
+			},
+		},
+		config.ReloadRule{
+			Paths: transportConfig.FeedPaths("transport"),
+			Prepare: func() (func(), error) {
+				return s.config.Transport.ReloadFeeds(&next.Transport)
+			},
+		},
+		config.ReloadRule{
+			Paths: s.config.Ethereum.KeySetPaths("ethereum"),
+			Prepare: func() (func(), error) {
+				return s.config.Ethereum.ReloadKeySets(&next.Ethereum)
+			},
+		},
+		config.ReloadRule{
+			Paths: []string{"spire.pairs"},
+			Prepare: func() (func(), error) {
+				return func() { s.PriceStore.SetPairs(next.Spire.Pairs) }, nil
+			},
+		},
+		config.ReloadRule{
+			Paths: []string{"spire.feeds"},
+			Prepare: func() (func(), error) {
+				return func() { s.PriceStore.SetFeeds(next.Spire.Feeds) }, nil
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	s.current = next
+	return nil
+}
+
 // Start implements the supervisor.Service interface.
 func (s *StreamServices) Start(ctx context.Context) error {
 	if s.supervisor != nil {
 		return fmt.Errorf("services already started")
 	}
 	s.supervisor = pkgSupervisor.New(s.Logger)
-	s.supervisor.Watch(s.Transport, sysmon.New(time.Minute, s.Logger))
+	s.supervisor.WatchService(s.config.Transport.Services()...)
+	s.supervisor.Watch(sysmon.New(time.Minute, s.Logger))
 	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
 		s.supervisor.Watch(l)
 	}
@@ -186,6 +287,13 @@ func (c *Config) AgentServices(baseLogger log.Logger) (*AgentServices, error) {
 	if err != nil {
 		return nil, err
 	}
+	traceExporter, err := c.Logger.TraceExporter(loggerConfig.Dependencies{
+		AppName:    "spire",
+		BaseLogger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
 	keys, err := c.Ethereum.KeyRegistry(ethereumConfig.Dependencies{Logger: logger})
 	if err != nil {
 		return nil, err
@@ -194,6 +302,7 @@ func (c *Config) AgentServices(baseLogger log.Logger) (*AgentServices, error) {
 	if err != nil {
 		return nil, err
 	}
+	registry := health.NewRegistry()
 	transport, err := c.Transport.Transport(transportConfig.Dependencies{
 		Keys:    keys,
 		Clients: clients,
@@ -202,11 +311,12 @@ func (c *Config) AgentServices(baseLogger log.Logger) (*AgentServices, error) {
 			messages.PriceV1MessageName: (*messages.Price)(nil),
 		},
 		Logger: logger,
+		Health: registry,
 	})
 	if err != nil {
 		return nil, err
 	}
-	priceStore, err := c.Spire.PriceStore(logger, transport)
+	priceStore, err := c.Spire.PriceStore(logger, transport, registry)
 	if err != nil {
 		return nil, err
 	}
@@ -214,12 +324,27 @@ func (c *Config) AgentServices(baseLogger log.Logger) (*AgentServices, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &AgentServices{
+	services := &AgentServices{
 		SpireAgent: spireAgent,
 		Transport:  transport,
 		PriceStore: priceStore,
 		Logger:     logger,
-	}, nil
+		Tracing:    traceExporter,
+		config:     c,
+		current:    c,
+		health:     registry,
+		baseLogger: baseLogger,
+	}
+	services.Admin, err = c.Logger.AdminServer(loggerConfig.AdminDependencies{
+		Logger:   logger,
+		Health:   registry,
+		Handlers: c.Transport.AdminHandlers(),
+		Reload:   services.reload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
 }
 
 // StreamServices returns the services configured for Spire.
@@ -254,6 +379,7 @@ func (c *Config) StreamServices(baseLogger log.Logger) (*StreamServices, error)
 	return &StreamServices{
 		Transport: transport,
 		Logger:    logger,
+		config:    c,
 	}, nil
 }
 
@@ -307,7 +433,11 @@ func (c *ConfigSpire) ConfigureClient(kr ethereumConfig.KeyRegistry) (*spire.Cli
 	return client, nil
 }
 
-func (c *ConfigSpire) PriceStore(l log.Logger, t pkgTransport.Transport) (*store.PriceStore, error) {
+func (c *ConfigSpire) PriceStore(
+	l log.Logger,
+	t pkgTransport.Transport,
+	h *health.Registry,
+) (*store.PriceStore, error) {
 	if c.priceStore != nil {
 		return c.priceStore, nil
 	}
@@ -317,6 +447,7 @@ func (c *ConfigSpire) PriceStore(l log.Logger, t pkgTransport.Transport) (*store
 		Pairs:     c.Pairs,
 		Feeds:     c.Feeds,
 		Logger:    l,
+		Health:    h,
 	})
 	if err != nil {
 		return nil, &hcl.Diagnostic{
@@ -329,3 +460,23 @@ func (c *ConfigSpire) PriceStore(l log.Logger, t pkgTransport.Transport) (*store
 	c.priceStore = priceStore
 	return priceStore, nil
 }
+
+// Validate checks the Spire configuration for semantic errors without
+// starting any services.
+func (c *Config) Validate() error {
+	keys, clients, err := c.Ethereum.Validate(ethereumConfig.Dependencies{Logger: null.New()})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if _, ok := keys[c.Spire.EthereumKey]; c.Spire.EthereumKey != "" && !ok {
+		errs = append(errs, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Ethereum key %q is not configured", c.Spire.EthereumKey),
+			Subject:  c.Spire.Content.Attributes["ethereum_key"].Range.Ptr(),
+		})
+	}
+	errs = append(errs, c.Transport.Validate(transportConfig.Dependencies{Keys: keys, Clients: clients}))
+	return config.JoinErrors(errs...)
+}
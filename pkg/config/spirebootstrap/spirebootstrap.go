@@ -0,0 +1,93 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package spirebootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+
+	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
+	transportConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	pkgSupervisor "github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
+	"github.com/chronicleprotocol/oracle-suite/pkg/sysmon"
+	pkgTransport "github.com/chronicleprotocol/oracle-suite/pkg/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/libp2p"
+)
+
+// Config is the configuration for Spire Bootstrap.
+type Config struct {
+	Transport transportConfig.Config `hcl:"transport,block"`
+	Logger    *loggerConfig.Config   `hcl:"logger,block,optional"`
+
+	// HCL fields:
+	Remain  hcl.Body        `hcl:",remain"` // To ignore unknown blocks.
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+// Services returns the services that are configured from the Config struct.
+type Services struct {
+	Transport pkgTransport.Transport
+	Logger    log.Logger
+
+	supervisor *pkgSupervisor.Supervisor
+}
+
+// Start implements the supervisor.Service interface.
+func (s *Services) Start(ctx context.Context) error {
+	if s.supervisor != nil {
+		return fmt.Errorf("services already started")
+	}
+	s.supervisor = pkgSupervisor.New(s.Logger)
+	s.supervisor.Watch(s.Transport, sysmon.New(time.Minute, s.Logger))
+	if l, ok := s.Logger.(pkgSupervisor.Service); ok {
+		s.supervisor.Watch(l)
+	}
+	return s.supervisor.Start(ctx)
+}
+
+// Wait implements the supervisor.Service interface.
+func (s *Services) Wait() <-chan error {
+	return s.supervisor.Wait()
+}
+
+// Services returns the services configured for Spire Bootstrap.
+func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
+	logger, err := c.Logger.Logger(loggerConfig.Dependencies{
+		AppName:    "spire-bootstrap",
+		BaseLogger: baseLogger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	transport, err := c.Transport.LibP2PBootstrap(transportConfig.BootstrapDependencies{
+		Logger: logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := transport.(*libp2p.P2P); !ok {
+		return nil, errors.New("spire-bootstrap works only with the libp2p transport")
+	}
+	return &Services{
+		Transport: transport,
+		Logger:    logger,
+	}, nil
+}
@@ -0,0 +1,37 @@
+package spirebootstrap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+)
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		test func(*testing.T, *Config)
+	}{
+		{
+			name: "valid",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				services, err := cfg.Services(null.New())
+				require.NoError(t, err)
+				require.NotNil(t, services.Transport)
+				require.NotNil(t, services.Logger)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cfg Config
+			err := config.LoadFiles(&cfg, []string{"./testdata/" + test.path})
+			require.NoError(t, err)
+			test.test(t, &cfg)
+		})
+	}
+}
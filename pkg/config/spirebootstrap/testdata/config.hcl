@@ -0,0 +1,7 @@
+transport {
+  libp2p {
+    feeds             = []
+    listen_addrs      = ["/ip4/0.0.0.0/tcp/6000"]
+    disable_discovery = false
+  }
+}
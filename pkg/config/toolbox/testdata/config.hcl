@@ -0,0 +1,9 @@
+ethereum {
+  rand_keys = ["key1"]
+
+  client "client1" {
+    rpc_urls     = ["https://rpc1.example"]
+    chain_id     = 1
+    ethereum_key = "key1"
+  }
+}
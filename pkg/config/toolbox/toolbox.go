@@ -1,4 +1,4 @@
-//  Copyright (C) 2020 Maker Ecosystem Growth Holdings, INC.
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
 //
 //  This program is free software: you can redistribute it and/or modify
 //  it under the terms of the GNU Affero General Public License as
@@ -13,52 +13,60 @@
 //  You should have received a copy of the GNU Affero General Public License
 //  along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
-package main
+package toolbox
 
 import (
-	"fmt"
-
 	"github.com/hashicorp/hcl/v2"
 
-	"github.com/chronicleprotocol/oracle-suite/pkg/config"
 	ethereumConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 	loggerConfig "github.com/chronicleprotocol/oracle-suite/pkg/config/logger"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 )
 
+// Config is the configuration for Toolbox.
 type Config struct {
 	Ethereum ethereumConfig.Config `hcl:"ethereum,block"`
-	Logger   *loggerConfig.Config  `hcl:"logger,block"`
+	Logger   *loggerConfig.Config  `hcl:"logger,block,optional"`
 
-	Remain hcl.Body `hcl:",remain"` // To ignore unknown blocks.
+	// HCL fields:
+	Remain  hcl.Body        `hcl:",remain"` // To ignore unknown blocks.
+	Content hcl.BodyContent `hcl:",content"`
 }
 
+// Services returns the services that are configured from the Config struct.
 type Services struct {
 	Keys    ethereumConfig.KeyRegistry
 	Clients ethereumConfig.ClientRegistry
+	Logger  log.Logger
 }
 
-func PrepareServices(opts *options) (*Services, error) {
-	err := config.LoadFiles(&opts.Config, opts.ConfigFilePath)
-	if err != nil {
-		return nil, fmt.Errorf(`config error: %w`, err)
-	}
-	logger, err := opts.Config.Logger.Logger(loggerConfig.Dependencies{
-		AppName:    "spire",
-		BaseLogger: opts.Logger(),
+// Services returns the services configured for Toolbox.
+func (c *Config) Services(baseLogger log.Logger) (*Services, error) {
+	logger, err := c.Logger.Logger(loggerConfig.Dependencies{
+		AppName:    "toolbox",
+		BaseLogger: baseLogger,
 	})
 	if err != nil {
-		return nil, fmt.Errorf(`ethereum config error: %w`, err)
+		return nil, err
 	}
-	keys, err := opts.Config.Ethereum.KeyRegistry(ethereumConfig.Dependencies{Logger: logger})
+	keys, err := c.Ethereum.KeyRegistry(ethereumConfig.Dependencies{Logger: logger})
 	if err != nil {
-		return nil, fmt.Errorf(`ethereum config error: %w`, err)
+		return nil, err
 	}
-	clients, err := opts.Config.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: logger})
+	clients, err := c.Ethereum.ClientRegistry(ethereumConfig.Dependencies{Logger: logger})
 	if err != nil {
-		return nil, fmt.Errorf(`ethereum config error: %w`, err)
+		return nil, err
 	}
 	return &Services{
 		Keys:    keys,
 		Clients: clients,
+		Logger:  logger,
 	}, nil
 }
+
+// Validate implements the config.Validator interface.
+func (c *Config) Validate() error {
+	_, _, err := c.Ethereum.Validate(ethereumConfig.Dependencies{Logger: null.New()})
+	return err
+}
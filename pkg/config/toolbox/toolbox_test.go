@@ -0,0 +1,45 @@
+package toolbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+)
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		test func(*testing.T, *Config)
+	}{
+		{
+			name: "valid",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				services, err := cfg.Services(null.New())
+				require.NoError(t, err)
+				require.Contains(t, services.Keys, "key1")
+				require.Contains(t, services.Clients, "client1")
+				require.NotNil(t, services.Logger)
+			},
+		},
+		{
+			name: "validate",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				require.NoError(t, cfg.Validate())
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cfg Config
+			err := config.LoadFiles(&cfg, []string{"./testdata/" + test.path})
+			require.NoError(t, err)
+			test.test(t, &cfg)
+		})
+	}
+}
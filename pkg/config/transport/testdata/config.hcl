@@ -7,6 +7,8 @@ libp2p {
   blocked_addrs      = ["/ip4/0.0.0.0/tcp/9000"]
   disable_discovery  = true
   ethereum_key       = "key"
+  reputation_file    = "/var/lib/spire/reputation.json"
+  admin_api          = true
 }
 
 webapi {
@@ -23,4 +25,22 @@ webapi {
   static_address_book {
     addresses = ["https://example.com/api/v1/endpoint"]
   }
+
+  http_address_book {
+    url         = "https://example.com/consumers.json"
+    signer_addr = "0x6789012345678901234567890123456789012345"
+    cache_ttl   = 600
+    max_age     = 1800
+  }
+
+  dns_address_book {
+    domain = "consumers.example.com"
+  }
+}
+
+nats {
+  feeds          = ["0x7890123456789012345678901234567890123456"]
+  url            = "nats://127.0.0.1:4222"
+  subject_prefix = "oracle."
+  ethereum_key   = "key"
 }
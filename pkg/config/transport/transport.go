@@ -21,12 +21,15 @@ import (
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"golang.org/x/net/proxy"
@@ -34,21 +37,55 @@ import (
 	"github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
 
 	suite "github.com/chronicleprotocol/oracle-suite"
+	"github.com/chronicleprotocol/oracle-suite/pkg/config"
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/chain"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/libp2p"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/libp2p/crypto/ethkey"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/nats"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/recoverer"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/restartable"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/webapi"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/timeutil"
 )
 
+const (
+	// dedupCacheSize is the number of messages remembered to drop messages
+	// received from more than one transport.
+	dedupCacheSize = 100000
+
+	// dedupCacheTTL is the time for which messages are remembered to drop
+	// messages received from more than one transport.
+	dedupCacheTTL = time.Hour
+
+	// restartMaxRetries is the number of consecutive restarts of a failed
+	// transport after which all services are stopped.
+	restartMaxRetries = 5
+)
+
+// ServiceName is the name of the service that runs the transport in the
+// supervisor. Services that use the transport should depend on it.
+const ServiceName = "transport"
+
 type Dependencies struct {
 	Keys     ethereum.KeyRegistry
 	Clients  ethereum.ClientRegistry
 	Messages map[string]transport.Message
 	Logger   log.Logger
+
+	// Health is an optional registry in which transports report their
+	// status.
+	Health *health.Registry
+
+	// Feeds is an optional allowlist of message authors shared with other
+	// services. It is used by the LibP2P transport, which adds configured
+	// feeds to it. It must not be shared with services that have their own
+	// list of feeds, because the LibP2P admin API replaces its content.
+	Feeds *keyset.Allowlist
 }
 
 type BootstrapDependencies struct {
@@ -58,13 +95,22 @@ type BootstrapDependencies struct {
 type Config struct {
 	LibP2P *libP2PConfig `hcl:"libp2p,block,optional"`
 	WebAPI *webAPIConfig `hcl:"webapi,block,optional"`
+	NATS   *natsConfig   `hcl:"nats,block,optional"`
 
 	// HCL fields:
 	Range   hcl.Range       `hcl:",range"`
 	Content hcl.BodyContent `hcl:",content"`
 
 	// Configured transport:
-	transport transport.Transport
+	transport      *restartable.Transport
+	instance       transport.Transport
+	deps           Dependencies
+	webAPI         *webapi.WebAPI
+	webAPIClient   *http.Client
+	webAPIAddrBook webapi.AddressBook
+	feeds          *keyset.Allowlist
+	peerPrivKey    crypto.PrivKey
+	libP2P         atomic.Pointer[libp2p.P2P]
 }
 
 type libP2PConfig struct {
@@ -104,11 +150,23 @@ type libP2PConfig struct {
 	// Required if the transport is used for sending messages.
 	EthereumKey string `hcl:"ethereum_key,optional"`
 
+	// ReputationFile is a path to a file in which peer scores and ban lists
+	// are persisted across restarts. If empty, they are kept in memory only.
+	ReputationFile string `hcl:"reputation_file,optional"`
+
+	// AdminAPI enables the admin API that allows to inspect peers and to ban
+	// or unban peers and feeds at runtime. The API is served under the
+	// "/libp2p" path of the admin server configured in the logger block.
+	AdminAPI bool `hcl:"admin_api,optional"`
+
 	// HCL fields:
 	Range   hcl.Range       `hcl:",range"`
 	Content hcl.BodyContent `hcl:",content"`
 }
 
+// Configuration of the WebAPI transport. Messages received by the transport
+// are deduplicated using a cache that remembers up to 100000 messages for
+// 1 hour.
 type webAPIConfig struct {
 	// Feeds is a list of Ethereum addresses that are allowed to send messages
 	// to the node.
@@ -129,7 +187,9 @@ type webAPIConfig struct {
 	EthereumKey string `hcl:"ethereum_key"`
 
 	// AddressBook configuration. Address book provides a list of addresses
-	// to which messages will be sent.
+	// to which messages will be sent. An address may be prefixed with the
+	// Ethereum address of the consumer followed by "@". In that case,
+	// delivery receipts are required and must be signed by that address.
 
 	// EthereumAddressBook is the configuration for the Ethereum address book.
 	EthereumAddressBook *webAPIEthereumAddressBook `hcl:"ethereum_address_book,block,optional"`
@@ -137,6 +197,35 @@ type webAPIConfig struct {
 	// StaticAddressBook is the configuration for the static address book.
 	StaticAddressBook *webAPIStaticAddressBook `hcl:"static_address_book,block,optional"`
 
+	// HTTPAddressBook is the configuration for the signed HTTP registry
+	// address book.
+	HTTPAddressBook *webAPIHTTPAddressBook `hcl:"http_address_book,block,optional"`
+
+	// DNSAddressBook is the configuration for the DNS TXT address book.
+	DNSAddressBook *webAPIDNSAddressBook `hcl:"dns_address_book,block,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type natsConfig struct {
+	// Feeds is a list of Ethereum addresses that are allowed to send messages
+	// to the node.
+	Feeds []types.Address `hcl:"feeds"`
+
+	// URL is the NATS server URL, e.g. `nats://127.0.0.1:4222`. Multiple
+	// servers may be provided as a comma separated list.
+	URL string `hcl:"url"`
+
+	// SubjectPrefix is the prefix added to topic names to create NATS
+	// subjects. If empty, `chronicle.` is used.
+	SubjectPrefix string `hcl:"subject_prefix,optional"`
+
+	// EthereumKey is the name of the Ethereum key to use for signing messages.
+	// Required if the transport is used for sending messages.
+	EthereumKey string `hcl:"ethereum_key,optional"`
+
 	// HCL fields:
 	Range   hcl.Range       `hcl:",range"`
 	Content hcl.BodyContent `hcl:",content"`
@@ -165,10 +254,91 @@ type webAPIStaticAddressBook struct {
 	Content hcl.BodyContent `hcl:",content"`
 }
 
+type webAPIHTTPAddressBook struct {
+	// URL is the URL of the signed registry document.
+	URL string `hcl:"url"`
+
+	// SignerAddr is the Ethereum address of the key that signs the registry
+	// document.
+	SignerAddr types.Address `hcl:"signer_addr"`
+
+	// CacheTTL is the time in seconds for which the list of addresses is
+	// cached. If not set, the default value of 3600 seconds is used.
+	CacheTTL uint32 `hcl:"cache_ttl,optional"`
+
+	// MaxAge is the maximum age in seconds of the registry document, based
+	// on its timestamp. Older documents are rejected, so the registry must
+	// sign the document again before it expires. If not set, four times the
+	// cache TTL is used.
+	MaxAge uint32 `hcl:"max_age,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+type webAPIDNSAddressBook struct {
+	// Domain is the domain name whose TXT records contain the list of
+	// addresses.
+	Domain string `hcl:"domain"`
+
+	// CacheTTL is the time in seconds for which the list of addresses is
+	// cached. If not set, the default value of 3600 seconds is used.
+	CacheTTL uint32 `hcl:"cache_ttl,optional"`
+
+	// HCL fields:
+	Range   hcl.Range       `hcl:",range"`
+	Content hcl.BodyContent `hcl:",content"`
+}
+
+// Transport returns the transport configured from the Config struct.
+//
+// The returned transport forwards calls to a running instance of the
+// configured transports, which is replaced by a new instance if it fails.
+// Instead of the returned transport, the services returned by the Services
+// method must be added to the supervisor.
 func (c *Config) Transport(d Dependencies) (transport.Transport, error) {
 	if c.transport != nil {
 		return c.transport, nil
 	}
+	instance, err := c.newTransport(d)
+	if err != nil {
+		return nil, err
+	}
+	c.transport = restartable.New()
+	c.instance = instance
+	c.deps = d
+	return c.transport, nil
+}
+
+// Services returns the configuration of services that run the transport
+// returned by the Transport method, which must be called first. A failed
+// transport is restarted with an exponential backoff. If it fails more than
+// 5 times in a row, all services are stopped.
+func (c *Config) Services() []supervisor.ServiceConfig {
+	return []supervisor.ServiceConfig{
+		{
+			Service: c.transport,
+			Name:    ServiceName + "_forwarder",
+		},
+		{
+			Service:       c.transport.Instance(c.instance),
+			Name:          ServiceName,
+			RestartPolicy: supervisor.RestartOnFailure,
+			MaxRetries:    restartMaxRetries,
+			Factory: func() (supervisor.Service, error) {
+				instance, err := c.newTransport(c.deps)
+				if err != nil {
+					return nil, err
+				}
+				return c.transport.Instance(instance), nil
+			},
+		},
+	}
+}
+
+// newTransport creates a new instance of the configured transports.
+func (c *Config) newTransport(d Dependencies) (transport.Transport, error) {
 	var transports []transport.Transport
 	if c.LibP2P != nil {
 		t, err := c.configureLibP2P(d)
@@ -184,6 +354,13 @@ func (c *Config) Transport(d Dependencies) (transport.Transport, error) {
 		}
 		transports = append(transports, t)
 	}
+	if c.NATS != nil {
+		t, err := c.configureNATS(d)
+		if err != nil {
+			return nil, err
+		}
+		transports = append(transports, t)
+	}
 	switch {
 	case len(transports) == 0:
 		return nil, &hcl.Diagnostic{
@@ -193,11 +370,43 @@ func (c *Config) Transport(d Dependencies) (transport.Transport, error) {
 			Subject:  &c.Range,
 		}
 	case len(transports) == 1:
-		c.transport = transports[0]
+		return transports[0], nil
 	default:
-		c.transport = chain.New(transports...)
+		// The same message may be received from more than one transport,
+		// so duplicates are dropped.
+		return chain.NewDeduplicated(dedupCacheSize, dedupCacheTTL, transports...), nil
 	}
-	return c.transport, nil
+}
+
+// Validate checks the configuration of transports, including references to
+// Ethereum keys and clients, without creating them.
+func (c *Config) Validate(d Dependencies) error {
+	if c.LibP2P == nil && c.WebAPI == nil && c.NATS == nil {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "At least one transport must be configured.",
+			Subject:  &c.Range,
+		}
+	}
+	var errs []error
+	if c.LibP2P != nil {
+		_, err := ethereumKey(d.Keys, c.LibP2P.EthereumKey, c.LibP2P.Content)
+		errs = append(errs, err)
+		_, err = c.generatePrivKey()
+		errs = append(errs, err)
+	}
+	if c.WebAPI != nil {
+		_, err := ethereumKey(d.Keys, c.WebAPI.EthereumKey, c.WebAPI.Content)
+		errs = append(errs, err)
+		_, err = c.WebAPI.addressBook(d, http.DefaultClient)
+		errs = append(errs, err)
+	}
+	if c.NATS != nil {
+		_, err := ethereumKey(d.Keys, c.NATS.EthereumKey, c.NATS.Content)
+		errs = append(errs, err)
+	}
+	return config.JoinErrors(errs...)
 }
 
 func (c *Config) LibP2PBootstrap(d BootstrapDependencies) (transport.Transport, error) {
@@ -256,102 +465,267 @@ func (c *Config) configureWebAPI(d Dependencies) (transport.Transport, error) {
 		}
 	}
 
-	// Configure address book:
+	// Configure address book. The address book is reused by restarted
+	// transports, so changes applied by ReloadAddressBook are kept:
+	addressBook := c.webAPIAddrBook
+	if addressBook == nil {
+		var err error
+		addressBook, err = c.WebAPI.addressBook(d, httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Configure signer:
+	key, err := ethereumKey(d.Keys, c.WebAPI.EthereumKey, c.WebAPI.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Configure transport:
+	webapiTransport, err := webapi.New(webapi.Config{
+		ListenAddr:      c.WebAPI.ListenAddr,
+		AddressBook:     addressBook,
+		Topics:          d.Messages,
+		AuthorAllowlist: c.WebAPI.Feeds,
+		FlushTicker:     timeutil.NewTicker(time.Minute),
+		Signer:          key,
+		Client:          httpClient,
+		Logger:          d.Logger,
+		Health:          d.Health,
+	})
+	if err != nil {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Runtime error",
+			Detail:   fmt.Sprintf("Failed to create the WebAPI transport: %v", err),
+			Subject:  &c.WebAPI.Range,
+		}
+	}
+	c.webAPI = webapiTransport
+	c.webAPIClient = httpClient
+	c.webAPIAddrBook = addressBook
+	return recoverer.New(webapiTransport, d.Logger), nil
+}
+
+// AddressBookPaths returns configuration paths of the WebAPI address books,
+// which can be changed at runtime using the ReloadAddressBook method. The
+// prefix is the path of the transport block.
+func AddressBookPaths(prefix string) []string {
+	return []string{
+		prefix + ".webapi.ethereum_address_book",
+		prefix + ".webapi.static_address_book",
+		prefix + ".webapi.http_address_book",
+		prefix + ".webapi.dns_address_book",
+	}
+}
+
+// FeedAddresses returns the feeds allowed by all configured transports,
+// without duplicates.
+func (c *Config) FeedAddresses() []types.Address {
 	var (
-		addressBook  webapi.AddressBook
-		addressBooks []webapi.AddressBook
+		feeds []types.Address
+		seen  = map[types.Address]struct{}{}
 	)
-	switch {
-	case c.WebAPI.EthereumAddressBook != nil:
-		rpcClient := d.Clients[c.WebAPI.EthereumAddressBook.EthereumClient]
+	add := func(addrs []types.Address) {
+		for _, addr := range addrs {
+			if _, ok := seen[addr]; !ok {
+				seen[addr] = struct{}{}
+				feeds = append(feeds, addr)
+			}
+		}
+	}
+	if c.LibP2P != nil {
+		add(c.LibP2P.Feeds)
+	}
+	if c.WebAPI != nil {
+		add(c.WebAPI.Feeds)
+	}
+	if c.NATS != nil {
+		add(c.NATS.Feeds)
+	}
+	return feeds
+}
+
+// FeedPaths returns configuration paths of the LibP2P feeds, which can be
+// changed at runtime using the ReloadFeeds method. The prefix is the path
+// of the transport block.
+func FeedPaths(prefix string) []string {
+	return []string{prefix + ".libp2p.feeds"}
+}
+
+// AdminHandlers returns HTTP handlers that must be served by the admin
+// server, mapped to their path prefixes. If the LibP2P admin API is
+// enabled, its handler is returned under the "/libp2p" prefix. The handler
+// always forwards requests to the running LibP2P transport, also after it
+// is restarted. The Transport method must be called first.
+func (c *Config) AdminHandlers() map[string]http.Handler {
+	if c.LibP2P == nil || !c.LibP2P.AdminAPI {
+		return nil
+	}
+	return map[string]http.Handler{
+		"/libp2p": http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			p := c.libP2P.Load()
+			if p == nil {
+				res.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			p.AdminHandler().ServeHTTP(res, req)
+		}),
+	}
+}
+
+// ReloadFeeds returns a function that replaces the feeds allowed by the
+// running LibP2P transport with the feeds from the next configuration.
+// Removed feeds are still allowed for keyset.DefaultOverlap. The Transport
+// method must be called first.
+func (c *Config) ReloadFeeds(next *Config) (func(), error) {
+	if c.feeds == nil || next.LibP2P == nil {
+		return nil, errors.New("LibP2P transport is not configured")
+	}
+	return func() { c.feeds.Update(next.LibP2P.Feeds, 0) }, nil
+}
+
+// ReloadAddressBook creates the address book from the next configuration
+// and returns a function that sets it on the running WebAPI transport. The
+// Transport method must be called first.
+func (c *Config) ReloadAddressBook(next *Config, d Dependencies) (func(), error) {
+	if c.webAPI == nil || next.WebAPI == nil {
+		return nil, errors.New("WebAPI transport is not configured")
+	}
+	addressBook, err := next.WebAPI.addressBook(d, c.webAPIClient)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		c.webAPI.SetAddressBook(addressBook)
+		c.webAPIAddrBook = addressBook
+	}, nil
+}
+
+// addressBook returns the address book configured in the WebAPI block.
+func (c *webAPIConfig) addressBook(d Dependencies, httpClient *http.Client) (webapi.AddressBook, error) {
+	var addressBooks []webapi.AddressBook
+	if c.EthereumAddressBook != nil {
+		rpcClient := d.Clients[c.EthereumAddressBook.EthereumClient]
 		if rpcClient == nil {
 			return nil, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Validation error",
-				Detail:   fmt.Sprintf("Ethereum client %q is not configured", c.WebAPI.EthereumAddressBook.EthereumClient),
-				Subject:  c.WebAPI.EthereumAddressBook.Content.Attributes["ethereum_client"].Range.Ptr(),
+				Detail:   fmt.Sprintf("Ethereum client %q is not configured", c.EthereumAddressBook.EthereumClient),
+				Subject:  c.EthereumAddressBook.Content.Attributes["ethereum_client"].Range.Ptr(),
+			}
+		}
+		if c.EthereumAddressBook.ContractAddr == types.ZeroAddress {
+			return nil, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   "Address book contract address must not be zero",
+				Subject:  c.EthereumAddressBook.Content.Attributes["contract_addr"].Range.Ptr(),
 			}
 		}
 		addressBooks = append(addressBooks, webapi.NewEthereumAddressBook(
 			rpcClient,
-			c.WebAPI.EthereumAddressBook.ContractAddr,
+			c.EthereumAddressBook.ContractAddr,
 			time.Hour,
 		))
-	case c.WebAPI.StaticAddressBook != nil:
+	}
+	if c.StaticAddressBook != nil {
 		addressBooks = append(
 			addressBooks,
-			webapi.NewStaticAddressBook(c.WebAPI.StaticAddressBook.Addresses),
+			webapi.NewStaticAddressBook(c.StaticAddressBook.Addresses),
 		)
 	}
+	if c.HTTPAddressBook != nil {
+		addressBooks = append(addressBooks, webapi.NewHTTPAddressBook(
+			httpClient,
+			c.HTTPAddressBook.URL,
+			c.HTTPAddressBook.SignerAddr,
+			cacheTTL(c.HTTPAddressBook.CacheTTL),
+			registryMaxAge(c.HTTPAddressBook.MaxAge, c.HTTPAddressBook.CacheTTL),
+		))
+	}
+	if c.DNSAddressBook != nil {
+		addressBooks = append(addressBooks, webapi.NewDNSAddressBook(
+			net.DefaultResolver,
+			c.DNSAddressBook.Domain,
+			cacheTTL(c.DNSAddressBook.CacheTTL),
+		))
+	}
 	switch {
 	case len(addressBooks) == 0:
 		return nil, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Validation error",
 			Detail:   "At least one address book must be configured.",
-			Subject:  &c.WebAPI.Range,
+			Subject:  &c.Range,
 		}
 	case len(addressBooks) == 1:
-		addressBook = addressBooks[0]
+		return addressBooks[0], nil
 	default:
-		addressBook = webapi.NewMultiAddressBook(addressBooks...)
+		return webapi.NewMultiAddressBook(addressBooks...), nil
 	}
+}
 
+func (c *Config) configureNATS(d Dependencies) (transport.Transport, error) {
 	// Configure signer:
-	key := d.Keys[c.WebAPI.EthereumKey]
-	if c.WebAPI.EthereumKey != "" && key == nil {
-		return nil, &hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Validation error",
-			Detail:   fmt.Sprintf("Ethereum key %q is not configured", c.WebAPI.EthereumKey),
-			Subject:  c.WebAPI.Content.Attributes["ethereum_key"].Range.Ptr(),
-		}
+	key, err := ethereumKey(d.Keys, c.NATS.EthereumKey, c.NATS.Content)
+	if err != nil {
+		return nil, err
 	}
 
 	// Configure transport:
-	webapiTransport, err := webapi.New(webapi.Config{
-		ListenAddr:      c.WebAPI.ListenAddr,
-		AddressBook:     addressBook,
+	natsTransport, err := nats.New(nats.Config{
+		URL:             c.NATS.URL,
+		SubjectPrefix:   c.NATS.SubjectPrefix,
 		Topics:          d.Messages,
-		AuthorAllowlist: c.WebAPI.Feeds,
-		FlushTicker:     timeutil.NewTicker(time.Minute),
+		AuthorAllowlist: c.NATS.Feeds,
 		Signer:          key,
-		Client:          httpClient,
 		Logger:          d.Logger,
+		Health:          d.Health,
 	})
 	if err != nil {
 		return nil, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Runtime error",
-			Detail:   fmt.Sprintf("Failed to create the WebAPI transport: %v", err),
-			Subject:  &c.WebAPI.Range,
+			Detail:   fmt.Sprintf("Failed to create the NATS transport: %v", err),
+			Subject:  &c.NATS.Range,
 		}
 	}
-	return recoverer.New(webapiTransport, d.Logger), nil
+	return recoverer.New(natsTransport, d.Logger), nil
 }
 
 func (c *Config) configureLibP2P(d Dependencies) (transport.Transport, error) {
 	// Configure signer:
-	key := d.Keys[c.LibP2P.EthereumKey]
-	if c.LibP2P.EthereumKey != "" && key == nil {
-		return nil, &hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Validation error",
-			Detail:   fmt.Sprintf("Ethereum key %q is not configured", c.LibP2P.EthereumKey),
-			Subject:  c.LibP2P.Content.Attributes["ethereum_key"].Range.Ptr(),
-		}
-	}
-
-	// Configure LibP2P private keys:
-	peerPrivKey, err := c.generatePrivKey()
+	key, err := ethereumKey(d.Keys, c.LibP2P.EthereumKey, c.LibP2P.Content)
 	if err != nil {
 		return nil, err
 	}
+
+	// Configure LibP2P private keys. The peer key is reused by restarted
+	// transports, so the peer ID does not change:
+	if c.peerPrivKey == nil {
+		c.peerPrivKey, err = c.generatePrivKey()
+		if err != nil {
+			return nil, err
+		}
+	}
+	peerPrivKey := c.peerPrivKey
 	var messagePrivKey crypto.PrivKey
 	if key != nil {
 		messagePrivKey = ethkey.NewPrivKey(key)
 	}
 
+	// Configure the allowlist of message authors, which can be updated
+	// at runtime:
+	feeds := d.Feeds
+	if feeds == nil {
+		feeds = c.feeds
+	}
+	if feeds == nil {
+		feeds = keyset.NewAllowlist(nil)
+	}
+
 	// Configure LibP2P transport:
 	cfg := libp2p.Config{
 		Mode:             libp2p.ClientMode,
@@ -363,9 +737,12 @@ func (c *Config) configureLibP2P(d Dependencies) (transport.Transport, error) {
 		DirectPeersAddrs: c.LibP2P.DirectPeersAddrs,
 		BlockedAddrs:     c.LibP2P.BlockedAddrs,
 		AuthorAllowlist:  c.LibP2P.Feeds,
+		Feeds:            feeds,
 		Discovery:        !c.LibP2P.DisableDiscovery,
 		Signer:           key,
+		ReputationFile:   c.LibP2P.ReputationFile,
 		Logger:           d.Logger,
+		Health:           d.Health,
 		AppName:          "spire",
 		AppVersion:       suite.Version,
 	}
@@ -378,6 +755,8 @@ func (c *Config) configureLibP2P(d Dependencies) (transport.Transport, error) {
 			Subject:  &c.LibP2P.Range,
 		}
 	}
+	c.feeds = feeds
+	c.libP2P.Store(libP2PTransport)
 	return recoverer.New(libP2PTransport, d.Logger), nil
 }
 
@@ -414,3 +793,39 @@ func (c *Config) generatePrivKey() (crypto.PrivKey, error) {
 	}
 	return privKey, nil
 }
+
+// ethereumKey returns the Ethereum key with the given name. If the name is
+// empty, it returns nil.
+func ethereumKey(keys ethereum.KeyRegistry, name string, content hcl.BodyContent) (wallet.Key, error) {
+	if name == "" {
+		return nil, nil
+	}
+	key, ok := keys[name]
+	if !ok {
+		return nil, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   fmt.Sprintf("Ethereum key %q is not configured", name),
+			Subject:  content.Attributes["ethereum_key"].Range.Ptr(),
+		}
+	}
+	return key, nil
+}
+
+// cacheTTL returns the address book cache TTL for the given number of
+// seconds, or one hour if the value is zero.
+func cacheTTL(seconds uint32) time.Duration {
+	if seconds == 0 {
+		return time.Hour
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// registryMaxAge returns the maximum age of the registry document for the
+// given number of seconds, or four times the cache TTL if the value is zero.
+func registryMaxAge(seconds, cacheTTLSeconds uint32) time.Duration {
+	if seconds == 0 {
+		return 4 * cacheTTL(cacheTTLSeconds)
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -1,6 +1,8 @@
 package transport
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/defiweb/go-eth/types"
@@ -9,8 +11,12 @@ import (
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/config"
 	"github.com/chronicleprotocol/oracle-suite/pkg/config/ethereum"
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
 	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/mocks"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/chain"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/restartable"
 )
 
 func TestConfig(t *testing.T) {
@@ -36,6 +42,8 @@ func TestConfig(t *testing.T) {
 				assert.Equal(t, []string{"/ip4/0.0.0.0/tcp/9000"}, cfg.LibP2P.BlockedAddrs)
 				assert.Equal(t, true, cfg.LibP2P.DisableDiscovery)
 				assert.Equal(t, "key", cfg.LibP2P.EthereumKey)
+				assert.Equal(t, "/var/lib/spire/reputation.json", cfg.LibP2P.ReputationFile)
+				assert.True(t, cfg.LibP2P.AdminAPI)
 
 				// WebAPI
 				assert.Equal(t, "0x3456789012345678901234567890123456789012", cfg.WebAPI.Feeds[0].String())
@@ -52,6 +60,22 @@ func TestConfig(t *testing.T) {
 
 				// StaticAddressBook
 				assert.Equal(t, []string{"https://example.com/api/v1/endpoint"}, cfg.WebAPI.StaticAddressBook.Addresses)
+
+				// HTTPAddressBook
+				assert.Equal(t, "https://example.com/consumers.json", cfg.WebAPI.HTTPAddressBook.URL)
+				assert.Equal(t, "0x6789012345678901234567890123456789012345", cfg.WebAPI.HTTPAddressBook.SignerAddr.String())
+				assert.Equal(t, uint32(600), cfg.WebAPI.HTTPAddressBook.CacheTTL)
+				assert.Equal(t, uint32(1800), cfg.WebAPI.HTTPAddressBook.MaxAge)
+
+				// DNSAddressBook
+				assert.Equal(t, "consumers.example.com", cfg.WebAPI.DNSAddressBook.Domain)
+				assert.Equal(t, uint32(0), cfg.WebAPI.DNSAddressBook.CacheTTL)
+
+				// NATS
+				assert.Equal(t, "0x7890123456789012345678901234567890123456", cfg.NATS.Feeds[0].String())
+				assert.Equal(t, "nats://127.0.0.1:4222", cfg.NATS.URL)
+				assert.Equal(t, "oracle.", cfg.NATS.SubjectPrefix)
+				assert.Equal(t, "key", cfg.NATS.EthereumKey)
 			},
 		},
 		{
@@ -66,14 +90,83 @@ func TestConfig(t *testing.T) {
 				clientRegistry := ethereum.ClientRegistry{
 					"client": &mocks.RPC{},
 				}
+				registry := health.NewRegistry()
 				transport, err := cfg.Transport(Dependencies{
 					Keys:     keyRegistry,
 					Clients:  clientRegistry,
 					Messages: nil,
 					Logger:   null.New(),
+					Health:   registry,
 				})
 				require.NoError(t, err)
-				assert.NotNil(t, transport)
+				assert.IsType(t, &restartable.Transport{}, transport)
+				assert.IsType(t, &chain.Chain{}, cfg.instance)
+
+				// Transports report their status in the given registry:
+				var names []string
+				for _, s := range registry.Statuses() {
+					names = append(names, s.Name)
+				}
+				assert.Equal(t, []string{"transport.libp2p", "transport.nats", "transport.webapi"}, names)
+
+				// The LibP2P admin API is served by the admin server, but
+				// it is not available until the transport is started:
+				handlers := cfg.AdminHandlers()
+				require.Contains(t, handlers, "/libp2p")
+				rec := httptest.NewRecorder()
+				handlers["/libp2p"].ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/peers", nil))
+				assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+				// The transport is restarted using a new instance with
+				// the same peer identity:
+				services := cfg.Services()
+				require.Len(t, services, 2)
+				assert.Same(t, transport, services[0].Service)
+				assert.Equal(t, ServiceName, services[1].Name)
+				peerPrivKey := cfg.peerPrivKey
+				srv, err := services[1].Factory()
+				require.NoError(t, err)
+				assert.NotNil(t, srv)
+				assert.Same(t, peerPrivKey, cfg.peerPrivKey)
+			},
+		},
+		{
+			name: "feeds",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				feeds := cfg.FeedAddresses()
+				require.Len(t, feeds, 5)
+				assert.Equal(t, "0x1234567890123456789012345678901234567890", feeds[0].String())
+				assert.Equal(t, "0x7890123456789012345678901234567890123456", feeds[4].String())
+			},
+		},
+		{
+			name: "reload feeds",
+			path: "config.hcl",
+			test: func(t *testing.T, cfg *Config) {
+				key := &mocks.Key{}
+				key.On("Address").Return(types.AddressFromHex("0x1234567890123456789012345678901234567890"))
+				feeds := keyset.NewAllowlist(nil)
+				_, err := cfg.Transport(Dependencies{
+					Keys:    ethereum.KeyRegistry{"key": key},
+					Clients: ethereum.ClientRegistry{"client": &mocks.RPC{}},
+					Logger:  null.New(),
+					Feeds:   feeds,
+				})
+				require.NoError(t, err)
+				assert.Len(t, feeds.Entries(), 2)
+
+				var next Config
+				require.NoError(t, config.LoadFiles(&next, []string{"./testdata/config.hcl"}))
+				next.LibP2P.Feeds = next.LibP2P.Feeds[:1]
+				commit, err := cfg.ReloadFeeds(&next)
+				require.NoError(t, err)
+				commit()
+
+				entries := feeds.Entries()
+				require.Len(t, entries, 2)
+				assert.True(t, entries[0].RetiringTill.IsZero())
+				assert.False(t, entries[1].RetiringTill.IsZero())
 			},
 		},
 	}
@@ -0,0 +1,73 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"errors"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Validator is implemented by configurations that can be checked for
+// semantic errors, like references to keys that are not configured or
+// cycles in price models, without starting any services.
+type Validator interface {
+	Validate() error
+}
+
+// ValidateFiles loads the given paths into the given config in the same way
+// as LoadFiles and, if the config implements the Validator interface, runs
+// its semantic checks.
+func ValidateFiles(config any, paths []string) error {
+	if err := LoadFiles(config, paths); err != nil {
+		return err
+	}
+	if v, ok := config.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// JoinErrors combines the given errors into hcl.Diagnostics. Nil errors are
+// skipped and errors that are not diagnostics are converted to diagnostics
+// without a subject. It returns nil if all errors are nil.
+func JoinErrors(errs ...error) error {
+	var diags hcl.Diagnostics
+	for _, err := range errs {
+		var (
+			d  hcl.Diagnostics
+			dp *hcl.Diagnostic
+		)
+		switch {
+		case err == nil:
+			continue
+		case errors.As(err, &d):
+			diags = append(diags, d...)
+		case errors.As(err, &dp):
+			diags = append(diags, dp)
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Validation error",
+				Detail:   err.Error(),
+			})
+		}
+	}
+	if len(diags) == 0 {
+		return nil
+	}
+	return diags
+}
@@ -3,12 +3,16 @@ package graph
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/datapoint"
 	"github.com/chronicleprotocol/oracle-suite/pkg/datapoint/origin"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/metrics"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing"
 )
 
 const UpdaterLoggerTag = "GRAPH_UPDATER"
@@ -22,6 +26,7 @@ type Updater struct {
 	origins map[string]origin.Origin
 	limiter chan struct{}
 	logger  log.Logger
+	health  *health.Registry
 }
 
 // NewUpdater returns a new Updater instance.
@@ -36,6 +41,12 @@ func NewUpdater(origins map[string]origin.Origin, logger log.Logger) *Updater {
 	}
 }
 
+// SetHealth sets the registry in which the status of every origin is
+// reported under the "origin.<name>" name.
+func (u *Updater) SetHealth(r *health.Registry) {
+	u.health = r
+}
+
 // Update updates the origin nodes in the given graphs.
 //
 // Only origin nodes that are not fresh will be updated.
@@ -98,7 +109,19 @@ func (u *Updater) fetchDataPoints(ctx context.Context, queries queryMap) dataPoi
 			defer func() { <-u.limiter }()
 
 			// Fetch data points from the origin and store them in the map.
-			points, err := origin.FetchDataPoints(ctx, queries)
+			spanCtx, span := tracing.Start(ctx, "graph.Updater.fetchDataPoints", tracing.WithAttributes(map[string]any{
+				"origin":  originName,
+				"queries": len(queries),
+			}))
+			defer span.End()
+			t := time.Now()
+			points, err := origin.FetchDataPoints(spanCtx, queries)
+			metrics.OriginFetchDuration.WithLabelValues(originName).Observe(time.Since(t).Seconds())
+			metrics.OriginFetches.WithLabelValues(originName, metrics.Status(err)).Inc()
+			span.RecordError(err)
+			h := u.health.Register("origin." + originName)
+			h.Ready()
+			h.Report(err)
 			if err != nil {
 				u.logger.
 					WithError(err).
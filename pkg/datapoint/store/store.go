@@ -19,12 +19,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/defiweb/go-eth/types"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/datapoint"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
 )
@@ -56,6 +59,7 @@ type Store struct {
 	transport  transport.Transport
 	models     []string
 	recoverers []datapoint.Recoverer
+	health     *health.Reporter
 }
 
 // Config is the configuration for Storage.
@@ -76,6 +80,10 @@ type Config struct {
 	// Logger is a current logger interface used by the Store.
 	// The Logger is required to monitor asynchronous processes.
 	Logger log.Logger
+
+	// Health is the registry in which the Store reports its status under
+	// the "data_point_store" name. If nil, the status is not reported.
+	Health *health.Registry
 }
 
 // New creates a new Store.
@@ -96,6 +104,7 @@ func New(cfg Config) (*Store, error) {
 		transport:  cfg.Transport,
 		models:     cfg.Models,
 		recoverers: cfg.Recoverers,
+		health:     cfg.Health.Register("data_point_store"),
 	}
 	return s, nil
 }
@@ -112,6 +121,7 @@ func (p *Store) Start(ctx context.Context) error {
 	p.ctx = ctx
 	go p.dataPointCollectorRoutine()
 	go p.contextCancelHandler()
+	p.health.Ready()
 	return nil
 }
 
@@ -130,14 +140,27 @@ func (p *Store) Latest(ctx context.Context, model string) (map[types.Address]dat
 	return p.storage.Latest(ctx, model)
 }
 
-func (p *Store) collectDataPoint(point *messages.DataPoint) error {
+func (p *Store) collectDataPoint(point *messages.DataPoint) (err error) {
+	ctx, span := tracing.Start(
+		tracing.Extract(p.ctx, point.Meta),
+		"datapoint.Store.collectDataPoint",
+		tracing.WithAttributes(map[string]any{
+			"model": point.Model,
+			"age":   time.Since(point.Value.Time),
+		}),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	for _, recoverer := range p.recoverers {
-		if recoverer.Supports(p.ctx, point.Value) {
-			from, err := recoverer.Recover(p.ctx, point.Model, point.Value, point.Signature)
+		if recoverer.Supports(ctx, point.Value) {
+			from, err := recoverer.Recover(ctx, point.Model, point.Value, point.Signature)
 			if err != nil {
 				return fmt.Errorf("unable to recover address: %w", err)
 			}
-			if err := p.storage.Add(p.ctx, *from, point.Model, point.Value); err != nil {
+			span.SetAttribute("feed", from.String())
+			if err := p.storage.Add(ctx, *from, point.Model, point.Value); err != nil {
 				return fmt.Errorf("unable to add data point: %w", err)
 			}
 			return nil
@@ -180,6 +203,7 @@ func (p *Store) dataPointCollectorRoutine() {
 					WithError(err).
 					Warn("Received invalid data point")
 			} else {
+				p.health.Success()
 				p.log.
 					Info("Data point received")
 			}
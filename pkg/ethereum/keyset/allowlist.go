@@ -0,0 +1,133 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package keyset
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+)
+
+// DefaultOverlap is the overlap window used by Allowlist.Update when the
+// given overlap is zero.
+const DefaultOverlap = 10 * time.Minute
+
+// Allowlist is a list of addresses allowed to send messages that can be
+// updated at runtime.
+//
+// When an address is removed from the allowlist using Update, it is not
+// removed immediately, but it is retired: it is still allowed until the
+// overlap window passes. This allows feeds to rotate their keys without
+// losing messages that were signed by the previous key and are still in
+// flight.
+type Allowlist struct {
+	mu       sync.RWMutex
+	addrs    map[types.Address]struct{}
+	retiring map[types.Address]time.Time
+}
+
+// AllowlistEntry describes an address on the allowlist.
+type AllowlistEntry struct {
+	Address types.Address
+	// RetiringTill is the time until which a retiring address is allowed.
+	// It is zero for active addresses.
+	RetiringTill time.Time
+}
+
+// NewAllowlist returns a new Allowlist with the given addresses.
+func NewAllowlist(addrs []types.Address) *Allowlist {
+	a := &Allowlist{
+		addrs:    map[types.Address]struct{}{},
+		retiring: map[types.Address]time.Time{},
+	}
+	for _, addr := range addrs {
+		a.addrs[addr] = struct{}{}
+	}
+	return a
+}
+
+// Allowed returns true if the address is on the allowlist, or it is
+// retiring and the overlap window has not passed yet.
+func (a *Allowlist) Allowed(addr types.Address) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if _, ok := a.addrs[addr]; ok {
+		return true
+	}
+	if till, ok := a.retiring[addr]; ok {
+		return time.Now().Before(till)
+	}
+	return false
+}
+
+// Add adds addresses to the allowlist.
+func (a *Allowlist) Add(addrs ...types.Address) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, addr := range addrs {
+		a.addrs[addr] = struct{}{}
+		delete(a.retiring, addr)
+	}
+}
+
+// Update replaces the addresses on the allowlist. Addresses that are not on
+// the new list are retired and allowed for the overlap window. If overlap
+// is zero, DefaultOverlap is used.
+func (a *Allowlist) Update(addrs []types.Address, overlap time.Duration) {
+	if overlap <= 0 {
+		overlap = DefaultOverlap
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	next := map[types.Address]struct{}{}
+	for _, addr := range addrs {
+		next[addr] = struct{}{}
+		delete(a.retiring, addr)
+	}
+	till := time.Now().Add(overlap)
+	for addr := range a.addrs {
+		if _, ok := next[addr]; !ok {
+			a.retiring[addr] = till
+		}
+	}
+	for addr, t := range a.retiring {
+		if time.Now().After(t) {
+			delete(a.retiring, addr)
+		}
+	}
+	a.addrs = next
+}
+
+// Entries returns active and retiring addresses, sorted by address.
+func (a *Allowlist) Entries() []AllowlistEntry {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	entries := make([]AllowlistEntry, 0, len(a.addrs)+len(a.retiring))
+	for addr := range a.addrs {
+		entries = append(entries, AllowlistEntry{Address: addr})
+	}
+	for addr, till := range a.retiring {
+		if time.Now().Before(till) {
+			entries = append(entries, AllowlistEntry{Address: addr, RetiringTill: till})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Address.String() < entries[j].Address.String()
+	})
+	return entries
+}
@@ -0,0 +1,77 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package keyset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testAddr1 = types.MustAddressFromHex("0x1111111111111111111111111111111111111111")
+	testAddr2 = types.MustAddressFromHex("0x2222222222222222222222222222222222222222")
+	testAddr3 = types.MustAddressFromHex("0x3333333333333333333333333333333333333333")
+)
+
+func TestAllowlist(t *testing.T) {
+	a := NewAllowlist([]types.Address{testAddr1})
+	assert.True(t, a.Allowed(testAddr1))
+	assert.False(t, a.Allowed(testAddr2))
+
+	a.Add(testAddr2)
+	assert.True(t, a.Allowed(testAddr2))
+}
+
+func TestAllowlist_Update(t *testing.T) {
+	a := NewAllowlist([]types.Address{testAddr1, testAddr2})
+
+	// The first address is replaced with the third one, but it is still
+	// allowed during the overlap window:
+	a.Update([]types.Address{testAddr2, testAddr3}, time.Hour)
+	assert.True(t, a.Allowed(testAddr1))
+	assert.True(t, a.Allowed(testAddr2))
+	assert.True(t, a.Allowed(testAddr3))
+
+	entries := a.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, testAddr1, entries[0].Address)
+	assert.False(t, entries[0].RetiringTill.IsZero())
+	assert.True(t, entries[1].RetiringTill.IsZero())
+	assert.True(t, entries[2].RetiringTill.IsZero())
+
+	// Without the overlap window, the default one is used:
+	a.Update([]types.Address{testAddr3}, 0)
+	assert.True(t, a.Allowed(testAddr1))
+	assert.True(t, a.Allowed(testAddr2))
+	assert.True(t, a.Allowed(testAddr3))
+	for _, e := range a.Entries() {
+		if e.Address == testAddr2 {
+			assert.WithinDuration(t, time.Now().Add(DefaultOverlap), e.RetiringTill, time.Minute)
+		}
+	}
+}
+
+func TestAllowlist_OverlapExpired(t *testing.T) {
+	a := NewAllowlist([]types.Address{testAddr1})
+	a.Update([]types.Address{testAddr2}, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	assert.False(t, a.Allowed(testAddr1))
+	assert.Len(t, a.Entries(), 1)
+}
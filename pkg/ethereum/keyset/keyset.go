@@ -0,0 +1,202 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package keyset
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+)
+
+// KeySet is a wallet.Key that allows rotating the signing key at runtime
+// without restarting services that use it.
+//
+// KeySet holds a list of candidate keys, one of which is active. Messages
+// are always signed using the active key. After a rotation, the previously
+// active key becomes a retiring key for the configured overlap window, so
+// signatures made by it are still accepted by the Verify methods while
+// messages signed before the rotation are in flight.
+type KeySet struct {
+	mu           sync.RWMutex
+	keys         []wallet.Key
+	active       wallet.Key
+	retiring     wallet.Key
+	retiringTill time.Time
+	overlap      time.Duration
+}
+
+// Unwrapper is implemented by keys that wrap another key, for example to
+// record signatures, so the key set behind them can be found by FromKey.
+type Unwrapper interface {
+	Unwrap() wallet.Key
+}
+
+// FromKey returns the KeySet used by the given key. Keys that implement the
+// Unwrapper interface are unwrapped first. It returns false if the key does
+// not use a KeySet.
+func FromKey(key wallet.Key) (*KeySet, bool) {
+	for key != nil {
+		if ks, ok := key.(*KeySet); ok {
+			return ks, true
+		}
+		u, ok := key.(Unwrapper)
+		if !ok {
+			break
+		}
+		key = u.Unwrap()
+	}
+	return nil, false
+}
+
+// New returns a new KeySet. The active key must be one of the given keys.
+// The overlap is the default time during which the previously active key
+// is accepted after a rotation.
+func New(keys []wallet.Key, active types.Address, overlap time.Duration) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("key set must contain at least one key")
+	}
+	ks := &KeySet{keys: keys, overlap: overlap}
+	seen := map[types.Address]bool{}
+	for _, k := range keys {
+		if seen[k.Address()] {
+			return nil, fmt.Errorf("duplicate key %s", k.Address())
+		}
+		seen[k.Address()] = true
+	}
+	ks.active = ks.find(active)
+	if ks.active == nil {
+		return nil, fmt.Errorf("active key %s is not in the key set", active)
+	}
+	return ks, nil
+}
+
+// Active returns the currently active key.
+func (k *KeySet) Active() wallet.Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// Retiring returns the retiring key and the time until which it is
+// accepted. If there is no retiring key, or the overlap window has passed,
+// it returns nil.
+func (k *KeySet) Retiring() (wallet.Key, time.Time) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.retiring == nil || time.Now().After(k.retiringTill) {
+		return nil, time.Time{}
+	}
+	return k.retiring, k.retiringTill
+}
+
+// Keys returns addresses of all keys in the set.
+func (k *KeySet) Keys() []types.Address {
+	addrs := make([]types.Address, len(k.keys))
+	for i, key := range k.keys {
+		addrs[i] = key.Address()
+	}
+	return addrs
+}
+
+// Has reports whether the key with the given address is in the set.
+func (k *KeySet) Has(addr types.Address) bool {
+	return k.find(addr) != nil
+}
+
+// Rotate makes the key with the given address active. The previously active
+// key is accepted by the Verify methods for the overlap window. If overlap
+// is zero, the default overlap given to New is used.
+func (k *KeySet) Rotate(addr types.Address, overlap time.Duration) error {
+	next := k.find(addr)
+	if next == nil {
+		return fmt.Errorf("key %s is not in the key set", addr)
+	}
+	if overlap == 0 {
+		overlap = k.overlap
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if next == k.active {
+		return nil
+	}
+	k.retiring = k.active
+	k.retiringTill = time.Now().Add(overlap)
+	k.active = next
+	return nil
+}
+
+// Address implements the wallet.Key interface.
+//
+// It returns the address of the active key.
+func (k *KeySet) Address() types.Address {
+	return k.Active().Address()
+}
+
+// SignHash implements the wallet.Key interface.
+func (k *KeySet) SignHash(hash types.Hash) (*types.Signature, error) {
+	return k.Active().SignHash(hash)
+}
+
+// SignMessage implements the wallet.Key interface.
+func (k *KeySet) SignMessage(data []byte) (*types.Signature, error) {
+	return k.Active().SignMessage(data)
+}
+
+// SignTransaction implements the wallet.Key interface.
+func (k *KeySet) SignTransaction(tx *types.Transaction) error {
+	return k.Active().SignTransaction(tx)
+}
+
+// VerifyHash implements the wallet.Key interface.
+//
+// It accepts signatures made by the active key and by the retiring key.
+func (k *KeySet) VerifyHash(hash types.Hash, sig types.Signature) bool {
+	if k.Active().VerifyHash(hash, sig) {
+		return true
+	}
+	if r, _ := k.Retiring(); r != nil {
+		return r.VerifyHash(hash, sig)
+	}
+	return false
+}
+
+// VerifyMessage implements the wallet.Key interface.
+//
+// It accepts signatures made by the active key and by the retiring key.
+func (k *KeySet) VerifyMessage(data []byte, sig types.Signature) bool {
+	if k.Active().VerifyMessage(data, sig) {
+		return true
+	}
+	if r, _ := k.Retiring(); r != nil {
+		return r.VerifyMessage(data, sig)
+	}
+	return false
+}
+
+func (k *KeySet) find(addr types.Address) wallet.Key {
+	for _, key := range k.keys {
+		if key.Address() == addr {
+			return key
+		}
+	}
+	return nil
+}
+
+var _ wallet.Key = (*KeySet)(nil)
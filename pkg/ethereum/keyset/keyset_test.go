@@ -0,0 +1,131 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package keyset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	k1 := wallet.NewRandomKey()
+	k2 := wallet.NewRandomKey()
+
+	_, err := New(nil, k1.Address(), 0)
+	assert.Error(t, err)
+
+	_, err = New([]wallet.Key{k1}, k2.Address(), 0)
+	assert.Error(t, err)
+
+	_, err = New([]wallet.Key{k1, k1}, k1.Address(), 0)
+	assert.Error(t, err)
+
+	ks, err := New([]wallet.Key{k1, k2}, k2.Address(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, k2.Address(), ks.Address())
+	assert.Equal(t, []types.Address{k1.Address(), k2.Address()}, ks.Keys())
+}
+
+type wrappedKey struct {
+	wallet.Key
+}
+
+func (k wrappedKey) Unwrap() wallet.Key {
+	return k.Key
+}
+
+func TestFromKey(t *testing.T) {
+	k1 := wallet.NewRandomKey()
+	ks, err := New([]wallet.Key{k1}, k1.Address(), 0)
+	require.NoError(t, err)
+
+	got, ok := FromKey(ks)
+	assert.True(t, ok)
+	assert.Same(t, ks, got)
+
+	got, ok = FromKey(wrappedKey{Key: wrappedKey{Key: ks}})
+	assert.True(t, ok)
+	assert.Same(t, ks, got)
+
+	_, ok = FromKey(k1)
+	assert.False(t, ok)
+
+	_, ok = FromKey(wrappedKey{Key: k1})
+	assert.False(t, ok)
+
+	_, ok = FromKey(nil)
+	assert.False(t, ok)
+}
+
+func TestKeySet_Rotate(t *testing.T) {
+	k1 := wallet.NewRandomKey()
+	k2 := wallet.NewRandomKey()
+	ks, err := New([]wallet.Key{k1, k2}, k1.Address(), time.Hour)
+	require.NoError(t, err)
+
+	data := []byte("foo")
+	oldSig, err := ks.SignMessage(data)
+	require.NoError(t, err)
+
+	// Rotate to the second key:
+	require.NoError(t, ks.Rotate(k2.Address(), 0))
+	assert.Equal(t, k2.Address(), ks.Address())
+	retiring, till := ks.Retiring()
+	require.NotNil(t, retiring)
+	assert.Equal(t, k1.Address(), retiring.Address())
+	assert.WithinDuration(t, time.Now().Add(time.Hour), till, time.Minute)
+
+	// New messages are signed with the new key:
+	newSig, err := ks.SignMessage(data)
+	require.NoError(t, err)
+	addr, err := crypto.ECRecoverer.RecoverMessage(data, *newSig)
+	require.NoError(t, err)
+	assert.Equal(t, k2.Address(), *addr)
+
+	// Signatures of both keys are accepted during the overlap window:
+	assert.True(t, ks.VerifyMessage(data, *oldSig))
+	assert.True(t, ks.VerifyMessage(data, *newSig))
+
+	// Unknown key:
+	unknown := wallet.NewRandomKey().Address()
+	assert.True(t, ks.Has(k1.Address()))
+	assert.False(t, ks.Has(unknown))
+	assert.Error(t, ks.Rotate(unknown, 0))
+}
+
+func TestKeySet_OverlapExpired(t *testing.T) {
+	k1 := wallet.NewRandomKey()
+	k2 := wallet.NewRandomKey()
+	ks, err := New([]wallet.Key{k1, k2}, k1.Address(), time.Hour)
+	require.NoError(t, err)
+
+	data := []byte("foo")
+	oldSig, err := ks.SignMessage(data)
+	require.NoError(t, err)
+
+	require.NoError(t, ks.Rotate(k2.Address(), time.Nanosecond))
+	time.Sleep(time.Millisecond)
+
+	retiring, _ := ks.Retiring()
+	assert.Nil(t, retiring)
+	assert.False(t, ks.VerifyMessage(data, *oldSig))
+}
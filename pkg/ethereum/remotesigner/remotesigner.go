@@ -0,0 +1,276 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/hexutil"
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+)
+
+// defaultTimeout is the default timeout for requests to the remote signer.
+const defaultTimeout = 10 * time.Second
+
+// maxResponseSize is the maximum size of a response from the remote signer.
+const maxResponseSize = 4096
+
+// ErrSignHashNotSupported is returned by Key.SignHash. The remote signer
+// protocol only allows signing data, which is hashed by the signer, so it
+// is not possible to sign an arbitrary hash.
+var ErrSignHashNotSupported = errors.New("remote signer does not support signing raw hashes")
+
+// Config is the configuration for Key.
+type Config struct {
+	// URL is the base URL of the remote signer.
+	URL string
+
+	// Address is the address of the key held by the remote signer.
+	Address types.Address
+
+	// Identifier identifies the key in the signing endpoint path. Web3Signer
+	// identifies keys by their public keys, other signers may use
+	// addresses. If empty, the address is used.
+	Identifier string
+
+	// AuthToken is a token sent in the Authorization header as a bearer
+	// token. If empty, the header is not sent.
+	AuthToken string
+
+	// Timeout is the timeout for requests to the remote signer. If zero,
+	// the default timeout of 10 seconds is used.
+	Timeout time.Duration
+
+	// Client is the HTTP client used to send requests. If nil, the default
+	// client is used.
+	Client *http.Client
+}
+
+// Key is a wallet.Key implementation that delegates signing to a remote
+// signer, so the private key never has to be stored on the same machine.
+//
+// The remote signer must implement the Web3Signer Eth1 signing endpoint:
+//
+//	POST /api/v1/eth1/sign/{identifier}
+//	{"data": "0x..."}
+//
+// The identifier is the public key of the key in the case of Web3Signer.
+// If it is not configured, the address is used.
+//
+// The response body must contain a 65-byte signature of the Keccak256 hash
+// of the data, encoded as a hex string, either as a plain text or as a JSON
+// string. Every returned signature is verified against the configured
+// address before it is used.
+type Key struct {
+	client    *http.Client
+	endpoint  string
+	address   types.Address
+	authToken string
+	timeout   time.Duration
+}
+
+// New returns a new instance of the Key struct.
+func New(cfg Config) (*Key, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("remote signer URL is not set")
+	}
+	if cfg.Address == types.ZeroAddress {
+		return nil, errors.New("remote signer address is not set")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signer URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported remote signer URL scheme: %s", u.Scheme)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.Identifier == "" {
+		cfg.Identifier = cfg.Address.String()
+	}
+	return &Key{
+		client:    cfg.Client,
+		endpoint:  strings.TrimRight(cfg.URL, "/") + "/api/v1/eth1/sign/" + url.PathEscape(cfg.Identifier),
+		address:   cfg.Address,
+		authToken: cfg.AuthToken,
+		timeout:   cfg.Timeout,
+	}, nil
+}
+
+// Address implements the wallet.Key interface.
+func (k *Key) Address() types.Address {
+	return k.address
+}
+
+// SignHash implements the wallet.Key interface.
+//
+// It always returns ErrSignHashNotSupported.
+func (k *Key) SignHash(_ types.Hash) (*types.Signature, error) {
+	return nil, ErrSignHashNotSupported
+}
+
+// SignMessage implements the wallet.Key interface.
+func (k *Key) SignMessage(data []byte) (*types.Signature, error) {
+	data = crypto.AddMessagePrefix(data)
+	sig, err := k.sign(data)
+	if err != nil {
+		return nil, err
+	}
+	sig.V = new(big.Int).Add(sig.V, big.NewInt(27))
+	return sig, nil
+}
+
+// SignTransaction implements the wallet.Key interface.
+func (k *Key) SignTransaction(tx *types.Transaction) error {
+	if tx.From != nil && *tx.From != k.address {
+		return fmt.Errorf("invalid signer address: %s", tx.From)
+	}
+	data, err := signingData(tx)
+	if err != nil {
+		return err
+	}
+	sig, err := k.sign(data)
+	if err != nil {
+		return err
+	}
+	v := sig.V
+	if tx.Type == types.LegacyTxType {
+		if tx.ChainID != nil {
+			v = new(big.Int).Add(v, new(big.Int).SetUint64(*tx.ChainID*2+35))
+		} else {
+			v = new(big.Int).Add(v, big.NewInt(27))
+		}
+	}
+	tx.From = &k.address
+	tx.Signature = types.SignatureFromVRSPtr(v, sig.R, sig.S)
+	return nil
+}
+
+// VerifyHash implements the wallet.Key interface.
+func (k *Key) VerifyHash(hash types.Hash, sig types.Signature) bool {
+	addr, err := crypto.ECRecoverer.RecoverHash(hash, sig)
+	if err != nil {
+		return false
+	}
+	return *addr == k.address
+}
+
+// VerifyMessage implements the wallet.Key interface.
+func (k *Key) VerifyMessage(data []byte, sig types.Signature) bool {
+	addr, err := crypto.ECRecoverer.RecoverMessage(data, sig)
+	if err != nil {
+		return false
+	}
+	return *addr == k.address
+}
+
+// sign requests a signature of the Keccak256 hash of the given data from
+// the remote signer. The V value of the returned signature is normalized
+// to 0 or 1.
+func (k *Key) sign(data []byte) (*types.Signature, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), k.timeout)
+	defer cancel()
+	body, err := json.Marshal(signRequest{Data: hexutil.BytesToHex(data)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if k.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+k.authToken)
+	}
+	res, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer request failed: %w", err)
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read remote signer response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer returned status %d: %s", res.StatusCode, strings.TrimSpace(string(resBody)))
+	}
+	sig, err := parseSignature(resBody)
+	if err != nil {
+		return nil, err
+	}
+	// Remote signer is not trusted to sign with the correct key, so the
+	// signature is verified before it is used.
+	addr, err := crypto.ECRecoverer.RecoverHash(crypto.Keccak256(data), *sig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to verify remote signer signature: %w", err)
+	}
+	if *addr != k.address {
+		return nil, fmt.Errorf("remote signer signed with a wrong key: expected %s, got %s", k.address, addr)
+	}
+	return sig, nil
+}
+
+type signRequest struct {
+	Data string `json:"data"`
+}
+
+// parseSignature parses a signature returned by the remote signer.
+func parseSignature(b []byte) (*types.Signature, error) {
+	s := strings.TrimSpace(string(b))
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal([]byte(s), &s); err != nil {
+			return nil, fmt.Errorf("invalid remote signer response: %w", err)
+		}
+	}
+	sb, err := hexutil.HexToBytes(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signer response: %w", err)
+	}
+	if len(sb) != 65 {
+		return nil, fmt.Errorf("invalid remote signer signature length: %d", len(sb))
+	}
+	sig, err := types.SignatureFromBytes(sb)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signer signature: %w", err)
+	}
+	switch sig.V.Uint64() {
+	case 0, 1:
+	case 27, 28:
+		sig.V = new(big.Int).Sub(sig.V, big.NewInt(27))
+	default:
+		return nil, fmt.Errorf("invalid remote signer signature V value: %s", sig.V)
+	}
+	return &sig, nil
+}
+
+var _ wallet.Key = (*Key)(nil)
@@ -0,0 +1,217 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package remotesigner
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/hexutil"
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "secret"
+
+// mockSigner returns a server that implements the Web3Signer Eth1 signing
+// endpoint for the given key.
+func mockSigner(t *testing.T, key *wallet.PrivateKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/api/v1/eth1/sign/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req signRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		data, err := hexutil.HexToBytes(req.Data)
+		require.NoError(t, err)
+		sig, err := key.SignHash(crypto.Keccak256(data))
+		require.NoError(t, err)
+		sig.V = new(big.Int).Add(sig.V, big.NewInt(27))
+		_, _ = w.Write([]byte(hexutil.BytesToHex(sig.Bytes())))
+	}))
+}
+
+func TestKey_SignMessage(t *testing.T) {
+	local := wallet.NewRandomKey()
+	srv := mockSigner(t, local)
+	defer srv.Close()
+
+	key, err := New(Config{URL: srv.URL, Address: local.Address(), AuthToken: testToken})
+	require.NoError(t, err)
+
+	data := []byte("hello")
+	sig, err := key.SignMessage(data)
+	require.NoError(t, err)
+
+	expected, err := local.SignMessage(data)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Bytes(), sig.Bytes())
+	assert.True(t, key.VerifyMessage(data, *sig))
+}
+
+func TestKey_SignTransaction(t *testing.T) {
+	local := wallet.NewRandomKey()
+	srv := mockSigner(t, local)
+	defer srv.Close()
+
+	key, err := New(Config{URL: srv.URL, Address: local.Address(), AuthToken: testToken})
+	require.NoError(t, err)
+
+	tests := map[string]func() *types.Transaction{
+		"legacy": func() *types.Transaction {
+			return (&types.Transaction{}).
+				SetType(types.LegacyTxType).
+				SetTo(types.MustAddressFromHex("0x3535353535353535353535353535353535353535")).
+				SetGasLimit(21000).
+				SetGasPrice(big.NewInt(20000000000)).
+				SetNonce(9).
+				SetValue(big.NewInt(1000000000000000000))
+		},
+		"legacy-eip155": func() *types.Transaction {
+			return (&types.Transaction{}).
+				SetType(types.LegacyTxType).
+				SetChainID(1).
+				SetTo(types.MustAddressFromHex("0x3535353535353535353535353535353535353535")).
+				SetGasLimit(21000).
+				SetGasPrice(big.NewInt(20000000000)).
+				SetNonce(9).
+				SetValue(big.NewInt(1000000000000000000))
+		},
+		"dynamic-fee": func() *types.Transaction {
+			return (&types.Transaction{}).
+				SetType(types.DynamicFeeTxType).
+				SetChainID(1).
+				SetTo(types.MustAddressFromHex("0x3535353535353535353535353535353535353535")).
+				SetGasLimit(21000).
+				SetMaxFeePerGas(big.NewInt(20000000000)).
+				SetMaxPriorityFeePerGas(big.NewInt(1000000000)).
+				SetNonce(9).
+				SetInput([]byte{1, 2, 3})
+		},
+	}
+	for name, tx := range tests {
+		t.Run(name, func(t *testing.T) {
+			remoteTx := tx()
+			localTx := tx()
+			require.NoError(t, key.SignTransaction(remoteTx))
+			require.NoError(t, local.SignTransaction(localTx))
+			assert.Equal(t, localTx.Signature.Bytes(), remoteTx.Signature.Bytes())
+			assert.Equal(t, local.Address(), *remoteTx.From)
+		})
+	}
+}
+
+func TestKey_Identifier(t *testing.T) {
+	local := wallet.NewRandomKey()
+	srv := mockSigner(t, local)
+	defer srv.Close()
+
+	tests := []struct {
+		identifier string
+		want       string
+	}{
+		{identifier: "", want: "/api/v1/eth1/sign/" + local.Address().String()},
+		{identifier: "0x04abcdef", want: "/api/v1/eth1/sign/0x04abcdef"},
+	}
+	for _, tt := range tests {
+		var path string
+		client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			path = r.URL.Path
+			return http.DefaultTransport.RoundTrip(r)
+		})}
+		key, err := New(Config{URL: srv.URL, Address: local.Address(), Identifier: tt.identifier, AuthToken: testToken, Client: client})
+		require.NoError(t, err)
+		_, err = key.SignMessage([]byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, path)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestKey_WrongKey(t *testing.T) {
+	srv := mockSigner(t, wallet.NewRandomKey())
+	defer srv.Close()
+
+	key, err := New(Config{URL: srv.URL, Address: wallet.NewRandomKey().Address(), AuthToken: testToken})
+	require.NoError(t, err)
+
+	_, err = key.SignMessage([]byte("hello"))
+	assert.ErrorContains(t, err, "wrong key")
+}
+
+func TestKey_Unauthorized(t *testing.T) {
+	local := wallet.NewRandomKey()
+	srv := mockSigner(t, local)
+	defer srv.Close()
+
+	key, err := New(Config{URL: srv.URL, Address: local.Address(), AuthToken: "invalid"})
+	require.NoError(t, err)
+
+	_, err = key.SignMessage([]byte("hello"))
+	assert.ErrorContains(t, err, "status 401")
+}
+
+func TestKey_SignHash(t *testing.T) {
+	key, err := New(Config{URL: "http://localhost", Address: wallet.NewRandomKey().Address()})
+	require.NoError(t, err)
+
+	_, err = key.SignHash(types.Hash{})
+	assert.ErrorIs(t, err, ErrSignHashNotSupported)
+}
+
+func TestParseSignature(t *testing.T) {
+	sig := strings.Repeat("11", 64)
+	tests := []struct {
+		input   string
+		wantV   uint64
+		wantErr bool
+	}{
+		{input: "0x" + sig + "1b", wantV: 0},
+		{input: "0x" + sig + "01\n", wantV: 1},
+		{input: `"0x` + sig + `1c"`, wantV: 1},
+		{input: "0x" + sig + "25", wantErr: true},
+		{input: "0x" + sig, wantErr: true},
+		{input: "foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			s, err := parseSignature([]byte(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantV, s.V.Uint64())
+		})
+	}
+}
@@ -0,0 +1,120 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package remotesigner
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-rlp"
+)
+
+// signingData returns the data whose Keccak256 hash is signed to sign
+// the transaction. It follows the encoding used by the go-eth library
+// to calculate the transaction signing hash.
+func signingData(t *types.Transaction) ([]byte, error) {
+	var (
+		chainID              = uint64(1)
+		nonce                = uint64(0)
+		gasPrice             = big.NewInt(0)
+		gasLimit             = uint64(0)
+		maxPriorityFeePerGas = big.NewInt(0)
+		maxFeePerGas         = big.NewInt(0)
+		to                   = ([]byte)(nil)
+		value                = big.NewInt(0)
+		accessList           = (types.AccessList)(nil)
+	)
+	if t.ChainID != nil {
+		chainID = *t.ChainID
+	}
+	if t.Nonce != nil {
+		nonce = *t.Nonce
+	}
+	if t.GasPrice != nil {
+		gasPrice = t.GasPrice
+	}
+	if t.GasLimit != nil {
+		gasLimit = *t.GasLimit
+	}
+	if t.MaxPriorityFeePerGas != nil {
+		maxPriorityFeePerGas = t.MaxPriorityFeePerGas
+	}
+	if t.MaxFeePerGas != nil {
+		maxFeePerGas = t.MaxFeePerGas
+	}
+	if t.To != nil {
+		to = t.To[:]
+	}
+	if t.Value != nil {
+		value = t.Value
+	}
+	if t.AccessList != nil {
+		accessList = t.AccessList
+	}
+	switch t.Type {
+	case types.LegacyTxType:
+		list := rlp.NewList(
+			rlp.NewUint(nonce),
+			rlp.NewBigInt(gasPrice),
+			rlp.NewUint(gasLimit),
+			rlp.NewBytes(to),
+			rlp.NewBigInt(value),
+			rlp.NewBytes(t.Input),
+		)
+		if t.ChainID != nil && *t.ChainID != 0 {
+			list.Append(
+				rlp.NewUint(chainID),
+				rlp.NewUint(0),
+				rlp.NewUint(0),
+			)
+		}
+		return list.EncodeRLP()
+	case types.AccessListTxType:
+		bin, err := rlp.NewList(
+			rlp.NewUint(chainID),
+			rlp.NewUint(nonce),
+			rlp.NewBigInt(gasPrice),
+			rlp.NewUint(gasLimit),
+			rlp.NewBytes(to),
+			rlp.NewBigInt(value),
+			rlp.NewBytes(t.Input),
+			&accessList,
+		).EncodeRLP()
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{byte(t.Type)}, bin...), nil
+	case types.DynamicFeeTxType:
+		bin, err := rlp.NewList(
+			rlp.NewUint(chainID),
+			rlp.NewUint(nonce),
+			rlp.NewBigInt(maxPriorityFeePerGas),
+			rlp.NewBigInt(maxFeePerGas),
+			rlp.NewUint(gasLimit),
+			rlp.NewBytes(to),
+			rlp.NewBigInt(value),
+			rlp.NewBytes(t.Input),
+			&accessList,
+		).EncodeRLP()
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{byte(t.Type)}, bin...), nil
+	default:
+		return nil, fmt.Errorf("unsupported transaction type: %d", t.Type)
+	}
+}
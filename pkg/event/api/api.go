@@ -49,12 +49,20 @@ const defaultTimeout = 3 * time.Second
 // Both parameters must be provided as hex encoded strings.
 //
 // Events are returned in JSON format.
+//
+// Additionally, new events and new signatures for existing events can be
+// streamed using Server-Sent Events on the /stream path or using WebSocket
+// on the /ws path. See streamFilter for the supported query parameters.
+//
+// If the SignerPolicy is configured, the /attestation path returns a bundle
+// of signatures for a teleport GUID. See attestationHandler for details.
 type EventAPI struct {
 	ctx context.Context
 
-	srv *httpserver.HTTPServer
-	es  *store.EventStore
-	log log.Logger
+	srv          *httpserver.HTTPServer
+	es           *store.EventStore
+	signerPolicy SignerPolicy
+	log          log.Logger
 }
 
 // Config is the configuration for the EventAPI.
@@ -66,6 +74,10 @@ type Config struct {
 	// form "host:port".
 	Address string
 
+	// SignerPolicy is used to filter signatures returned by the attestation
+	// endpoint. If nil, the attestation endpoint is disabled.
+	SignerPolicy SignerPolicy
+
 	// Logger is a current logger used by the EventAPI.
 	Logger log.Logger
 }
@@ -93,12 +105,18 @@ func New(cfg Config) (*EventAPI, error) {
 		cfg.Logger = null.New()
 	}
 	api := &EventAPI{
-		es:  cfg.EventStore,
-		log: cfg.Logger.WithField("tag", LoggerTag),
+		es:           cfg.EventStore,
+		signerPolicy: cfg.SignerPolicy,
+		log:          cfg.Logger.WithField("tag", LoggerTag),
 	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", api.handler)
+	mux.HandleFunc("/stream", api.sseHandler)
+	mux.HandleFunc("/ws", api.wsHandler)
+	mux.HandleFunc("/attestation", api.attestationHandler)
 	api.srv = httpserver.New(&http.Server{
 		Addr:              cfg.Address,
-		Handler:           http.HandlerFunc(api.handler),
+		Handler:           mux,
 		IdleTimeout:       defaultTimeout,
 		ReadTimeout:       defaultTimeout,
 		WriteTimeout:      defaultTimeout,
@@ -134,6 +134,11 @@ func TestEventAPI(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
 
+	// Return not found if the attestation endpoint is not configured:
+	res, err = http.Get(fmt.Sprintf("http://%s/attestation?type=event1&index=%x&guid=00", api.srv.Addr().String(), "idx1"))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
 	// Return method not allowed if the method is not GET:
 	res, err = http.Post(fmt.Sprintf("http://%s?index=%x", api.srv.Addr().String(), "idx1"), "application/json", nil)
 	assert.NoError(t, err)
@@ -0,0 +1,343 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/defiweb/go-eth/abi"
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/rpc"
+	"github.com/defiweb/go-eth/types"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+// SignerPolicy decides which signatures are included in attestation bundles
+// and how many of them are required to reach a quorum.
+type SignerPolicy interface {
+	// IsAuthorized returns true if the signer is an authorized oracle.
+	IsAuthorized(ctx context.Context, signer types.Address) (bool, error)
+
+	// Quorum returns the minimum number of signatures required.
+	Quorum(ctx context.Context) (int, error)
+}
+
+// StaticSignerPolicy is a SignerPolicy with a static list of authorized
+// signers and a fixed quorum.
+type StaticSignerPolicy struct {
+	signers map[types.Address]struct{}
+	quorum  int
+}
+
+// NewStaticSignerPolicy returns a new instance of StaticSignerPolicy.
+func NewStaticSignerPolicy(signers []types.Address, quorum int) *StaticSignerPolicy {
+	p := &StaticSignerPolicy{
+		signers: make(map[types.Address]struct{}, len(signers)),
+		quorum:  quorum,
+	}
+	for _, s := range signers {
+		p.signers[s] = struct{}{}
+	}
+	return p
+}
+
+// IsAuthorized implements the SignerPolicy interface.
+func (p *StaticSignerPolicy) IsAuthorized(_ context.Context, signer types.Address) (bool, error) {
+	_, ok := p.signers[signer]
+	return ok, nil
+}
+
+// Quorum implements the SignerPolicy interface.
+func (p *StaticSignerPolicy) Quorum(_ context.Context) (int, error) {
+	return p.quorum, nil
+}
+
+// OracleAuthSignerPolicy is a SignerPolicy that reads authorized signers and
+// the quorum from the TeleportOracleAuth contract:
+// https://github.com/makerdao/dss-teleport/blob/master/src/TeleportOracleAuth.sol
+type OracleAuthSignerPolicy struct {
+	mu sync.Mutex
+
+	client   rpc.RPC       // Ethereum client.
+	address  types.Address // Address of the TeleportOracleAuth contract.
+	quorum   int           // Quorum override, if zero, the contract threshold is used.
+	cacheTTL time.Duration // How long the fetched values should be cached.
+
+	signers   map[types.Address]cachedValue
+	threshold cachedValue
+}
+
+type cachedValue struct {
+	value int
+	time  time.Time
+}
+
+// NewOracleAuthSignerPolicy returns a new instance of OracleAuthSignerPolicy.
+// If the quorum is zero, the threshold defined in the contract is used.
+// The cacheTTL parameter specifies how long values fetched from the contract
+// should be cached.
+func NewOracleAuthSignerPolicy(client rpc.RPC, address types.Address, quorum int, cacheTTL time.Duration) *OracleAuthSignerPolicy {
+	return &OracleAuthSignerPolicy{
+		client:   client,
+		address:  address,
+		quorum:   quorum,
+		cacheTTL: cacheTTL,
+		signers:  map[types.Address]cachedValue{},
+	}
+}
+
+// IsAuthorized implements the SignerPolicy interface.
+func (p *OracleAuthSignerPolicy) IsAuthorized(ctx context.Context, signer types.Address) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.signers[signer]; ok && time.Since(c.time) < p.cacheTTL {
+		return c.value == 1, nil
+	}
+	v, err := p.call(ctx, signersMethod, signer)
+	if err != nil {
+		return false, err
+	}
+	p.signers[signer] = cachedValue{value: v, time: time.Now()}
+	return v == 1, nil
+}
+
+// Quorum implements the SignerPolicy interface.
+func (p *OracleAuthSignerPolicy) Quorum(ctx context.Context) (int, error) {
+	if p.quorum > 0 {
+		return p.quorum, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.threshold.time.IsZero() && time.Since(p.threshold.time) < p.cacheTTL {
+		return p.threshold.value, nil
+	}
+	v, err := p.call(ctx, thresholdMethod)
+	if err != nil {
+		return 0, err
+	}
+	p.threshold = cachedValue{value: v, time: time.Now()}
+	return v, nil
+}
+
+func (p *OracleAuthSignerPolicy) call(ctx context.Context, method *abi.Method, args ...any) (int, error) {
+	cd, err := method.EncodeArgs(args...)
+	if err != nil {
+		return 0, err
+	}
+	res, err := p.client.Call(ctx, types.Call{
+		To:    &p.address,
+		Input: cd,
+	}, types.LatestBlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	var v *big.Int
+	if err := method.DecodeValues(res, &v); err != nil {
+		return 0, err
+	}
+	if !v.IsInt64() {
+		return 0, fmt.Errorf("value returned by the %s method is too large", method.Name())
+	}
+	return int(v.Int64()), nil
+}
+
+// teleportGUIDFields is the number of fields in the TeleportGUID struct.
+const teleportGUIDFields = 7
+
+var (
+	signersMethod   = abi.MustParseMethod("function signers(address) view returns (uint256)")
+	thresholdMethod = abi.MustParseMethod("function threshold() view returns (uint256)")
+)
+
+// jsonAttestation is a bundle of signatures returned by the attestation
+// endpoint.
+type jsonAttestation struct {
+	Hash       string   `json:"hash"`
+	Signatures string   `json:"signatures"`
+	Signers    []string `json:"signers"`
+	Quorum     int      `json:"quorum"`
+	QuorumMet  bool     `json:"quorum_met"`
+}
+
+// attestationHandler returns a bundle of signatures for a teleport GUID.
+//
+// It expects the following query parameters:
+// type - the type of the event
+// index - the search index for the events, optional
+// guid - the ABI encoded TeleportGUID
+//
+// If the index is not provided, events are looked up by the timestamp of
+// the GUID, which is the event date of teleport events. This requires
+// a storage that supports queries.
+//
+// Signatures are verified, filtered using the SignerPolicy and sorted by
+// the signer address, so they can be submitted directly to the
+// TeleportOracleAuth contract.
+func (e *EventAPI) attestationHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if e.signerPolicy == nil {
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
+	q := req.URL.Query()
+	typ, ok := q["type"]
+	if !ok || len(typ) != 1 {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	idxHex, ok := q["index"]
+	if ok && len(idxHex) != 1 {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	guidHex, ok := q["guid"]
+	if !ok || len(guidHex) != 1 {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	guid, err := decodeHex(guidHex[0])
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	ctx, ctxCancel := context.WithTimeout(e.ctx, defaultTimeout)
+	defer ctxCancel()
+	var events []*messages.Event
+	if len(idxHex) == 1 {
+		idx, err := decodeHex(idxHex[0])
+		if err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events, err = e.es.Events(ctx, typ[0], idx)
+		if err != nil {
+			e.log.WithError(err).Error("Event store error")
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	} else {
+		ts, err := guidTimestamp(guid)
+		if err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events, err = e.es.Query(ctx, store.Query{Type: typ[0], From: ts, To: ts.Add(time.Second)})
+		if errors.Is(err, store.ErrQueryNotSupported) {
+			// Without queries, the index is required.
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			e.log.WithError(err).Error("Event store error")
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+	att, err := e.attestation(ctx, crypto.Keccak256(guid), events)
+	if err != nil {
+		e.log.WithError(err).Error("Unable to prepare the attestation")
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(res).Encode(att)
+}
+
+// guidTimestamp returns the timestamp of the ABI encoded TeleportGUID, which
+// is the last of its seven static fields.
+func guidTimestamp(guid []byte) (time.Time, error) {
+	if len(guid) != teleportGUIDFields*types.HashLength {
+		return time.Time{}, errors.New("invalid TeleportGUID length")
+	}
+	ts := new(big.Int).SetBytes(guid[len(guid)-types.HashLength:])
+	if ts.BitLen() > 48 {
+		return time.Time{}, errors.New("invalid TeleportGUID timestamp")
+	}
+	return time.Unix(ts.Int64(), 0), nil
+}
+
+// attestation collects valid signatures of the given hash from authorized
+// signers.
+func (e *EventAPI) attestation(ctx context.Context, hash types.Hash, events []*messages.Event) (*jsonAttestation, error) {
+	type signature struct {
+		signer    types.Address
+		signature []byte
+	}
+	quorum, err := e.signerPolicy.Quorum(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signatures := map[types.Address]signature{}
+	for _, evt := range events {
+		if !bytes.Equal(evt.Data["hash"], hash.Bytes()) {
+			continue
+		}
+		for _, s := range evt.Signatures {
+			sig, err := types.SignatureFromBytes(s.Signature)
+			if err != nil {
+				continue
+			}
+			signer, err := crypto.ECRecoverer.RecoverMessage(hash.Bytes(), sig)
+			if err != nil || !bytes.Equal(signer.Bytes(), s.Signer) {
+				continue
+			}
+			authorized, err := e.signerPolicy.IsAuthorized(ctx, *signer)
+			if err != nil {
+				return nil, err
+			}
+			if !authorized {
+				continue
+			}
+			signatures[*signer] = signature{signer: *signer, signature: s.Signature}
+		}
+	}
+	sorted := make([]signature, 0, len(signatures))
+	for _, s := range signatures {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].signer.Bytes(), sorted[j].signer.Bytes()) < 0
+	})
+	att := &jsonAttestation{
+		Hash:      hex.EncodeToString(hash.Bytes()),
+		Signers:   make([]string, 0, len(sorted)),
+		Quorum:    quorum,
+		QuorumMet: len(sorted) >= quorum,
+	}
+	var concat []byte
+	for _, s := range sorted {
+		att.Signers = append(att.Signers, hex.EncodeToString(s.signer.Bytes()))
+		concat = append(concat, s.signature...)
+	}
+	att.Signatures = hex.EncodeToString(concat)
+	return att, nil
+}
@@ -0,0 +1,220 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/abi"
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/mocks"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/local"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+func signedEvent(t *testing.T, key wallet.Key, hash types.Hash) *messages.Event {
+	sig, err := key.SignMessage(hash.Bytes())
+	require.NoError(t, err)
+	return &messages.Event{
+		Type:  "teleport_evm",
+		ID:    []byte("id"),
+		Index: []byte("idx"),
+		Data:  map[string][]byte{"hash": hash.Bytes()},
+		Signatures: map[string]messages.EventSignature{
+			"ethereum": {Signer: key.Address().Bytes(), Signature: sig.Bytes()},
+		},
+	}
+}
+
+func TestEventAPI_attestation(t *testing.T) {
+	keys := []wallet.Key{wallet.NewRandomKey(), wallet.NewRandomKey(), wallet.NewRandomKey()}
+	unauthorized := wallet.NewRandomKey()
+	hash := crypto.Keccak256([]byte("guid"))
+
+	// Event with a signature that does not match the signer.
+	forged := signedEvent(t, keys[2], hash)
+	forged.Signatures["ethereum"] = messages.EventSignature{
+		Signer:    keys[1].Address().Bytes(),
+		Signature: forged.Signatures["ethereum"].Signature,
+	}
+
+	events := []*messages.Event{
+		signedEvent(t, keys[0], hash),
+		signedEvent(t, keys[1], hash),
+		signedEvent(t, keys[1], crypto.Keccak256([]byte("other"))), // different GUID
+		signedEvent(t, unauthorized, hash),
+		forged,
+	}
+
+	api := &EventAPI{signerPolicy: NewStaticSignerPolicy([]types.Address{
+		keys[0].Address(),
+		keys[1].Address(),
+		keys[2].Address(),
+	}, 2)}
+	att, err := api.attestation(context.Background(), hash, events)
+	require.NoError(t, err)
+
+	// Signatures must be sorted by the signer address.
+	sorted := []wallet.Key{keys[0], keys[1]}
+	if bytes.Compare(sorted[0].Address().Bytes(), sorted[1].Address().Bytes()) > 0 {
+		sorted[0], sorted[1] = sorted[1], sorted[0]
+	}
+	var concat []byte
+	for _, k := range sorted {
+		sig, err := k.SignMessage(hash.Bytes())
+		require.NoError(t, err)
+		concat = append(concat, sig.Bytes()...)
+	}
+	assert.Equal(t, hex.EncodeToString(hash.Bytes()), att.Hash)
+	assert.Equal(t, []string{
+		hex.EncodeToString(sorted[0].Address().Bytes()),
+		hex.EncodeToString(sorted[1].Address().Bytes()),
+	}, att.Signers)
+	assert.Equal(t, hex.EncodeToString(concat), att.Signatures)
+	assert.Equal(t, 2, att.Quorum)
+	assert.True(t, att.QuorumMet)
+
+	// Quorum is not met if there are not enough signatures.
+	api.signerPolicy = NewStaticSignerPolicy([]types.Address{keys[0].Address()}, 2)
+	att, err = api.attestation(context.Background(), hash, events)
+	require.NoError(t, err)
+	assert.Len(t, att.Signers, 1)
+	assert.False(t, att.QuorumMet)
+}
+
+func TestOracleAuthSignerPolicy(t *testing.T) {
+	ctx := context.Background()
+	client := &mocks.RPC{}
+	contract := types.MustAddressFromHex("0x1234567890123456789012345678901234567890")
+	authorized := types.MustAddressFromHex("0x2345678901234567890123456789012345678901")
+	unauthorized := types.MustAddressFromHex("0x3456789012345678901234567890123456789012")
+
+	isCall := func(method *abi.Method, args ...any) any {
+		cd, err := method.EncodeArgs(args...)
+		require.NoError(t, err)
+		return mock.MatchedBy(func(call types.Call) bool {
+			return *call.To == contract && bytes.Equal(call.Input, cd)
+		})
+	}
+	uint256 := func(n int64) []byte {
+		return abi.MustEncodeValue(abi.MustParseType("uint256"), big.NewInt(n))
+	}
+	client.On("Call", ctx, isCall(signersMethod, authorized), types.LatestBlockNumber).Return(uint256(1), nil).Once()
+	client.On("Call", ctx, isCall(signersMethod, unauthorized), types.LatestBlockNumber).Return(uint256(0), nil).Once()
+	client.On("Call", ctx, isCall(thresholdMethod), types.LatestBlockNumber).Return(uint256(13), nil).Once()
+
+	p := NewOracleAuthSignerPolicy(client, contract, 0, time.Minute)
+	for i := 0; i < 2; i++ { // Second iteration must use the cache.
+		ok, err := p.IsAuthorized(ctx, authorized)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = p.IsAuthorized(ctx, unauthorized)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		q, err := p.Quorum(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 13, q)
+	}
+	client.AssertExpectations(t)
+
+	// Quorum override.
+	q, err := NewOracleAuthSignerPolicy(client, contract, 5, time.Minute).Quorum(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, q)
+}
+
+func TestEventAPI_attestationHandler(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	loc := local.New([]byte("test"), 4, map[string]transport.Message{messages.EventV1MessageName: (*messages.Event)(nil)})
+	evs, err := store.New(store.Config{
+		EventTypes: []string{"teleport_evm"},
+		Storage:    store.NewMemoryStorage(time.Hour),
+		Transport:  loc,
+		Logger:     null.New(),
+	})
+	require.NoError(t, err)
+	key := wallet.NewRandomKey()
+	api, err := New(Config{
+		EventStore:   evs,
+		Address:      "127.0.0.1:0",
+		SignerPolicy: NewStaticSignerPolicy([]types.Address{key.Address()}, 1),
+		Logger:       null.New(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, loc.Start(ctx))
+	require.NoError(t, evs.Start(ctx))
+	require.NoError(t, api.Start(ctx))
+
+	// Wait for services to start.
+	time.Sleep(time.Millisecond * 100)
+
+	// ABI encoded TeleportGUID with the timestamp in the last field.
+	ts := time.Now().Unix()
+	guid := make([]byte, teleportGUIDFields*types.HashLength)
+	big.NewInt(ts).FillBytes(guid[len(guid)-types.HashLength:])
+	hash := crypto.Keccak256(guid)
+	evt := signedEvent(t, key, hash)
+	evt.EventDate = time.Unix(ts, 0)
+	evt.MessageDate = time.Now()
+	require.NoError(t, loc.Broadcast(messages.EventV1MessageName, evt))
+
+	get := func(query string) (int, *jsonAttestation) {
+		res, err := http.Get(fmt.Sprintf("http://%s/attestation?%s", api.srv.Addr().String(), query))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return res.StatusCode, nil
+		}
+		var att jsonAttestation
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&att))
+		return res.StatusCode, &att
+	}
+
+	// Lookup by the index:
+	assert.Eventually(t, func() bool {
+		_, att := get(fmt.Sprintf("type=teleport_evm&index=%x&guid=%x", evt.Index, guid))
+		return att != nil && att.QuorumMet
+	}, time.Second, 10*time.Millisecond)
+
+	// Lookup by the GUID only:
+	code, att := get(fmt.Sprintf("type=teleport_evm&guid=%x", guid))
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, att.QuorumMet)
+	assert.Equal(t, []string{hex.EncodeToString(key.Address().Bytes())}, att.Signers)
+
+	// Invalid GUID:
+	code, _ = get("type=teleport_evm&guid=00")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
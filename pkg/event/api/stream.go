@@ -0,0 +1,355 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+// streamPingInterval specifies how often keep-alive messages are sent to
+// the stream subscribers.
+const streamPingInterval = 15 * time.Second
+
+// streamWriteTimeout is the timeout for writing a single message to the
+// stream subscriber.
+const streamWriteTimeout = 10 * time.Second
+
+// jsonStreamEvent is a message sent to the stream subscribers.
+type jsonStreamEvent struct {
+	Type   string       `json:"type"`
+	Index  string       `json:"index"`
+	ID     string       `json:"id"`
+	Events []*jsonEvent `json:"events"`
+}
+
+// streamFilter is a filter for the streamed events.
+//
+// It is created from the following query parameters:
+// type - the type of the event, may be repeated
+// index - the hex encoded search index of the event, may be repeated
+// signer - the hex encoded signer address, may be repeated
+// threshold - the number of distinct signatures required to emit an event
+//
+// All parameters are optional.
+type streamFilter struct {
+	types     []string
+	indexes   [][]byte
+	signers   [][]byte
+	threshold int
+}
+
+func parseStreamFilter(q url.Values) (*streamFilter, error) {
+	f := &streamFilter{types: q["type"]}
+	for _, h := range q["index"] {
+		idx, err := decodeHex(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index: %w", err)
+		}
+		f.indexes = append(f.indexes, idx)
+	}
+	for _, h := range q["signer"] {
+		signer, err := decodeHex(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer: %w", err)
+		}
+		f.signers = append(f.signers, signer)
+	}
+	if t := q.Get("threshold"); t != "" {
+		n, err := strconv.Atoi(t)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid threshold: %s", t)
+		}
+		f.threshold = n
+	}
+	return f, nil
+}
+
+// matchEvent returns true if the type and index of the event match the
+// filter.
+func (f *streamFilter) matchEvent(evt *messages.Event) bool {
+	if len(f.types) > 0 && !containsString(f.types, evt.Type) {
+		return false
+	}
+	if len(f.indexes) > 0 && !containsBytes(f.indexes, evt.Index) {
+		return false
+	}
+	return true
+}
+
+// matchSigners returns true if any of the signatures of the given events is
+// made by one of the signers in the filter.
+func (f *streamFilter) matchSigners(evts []*messages.Event) bool {
+	if len(f.signers) == 0 {
+		return true
+	}
+	for _, evt := range evts {
+		for _, s := range evt.Signatures {
+			if containsBytes(f.signers, s.Signer) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stream subscribes to the event store and calls the send function for
+// every event that matches the filter. It blocks until the context is
+// canceled or the send function returns an error.
+//
+// If the threshold is set, events are sent only once, after the number of
+// distinct signatures for the event reaches the threshold. In that case,
+// the message contains all known signed copies of the event.
+func (e *EventAPI) stream(ctx context.Context, f *streamFilter, send func(*jsonStreamEvent) error) error {
+	emitted := map[string]struct{}{}
+	for evt := range e.es.Subscribe(ctx) {
+		if !f.matchEvent(evt) {
+			continue
+		}
+		evts := []*messages.Event{evt}
+		if f.threshold > 0 {
+			key := evt.Type + hex.EncodeToString(evt.Index) + hex.EncodeToString(evt.ID)
+			if _, ok := emitted[key]; ok {
+				continue
+			}
+			var err error
+			if evts, err = e.eventsByID(ctx, evt); err != nil {
+				e.log.WithError(err).Error("Event store error")
+				continue
+			}
+			if countSigners(evts) < f.threshold {
+				continue
+			}
+			if !f.matchSigners(evts) {
+				continue
+			}
+			emitted[key] = struct{}{}
+		} else if !f.matchSigners(evts) {
+			continue
+		}
+		if err := send(&jsonStreamEvent{
+			Type:   evt.Type,
+			Index:  hex.EncodeToString(evt.Index),
+			ID:     hex.EncodeToString(evt.ID),
+			Events: mapEvents(evts),
+		}); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// eventsByID returns all stored events with the same type, index and ID as
+// the given event.
+func (e *EventAPI) eventsByID(ctx context.Context, evt *messages.Event) ([]*messages.Event, error) {
+	ctx, ctxCancel := context.WithTimeout(ctx, defaultTimeout)
+	defer ctxCancel()
+	all, err := e.es.Events(ctx, evt.Type, evt.Index)
+	if err != nil {
+		return nil, err
+	}
+	var evts []*messages.Event
+	for _, ev := range all {
+		if bytes.Equal(ev.ID, evt.ID) {
+			evts = append(evts, ev)
+		}
+	}
+	return evts, nil
+}
+
+// sseHandler streams events using Server-Sent Events.
+func (e *EventAPI) sseHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	f, err := parseStreamFilter(req.URL.Query())
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// Streams are long-lived, so the server timeouts must be disabled.
+	rc := http.NewResponseController(res)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		e.log.WithError(err).Error("Unable to disable the read deadline")
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		e.log.WithError(err).Error("Unable to disable the write deadline")
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ctx, ctxCancel := e.requestContext(req)
+	defer ctxCancel()
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+	var mu sync.Mutex
+	write := func(s string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := fmt.Fprint(res, s); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	go func() {
+		t := time.NewTicker(streamPingInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if err := write(": ping\n\n"); err != nil {
+					ctxCancel()
+					return
+				}
+			}
+		}
+	}()
+	_ = e.stream(ctx, f, func(evt *jsonStreamEvent) error {
+		b, err := json.Marshal(evt)
+		if err != nil {
+			return err
+		}
+		return write(fmt.Sprintf("event: event\ndata: %s\n\n", b))
+	})
+}
+
+// wsHandler streams events using WebSocket. Events are sent as JSON text
+// messages. Messages sent by the client are ignored.
+func (e *EventAPI) wsHandler(res http.ResponseWriter, req *http.Request) {
+	f, err := parseStreamFilter(req.URL.Query())
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	conn, err := upgrader.Upgrade(res, req, nil)
+	if err != nil {
+		// The upgrader already responded with an error.
+		return
+	}
+	defer conn.Close()
+	ctx, ctxCancel := e.requestContext(req)
+	defer ctxCancel()
+	go func() {
+		// Read messages to process control frames and to detect when the
+		// connection is closed.
+		defer ctxCancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	var mu sync.Mutex
+	write := func(typ int, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+			return err
+		}
+		return conn.WriteMessage(typ, data)
+	}
+	go func() {
+		t := time.NewTicker(streamPingInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if err := write(websocket.PingMessage, nil); err != nil {
+					ctxCancel()
+					return
+				}
+			}
+		}
+	}()
+	err = e.stream(ctx, f, func(evt *jsonStreamEvent) error {
+		b, err := json.Marshal(evt)
+		if err != nil {
+			return err
+		}
+		return write(websocket.TextMessage, b)
+	})
+	if err != nil && e.ctx.Err() != nil {
+		_ = write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+	}
+}
+
+// requestContext returns a context that is canceled when either the request
+// or the EventAPI context is canceled.
+func (e *EventAPI) requestContext(req *http.Request) (context.Context, context.CancelFunc) {
+	ctx, ctxCancel := context.WithCancel(req.Context())
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-e.ctx.Done():
+			ctxCancel()
+		}
+	}()
+	return ctx, ctxCancel
+}
+
+// countSigners returns the number of distinct signers of the given events.
+func countSigners(evts []*messages.Event) int {
+	signers := map[string]struct{}{}
+	for _, evt := range evts {
+		for _, s := range evt.Signatures {
+			signers[string(s.Signer)] = struct{}{}
+		}
+	}
+	return len(signers)
+}
+
+func containsString(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsBytes(s [][]byte, v []byte) bool {
+	for _, x := range s {
+		if bytes.Equal(x, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/local"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+func TestEventAPI_Stream(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	loc := local.New([]byte("test"), 4, map[string]transport.Message{messages.EventV1MessageName: (*messages.Event)(nil)})
+	mem := store.NewMemoryStorage(time.Minute)
+	evs, err := store.New(store.Config{
+		EventTypes: []string{"event1", "event2"},
+		Storage:    mem,
+		Transport:  loc,
+		Logger:     null.New(),
+	})
+	require.NoError(t, err)
+	api, err := New(Config{
+		EventStore: evs,
+		Address:    "127.0.0.1:0",
+		Logger:     null.New(),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, loc.Start(ctx))
+	require.NoError(t, evs.Start(ctx))
+	require.NoError(t, api.Start(ctx))
+	defer func() {
+		cancelFunc()
+		require.NoError(t, <-loc.Wait())
+		require.NoError(t, <-evs.Wait())
+		require.NoError(t, <-api.Wait())
+	}()
+
+	// Wait for services to start.
+	time.Sleep(time.Millisecond * 100)
+
+	// SSE stream that emits events after two distinct signatures are
+	// collected.
+	sseRes, err := http.Get(fmt.Sprintf("http://%s/stream?type=event1&index=%x&threshold=2", api.srv.Addr().String(), "idx1"))
+	require.NoError(t, err)
+	defer sseRes.Body.Close()
+	assert.Equal(t, http.StatusOK, sseRes.StatusCode)
+	assert.Equal(t, "text/event-stream", sseRes.Header.Get("Content-Type"))
+
+	// WebSocket stream that emits every new event of the event1 type.
+	wsURL := url.URL{Scheme: "ws", Host: api.srv.Addr().String(), Path: "/ws", RawQuery: "type=event1"}
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL.String(), nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	// Wait for subscriptions.
+	time.Sleep(time.Millisecond * 100)
+
+	// Event signed by another oracle that is already in the storage.
+	_, err = mem.Add(ctx, []byte("other"), &messages.Event{
+		Type:        "event1",
+		ID:          []byte("id1"),
+		Index:       []byte("idx1"),
+		EventDate:   time.Unix(1, 0),
+		MessageDate: time.Unix(1, 0),
+		Data:        map[string][]byte{"data_key": []byte("val")},
+		Signatures:  map[string]messages.EventSignature{"sig_key": {Signer: []byte("s1"), Signature: []byte("val")}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, loc.Broadcast(messages.EventV1MessageName, &messages.Event{
+		Type:        "event2", // different type
+		ID:          []byte("id2"),
+		Index:       []byte("idx1"),
+		EventDate:   time.Unix(1, 0),
+		MessageDate: time.Unix(2, 0),
+		Data:        map[string][]byte{"data_key": []byte("val")},
+		Signatures:  map[string]messages.EventSignature{"sig_key": {Signer: []byte("s2"), Signature: []byte("val")}},
+	}))
+	require.NoError(t, loc.Broadcast(messages.EventV1MessageName, &messages.Event{
+		Type:        "event1",
+		ID:          []byte("id1"),
+		Index:       []byte("idx1"),
+		EventDate:   time.Unix(1, 0),
+		MessageDate: time.Unix(2, 0),
+		Data:        map[string][]byte{"data_key": []byte("val")},
+		Signatures:  map[string]messages.EventSignature{"sig_key": {Signer: []byte("s2"), Signature: []byte("val")}},
+	}))
+
+	// WebSocket stream must receive only the new event1 signature.
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	_, msg, err := ws.ReadMessage()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"event1","index":"69647831","id":"696431","events":[{"timestamp":1,"data":{"data_key":"76616c"},"signatures":{"sig_key":{"signer":"7332","signature":"76616c"}}}]}`, string(msg))
+
+	// SSE stream must receive both signatures at once.
+	data := readSSE(t, bufio.NewReader(sseRes.Body))
+	var sseEvt jsonStreamEvent
+	require.NoError(t, json.Unmarshal([]byte(data), &sseEvt))
+	assert.Equal(t, "696431", sseEvt.ID)
+	assert.Len(t, sseEvt.Events, 2)
+
+	// Return bad request if the threshold is invalid:
+	res, err := http.Get(fmt.Sprintf("http://%s/stream?threshold=abc", api.srv.Addr().String()))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func readSSE(t *testing.T, r *bufio.Reader) string {
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, "data: ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
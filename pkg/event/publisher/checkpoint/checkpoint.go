@@ -0,0 +1,155 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/defiweb/go-eth/types"
+)
+
+// Block identifies a processed block.
+type Block struct {
+	Number uint64     `json:"number"`
+	Hash   types.Hash `json:"hash"`
+}
+
+// Event identifies an event emitted from a processed block. It is used to
+// retract events from blocks that were orphaned by a chain reorganization.
+type Event struct {
+	Block Block  `json:"block"`
+	Type  string `json:"type"`
+	ID    []byte `json:"id"`
+	Index []byte `json:"index"`
+}
+
+// State is the ingestion state of a single contract.
+type State struct {
+	// Blocks is a list of recently processed blocks, ordered from the oldest
+	// to the newest. The last block is the checkpoint, the block from which
+	// ingestion should resume. Older blocks are used to find the common
+	// ancestor after a chain reorganization.
+	Blocks []Block `json:"blocks"`
+
+	// Events is a list of events emitted from blocks that are not older than
+	// the oldest block in Blocks.
+	Events []Event `json:"events"`
+}
+
+// Last returns the checkpoint block. The second return value is false if
+// there is no checkpoint.
+func (s *State) Last() (Block, bool) {
+	if len(s.Blocks) == 0 {
+		return Block{}, false
+	}
+	return s.Blocks[len(s.Blocks)-1], true
+}
+
+// Store persists ingestion states.
+type Store interface {
+	// Get returns the state for the given key. If there is no state for
+	// the key, an empty state is returned.
+	Get(key string) (State, error)
+
+	// Set updates the state for the given key.
+	Set(key string, state State) error
+}
+
+// MemoryStore is a Store that keeps states in memory only.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+// NewMemoryStore returns a new instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: map[string]State{}}
+}
+
+// Get implements the Store interface.
+func (m *MemoryStore) Get(key string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.states[key], nil
+}
+
+// Set implements the Store interface.
+func (m *MemoryStore) Set(key string, state State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[key] = state
+	return nil
+}
+
+// FileStore is a Store that persists states in a JSON file. The file is
+// rewritten atomically on every update.
+type FileStore struct {
+	mu     sync.Mutex
+	path   string
+	states map[string]State
+}
+
+// NewFileStore returns a new instance of FileStore. If the file exists,
+// the states are loaded from it.
+func NewFileStore(path string) (*FileStore, error) {
+	f := &FileStore{path: path, states: map[string]State{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &f.states); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Get implements the Store interface.
+func (f *FileStore) Get(key string) (State, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.states[key], nil
+}
+
+// Set implements the Store interface.
+func (f *FileStore) Set(key string, state State) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states[key] = state
+	b, err := json.MarshalIndent(f.states, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
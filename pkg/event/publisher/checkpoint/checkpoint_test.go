@@ -0,0 +1,59 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	state := State{
+		Blocks: []Block{
+			{Number: 1, Hash: types.MustHashFromHex("0x01", types.PadLeft)},
+			{Number: 2, Hash: types.MustHashFromHex("0x02", types.PadLeft)},
+		},
+		Events: []Event{{
+			Block: Block{Number: 2, Hash: types.MustHashFromHex("0x02", types.PadLeft)},
+			Type:  "test",
+			ID:    []byte("id"),
+			Index: []byte("index"),
+		}},
+	}
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+	empty, err := s.Get("a")
+	require.NoError(t, err)
+	_, ok := empty.Last()
+	assert.False(t, ok)
+	require.NoError(t, s.Set("a", state))
+
+	// State must be restored from the file.
+	s, err = NewFileStore(path)
+	require.NoError(t, err)
+	restored, err := s.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, state, restored)
+	last, ok := restored.Last()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), last.Number)
+}
@@ -0,0 +1,230 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package evmlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/defiweb/go-eth/abi"
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+// hashField is the name of the event data field that contains the hash used
+// to calculate a signature.
+const hashField = "hash"
+
+// ParseEvent parses an event ABI. The ABI may be given as a human-readable
+// signature, e.g. "event Transfer(address indexed from, address indexed to,
+// uint256 value)", or as a JSON fragment. The JSON fragment may be a single
+// event object or an array that contains exactly one event.
+func ParseEvent(s string) (*abi.Event, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "{"):
+		return parseJSONEvent("[" + s + "]")
+	case strings.HasPrefix(s, "["):
+		return parseJSONEvent(s)
+	default:
+		return abi.ParseEvent(s)
+	}
+}
+
+func parseJSONEvent(s string) (*abi.Event, error) {
+	if !json.Valid([]byte(s)) {
+		return nil, errors.New("invalid JSON ABI")
+	}
+	c, err := abi.ParseJSON([]byte(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Events) != 1 {
+		return nil, fmt.Errorf("ABI must contain exactly one event, found %d", len(c.Events))
+	}
+	for _, e := range c.Events {
+		return e, nil
+	}
+	return nil, nil
+}
+
+// field describes an event argument used in a message.
+type field struct {
+	name    string
+	typ     abi.Type
+	indexed bool
+	pos     int // Topic index (starting from 1) or position in the data tuple.
+}
+
+// converter converts logs emitted by an event into event messages.
+type converter struct {
+	event       *abi.Event
+	eventType   string
+	dataType    *abi.TupleType
+	indexFields []field
+	dataFields  []field
+}
+
+// newConverter returns a new converter for the given event. Index fields
+// must be indexed event arguments, data fields may be any event arguments.
+// At least one data field is required, otherwise the signed hash would be
+// the same for all logs of the event.
+func newConverter(event *abi.Event, eventType string, indexFields, dataFields []string) (*converter, error) {
+	if event == nil {
+		return nil, errors.New("event is not set")
+	}
+	if eventType == "" {
+		return nil, errors.New("event type is not set")
+	}
+	fields := map[string]field{}
+	var dataElems []abi.TupleTypeElem
+	topicIdx := 1
+	for _, e := range event.Inputs().Elements() {
+		f := field{name: e.Name, typ: e.Type, indexed: e.Indexed}
+		if e.Indexed {
+			f.pos = topicIdx
+			topicIdx++
+		} else {
+			f.pos = len(dataElems)
+			dataElems = append(dataElems, abi.TupleTypeElem{Name: e.Name, Type: e.Type})
+		}
+		if e.Name != "" {
+			fields[e.Name] = f
+		}
+	}
+	c := &converter{
+		event:     event,
+		eventType: eventType,
+		dataType:  abi.NewTupleType(dataElems...),
+	}
+	for _, name := range indexFields {
+		f, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("event %s has no argument %q", event.Name(), name)
+		}
+		if !f.indexed {
+			return nil, fmt.Errorf("argument %q used as an index must be indexed", name)
+		}
+		c.indexFields = append(c.indexFields, f)
+	}
+	for _, name := range dataFields {
+		f, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("event %s has no argument %q", event.Name(), name)
+		}
+		if name == hashField {
+			return nil, fmt.Errorf("argument name %q is reserved", hashField)
+		}
+		c.dataFields = append(c.dataFields, f)
+	}
+	if len(c.dataFields) == 0 {
+		return nil, errors.New("at least one data field is required")
+	}
+	return c, nil
+}
+
+// topic0 returns the topic used to filter logs.
+func (c *converter) topic0() types.Hash {
+	return c.event.Topic0()
+}
+
+// convert converts a log into an event message.
+//
+// The Index of the message is the concatenation of topics of index fields,
+// or the transaction hash if there are no index fields. The message data
+// contains the ABI-encoded values of the data fields, as abi.encode(value)
+// would encode them, and the "hash" field, which is the Keccak256 hash of
+// abi.encode(chainID, emitter, topic0, dataField1, dataField2, ...).
+// The chain ID, the address of the emitting contract and topic0 separate
+// hashes of logs emitted on different chains, by different contracts or
+// by different events.
+// Indexed arguments of a dynamic type are represented by their topics.
+func (c *converter) convert(chainID uint64, l types.Log, eventDate time.Time) (*messages.Event, error) {
+	if len(l.Topics) != c.event.Inputs().IndexedSize()+1 {
+		return nil, fmt.Errorf("wrong number of topics for event %s", c.event.Name())
+	}
+	if l.Topics[0] != c.event.Topic0() {
+		return nil, fmt.Errorf("topic0 mismatch for event %s", c.event.Name())
+	}
+	if l.TransactionHash == nil || l.LogIndex == nil {
+		return nil, errors.New("log is pending")
+	}
+	dataValue := c.dataType.Value().(*abi.TupleValue)
+	if _, err := dataValue.DecodeABI(abi.BytesToWords(l.Data)); err != nil {
+		return nil, fmt.Errorf("unable to decode event %s: %w", c.event.Name(), err)
+	}
+	data := map[string][]byte{}
+	chainIDValue := &abi.UintValue{Size: 256}
+	chainIDValue.SetUint64(chainID)
+	emitterValue := abi.AddressValue(l.Address)
+	topic0Value := abi.FixedBytesValue(l.Topics[0].Bytes())
+	hashTuple := abi.TupleValue{
+		{Name: "chainID", Value: chainIDValue},
+		{Name: "emitter", Value: &emitterValue},
+		{Name: "topic0", Value: &topic0Value},
+	}
+	for _, f := range c.dataFields {
+		var v abi.Value
+		if f.indexed {
+			if f.typ.Value().IsDynamic() {
+				v = abi.NewFixedBytesType(types.HashLength).Value()
+			} else {
+				v = f.typ.Value()
+			}
+			if _, err := v.DecodeABI(abi.BytesToWords(l.Topics[f.pos].Bytes())); err != nil {
+				return nil, fmt.Errorf("unable to decode argument %q: %w", f.name, err)
+			}
+		} else {
+			v = (*dataValue)[f.pos].Value
+		}
+		enc, err := (&abi.TupleValue{{Value: v}}).EncodeABI()
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode argument %q: %w", f.name, err)
+		}
+		data[f.name] = enc.Bytes()
+		hashTuple = append(hashTuple, abi.TupleValueElem{Name: f.name, Value: v})
+	}
+	enc, err := hashTuple.EncodeABI()
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode event %s: %w", c.event.Name(), err)
+	}
+	data[hashField] = crypto.Keccak256(enc.Bytes()).Bytes()
+	var index []byte
+	for _, f := range c.indexFields {
+		index = append(index, l.Topics[f.pos].Bytes()...)
+	}
+	if len(index) == 0 {
+		index = l.TransactionHash.Bytes()
+	}
+	return &messages.Event{
+		Type: c.eventType,
+		// ID is additionally hashed to ensure that it is not similar to
+		// any other field, so it will not be misused. This field is intended
+		// to be used only be the event store.
+		ID:          crypto.Keccak256(l.TransactionHash.Bytes(), new(big.Int).SetUint64(*l.LogIndex).Bytes()).Bytes(),
+		Index:       index,
+		EventDate:   eventDate,
+		MessageDate: time.Now(),
+		Data:        data,
+		Signatures:  map[string]messages.EventSignature{},
+	}, nil
+}
@@ -0,0 +1,164 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package evmlog
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/abi"
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/ptrutil"
+)
+
+var (
+	testEvent  = abi.MustParseEvent("event Transfer(address indexed from, address indexed to, uint256 value, string memo)")
+	testFrom   = types.MustAddressFromHex("0x1111111111111111111111111111111111111111")
+	testTo     = types.MustAddressFromHex("0x2222222222222222222222222222222222222222")
+	testTxHash = types.MustHashFromHex("0x66e8ab5a41d4b109c7f6ea5303e3c292771e57fb0b93a8474ca6f72e53eac0e8", types.PadNone)
+)
+
+func testLog(t *testing.T) types.Log {
+	data, err := abi.EncodeValues(abi.MustParseType("(uint256,string)"), big.NewInt(42), "memo")
+	require.NoError(t, err)
+	return types.Log{
+		Address: testFrom,
+		Topics: []types.Hash{
+			testEvent.Topic0(),
+			types.MustHashFromBytes(testFrom.Bytes(), types.PadLeft),
+			types.MustHashFromBytes(testTo.Bytes(), types.PadLeft),
+		},
+		Data:            data,
+		BlockNumber:     big.NewInt(42),
+		TransactionHash: &testTxHash,
+		LogIndex:        ptrutil.Ptr(uint64(3)),
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		abi     string
+		wantErr bool
+	}{
+		{abi: "event Transfer(address indexed from, address indexed to, uint256 value, string memo)"},
+		{abi: `{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256"},{"name":"memo","type":"string"}]}`},
+		{abi: `[{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256"},{"name":"memo","type":"string"}]}]`},
+		{abi: `[]`, wantErr: true},
+		{abi: `{`, wantErr: true},
+		{abi: "foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.abi, func(t *testing.T) {
+			e, err := ParseEvent(tt.abi)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testEvent.Topic0(), e.Topic0())
+		})
+	}
+}
+
+func Test_newConverter(t *testing.T) {
+	tests := []struct {
+		name        string
+		indexFields []string
+		dataFields  []string
+		wantErr     bool
+	}{
+		{name: "valid", indexFields: []string{"from", "to"}, dataFields: []string{"value", "memo", "to"}},
+		{name: "unknown index field", indexFields: []string{"foo"}, wantErr: true},
+		{name: "not indexed index field", indexFields: []string{"value"}, wantErr: true},
+		{name: "unknown data field", dataFields: []string{"foo"}, wantErr: true},
+		{name: "no data fields", indexFields: []string{"to"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newConverter(testEvent, "transfer", tt.indexFields, tt.dataFields)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_converter_convert(t *testing.T) {
+	c, err := newConverter(testEvent, "transfer", []string{"to"}, []string{"from", "value", "memo"})
+	require.NoError(t, err)
+
+	ts := time.Unix(1234567890, 0)
+	evt, err := c.convert(1, testLog(t), ts)
+	require.NoError(t, err)
+
+	hashData, err := abi.EncodeValues(
+		abi.MustParseType("(uint256,address,bytes32,address,uint256,string)"),
+		big.NewInt(1), testFrom, testEvent.Topic0(), testFrom, big.NewInt(42), "memo",
+	)
+	require.NoError(t, err)
+	memo, err := abi.EncodeValues(abi.MustParseType("(string)"), "memo")
+	require.NoError(t, err)
+
+	assert.Equal(t, "transfer", evt.Type)
+	assert.Equal(t, types.MustHashFromBytes(testTo.Bytes(), types.PadLeft).Bytes(), evt.Index)
+	assert.Equal(t, crypto.Keccak256(testTxHash.Bytes(), []byte{3}).Bytes(), evt.ID)
+	assert.Equal(t, ts, evt.EventDate)
+	assert.Equal(t, types.MustHashFromBytes(testFrom.Bytes(), types.PadLeft).Bytes(), evt.Data["from"])
+	assert.Equal(t, types.MustHashFromBigInt(big.NewInt(42)).Bytes(), evt.Data["value"])
+	assert.Equal(t, memo, evt.Data["memo"])
+	assert.Equal(t, crypto.Keccak256(hashData).Bytes(), evt.Data["hash"])
+
+	// Without index fields, the transaction hash must be used as an index.
+	c, err = newConverter(testEvent, "transfer", nil, []string{"value"})
+	require.NoError(t, err)
+	evt, err = c.convert(1, testLog(t), ts)
+	require.NoError(t, err)
+	assert.Equal(t, testTxHash.Bytes(), evt.Index)
+
+	// Logs emitted by other events must be rejected.
+	l := testLog(t)
+	l.Topics[0] = types.Hash{}
+	_, err = c.convert(1, l, ts)
+	assert.Error(t, err)
+}
+
+func Test_converter_convert_hashDomain(t *testing.T) {
+	c, err := newConverter(testEvent, "transfer", nil, []string{"value"})
+	require.NoError(t, err)
+
+	ts := time.Unix(1234567890, 0)
+	hash := func(chainID uint64, l types.Log) []byte {
+		evt, err := c.convert(chainID, l, ts)
+		require.NoError(t, err)
+		return evt.Data["hash"]
+	}
+
+	// Logs with the same data emitted on different chains or by different
+	// contracts must have different hashes.
+	l1 := testLog(t)
+	l2 := testLog(t)
+	l2.Address = testTo
+	assert.NotEqual(t, hash(1, l1), hash(2, l1))
+	assert.NotEqual(t, hash(1, l1), hash(1, l2))
+	assert.Equal(t, hash(1, l1), hash(1, testLog(t)))
+}
@@ -0,0 +1,414 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package evmlog
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/defiweb/go-eth/abi"
+	"github.com/defiweb/go-eth/rpc"
+	"github.com/defiweb/go-eth/types"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/internal/blockrange"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/bn"
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/retry"
+)
+
+const LoggerTag = "EVM_LOG"
+
+// retryInterval is the interval between retry attempts in case of an error
+// while communicating with a node.
+const retryInterval = 5 * time.Second
+
+// Config contains a configuration options for EventProvider.
+type Config struct {
+	// Client is an instance of Ethereum RPC client.
+	Client rpc.RPC
+
+	// Addresses is a list of contracts from which logs will be fetched.
+	Addresses []types.Address
+
+	// Event is the ABI of the event to watch.
+	Event *abi.Event
+
+	// EventType is the type of produced event messages.
+	EventType string
+
+	// IndexFields is a list of indexed event arguments whose topics are
+	// used as the event index. If empty, the transaction hash is used.
+	IndexFields []string
+
+	// DataFields is a list of event arguments to include in the event data.
+	// At least one data field is required.
+	DataFields []string
+
+	// Interval specifies how often provider should check for new logs.
+	Interval time.Duration
+
+	// PrefetchPeriod specifies how far back in time provider should prefetch
+	// logs. It is used only during the initial start of the provider.
+	PrefetchPeriod time.Duration
+
+	// BlockLimit specifies how from many blocks logs can be fetched at once.
+	BlockLimit uint64
+
+	// BlockConfirmations specifies how many blocks should be confirmed before
+	// fetching logs.
+	BlockConfirmations uint64
+
+	// Logger is a current logger interface used by the EventProvider.
+	Logger log.Logger
+}
+
+// EventProvider listens to arbitrary events on Ethereum compatible
+// blockchains.
+//
+// It periodically fetches logs of the configured event from the blockchain,
+// converts them into messages.Event and sends them to the channel provided
+// by Events method. The event date is the timestamp of the block in which
+// the log was emitted.
+//
+// During the initial start of the provider it also fetches older blocks
+// until it reaches the block that is older than the prefetch period.
+// Before fetching logs, the provider fetches the chain ID, which is a part
+// of the signed hash.
+//
+// In the event of an error in communication with a node, whether related to
+// network errors or the node itself, the provider will try to repeat requests
+// to the node indefinitely.
+type EventProvider struct {
+	eventCh   chan *messages.Event
+	converter *converter
+	chainID   uint64
+
+	// Configuration parameters copied from Config:
+	client         rpc.RPC
+	addresses      []types.Address
+	interval       time.Duration
+	prefetchPeriod time.Duration
+	blockLimit     uint64
+	blockConfirms  uint64
+	log            log.Logger
+
+	// Used in tests only:
+	disablePrefetchEventsRoutine bool
+	disableFetchEventsRoutine    bool
+}
+
+// New returns a new instance of the EventProvider struct.
+func New(cfg Config) (*EventProvider, error) {
+	if cfg.Client == nil {
+		return nil, errors.New("client is not set")
+	}
+	if cfg.Interval == 0 {
+		return nil, errors.New("interval is not set")
+	}
+	if len(cfg.Addresses) == 0 {
+		return nil, errors.New("no addresses provided")
+	}
+	if cfg.BlockLimit <= 0 {
+		return nil, errors.New("block limit must be greater than 0")
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = null.New()
+	}
+	c, err := newConverter(cfg.Event, cfg.EventType, cfg.IndexFields, cfg.DataFields)
+	if err != nil {
+		return nil, err
+	}
+	return &EventProvider{
+		eventCh:        make(chan *messages.Event),
+		converter:      c,
+		client:         cfg.Client,
+		interval:       cfg.Interval,
+		addresses:      cfg.Addresses,
+		prefetchPeriod: cfg.PrefetchPeriod,
+		blockLimit:     cfg.BlockLimit,
+		blockConfirms:  cfg.BlockConfirmations,
+		log: cfg.Logger.
+			WithField("tag", LoggerTag).
+			WithField("eventType", cfg.EventType),
+	}, nil
+}
+
+// Events implements the publisher.EventPublisher interface.
+func (ep *EventProvider) Events() chan *messages.Event {
+	return ep.eventCh
+}
+
+// Start implements the publisher.EventPublisher interface.
+func (ep *EventProvider) Start(ctx context.Context) error {
+	go func() {
+		chainID, ok := ep.getChainID(ctx)
+		if !ok {
+			return // Context was canceled.
+		}
+		ep.chainID = chainID
+		if !ep.disablePrefetchEventsRoutine {
+			go ep.prefetchEventsRoutine(ctx)
+		}
+		if !ep.disableFetchEventsRoutine {
+			go ep.fetchEventsRoutine(ctx)
+		}
+	}()
+	return nil
+}
+
+// prefetchEventsRoutine fetches events from older blocks until it reaches the
+// block that is older than the prefetch period.
+func (ep *EventProvider) prefetchEventsRoutine(ctx context.Context) {
+	if ep.prefetchPeriod == 0 {
+		return
+	}
+	latestBlock, ok := ep.getBlockNumber(ctx)
+	if !ok {
+		return // Context was canceled.
+	}
+	for d := ep.blockConfirms; ctx.Err() == nil; d += ep.blockLimit {
+		to, ok := blockrange.Confirmed(bn.Int(latestBlock), d)
+		if !ok {
+			return // There are no confirmed blocks yet.
+		}
+		from, _ := blockrange.Confirmed(bn.Int(latestBlock), d+ep.blockLimit-1)
+
+		ep.handleEvents(ctx, from, to)
+		ts, ok := ep.getBlockTimestamp(ctx, to.BigInt())
+		if !ok {
+			return // Context was canceled.
+		}
+		if from.Sign() == 0 || time.Since(ts) > ep.prefetchPeriod {
+			return // End of the prefetch period reached.
+		}
+	}
+}
+
+// fetchEventsRoutine periodically fetches new logs from the blockchain.
+func (ep *EventProvider) fetchEventsRoutine(ctx context.Context) {
+	latestBlock, ok := ep.getBlockNumber(ctx)
+	if !ok {
+		return // Context was canceled.
+	}
+	t := time.NewTicker(ep.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			currentBlock, ok := ep.getBlockNumber(ctx)
+			if !ok {
+				return // Context was canceled.
+			}
+			if currentBlock.Cmp(latestBlock) <= 0 {
+				continue // There are no new blocks.
+			}
+			ranges := blockrange.Split(
+				bn.Int(latestBlock).Add(bn.Int(1)),
+				bn.Int(currentBlock),
+				bn.Int(ep.blockLimit),
+			)
+			for _, b := range ranges {
+				to, ok := blockrange.Confirmed(b[1], ep.blockConfirms)
+				if !ok {
+					continue // There are no confirmed blocks in the range yet.
+				}
+				from, _ := blockrange.Confirmed(b[0], ep.blockConfirms)
+				ep.handleEvents(ctx, from, to)
+			}
+			latestBlock = currentBlock
+		}
+	}
+}
+
+// handleEvents fetches logs from the given block range and sends them to the
+// eventCh channel.
+func (ep *EventProvider) handleEvents(ctx context.Context, from, to *bn.IntNumber) {
+	blockTimestamps := map[string]time.Time{}
+	for _, address := range ep.addresses {
+		ep.log.
+			WithFields(log.Fields{
+				"from":    from,
+				"to":      to,
+				"address": address.String(),
+			}).
+			Info("Fetching logs")
+		logs, ok := ep.filterLogs(ctx, address, from, to)
+		if !ok {
+			return // Context was canceled.
+		}
+		for _, l := range logs {
+			if l.Address != address {
+				// PANIC!
+				// This should never happen. All logs returned by
+				// eth_getLogs should be emitted by the specified
+				// contract. If it happens, there is a bug somewhere.
+				ep.log.
+					WithFields(log.Fields{
+						"expected": address.String(),
+						"actual":   l.Address.String(),
+					}).
+					Panic("Log emitted by wrong contract")
+			}
+			if l.Removed {
+				ep.log.
+					WithFields(log.Fields{
+						"address":     l.Address.String(),
+						"blockNumber": l.BlockNumber,
+						"txHash":      l.TransactionHash.String(),
+					}).
+					Warn("Received removed log")
+				continue
+			}
+			if l.BlockNumber == nil {
+				ep.log.Warn("Received pending log")
+				continue
+			}
+			ts, ok := blockTimestamps[l.BlockNumber.String()]
+			if !ok {
+				ts, ok = ep.getBlockTimestamp(ctx, l.BlockNumber)
+				if !ok {
+					return // Context was canceled.
+				}
+				blockTimestamps[l.BlockNumber.String()] = ts
+			}
+			evt, err := ep.converter.convert(ep.chainID, l, ts)
+			if err != nil {
+				ep.log.
+					WithError(err).
+					Error("Unable to convert log to event")
+				continue
+			}
+			ep.eventCh <- evt
+		}
+	}
+}
+
+// getChainID returns the chain ID of the blockchain.
+//
+// The method will try to fetch the chain ID indefinitely in case of an error.
+// The only way to stop this method from trying again is to cancel the
+// context. In that case, the method will return false as a second return
+// value.
+func (ep *EventProvider) getChainID(ctx context.Context) (uint64, bool) {
+	var err error
+	var res uint64
+	retry.TryForever(
+		ctx,
+		func() error {
+			res, err = ep.client.ChainID(ctx)
+			if err != nil {
+				ep.log.WithError(err).Error("Unable to get chain ID")
+			}
+			return err
+		},
+		retryInterval,
+	)
+	if ctx.Err() != nil {
+		return 0, false
+	}
+	return res, true
+}
+
+// getBlockNumber returns the latest block number on the blockchain.
+//
+// The method will try to fetch blocks indefinitely in case of an error.
+// The only way to stop this method from trying again is to cancel the
+// context. In that case, the method will return false as a second return
+// value.
+func (ep *EventProvider) getBlockNumber(ctx context.Context) (*big.Int, bool) {
+	var err error
+	var res *big.Int
+	retry.TryForever(
+		ctx,
+		func() error {
+			res, err = ep.client.BlockNumber(ctx)
+			if err != nil {
+				ep.log.WithError(err).Error("Unable to get block number")
+			}
+			return err
+		},
+		retryInterval,
+	)
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+// getBlockTimestamp returns the timestamp of the given block.
+//
+// The method will try to fetch blocks indefinitely in case of an error.
+// The only way to stop this method from trying again is to cancel the
+// context. In that case, the method will return false as a second return
+// value.
+func (ep *EventProvider) getBlockTimestamp(ctx context.Context, block *big.Int) (time.Time, bool) {
+	var err error
+	var res *types.Block
+	retry.TryForever(
+		ctx,
+		func() error {
+			res, err = ep.client.BlockByNumber(ctx, types.BlockNumberFromBigInt(block), false)
+			if err != nil {
+				ep.log.WithError(err).Error("Unable to get block timestamp")
+			}
+			return err
+		},
+		retryInterval,
+	)
+	if res == nil || ctx.Err() != nil {
+		return time.Time{}, false
+	}
+	return res.Timestamp, true
+}
+
+// filterLogs fetches logs of the event from the blockchain.
+//
+// The method will try to fetch logs indefinitely in case of an error.
+// The only way to stop this method from trying again is to cancel the
+// context. In that case, the method will return false as a second return
+// value.
+func (ep *EventProvider) filterLogs(ctx context.Context, addr types.Address, from, to *bn.IntNumber) ([]types.Log, bool) {
+	var err error
+	var res []types.Log
+	retry.TryForever(
+		ctx,
+		func() error {
+			fromBlockNumber := types.BlockNumberFromBigInt(from.BigInt())
+			toBlockNumber := types.BlockNumberFromBigInt(to.BigInt())
+			res, err = ep.client.GetLogs(ctx, types.FilterLogsQuery{
+				FromBlock: &fromBlockNumber,
+				ToBlock:   &toBlockNumber,
+				Address:   []types.Address{addr},
+				Topics:    [][]types.Hash{{ep.converter.topic0()}},
+			})
+			if err != nil {
+				ep.log.WithError(err).Error("Unable to filter logs")
+			}
+			return err
+		},
+		retryInterval,
+	)
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	return res, true
+}
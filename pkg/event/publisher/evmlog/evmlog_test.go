@@ -0,0 +1,83 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package evmlog
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/mocks"
+)
+
+func TestEventProvider_FetchEventsRoutine(t *testing.T) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFunc()
+
+	cli := &mocks.RPC{}
+	ep, err := New(Config{
+		Client:             cli,
+		Addresses:          []types.Address{testFrom},
+		Event:              testEvent,
+		EventType:          "transfer",
+		IndexFields:        []string{"to"},
+		DataFields:         []string{"value"},
+		Interval:           100 * time.Millisecond,
+		BlockLimit:         10,
+		BlockConfirmations: 1,
+	})
+	require.NoError(t, err)
+	ep.disablePrefetchEventsRoutine = true
+
+	ts := time.Unix(1234567890, 0)
+	cli.On("ChainID", ctx).Return(uint64(1), nil)
+	cli.On("BlockNumber", ctx).Return(big.NewInt(100), nil).Once()
+	cli.On("BlockNumber", ctx).Return(big.NewInt(115), nil)
+	cli.On("BlockByNumber", ctx, types.BlockNumberFromUint64(42), false).Return(&types.Block{Timestamp: ts}, nil).Once()
+
+	// The range must be split into two GetLogs calls to avoid exceeding the block limit.
+	cli.On("GetLogs", ctx, mock.Anything).Return([]types.Log{testLog(t), testLog(t)}, nil).Once().Run(func(args mock.Arguments) {
+		fq := args.Get(1).(types.FilterLogsQuery)
+		assert.Equal(t, uint64(100), fq.FromBlock.Big().Uint64())
+		assert.Equal(t, uint64(109), fq.ToBlock.Big().Uint64())
+		assert.Equal(t, []types.Address{testFrom}, fq.Address)
+		assert.Equal(t, [][]types.Hash{{testEvent.Topic0()}}, fq.Topics)
+	})
+	cli.On("GetLogs", ctx, mock.Anything).Return([]types.Log{}, nil).Once().Run(func(args mock.Arguments) {
+		fq := args.Get(1).(types.FilterLogsQuery)
+		assert.Equal(t, uint64(110), fq.FromBlock.Big().Uint64())
+		assert.Equal(t, uint64(114), fq.ToBlock.Big().Uint64())
+	})
+
+	require.NoError(t, ep.Start(ctx))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case evt := <-ep.Events():
+			assert.Equal(t, "transfer", evt.Type)
+			assert.Equal(t, ts, evt.EventDate)
+			assert.Equal(t, types.MustHashFromBigInt(big.NewInt(42)).Bytes(), evt.Data["value"])
+		case <-ctx.Done():
+			require.Fail(t, "timeout")
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package blockrange provides helpers used by event providers to fetch logs
+// in block ranges.
+package blockrange
+
+import (
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/bn"
+)
+
+// Split splits a block range into smaller ranges of at most "limit" blocks.
+// Some RPC providers have a limit on the number of blocks that can be
+// fetched in a single request and this function is used to keep the number
+// of blocks in each request below that limit.
+func Split(from, to, limit *bn.IntNumber) [][2]*bn.IntNumber {
+	if from.Cmp(to) > 0 {
+		return nil
+	}
+	if to.Sub(from).Cmp(limit) < 0 {
+		return [][2]*bn.IntNumber{{from, to}}
+	}
+	var ranges [][2]*bn.IntNumber
+	rangeFrom := from
+	rangeTo := from
+	for rangeTo.Cmp(to) < 0 {
+		rangeTo = rangeFrom.Add(limit).Sub(bn.Int(1))
+		if rangeTo.Cmp(to) > 0 {
+			rangeTo = to
+		}
+		ranges = append(ranges, [2]*bn.IntNumber{rangeFrom, rangeTo})
+		rangeFrom = rangeTo.Add(bn.Int(1))
+	}
+	return ranges
+}
+
+// Confirmed returns the number of the block that is "confirmations" blocks
+// behind the given block. If the chain is shorter than the number of
+// confirmations, it returns the genesis block and false, because a negative
+// block number would be interpreted as a block tag by the RPC client.
+func Confirmed(block *bn.IntNumber, confirmations uint64) (*bn.IntNumber, bool) {
+	n := block.Sub(bn.Int(confirmations))
+	if n.Sign() < 0 {
+		return bn.Int(0), false
+	}
+	return n, true
+}
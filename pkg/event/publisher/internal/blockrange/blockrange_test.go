@@ -0,0 +1,58 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package blockrange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/bn"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		from, to, limit int64
+		want            [][2]int64
+	}{
+		{from: 5, to: 4, limit: 10, want: nil},
+		{from: 1, to: 1, limit: 10, want: [][2]int64{{1, 1}}},
+		{from: 1, to: 10, limit: 10, want: [][2]int64{{1, 10}}},
+		{from: 1, to: 11, limit: 10, want: [][2]int64{{1, 10}, {11, 11}}},
+		{from: 1, to: 25, limit: 10, want: [][2]int64{{1, 10}, {11, 20}, {21, 25}}},
+	}
+	for _, tt := range tests {
+		var got [][2]int64
+		for _, r := range Split(bn.Int(tt.from), bn.Int(tt.to), bn.Int(tt.limit)) {
+			got = append(got, [2]int64{r[0].BigInt().Int64(), r[1].BigInt().Int64()})
+		}
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestConfirmed(t *testing.T) {
+	n, ok := Confirmed(bn.Int(10), 3)
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), n.BigInt().Int64())
+
+	n, ok = Confirmed(bn.Int(3), 3)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), n.BigInt().Int64())
+
+	n, ok = Confirmed(bn.Int(2), 3)
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), n.BigInt().Int64())
+}
@@ -0,0 +1,571 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package opstack
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/defiweb/go-eth/abi"
+	"github.com/defiweb/go-eth/rpc"
+	"github.com/defiweb/go-eth/types"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/checkpoint"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/internal/blockrange"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/bn"
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/retry"
+)
+
+const LoggerTag = "OP_STACK"
+
+// WithdrawalEventType is the default type of produced event messages.
+const WithdrawalEventType = "op_withdrawal"
+
+// MessagePasserAddress is the address of the L2ToL1MessagePasser predeploy
+// on OP Stack chains.
+var MessagePasserAddress = types.MustAddressFromHex("0x4200000000000000000000000000000000000016")
+
+// retryInterval is the interval between retry attempts in case of an error
+// while communicating with a node.
+const retryInterval = 5 * time.Second
+
+// Config contains a configuration options for EventProvider.
+type Config struct {
+	// L2Client is an instance of Ethereum RPC client connected to the L2
+	// chain on which withdrawals are initiated.
+	L2Client rpc.RPC
+
+	// L1Client is an instance of Ethereum RPC client connected to the L1
+	// chain on which output roots are proposed.
+	L1Client rpc.RPC
+
+	// MessagePasser is the address of the L2ToL1MessagePasser contract on L2.
+	// If empty, the predeploy address is used.
+	MessagePasser types.Address
+
+	// OutputOracle is the address of the L2OutputOracle contract on L1.
+	OutputOracle types.Address
+
+	// EventType is the type of produced event messages. If empty,
+	// WithdrawalEventType is used.
+	EventType string
+
+	// Interval specifies how often provider should check for new withdrawals
+	// and output proposals.
+	Interval time.Duration
+
+	// PrefetchPeriod specifies how far back in time provider should prefetch
+	// withdrawals. It is used only during the initial start of the provider.
+	PrefetchPeriod time.Duration
+
+	// BlockLimit specifies how from many blocks logs can be fetched at once.
+	BlockLimit uint64
+
+	// BlockConfirmations specifies how many L2 blocks should be confirmed
+	// before fetching logs.
+	BlockConfirmations uint64
+
+	// L1BlockConfirmations specifies how many L1 blocks should be confirmed
+	// before an output proposal is used.
+	L1BlockConfirmations uint64
+
+	// Checkpoints is an optional store used to persist the L2 block from
+	// which fetching should resume. The checkpoint is never newer than the
+	// oldest pending withdrawal, so withdrawals that are still waiting for
+	// an output proposal are fetched again after a restart. If nil,
+	// checkpoints are not used.
+	Checkpoints checkpoint.Store
+
+	// Logger is a current logger interface used by the EventProvider.
+	Logger log.Logger
+}
+
+// EventProvider listens to withdrawals initiated on OP Stack rollups.
+//
+// It periodically fetches MessagePassed logs from the L2ToL1MessagePasser
+// contract on L2. A withdrawal can be proven on L1 only after an output root
+// that includes the block in which the withdrawal was initiated is proposed
+// to the L2OutputOracle contract, so the provider keeps withdrawals pending
+// until such an output proposal appears on L1. Then, the withdrawal is
+// converted to a messages.Event that contains the withdrawal, the output
+// root and its index, and sent to the channel provided by Events method.
+// The event date is the timestamp of the L2 block in which the withdrawal
+// was initiated.
+//
+// During the initial start of the provider it also fetches older blocks
+// until it reaches the block that is older than the prefetch period.
+//
+// If checkpoints are enabled, the provider persists the block before the
+// oldest pending withdrawal, or the last fetched block if there are no
+// pending withdrawals. After a restart, it resumes from that block instead
+// of prefetching, so pending withdrawals are not lost.
+//
+// In the event of an error in communication with a node, whether related to
+// network errors or the node itself, the provider will try to repeat requests
+// to the node indefinitely.
+type EventProvider struct {
+	mu      sync.Mutex
+	eventCh chan *messages.Event
+	pending []*pendingWithdrawal
+
+	// Configuration parameters copied from Config:
+	l2Client        rpc.RPC
+	l1Client        rpc.RPC
+	messagePasser   types.Address
+	outputOracle    types.Address
+	eventType       string
+	interval        time.Duration
+	prefetchPeriod  time.Duration
+	blockLimit      uint64
+	blockConfirms   uint64
+	l1BlockConfirms uint64
+	checkpoints     checkpoint.Store
+	log             log.Logger
+
+	// Used in tests only:
+	disablePrefetchEventsRoutine bool
+	disableFetchEventsRoutine    bool
+}
+
+// New returns a new instance of the EventProvider struct.
+func New(cfg Config) (*EventProvider, error) {
+	if cfg.L2Client == nil {
+		return nil, errors.New("L2 client is not set")
+	}
+	if cfg.L1Client == nil {
+		return nil, errors.New("L1 client is not set")
+	}
+	if cfg.OutputOracle == types.ZeroAddress {
+		return nil, errors.New("output oracle address is not set")
+	}
+	if cfg.Interval == 0 {
+		return nil, errors.New("interval is not set")
+	}
+	if cfg.BlockLimit <= 0 {
+		return nil, errors.New("block limit must be greater than 0")
+	}
+	if cfg.MessagePasser == types.ZeroAddress {
+		cfg.MessagePasser = MessagePasserAddress
+	}
+	if cfg.EventType == "" {
+		cfg.EventType = WithdrawalEventType
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = null.New()
+	}
+	return &EventProvider{
+		eventCh:         make(chan *messages.Event),
+		l2Client:        cfg.L2Client,
+		l1Client:        cfg.L1Client,
+		messagePasser:   cfg.MessagePasser,
+		outputOracle:    cfg.OutputOracle,
+		eventType:       cfg.EventType,
+		interval:        cfg.Interval,
+		prefetchPeriod:  cfg.PrefetchPeriod,
+		blockLimit:      cfg.BlockLimit,
+		blockConfirms:   cfg.BlockConfirmations,
+		l1BlockConfirms: cfg.L1BlockConfirmations,
+		checkpoints:     cfg.Checkpoints,
+		log: cfg.Logger.
+			WithField("tag", LoggerTag).
+			WithField("eventType", cfg.EventType),
+	}, nil
+}
+
+// Events implements the publisher.EventPublisher interface.
+func (ep *EventProvider) Events() chan *messages.Event {
+	return ep.eventCh
+}
+
+// Start implements the publisher.EventPublisher interface.
+func (ep *EventProvider) Start(ctx context.Context) error {
+	if !ep.disablePrefetchEventsRoutine {
+		go ep.prefetchEventsRoutine(ctx)
+	}
+	if !ep.disableFetchEventsRoutine {
+		go ep.fetchEventsRoutine(ctx)
+	}
+	return nil
+}
+
+// prefetchEventsRoutine fetches withdrawals from older blocks until it
+// reaches the block that is older than the prefetch period. Prefetching is
+// skipped if there is a checkpoint, because the fetchEventsRoutine resumes
+// from it.
+func (ep *EventProvider) prefetchEventsRoutine(ctx context.Context) {
+	if ep.prefetchPeriod == 0 {
+		return
+	}
+	if _, ok := ep.checkpoint(); ok {
+		return
+	}
+	latestBlock, ok := ep.getBlockNumber(ctx, ep.l2Client)
+	if !ok {
+		return // Context was canceled.
+	}
+	for d := ep.blockConfirms; ctx.Err() == nil; d += ep.blockLimit {
+		to, ok := blockrange.Confirmed(bn.Int(latestBlock), d)
+		if !ok {
+			return // There are no confirmed blocks yet.
+		}
+		from, _ := blockrange.Confirmed(bn.Int(latestBlock), d+ep.blockLimit-1)
+
+		ep.handleWithdrawals(ctx, from, to)
+		ts, ok := ep.getBlockTimestamp(ctx, to.BigInt())
+		if !ok {
+			return // Context was canceled.
+		}
+		if from.Sign() == 0 || time.Since(ts) > ep.prefetchPeriod {
+			return // End of the prefetch period reached.
+		}
+	}
+}
+
+// fetchEventsRoutine periodically fetches new withdrawals from L2 and
+// publishes pending withdrawals for which an output proposal is available.
+// Withdrawals are fetched from the checkpoint, if there is one, or from the
+// latest confirmed block otherwise.
+func (ep *EventProvider) fetchEventsRoutine(ctx context.Context) {
+	latestBlock, ok := ep.getBlockNumber(ctx, ep.l2Client)
+	if !ok {
+		return // Context was canceled.
+	}
+	next, _ := blockrange.Confirmed(bn.Int(latestBlock).Add(bn.Int(1)), ep.blockConfirms)
+	if cp, ok := ep.checkpoint(); ok {
+		next = bn.Int(cp.Number + 1)
+	}
+	t := time.NewTicker(ep.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			currentBlock, ok := ep.getBlockNumber(ctx, ep.l2Client)
+			if !ok {
+				return // Context was canceled.
+			}
+			if to, ok := blockrange.Confirmed(bn.Int(currentBlock), ep.blockConfirms); ok {
+				for _, b := range blockrange.Split(next, to, bn.Int(ep.blockLimit)) {
+					ep.handleWithdrawals(ctx, b[0], b[1])
+					if ctx.Err() != nil {
+						return
+					}
+					next = b[1].Add(bn.Int(1))
+				}
+			}
+			if !ep.publishPending(ctx) {
+				return // Context was canceled.
+			}
+			if next.Sign() > 0 {
+				ep.saveCheckpoint(next.Sub(bn.Int(1)))
+			}
+		}
+	}
+}
+
+// handleWithdrawals fetches withdrawals from the given block range and adds
+// them to the list of pending withdrawals.
+func (ep *EventProvider) handleWithdrawals(ctx context.Context, from, to *bn.IntNumber) {
+	ep.log.
+		WithFields(log.Fields{
+			"from":    from,
+			"to":      to,
+			"address": ep.messagePasser.String(),
+		}).
+		Info("Fetching withdrawals")
+	logs, ok := ep.filterLogs(ctx, from, to)
+	if !ok {
+		return // Context was canceled.
+	}
+	blockTimestamps := map[string]time.Time{}
+	for _, l := range logs {
+		if l.Address != ep.messagePasser {
+			// PANIC!
+			// This should never happen. All logs returned by
+			// eth_getLogs should be emitted by the specified
+			// contract. If it happens, there is a bug somewhere.
+			ep.log.
+				WithFields(log.Fields{
+					"expected": ep.messagePasser.String(),
+					"actual":   l.Address.String(),
+				}).
+				Panic("Log emitted by wrong contract")
+		}
+		if l.Removed {
+			ep.log.
+				WithFields(log.Fields{
+					"blockNumber": l.BlockNumber,
+					"txHash":      l.TransactionHash.String(),
+				}).
+				Warn("Received removed log")
+			continue
+		}
+		if l.BlockNumber == nil {
+			ep.log.Warn("Received pending log")
+			continue
+		}
+		ts, ok := blockTimestamps[l.BlockNumber.String()]
+		if !ok {
+			ts, ok = ep.getBlockTimestamp(ctx, l.BlockNumber)
+			if !ok {
+				return // Context was canceled.
+			}
+			blockTimestamps[l.BlockNumber.String()] = ts
+		}
+		w, err := logToWithdrawal(l, ts)
+		if err != nil {
+			ep.log.
+				WithError(err).
+				Error("Unable to convert log to withdrawal")
+			continue
+		}
+		ep.mu.Lock()
+		ep.pending = append(ep.pending, w)
+		ep.mu.Unlock()
+	}
+}
+
+// publishPending sends pending withdrawals for which an output proposal
+// is available on L1 to the eventCh channel. Withdrawals that are not yet
+// included in any output proposal remain pending. It returns false if the
+// context was canceled.
+func (ep *EventProvider) publishPending(ctx context.Context) bool {
+	ep.mu.Lock()
+	pending := make([]*pendingWithdrawal, len(ep.pending))
+	copy(pending, ep.pending)
+	ep.mu.Unlock()
+	if len(pending) == 0 {
+		return true
+	}
+	l1Block, ok := ep.getBlockNumber(ctx, ep.l1Client)
+	if !ok {
+		return false // Context was canceled.
+	}
+	confirmed, ok := blockrange.Confirmed(bn.Int(l1Block), ep.l1BlockConfirms)
+	if !ok {
+		return true // There are no confirmed L1 blocks yet.
+	}
+	block := types.BlockNumberFromBigInt(confirmed.BigInt())
+	var latest *big.Int
+	if err := ep.call(ctx, block, latestBlockNumberMethod, &latest); err != nil {
+		ep.log.WithError(err).Error("Unable to get the latest L2 block number of the output oracle")
+		return true
+	}
+	published := map[types.Hash]bool{}
+	outputs := map[string]*output{}
+	for _, w := range pending {
+		if w.blockNumber.Cmp(latest) > 0 {
+			continue // Output proposal is not yet available.
+		}
+		o, err := ep.getOutput(ctx, block, w.blockNumber, outputs)
+		if err != nil {
+			ep.log.WithError(err).Error("Unable to get the output proposal")
+			break
+		}
+		evt, err := w.toMessage(ep.eventType, o)
+		if err != nil {
+			ep.log.WithError(err).Error("Unable to convert withdrawal to event")
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case ep.eventCh <- evt:
+		}
+		published[w.hash] = true
+	}
+	ep.mu.Lock()
+	var remaining []*pendingWithdrawal
+	for _, w := range ep.pending {
+		if !published[w.hash] {
+			remaining = append(remaining, w)
+		}
+	}
+	ep.pending = remaining
+	ep.mu.Unlock()
+	return true
+}
+
+// checkpoint returns the block from which fetching should resume. The second
+// return value is false if checkpoints are disabled or if there is no
+// checkpoint.
+func (ep *EventProvider) checkpoint() (checkpoint.Block, bool) {
+	if ep.checkpoints == nil {
+		return checkpoint.Block{}, false
+	}
+	state, err := ep.checkpoints.Get(ep.messagePasser.String())
+	if err != nil {
+		ep.log.WithError(err).Error("Unable to load checkpoint")
+		return checkpoint.Block{}, false
+	}
+	return state.Last()
+}
+
+// saveCheckpoint persists the given block as the checkpoint, or the block
+// before the oldest pending withdrawal if it is older.
+func (ep *EventProvider) saveCheckpoint(fetched *bn.IntNumber) {
+	if ep.checkpoints == nil {
+		return
+	}
+	n := fetched.BigInt()
+	ep.mu.Lock()
+	for _, w := range ep.pending {
+		if w.blockNumber.Sign() > 0 && w.blockNumber.Cmp(n) <= 0 {
+			n = new(big.Int).Sub(w.blockNumber, big.NewInt(1))
+		}
+	}
+	ep.mu.Unlock()
+	state := checkpoint.State{Blocks: []checkpoint.Block{{Number: n.Uint64()}}}
+	if err := ep.checkpoints.Set(ep.messagePasser.String(), state); err != nil {
+		ep.log.WithError(err).Error("Unable to save checkpoint")
+	}
+}
+
+// getOutput returns the first output proposal that includes the given
+// L2 block. Outputs already fetched in the current round are taken from
+// the cache.
+func (ep *EventProvider) getOutput(ctx context.Context, block types.BlockNumber, l2Block *big.Int, cache map[string]*output) (*output, error) {
+	var idx *big.Int
+	if err := ep.call(ctx, block, getL2OutputIndexAfterMethod, &idx, l2Block); err != nil {
+		return nil, err
+	}
+	if o, ok := cache[idx.String()]; ok {
+		return o, nil
+	}
+	var res struct {
+		OutputRoot    types.Hash `abi:"outputRoot"`
+		Timestamp     *big.Int   `abi:"timestamp"`
+		L2BlockNumber *big.Int   `abi:"l2BlockNumber"`
+	}
+	if err := ep.call(ctx, block, getL2OutputMethod, &res, idx); err != nil {
+		return nil, err
+	}
+	o := &output{
+		Index:         idx,
+		OutputRoot:    res.OutputRoot,
+		L2BlockNumber: res.L2BlockNumber,
+	}
+	cache[idx.String()] = o
+	return o, nil
+}
+
+// call calls a method of the L2OutputOracle contract at the given block.
+func (ep *EventProvider) call(ctx context.Context, block types.BlockNumber, method *abi.Method, res any, args ...any) error {
+	cd, err := method.EncodeArgs(args...)
+	if err != nil {
+		return err
+	}
+	data, err := ep.l1Client.Call(ctx, types.Call{
+		To:    &ep.outputOracle,
+		Input: cd,
+	}, block)
+	if err != nil {
+		return err
+	}
+	return method.DecodeValues(data, res)
+}
+
+// getBlockNumber returns the latest block number on the blockchain.
+//
+// The method will try to fetch blocks indefinitely in case of an error.
+// The only way to stop this method from trying again is to cancel the
+// context. In that case, the method will return false as a second return
+// value.
+func (ep *EventProvider) getBlockNumber(ctx context.Context, client rpc.RPC) (*big.Int, bool) {
+	var err error
+	var res *big.Int
+	retry.TryForever(
+		ctx,
+		func() error {
+			res, err = client.BlockNumber(ctx)
+			if err != nil {
+				ep.log.WithError(err).Error("Unable to get block number")
+			}
+			return err
+		},
+		retryInterval,
+	)
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+// getBlockTimestamp returns the timestamp of the given L2 block.
+//
+// The method will try to fetch blocks indefinitely in case of an error.
+// The only way to stop this method from trying again is to cancel the
+// context. In that case, the method will return false as a second return
+// value.
+func (ep *EventProvider) getBlockTimestamp(ctx context.Context, block *big.Int) (time.Time, bool) {
+	var err error
+	var res *types.Block
+	retry.TryForever(
+		ctx,
+		func() error {
+			res, err = ep.l2Client.BlockByNumber(ctx, types.BlockNumberFromBigInt(block), false)
+			if err != nil {
+				ep.log.WithError(err).Error("Unable to get block timestamp")
+			}
+			return err
+		},
+		retryInterval,
+	)
+	if res == nil || ctx.Err() != nil {
+		return time.Time{}, false
+	}
+	return res.Timestamp, true
+}
+
+// filterLogs fetches MessagePassed logs from L2.
+//
+// The method will try to fetch logs indefinitely in case of an error.
+// The only way to stop this method from trying again is to cancel the
+// context. In that case, the method will return false as a second return
+// value.
+func (ep *EventProvider) filterLogs(ctx context.Context, from, to *bn.IntNumber) ([]types.Log, bool) {
+	var err error
+	var res []types.Log
+	retry.TryForever(
+		ctx,
+		func() error {
+			fromBlockNumber := types.BlockNumberFromBigInt(from.BigInt())
+			toBlockNumber := types.BlockNumberFromBigInt(to.BigInt())
+			res, err = ep.l2Client.GetLogs(ctx, types.FilterLogsQuery{
+				FromBlock: &fromBlockNumber,
+				ToBlock:   &toBlockNumber,
+				Address:   []types.Address{ep.messagePasser},
+				Topics:    [][]types.Hash{{abiMessagePassed.Topic0()}},
+			})
+			if err != nil {
+				ep.log.WithError(err).Error("Unable to filter logs")
+			}
+			return err
+		},
+		retryInterval,
+	)
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	return res, true
+}
@@ -0,0 +1,331 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package opstack
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/abi"
+	"github.com/defiweb/go-eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/mocks"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/checkpoint"
+)
+
+var (
+	testOutputOracle = types.MustAddressFromHex("0xdfe97868233d1aa22e815a266982f2cf17685a27")
+	testOutputRoot   = types.MustHashFromHex("0x1111111111111111111111111111111111111111111111111111111111111111", types.PadNone)
+	testWithdrawal   = &withdrawal{
+		Nonce:    big.NewInt(7),
+		Sender:   types.MustAddressFromHex("0x2d800d93b065ce011af83f316cef9f0d005b0aa4"),
+		Target:   types.MustAddressFromHex("0x1234567890123456789012345678901234567890"),
+		Value:    big.NewInt(1000),
+		GasLimit: big.NewInt(100000),
+		Data:     []byte{0xde, 0xad, 0xbe, 0xef},
+	}
+)
+
+func testLog(t *testing.T, blockNumber uint64) types.Log {
+	hash, err := testWithdrawal.hash()
+	require.NoError(t, err)
+	data, err := abi.EncodeValues(
+		abi.MustParseType("(uint256,uint256,bytes,bytes32)"),
+		testWithdrawal.Value, testWithdrawal.GasLimit, testWithdrawal.Data, hash,
+	)
+	require.NoError(t, err)
+	txHash := types.MustHashFromHex("0x66e8ab5a41d4b109c7f6ba5695c4c3c8bcd8e7b1e2d4c3c0b6f1c2d3e4f5a6b7", types.PadNone)
+	logIndex := uint64(3)
+	return types.Log{
+		Address: MessagePasserAddress,
+		Topics: []types.Hash{
+			abiMessagePassed.Topic0(),
+			types.MustHashFromBigInt(testWithdrawal.Nonce),
+			types.MustHashFromBytes(testWithdrawal.Sender.Bytes(), types.PadLeft),
+			types.MustHashFromBytes(testWithdrawal.Target.Bytes(), types.PadLeft),
+		},
+		Data:            data,
+		BlockNumber:     new(big.Int).SetUint64(blockNumber),
+		TransactionHash: &txHash,
+		LogIndex:        &logIndex,
+	}
+}
+
+func outputOracleCall(t *testing.T, method *abi.Method, args ...any) types.Call {
+	cd, err := method.EncodeArgs(args...)
+	require.NoError(t, err)
+	return types.Call{To: &testOutputOracle, Input: cd}
+}
+
+func TestEventProvider_FetchEventsRoutine(t *testing.T) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFunc()
+
+	l2 := &mocks.RPC{}
+	l1 := &mocks.RPC{}
+	ep, err := New(Config{
+		L2Client:             l2,
+		L1Client:             l1,
+		OutputOracle:         testOutputOracle,
+		Interval:             100 * time.Millisecond,
+		BlockLimit:           10,
+		BlockConfirmations:   1,
+		L1BlockConfirmations: 2,
+	})
+	require.NoError(t, err)
+	ep.disablePrefetchEventsRoutine = true
+
+	ts := time.Unix(1234567890, 0)
+	l2.On("BlockNumber", ctx).Return(big.NewInt(100), nil).Once()
+	l2.On("BlockNumber", ctx).Return(big.NewInt(105), nil)
+	l2.On("BlockByNumber", ctx, types.BlockNumberFromUint64(101), false).Return(&types.Block{Timestamp: ts}, nil).Once()
+	l2.On("GetLogs", ctx, mock.Anything).Return([]types.Log{testLog(t, 101)}, nil).Once().Run(func(args mock.Arguments) {
+		fq := args.Get(1).(types.FilterLogsQuery)
+		assert.Equal(t, uint64(100), fq.FromBlock.Big().Uint64())
+		assert.Equal(t, uint64(104), fq.ToBlock.Big().Uint64())
+		assert.Equal(t, []types.Address{MessagePasserAddress}, fq.Address)
+		assert.Equal(t, [][]types.Hash{{abiMessagePassed.Topic0()}}, fq.Topics)
+	})
+
+	// The first time, the output oracle does not have an output that
+	// includes the withdrawal. The second time, it does.
+	l1Block := types.BlockNumberFromUint64(18)
+	l1.On("BlockNumber", ctx).Return(big.NewInt(20), nil)
+	l1.On("Call", ctx, outputOracleCall(t, latestBlockNumberMethod), l1Block).
+		Return(types.MustHashFromBigInt(big.NewInt(100)).Bytes(), nil).Once()
+	l1.On("Call", ctx, outputOracleCall(t, latestBlockNumberMethod), l1Block).
+		Return(types.MustHashFromBigInt(big.NewInt(120)).Bytes(), nil)
+	l1.On("Call", ctx, outputOracleCall(t, getL2OutputIndexAfterMethod, big.NewInt(101)), l1Block).
+		Return(types.MustHashFromBigInt(big.NewInt(5)).Bytes(), nil)
+	l1.On("Call", ctx, outputOracleCall(t, getL2OutputMethod, big.NewInt(5)), l1Block).
+		Return(abi.MustEncodeValues(
+			abi.MustParseType("(bytes32,uint128,uint128)"),
+			testOutputRoot, big.NewInt(1234567900), big.NewInt(120),
+		), nil)
+
+	require.NoError(t, ep.Start(ctx))
+
+	select {
+	case evt := <-ep.Events():
+		hash, err := testWithdrawal.hash()
+		require.NoError(t, err)
+		assert.Equal(t, WithdrawalEventType, evt.Type)
+		assert.Equal(t, ts, evt.EventDate)
+		assert.Equal(t, hash.Bytes(), evt.Index)
+		assert.Equal(t, hash.Bytes(), evt.Data[withdrawalHashField])
+		assert.Equal(t, testOutputRoot.Bytes(), evt.Data[outputRootField])
+		assert.Equal(t, types.MustHashFromBigInt(big.NewInt(5)).Bytes(), evt.Data[outputIndexField])
+		assert.Equal(t, types.MustHashFromBigInt(big.NewInt(120)).Bytes(), evt.Data[outputBlockField])
+		assert.NoError(t, verifyMessage(evt))
+	case <-ctx.Done():
+		require.Fail(t, "timeout")
+	}
+
+	// The withdrawal must not be published again:
+	time.Sleep(300 * time.Millisecond)
+	ep.mu.Lock()
+	assert.Empty(t, ep.pending)
+	ep.mu.Unlock()
+	select {
+	case <-ep.Events():
+		require.Fail(t, "unexpected event")
+	default:
+	}
+}
+
+func TestEventProvider_PrefetchEventsRoutine(t *testing.T) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFunc()
+
+	l2 := &mocks.RPC{}
+	l1 := &mocks.RPC{}
+	ep, err := New(Config{
+		L2Client:       l2,
+		L1Client:       l1,
+		OutputOracle:   testOutputOracle,
+		Interval:       100 * time.Millisecond,
+		PrefetchPeriod: time.Hour,
+		BlockLimit:     10,
+	})
+	require.NoError(t, err)
+	ep.disableFetchEventsRoutine = true
+
+	l2.On("BlockNumber", ctx).Return(big.NewInt(15), nil).Once()
+	l2.On("BlockByNumber", ctx, types.BlockNumberFromUint64(12), false).Return(&types.Block{Timestamp: time.Now()}, nil).Once()
+	l2.On("BlockByNumber", ctx, types.BlockNumberFromUint64(15), false).Return(&types.Block{Timestamp: time.Now()}, nil).Once()
+	l2.On("BlockByNumber", ctx, types.BlockNumberFromUint64(5), false).Return(&types.Block{Timestamp: time.Now()}, nil).Once()
+	l2.On("GetLogs", ctx, mock.Anything).Return([]types.Log{testLog(t, 12)}, nil).Once()
+	l2.On("GetLogs", ctx, mock.Anything).Return([]types.Log{}, nil).Once()
+
+	require.NoError(t, ep.Start(ctx))
+
+	require.Eventually(t, func() bool {
+		ep.mu.Lock()
+		defer ep.mu.Unlock()
+		return len(ep.pending) == 1
+	}, time.Second, 10*time.Millisecond)
+	ep.mu.Lock()
+	assert.Equal(t, big.NewInt(12), ep.pending[0].blockNumber)
+	ep.mu.Unlock()
+}
+
+func TestEventProvider_Checkpoint(t *testing.T) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFunc()
+
+	checkpoints := checkpoint.NewMemoryStore()
+	newProvider := func(l2, l1 *mocks.RPC) *EventProvider {
+		ep, err := New(Config{
+			L2Client:           l2,
+			L1Client:           l1,
+			OutputOracle:       testOutputOracle,
+			Interval:           100 * time.Millisecond,
+			PrefetchPeriod:     time.Hour,
+			BlockLimit:         10,
+			BlockConfirmations: 1,
+			Checkpoints:        checkpoints,
+		})
+		require.NoError(t, err)
+		return ep
+	}
+
+	// The withdrawal from block 101 stays pending, because there is no
+	// output proposal that includes it.
+	l2 := &mocks.RPC{}
+	l1 := &mocks.RPC{}
+	ep := newProvider(l2, l1)
+	ep.disablePrefetchEventsRoutine = true
+	l2.On("BlockNumber", mock.Anything).Return(big.NewInt(100), nil).Once()
+	l2.On("BlockNumber", mock.Anything).Return(big.NewInt(105), nil)
+	l2.On("BlockByNumber", mock.Anything, types.BlockNumberFromUint64(101), false).Return(&types.Block{Timestamp: time.Now()}, nil)
+	l2.On("GetLogs", mock.Anything, mock.Anything).Return([]types.Log{testLog(t, 101)}, nil).Once()
+	l2.On("GetLogs", mock.Anything, mock.Anything).Return([]types.Log{}, nil)
+	l1.On("BlockNumber", mock.Anything).Return(big.NewInt(20), nil)
+	l1.On("Call", mock.Anything, outputOracleCall(t, latestBlockNumberMethod), types.BlockNumberFromUint64(20)).
+		Return(types.MustHashFromBigInt(big.NewInt(100)).Bytes(), nil)
+
+	epCtx, epCancel := context.WithCancel(ctx)
+	require.NoError(t, ep.Start(epCtx))
+
+	// Although blocks up to 104 were fetched, the checkpoint must point to
+	// the block before the pending withdrawal:
+	require.Eventually(t, func() bool {
+		_, ok := ep.checkpoint()
+		return ok
+	}, time.Second, 10*time.Millisecond)
+	cp, _ := ep.checkpoint()
+	assert.Equal(t, uint64(100), cp.Number)
+	epCancel()
+
+	// After a restart, the provider must skip prefetching and fetch the
+	// pending withdrawal again.
+	l2 = &mocks.RPC{}
+	l1 = &mocks.RPC{}
+	ep = newProvider(l2, l1)
+	l2.On("BlockNumber", mock.Anything).Return(big.NewInt(110), nil)
+	l2.On("BlockByNumber", mock.Anything, types.BlockNumberFromUint64(101), false).Return(&types.Block{Timestamp: time.Now()}, nil)
+	l2.On("GetLogs", mock.Anything, mock.Anything).Return([]types.Log{testLog(t, 101)}, nil).Once().Run(func(args mock.Arguments) {
+		fq := args.Get(1).(types.FilterLogsQuery)
+		assert.Equal(t, uint64(101), fq.FromBlock.Big().Uint64())
+		assert.Equal(t, uint64(109), fq.ToBlock.Big().Uint64())
+	})
+	l1.On("BlockNumber", mock.Anything).Return(big.NewInt(20), nil)
+	l1.On("Call", mock.Anything, outputOracleCall(t, latestBlockNumberMethod), types.BlockNumberFromUint64(20)).
+		Return(types.MustHashFromBigInt(big.NewInt(100)).Bytes(), nil)
+
+	require.NoError(t, ep.Start(ctx))
+	require.Eventually(t, func() bool {
+		ep.mu.Lock()
+		defer ep.mu.Unlock()
+		return len(ep.pending) == 1
+	}, time.Second, 10*time.Millisecond)
+	l2.AssertNumberOfCalls(t, "GetLogs", 1)
+}
+
+func TestEventProvider_NotEnoughL1Blocks(t *testing.T) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFunc()
+
+	l2 := &mocks.RPC{}
+	l1 := &mocks.RPC{}
+	ep, err := New(Config{
+		L2Client:             l2,
+		L1Client:             l1,
+		OutputOracle:         testOutputOracle,
+		Interval:             time.Second,
+		BlockLimit:           10,
+		L1BlockConfirmations: 10,
+	})
+	require.NoError(t, err)
+	w, err := logToWithdrawal(testLog(t, 1), time.Now())
+	require.NoError(t, err)
+	ep.pending = append(ep.pending, w)
+
+	// The output oracle must not be called with a negative block number,
+	// which would be interpreted as a block tag:
+	l1.On("BlockNumber", ctx).Return(big.NewInt(5), nil)
+	assert.True(t, ep.publishPending(ctx))
+	l1.AssertNotCalled(t, "Call", mock.Anything, mock.Anything, mock.Anything)
+	assert.Len(t, ep.pending, 1)
+}
+
+func TestEventProvider_PublishPendingCanceled(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	l2 := &mocks.RPC{}
+	l1 := &mocks.RPC{}
+	ep, err := New(Config{
+		L2Client:     l2,
+		L1Client:     l1,
+		OutputOracle: testOutputOracle,
+		Interval:     time.Second,
+		BlockLimit:   10,
+	})
+	require.NoError(t, err)
+	w, err := logToWithdrawal(testLog(t, 101), time.Now())
+	require.NoError(t, err)
+	ep.pending = append(ep.pending, w)
+
+	l1Block := types.BlockNumberFromUint64(20)
+	l1.On("BlockNumber", ctx).Return(big.NewInt(20), nil)
+	l1.On("Call", ctx, outputOracleCall(t, latestBlockNumberMethod), l1Block).
+		Return(types.MustHashFromBigInt(big.NewInt(120)).Bytes(), nil)
+	l1.On("Call", ctx, outputOracleCall(t, getL2OutputIndexAfterMethod, big.NewInt(101)), l1Block).
+		Return(types.MustHashFromBigInt(big.NewInt(5)).Bytes(), nil)
+	l1.On("Call", ctx, outputOracleCall(t, getL2OutputMethod, big.NewInt(5)), l1Block).
+		Return(abi.MustEncodeValues(
+			abi.MustParseType("(bytes32,uint128,uint128)"),
+			testOutputRoot, big.NewInt(1234567900), big.NewInt(120),
+		), nil)
+
+	// Nobody reads from the events channel, so publishPending must return
+	// once the context is canceled instead of blocking forever.
+	time.AfterFunc(100*time.Millisecond, cancelFunc)
+	assert.False(t, ep.publishPending(ctx))
+	assert.Len(t, ep.pending, 1)
+}
+
+func TestLogToWithdrawal_HashMismatch(t *testing.T) {
+	l := testLog(t, 1)
+	l.Data[127] ^= 0xff // Last byte of the withdrawalHash field.
+	_, err := logToWithdrawal(l, time.Now())
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package opstack
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/mocks"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/teleportevm"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+func testMessage(t *testing.T) *messages.Event {
+	w, err := logToWithdrawal(testLog(t, 101), time.Now())
+	require.NoError(t, err)
+	evt, err := w.toMessage(WithdrawalEventType, &output{
+		Index:         big.NewInt(5),
+		OutputRoot:    testOutputRoot,
+		L2BlockNumber: big.NewInt(120),
+	})
+	require.NoError(t, err)
+	return evt
+}
+
+func TestSigner_IgnoreUnsupportedType(t *testing.T) {
+	msg := &messages.Event{Type: "foo"}
+	signer := NewSigner(&mocks.Key{}, []string{"bar"})
+
+	ok, err := signer.Sign(msg)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestSigner_Sign(t *testing.T) {
+	key := wallet.NewRandomKey()
+	msg := testMessage(t)
+	signer := NewSigner(key, []string{WithdrawalEventType})
+
+	ok, err := signer.Sign(msg)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, key.Address().Bytes(), msg.Signatures[teleportevm.SignatureKey].Signer)
+
+	recovered, err := crypto.ECRecoverer.RecoverMessage(
+		msg.Data[hashField],
+		types.MustSignatureFromBytes(msg.Signatures[teleportevm.SignatureKey].Signature),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, key.Address(), *recovered)
+}
+
+func TestSigner_RejectTamperedData(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(evt *messages.Event)
+	}{
+		{
+			name:   "output root",
+			tamper: func(evt *messages.Event) { evt.Data[outputRootField][0] ^= 0xff },
+		},
+		{
+			name:   "output index",
+			tamper: func(evt *messages.Event) { evt.Data[outputIndexField][31]++ },
+		},
+		{
+			name:   "withdrawal",
+			tamper: func(evt *messages.Event) { evt.Data[withdrawalField][31]++ },
+		},
+		{
+			name:   "missing field",
+			tamper: func(evt *messages.Event) { delete(evt.Data, outputBlockField) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := testMessage(t)
+			tt.tamper(msg)
+			signer := NewSigner(wallet.NewRandomKey(), []string{WithdrawalEventType})
+
+			ok, err := signer.Sign(msg)
+			assert.False(t, ok)
+			assert.Error(t, err)
+			assert.Empty(t, msg.Signatures)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package opstack
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/defiweb/go-eth/abi"
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+// Names of the fields in the event data.
+const (
+	hashField           = "hash"            // Hash to be used to calculate a signature.
+	withdrawalField     = "withdrawal"      // ABI-encoded withdrawal transaction.
+	withdrawalHashField = "withdrawal_hash" // Hash of the withdrawal transaction.
+	outputRootField     = "output_root"     // Output root that includes the withdrawal.
+	outputIndexField    = "l2_output_index" // Index of the output in the L2OutputOracle.
+	outputBlockField    = "l2_block_number" // L2 block number of the output.
+)
+
+// withdrawal is a withdrawal transaction as defined in:
+// https://github.com/ethereum-optimism/optimism/blob/develop/packages/contracts-bedrock/src/libraries/Types.sol
+type withdrawal struct {
+	Nonce    *big.Int      `abi:"nonce"`
+	Sender   types.Address `abi:"sender"`
+	Target   types.Address `abi:"target"`
+	Value    *big.Int      `abi:"value"`
+	GasLimit *big.Int      `abi:"gasLimit"`
+	Data     []byte        `abi:"data"`
+}
+
+// hash returns the withdrawal hash, as calculated by the
+// Hashing.hashWithdrawal function of the OP Stack contracts.
+func (w *withdrawal) hash() (types.Hash, error) {
+	b, err := abi.EncodeValue(abiWithdrawal, w)
+	if err != nil {
+		return types.Hash{}, fmt.Errorf("unable to encode withdrawal: %w", err)
+	}
+	return crypto.Keccak256(b), nil
+}
+
+// output is an L2 output proposal stored in the L2OutputOracle contract.
+type output struct {
+	Index         *big.Int
+	OutputRoot    types.Hash
+	L2BlockNumber *big.Int
+}
+
+// pendingWithdrawal is a withdrawal that waits for an output proposal that
+// includes it.
+type pendingWithdrawal struct {
+	id          []byte
+	withdrawal  *withdrawal
+	hash        types.Hash
+	blockNumber *big.Int
+	eventDate   time.Time
+}
+
+// logToWithdrawal converts a MessagePassed log to a pending withdrawal.
+func logToWithdrawal(l types.Log, eventDate time.Time) (*pendingWithdrawal, error) {
+	if l.TransactionHash == nil || l.LogIndex == nil || l.BlockNumber == nil {
+		return nil, errors.New("log is pending")
+	}
+	if len(l.Topics) != 4 || l.Topics[0] != abiMessagePassed.Topic0() {
+		return nil, errors.New("log is not a MessagePassed event")
+	}
+	// Indexed arguments are decoded separately from the data, because the
+	// offsets of dynamic types in the data do not account for topics.
+	var evt struct {
+		Value          *big.Int   `abi:"value"`
+		GasLimit       *big.Int   `abi:"gasLimit"`
+		Data           []byte     `abi:"data"`
+		WithdrawalHash types.Hash `abi:"withdrawalHash"`
+	}
+	if err := abi.DecodeValue(abiMessagePassedData, l.Data, &evt); err != nil {
+		return nil, fmt.Errorf("unable to decode MessagePassed event: %w", err)
+	}
+	w := &withdrawal{
+		Nonce:    new(big.Int).SetBytes(l.Topics[1].Bytes()),
+		Sender:   types.MustAddressFromBytes(l.Topics[2][types.HashLength-types.AddressLength:]),
+		Target:   types.MustAddressFromBytes(l.Topics[3][types.HashLength-types.AddressLength:]),
+		Value:    evt.Value,
+		GasLimit: evt.GasLimit,
+		Data:     evt.Data,
+	}
+	hash, err := w.hash()
+	if err != nil {
+		return nil, err
+	}
+	if hash != evt.WithdrawalHash {
+		return nil, fmt.Errorf("withdrawal hash mismatch: expected %s, got %s", hash, evt.WithdrawalHash)
+	}
+	return &pendingWithdrawal{
+		// ID is additionally hashed to ensure that it is not similar to
+		// any other field, so it will not be misused. This field is intended
+		// to be used only be the event store.
+		id:          crypto.Keccak256(l.TransactionHash.Bytes(), new(big.Int).SetUint64(*l.LogIndex).Bytes()).Bytes(),
+		withdrawal:  w,
+		hash:        hash,
+		blockNumber: l.BlockNumber,
+		eventDate:   eventDate,
+	}, nil
+}
+
+// toMessage converts a withdrawal and the output proposal that includes it
+// to an event message.
+//
+// The Index of the message is the withdrawal hash. The "hash" field of the
+// message data, which is used to calculate a signature, is the Keccak256
+// hash of abi.encode(withdrawalHash, outputRoot, l2OutputIndex,
+// l2BlockNumber), so the signature commits to both the withdrawal and the
+// output root against which it can be proven.
+func (p *pendingWithdrawal) toMessage(eventType string, o *output) (*messages.Event, error) {
+	w, err := abi.EncodeValue(abiWithdrawal, p.withdrawal)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode withdrawal: %w", err)
+	}
+	h, err := commitment(p.hash, o.OutputRoot, o.Index, o.L2BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return &messages.Event{
+		Type:        eventType,
+		ID:          p.id,
+		Index:       p.hash.Bytes(),
+		EventDate:   p.eventDate,
+		MessageDate: time.Now(),
+		Data: map[string][]byte{
+			hashField:           h.Bytes(),
+			withdrawalField:     w,
+			withdrawalHashField: p.hash.Bytes(),
+			outputRootField:     o.OutputRoot.Bytes(),
+			outputIndexField:    types.MustHashFromBigInt(o.Index).Bytes(),
+			outputBlockField:    types.MustHashFromBigInt(o.L2BlockNumber).Bytes(),
+		},
+		Signatures: map[string]messages.EventSignature{},
+	}, nil
+}
+
+// commitment returns the hash that is signed for a withdrawal.
+func commitment(withdrawalHash, outputRoot types.Hash, outputIndex, l2BlockNumber *big.Int) (types.Hash, error) {
+	b, err := abi.EncodeValues(abiCommitment, withdrawalHash, outputRoot, outputIndex, l2BlockNumber)
+	if err != nil {
+		return types.Hash{}, fmt.Errorf("unable to encode withdrawal commitment: %w", err)
+	}
+	return crypto.Keccak256(b), nil
+}
+
+// verifyMessage checks that the "hash" field of the event data commits to
+// the rest of the data. It prevents signing a hash that does not match
+// the withdrawal or the output root included in the event.
+func verifyMessage(event *messages.Event) error {
+	for _, f := range []string{hashField, withdrawalField, withdrawalHashField, outputRootField, outputIndexField, outputBlockField} {
+		if _, ok := event.Data[f]; !ok {
+			return fmt.Errorf("missing %s field", f)
+		}
+	}
+	for _, f := range []string{hashField, withdrawalHashField, outputRootField, outputIndexField, outputBlockField} {
+		if len(event.Data[f]) != types.HashLength {
+			return fmt.Errorf("invalid %s field length", f)
+		}
+	}
+	var w withdrawal
+	if err := abi.DecodeValue(abiWithdrawal, event.Data[withdrawalField], &w); err != nil {
+		return fmt.Errorf("unable to decode withdrawal: %w", err)
+	}
+	wh, err := w.hash()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(wh.Bytes(), event.Data[withdrawalHashField]) {
+		return errors.New("withdrawal hash does not match the withdrawal")
+	}
+	h, err := commitment(
+		wh,
+		types.MustHashFromBytes(event.Data[outputRootField], types.PadNone),
+		new(big.Int).SetBytes(event.Data[outputIndexField]),
+		new(big.Int).SetBytes(event.Data[outputBlockField]),
+	)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Bytes(), event.Data[hashField]) {
+		return errors.New("hash does not match the withdrawal and the output root")
+	}
+	return nil
+}
+
+var (
+	abiWithdrawal = abi.MustParseType(
+		`(
+			uint256 nonce,
+			address sender,
+			address target,
+			uint256 value,
+			uint256 gasLimit,
+			bytes data
+		)`,
+	)
+	abiCommitment = abi.MustParseType(
+		`(
+			bytes32 withdrawalHash,
+			bytes32 outputRoot,
+			uint256 l2OutputIndex,
+			uint256 l2BlockNumber
+		)`,
+	)
+	abiMessagePassed = abi.MustParseEvent(
+		`event MessagePassed(
+			uint256 indexed nonce,
+			address indexed sender,
+			address indexed target,
+			uint256 value,
+			uint256 gasLimit,
+			bytes data,
+			bytes32 withdrawalHash
+		)`,
+	)
+	abiMessagePassedData = abi.MustParseType(
+		`(
+			uint256 value,
+			uint256 gasLimit,
+			bytes data,
+			bytes32 withdrawalHash
+		)`,
+	)
+	latestBlockNumberMethod     = abi.MustParseMethod("function latestBlockNumber() view returns (uint256)")
+	getL2OutputIndexAfterMethod = abi.MustParseMethod("function getL2OutputIndexAfter(uint256) view returns (uint256)")
+	getL2OutputMethod           = abi.MustParseMethod("function getL2Output(uint256) view returns ((bytes32 outputRoot, uint128 timestamp, uint128 l2BlockNumber))")
+)
@@ -0,0 +1,111 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package opstack
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/hexutil"
+	"github.com/defiweb/go-eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors are taken from the OP Stack repository, so the hashes are
+// known to match the ones calculated by the OP Stack contracts.
+
+func TestWithdrawal_Hash(t *testing.T) {
+	tests := []struct {
+		withdrawal *withdrawal
+		want       string
+	}{
+		{
+			withdrawal: &withdrawal{
+				Nonce:    big.NewInt(0),
+				Sender:   types.MustAddressFromHex("0x00000000000000000000000000000000000011bc"),
+				Target:   types.MustAddressFromHex("0x00000000000000000000000000000000000033eb"),
+				Value:    big.NewInt(26),
+				GasLimit: big.NewInt(22338),
+				Data:     hexutil.MustHexToBytes("0x0000000000000000000000000000000000000000000000000000000000000004"),
+			},
+			want: "0x65768976d27ba8a7f91c5b267b97d29830103171863c0ba24f3234ef07d0f8e3",
+		},
+		{
+			withdrawal: &withdrawal{
+				Nonce:    big.NewInt(0),
+				Sender:   types.MustAddressFromHex("0x4b0ca57cb88a41771d2cc24ac9fd50afeaa3eedd"),
+				Target:   types.MustAddressFromHex("0x8a5e8410b2c3e1036c49ff8acae1e659e2508200"),
+				Value:    big.NewInt(3),
+				GasLimit: new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+				Data:     hexutil.MustHexToBytes("0xce6b96a23be7a1ac1de74f3202dfc4cedaef69502204c0d92f7b352a837a"),
+			},
+			want: "0x4ba164b689ac62c27c68f41b5f3c4731eb2c25c2d39e4aadcc413d150764624f",
+		},
+	}
+	for _, tt := range tests {
+		hash, err := tt.withdrawal.hash()
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, hash.String())
+	}
+}
+
+func TestLogToWithdrawal_KnownLog(t *testing.T) {
+	txHash := types.MustHashFromHex("0x9346381068b59d2098495baa72ed2f773c1e09458610a7a208984859dff73add", types.PadNone)
+	logIndex := uint64(2)
+	l := types.Log{
+		Address: MessagePasserAddress,
+		Topics: []types.Hash{
+			types.MustHashFromHex("0x02a52367d10742d8032712c1bb8e0144ff1ec5ffda1ed7d70bb05a2744955054", types.PadNone),
+			types.MustHashFromHex("0x0000000000000000000000000000000000000000000000000000000000000000", types.PadNone),
+			types.MustHashFromHex("0x0000000000000000000000004200000000000000000000000000000000000007", types.PadNone),
+			types.MustHashFromHex("0x0000000000000000000000006900000000000000000000000000000000000002", types.PadNone),
+		},
+		Data: hexutil.MustHexToBytes(
+			"0x" +
+				"0000000000000000000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000031b80" +
+				"0000000000000000000000000000000000000000000000000000000000000080" +
+				"0d827f8148288e3a2466018f71b968ece4ea9f9e2a81c30da9bd46cce2868285" +
+				"00000000000000000000000000000000000000000000000000000000000001e4" +
+				"d764ad0b00010000000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000042000000000000000000000000000000" +
+				"0000001000000000000000000000000069000000000000000000000000000000" +
+				"0000000300000000000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000000000" +
+				"000000c000000000000000000000000000000000000000000000000000000000" +
+				"000000e40166a07a00000000000000000000000089d51be807d98fc974a0f41b" +
+				"2e67a8228d7846ef0000000000000000000000007c6b91d9be155a6db01f7492" +
+				"17d76ff02a7227f2000000000000000000000000c20c5ec92fda6e611a084851" +
+				"23cdc0d5b84bd3a2000000000000000000000000c20c5ec92fda6e611a084851" +
+				"23cdc0d5b84bd3a2000000000000000000000000000000000000000000000000" +
+				"00000000000001f4000000000000000000000000000000000000000000000000" +
+				"00000000000000c0000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000000000",
+		),
+		BlockNumber:     big.NewInt(0x36),
+		TransactionHash: &txHash,
+		LogIndex:        &logIndex,
+	}
+	w, err := logToWithdrawal(l, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "0x0d827f8148288e3a2466018f71b968ece4ea9f9e2a81c30da9bd46cce2868285", w.hash.String())
+	assert.Equal(t, types.MustAddressFromHex("0x4200000000000000000000000000000000000007"), w.withdrawal.Sender)
+	assert.Equal(t, types.MustAddressFromHex("0x6900000000000000000000000000000000000002"), w.withdrawal.Target)
+}
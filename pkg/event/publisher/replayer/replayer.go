@@ -16,6 +16,7 @@
 package replayer
 
 import (
+	"bytes"
 	"container/list"
 	"context"
 	"errors"
@@ -49,7 +50,7 @@ type Config struct {
 // at the same time all received events are cached. The cache is checked at the
 // configured interval, and events that are older than the configured playback
 // periods are replayed. Events are removed from the cache when they are older
-// than the oldest playback period.
+// than the oldest playback period or when they are retracted.
 type EventProvider struct {
 	mu            sync.Mutex
 	eventCh       chan *messages.Event
@@ -112,7 +113,11 @@ func (r *EventProvider) collectEventsRoutine(ctx context.Context) {
 			func() {
 				r.mu.Lock()
 				defer r.mu.Unlock()
-				r.eventCache.add(evt)
+				if evt.IsRetraction() {
+					r.eventCache.removeByID(evt.Type, evt.ID)
+				} else {
+					r.eventCache.add(evt)
+				}
 				r.eventCh <- evt
 			}()
 		}
@@ -185,6 +190,19 @@ func (m *events) iterate(fn func(*messages.Event)) {
 	}
 }
 
+// removeByID removes all events with the given type and ID from the list.
+func (m *events) removeByID(typ string, id []byte) {
+	var next *list.Element
+	for e := m.list.Front(); e != nil; e = next {
+		next = e.Next()
+		evt := e.Value.(*messages.Event)
+		if evt.Type == typ && bytes.Equal(evt.ID, id) {
+			m.list.Remove(e)
+		}
+	}
+	m.last = nil
+}
+
 // remove removes the last added event from the list or the last iterated event.
 func (m *events) remove() {
 	if m.last == nil {
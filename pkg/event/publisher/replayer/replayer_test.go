@@ -93,3 +93,43 @@ func Test_Replayer(t *testing.T) {
 	assert.Equal(t, 0, rep.eventCache.list.Len())
 	rep.mu.Unlock()
 }
+
+func Test_Replayer_Retraction(t *testing.T) {
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer ctxCancel()
+
+	ch := make(chan *messages.Event)
+	rep, err := New(Config{
+		EventProvider: eventProvider{eventsCh: ch},
+		Interval:      100 * time.Millisecond,
+		ReplayAfter:   []time.Duration{100 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	require.NoError(t, rep.Start(ctx))
+
+	evt := &messages.Event{
+		Type:      "test",
+		ID:        []byte("test"),
+		Index:     []byte("test"),
+		EventDate: time.Now(),
+		Data:      map[string][]byte{"test": []byte("test")},
+	}
+	go func() {
+		ch <- evt
+		ch <- messages.NewEventRetraction(evt.Type, evt.ID, evt.Index)
+	}()
+
+	// Both the event and the retraction must be forwarded.
+	assert.False(t, (<-rep.Events()).IsRetraction())
+	assert.True(t, (<-rep.Events()).IsRetraction())
+
+	// Retracted event must not be replayed.
+	select {
+	case <-rep.Events():
+		assert.Fail(t, "retracted event was replayed")
+	case <-time.After(300 * time.Millisecond):
+	}
+	rep.mu.Lock()
+	assert.Equal(t, 0, rep.eventCache.list.Len())
+	rep.mu.Unlock()
+}
@@ -29,8 +29,9 @@ const SignatureKey = "ethereum"
 //
 // Signer could only sign events that have a "hash" field in the data. The
 // value of that field is used to calculate the signature. The rest of the
-// fields in the data are ignored. The calculated signature is stored in the
-// "ethereum" field of the event's signatures map.
+// fields in the data are ignored. Retractions are signed using the hash
+// returned by the RetractionHash method. The calculated signature is stored
+// in the "ethereum" field of the event's signatures map.
 type Signer struct {
 	signer wallet.Key
 	types  []string
@@ -53,12 +54,18 @@ func (l *Signer) Sign(event *messages.Event) (bool, error) {
 	if !supports {
 		return false, nil
 	}
-	if event.Data == nil {
-		return false, errors.New("event data is nil")
-	}
-	h, ok := event.Data["hash"]
-	if !ok {
-		return false, errors.New("missing hash field")
+	var h []byte
+	if event.IsRetraction() {
+		h = event.RetractionHash().Bytes()
+	} else {
+		if event.Data == nil {
+			return false, errors.New("event data is nil")
+		}
+		var ok bool
+		h, ok = event.Data["hash"]
+		if !ok {
+			return false, errors.New("missing hash field")
+		}
 	}
 	s, err := l.signer.SignMessage(h)
 	if err != nil {
@@ -70,3 +70,18 @@ func TestSigner_Sign(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, address, *recovered)
 }
+
+func TestSigner_SignRetraction(t *testing.T) {
+	key := wallet.NewRandomKey()
+	msg := messages.NewEventRetraction("foo", []byte("id"), []byte("index"))
+	signer := NewSigner(key, []string{"foo"})
+
+	ok, err := signer.Sign(msg)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+
+	// Retraction must be signed using the retraction hash:
+	recovered, err := crypto.ECRecoverer.RecoverMessage(msg.RetractionHash().Bytes(), types.MustSignatureFromBytes(msg.Signatures[SignatureKey].Signature))
+	require.NoError(t, err)
+	assert.Equal(t, key.Address(), *recovered)
+}
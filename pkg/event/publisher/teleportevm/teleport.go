@@ -24,8 +24,10 @@ import (
 	"github.com/defiweb/go-eth/types"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/checkpoint"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/bn"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/internal/blockrange"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
@@ -39,6 +41,11 @@ const LoggerTag = "ETHEREUM_TELEPORT"
 // while communicating with a node.
 const retryInterval = 5 * time.Second
 
+// maxCheckpointBlocks is the number of recently processed blocks kept in the
+// checkpoint state. It limits how deep a chain reorganization can be resolved
+// by finding the common ancestor.
+const maxCheckpointBlocks = 128
+
 // teleportTopic0 is Keccak256("TeleportInitialized((bytes32,bytes32,bytes32,bytes32,uint128,uint80,uint48))")
 var teleportTopic0 = types.MustHashFromHex(
 	"0x61aedca97129bac4264ec6356bd1f66431e65ab80e2d07b7983647d72776f545",
@@ -67,6 +74,13 @@ type Config struct {
 	// fetching logs.
 	BlockConfirmations uint64
 
+	// Checkpoints is an optional store used to keep the last processed block
+	// of each contract along with recently emitted events. If set, the
+	// provider resumes from the checkpoints after a restart instead of
+	// prefetching past events, detects chain reorganizations, and retracts
+	// events emitted from orphaned blocks.
+	Checkpoints checkpoint.Store
+
 	// Logger is a current logger interface used by the EventProvider.
 	Logger log.Logger
 }
@@ -84,6 +98,17 @@ type Config struct {
 // until it reaches the block that is older than the prefetch period. This is
 // done to fetch events that were emitted before the provider was started.
 //
+// If checkpoints are enabled, before processing a new block range for
+// a contract, the provider compares the parent hash of the first block in
+// the range with the hash of the last processed block. If they differ, a
+// chain reorganization occurred. In that case, the provider looks for the
+// newest processed block that is still canonical, sends retractions for
+// events emitted from orphaned blocks, and resumes from that block.
+// Because logs and blocks are fetched in separate requests, the hashes of
+// blocks in which events were emitted are verified again before the events
+// are sent. The hashes are kept in the checkpoint along with the hash of
+// the last block of the range.
+//
 // In the event of an error in communication with a node, whether related to
 // network errors or the node itself, the provider will try to repeat requests
 // to the node indefinitely.
@@ -97,6 +122,7 @@ type EventProvider struct {
 	prefetchPeriod time.Duration
 	blockLimit     uint64
 	blockConfirms  uint64
+	checkpoints    checkpoint.Store
 	log            log.Logger
 
 	// Used in tests only:
@@ -126,6 +152,7 @@ func New(cfg Config) (*EventProvider, error) {
 		prefetchPeriod: cfg.PrefetchPeriod,
 		blockLimit:     cfg.BlockLimit,
 		blockConfirms:  cfg.BlockConfirmations,
+		checkpoints:    cfg.Checkpoints,
 		log:            cfg.Logger.WithField("tag", LoggerTag),
 	}, nil
 }
@@ -148,40 +175,64 @@ func (ep *EventProvider) Start(ctx context.Context) error {
 
 // prefetchEventsRoutine fetches events from older blocks until it reaches the
 // block that is older than the prefetch period. This is done to fetch events
-// that were emitted before the provider was started.
+// that were emitted before the provider was started. Contracts that have
+// a checkpoint are skipped, because the fetchEventsRoutine resumes from the
+// checkpoint.
 func (ep *EventProvider) prefetchEventsRoutine(ctx context.Context) {
 	if ep.prefetchPeriod == 0 {
 		return
 	}
+	var addresses []types.Address
+	for _, address := range ep.addresses {
+		if _, ok := ep.checkpoint(address); !ok {
+			addresses = append(addresses, address)
+		}
+	}
+	if len(addresses) == 0 {
+		return
+	}
 	latestBlock, ok := ep.getBlockNumber(ctx)
 	if !ok {
 		return // Context was canceled.
 	}
 	for d := ep.blockConfirms; ctx.Err() == nil; d += ep.blockLimit {
-		from := bn.Int(latestBlock).Sub(d + ep.blockLimit - 1)
-		to := bn.Int(latestBlock).Sub(d)
-		if from.Sign() < 0 {
-			from = bn.Int(0)
+		to, ok := blockrange.Confirmed(bn.Int(latestBlock), d)
+		if !ok {
+			return // There are no confirmed blocks yet.
 		}
+		from, _ := blockrange.Confirmed(bn.Int(latestBlock), d+ep.blockLimit-1)
 
-		ep.handleEvents(ctx, from, to)
-		ts, ok := ep.getBlockTimestamp(ctx, to)
+		for _, address := range addresses {
+			msgs, _, ok := ep.handleEvents(ctx, address, from, to)
+			if !ok || !ep.sendEvents(ctx, msgs) {
+				return // Context was canceled.
+			}
+		}
+		block, ok := ep.getBlock(ctx, to)
 		if !ok {
 			return // Context was canceled.
 		}
-		if from.Sign() == 0 || time.Since(ts) > ep.prefetchPeriod {
+		if from.Sign() == 0 || time.Since(block.Timestamp) > ep.prefetchPeriod {
 			return // End of the prefetch period reached.
 		}
 	}
 }
 
 // fetchEventsRoutine periodically fetches new TeleportGUID logs from the
-// blockchain.
+// blockchain. Each contract is processed from its checkpoint, if there is
+// one, or from the latest confirmed block otherwise.
 func (ep *EventProvider) fetchEventsRoutine(ctx context.Context) {
 	latestBlock, ok := ep.getBlockNumber(ctx)
 	if !ok {
 		return // Context was canceled.
 	}
+	next := make(map[types.Address]*bn.IntNumber, len(ep.addresses))
+	for _, address := range ep.addresses {
+		next[address], _ = blockrange.Confirmed(bn.Int(latestBlock).Add(bn.Int(1)), ep.blockConfirms)
+		if cp, ok := ep.checkpoint(address); ok {
+			next[address] = bn.Int(cp.Number + 1)
+		}
+	}
 	t := time.NewTicker(ep.interval)
 	defer t.Stop()
 	for {
@@ -193,75 +244,288 @@ func (ep *EventProvider) fetchEventsRoutine(ctx context.Context) {
 			if !ok {
 				return // Context was canceled.
 			}
-			if currentBlock.Cmp(latestBlock) <= 0 {
-				continue // There are no new blocks.
+			to, ok := blockrange.Confirmed(bn.Int(currentBlock), ep.blockConfirms)
+			if !ok {
+				continue // There are no confirmed blocks yet.
 			}
-			ranges := splitBlockRanges(
-				bn.Int(latestBlock).Add(bn.Int(1)),
-				bn.Int(currentBlock),
-				bn.Int(ep.blockLimit),
-			)
-			for _, b := range ranges {
-				from := b[0].Sub(bn.Int(ep.blockConfirms))
-				to := b[1].Sub(bn.Int(ep.blockConfirms))
-				ep.handleEvents(ctx, from, to)
+			for _, address := range ep.addresses {
+				ranges := blockrange.Split(next[address], to, bn.Int(ep.blockLimit))
+				for _, b := range ranges {
+					n, ok := ep.processRange(ctx, address, b[0], b[1])
+					if !ok {
+						return // Context was canceled.
+					}
+					next[address] = n
+					if n.Cmp(b[1]) <= 0 {
+						break // Rewound after a reorganization.
+					}
+				}
 			}
-			latestBlock = currentBlock
 		}
 	}
 }
 
-// handleEvents fetches TeleportGUID events from the given block range and
-// sends them to the eventCh channel.
-func (ep *EventProvider) handleEvents(ctx context.Context, from, to *bn.IntNumber) {
-	for _, address := range ep.addresses {
+// processRange fetches TeleportGUID events emitted by the given contract in
+// the given block range. If checkpoints are enabled, it verifies that the
+// range continues the chain of the last processed block and updates the
+// checkpoint. It returns the number of the next block to process.
+func (ep *EventProvider) processRange(
+	ctx context.Context,
+	address types.Address,
+	from, to *bn.IntNumber,
+) (*bn.IntNumber, bool) {
+
+	if ep.checkpoints == nil {
+		msgs, _, ok := ep.handleEvents(ctx, address, from, to)
+		if !ok || !ep.sendEvents(ctx, msgs) {
+			return nil, false // Context was canceled.
+		}
+		return to.Add(bn.Int(1)), true
+	}
+	key := address.String()
+	state, err := ep.checkpoints.Get(key)
+	if err != nil {
+		ep.log.WithError(err).Error("Unable to load checkpoint")
+		return from, true
+	}
+	if last, ok := state.Last(); ok && last.Number+1 == from.BigInt().Uint64() {
+		block, ok := ep.getBlock(ctx, from)
+		if !ok {
+			return nil, false // Context was canceled.
+		}
+		if block.ParentHash != last.Hash {
+			return ep.handleReorg(ctx, address, state)
+		}
+	}
+	msgs, evts, ok := ep.handleEvents(ctx, address, from, to)
+	if !ok {
+		return nil, false // Context was canceled.
+	}
+	block, ok := ep.getBlock(ctx, to)
+	if !ok {
+		return nil, false // Context was canceled.
+	}
+	blocks, canonical, ok := ep.verifyBlocks(ctx, evts)
+	if !ok {
+		return nil, false // Context was canceled.
+	}
+	if !canonical {
+		// The chain was reorganized while the range was being processed.
+		// The range is processed again on the next tick.
+		ep.log.
+			WithField("address", address.String()).
+			Warn("Events emitted from orphaned blocks, retrying")
+		return from, true
+	}
+	if !ep.sendEvents(ctx, msgs) {
+		return nil, false // Context was canceled.
+	}
+	if len(blocks) == 0 || blocks[len(blocks)-1].Number != to.BigInt().Uint64() {
+		blocks = append(blocks, checkpoint.Block{Number: to.BigInt().Uint64(), Hash: block.Hash})
+	}
+	state.Blocks = append(state.Blocks, blocks...)
+	state.Events = append(state.Events, evts...)
+	if len(state.Blocks) > maxCheckpointBlocks {
+		state.Blocks = state.Blocks[len(state.Blocks)-maxCheckpointBlocks:]
+		var events []checkpoint.Event
+		for _, evt := range state.Events {
+			if evt.Block.Number >= state.Blocks[0].Number {
+				events = append(events, evt)
+			}
+		}
+		state.Events = events
+	}
+	if err := ep.checkpoints.Set(key, state); err != nil {
+		ep.log.WithError(err).Error("Unable to save checkpoint")
+	}
+	return to.Add(bn.Int(1)), true
+}
+
+// handleReorg handles a chain reorganization detected for the given contract.
+// It finds the newest processed block that is still canonical, retracts events
+// emitted from blocks after it and returns the number of the next block to
+// process.
+func (ep *EventProvider) handleReorg(
+	ctx context.Context,
+	address types.Address,
+	state checkpoint.State,
+) (*bn.IntNumber, bool) {
+
+	var (
+		fork  uint64
+		found bool
+	)
+	for i := len(state.Blocks) - 1; i >= 0; i-- {
+		block, ok := ep.getBlock(ctx, bn.Int(state.Blocks[i].Number))
+		if !ok {
+			return nil, false // Context was canceled.
+		}
+		if block.Hash == state.Blocks[i].Hash {
+			fork, found = state.Blocks[i].Number, true
+			state.Blocks = state.Blocks[:i+1]
+			break
+		}
+	}
+	if !found {
+		// The reorganization is deeper than the checkpoint history. All
+		// known events are retracted and processing resumes from the
+		// oldest known block.
+		if state.Blocks[0].Number > 0 {
+			fork = state.Blocks[0].Number - 1
+		}
+		state.Blocks = nil
+	}
+	ep.log.
+		WithFields(log.Fields{
+			"address":     address.String(),
+			"commonBlock": fork,
+		}).
+		Warn("Chain reorganization detected")
+	var events []checkpoint.Event
+	for _, evt := range state.Events {
+		if found && evt.Block.Number <= fork {
+			events = append(events, evt)
+			continue
+		}
 		ep.log.
 			WithFields(log.Fields{
-				"from":    from,
-				"to":      to,
-				"address": address.String(),
+				"address":     address.String(),
+				"blockNumber": evt.Block.Number,
+				"blockHash":   evt.Block.Hash.String(),
 			}).
-			Info("Fetching logs")
-		logs, ok := ep.filterLogs(ctx, address, from, to, teleportTopic0)
-		if !ok {
-			return // Context was canceled.
+			Warn("Retracting event from orphaned block")
+		if !ep.sendEvents(ctx, []*messages.Event{messages.NewEventRetraction(evt.Type, evt.ID, evt.Index)}) {
+			return nil, false // Context was canceled.
 		}
-		for _, l := range logs {
-			if l.Address != address {
-				// PANIC!
-				// This should never happen. All logs returned by
-				// eth_filterLogs should be emitted by the specified
-				// contract. If it happens, there is a bug somewhere.
-				ep.log.
-					WithFields(log.Fields{
-						"expected": address.String(),
-						"actual":   l.Address.String(),
-					}).
-					Panic("Log emitted by wrong contract")
-			}
-			if l.Removed {
-				// This should never happen. All logs returned by
-				// eth_filterLogs should not be removed.
-				ep.log.
-					WithFields(log.Fields{
-						"address":     l.Address.String(),
-						"blockNumber": l.BlockNumber,
-						"blockHash":   l.BlockHash.String(),
-						"txHash":      l.TransactionHash.String(),
-					}).
-					Warn("Received removed log")
-				continue
-			}
-			evt, err := logToMessage(l)
-			if err != nil {
-				ep.log.
-					WithError(err).
-					Error("Unable to convert log to event")
-				continue
+	}
+	state.Events = events
+	if err := ep.checkpoints.Set(address.String(), state); err != nil {
+		ep.log.WithError(err).Error("Unable to save checkpoint")
+	}
+	return bn.Int(fork + 1), true
+}
+
+// handleEvents fetches TeleportGUID events emitted by the given contract from
+// the given block range. It returns the event messages and the list of
+// events to store in the checkpoint.
+func (ep *EventProvider) handleEvents(
+	ctx context.Context,
+	address types.Address,
+	from, to *bn.IntNumber,
+) ([]*messages.Event, []checkpoint.Event, bool) {
+
+	ep.log.
+		WithFields(log.Fields{
+			"from":    from,
+			"to":      to,
+			"address": address.String(),
+		}).
+		Info("Fetching logs")
+	logs, ok := ep.filterLogs(ctx, address, from, to, teleportTopic0)
+	if !ok {
+		return nil, nil, false // Context was canceled.
+	}
+	var (
+		msgs []*messages.Event
+		evts []checkpoint.Event
+	)
+	for _, l := range logs {
+		if l.Address != address {
+			// PANIC!
+			// This should never happen. All logs returned by
+			// eth_filterLogs should be emitted by the specified
+			// contract. If it happens, there is a bug somewhere.
+			ep.log.
+				WithFields(log.Fields{
+					"expected": address.String(),
+					"actual":   l.Address.String(),
+				}).
+				Panic("Log emitted by wrong contract")
+		}
+		if l.Removed {
+			// This should never happen. All logs returned by
+			// eth_filterLogs should not be removed.
+			ep.log.
+				WithFields(log.Fields{
+					"address":     l.Address.String(),
+					"blockNumber": l.BlockNumber,
+					"blockHash":   l.BlockHash.String(),
+					"txHash":      l.TransactionHash.String(),
+				}).
+				Warn("Received removed log")
+			continue
+		}
+		evt, err := logToMessage(l)
+		if err != nil {
+			ep.log.
+				WithError(err).
+				Error("Unable to convert log to event")
+			continue
+		}
+		msgs = append(msgs, evt)
+		if l.BlockNumber != nil && l.BlockHash != nil {
+			evts = append(evts, checkpoint.Event{
+				Block: checkpoint.Block{Number: l.BlockNumber.Uint64(), Hash: *l.BlockHash},
+				Type:  evt.Type,
+				ID:    evt.ID,
+				Index: evt.Index,
+			})
+		}
+	}
+	return msgs, evts, true
+}
+
+// verifyBlocks verifies that the blocks in which the given events were
+// emitted are still canonical. It returns the list of unique blocks sorted
+// by number. The second return value is false if any of the blocks was
+// orphaned.
+func (ep *EventProvider) verifyBlocks(ctx context.Context, evts []checkpoint.Event) ([]checkpoint.Block, bool, bool) {
+	var blocks []checkpoint.Block
+	for _, evt := range evts {
+		if len(blocks) > 0 && blocks[len(blocks)-1].Number == evt.Block.Number {
+			if blocks[len(blocks)-1].Hash != evt.Block.Hash {
+				return nil, false, true
 			}
-			ep.eventCh <- evt
+			continue
+		}
+		block, ok := ep.getBlock(ctx, bn.Int(evt.Block.Number))
+		if !ok {
+			return nil, false, false // Context was canceled.
+		}
+		if block.Hash != evt.Block.Hash {
+			return nil, false, true
+		}
+		blocks = append(blocks, evt.Block)
+	}
+	return blocks, true, true
+}
+
+// sendEvents sends the given events to the eventCh channel. It returns false
+// if the context was canceled.
+func (ep *EventProvider) sendEvents(ctx context.Context, evts []*messages.Event) bool {
+	for _, evt := range evts {
+		select {
+		case <-ctx.Done():
+			return false
+		case ep.eventCh <- evt:
 		}
 	}
+	return true
+}
+
+// checkpoint returns the last processed block of the given contract. The
+// second return value is false if checkpoints are disabled or if there is
+// no checkpoint for the contract.
+func (ep *EventProvider) checkpoint(address types.Address) (checkpoint.Block, bool) {
+	if ep.checkpoints == nil {
+		return checkpoint.Block{}, false
+	}
+	state, err := ep.checkpoints.Get(address.String())
+	if err != nil {
+		ep.log.WithError(err).Error("Unable to load checkpoint")
+		return checkpoint.Block{}, false
+	}
+	return state.Last()
 }
 
 // getBlockNumber returns the latest block number on the blockchain.
@@ -290,30 +554,30 @@ func (ep *EventProvider) getBlockNumber(ctx context.Context) (*big.Int, bool) {
 	return res, true
 }
 
-// getBlockNumber returns the latest block number on the blockchain.
+// getBlock returns the block with the given number.
 //
 // The method will try to fetch blocks indefinitely in case of an error.
 // The only way to stop this method from trying again is to cancel the
 // context. In that case, the method will return false as a second return
 // value.
-func (ep *EventProvider) getBlockTimestamp(ctx context.Context, block *bn.IntNumber) (time.Time, bool) {
+func (ep *EventProvider) getBlock(ctx context.Context, block *bn.IntNumber) (*types.Block, bool) {
 	var err error
-	var res any
+	var res *types.Block
 	retry.TryForever(
 		ctx,
 		func() error {
 			res, err = ep.client.Block(ethereum.WithBlockNumber(ctx, block.BigInt()))
 			if err != nil {
-				ep.log.WithError(err).Error("Unable to get block timestamp")
+				ep.log.WithError(err).Error("Unable to get block")
 			}
 			return err
 		},
 		retryInterval,
 	)
 	if res == nil || ctx.Err() != nil {
-		return time.Time{}, false
+		return nil, false
 	}
-	return res.(*types.Block).Timestamp, true
+	return res, true
 }
 
 // filterLogs fetches TeleportGUID events from the blockchain.
@@ -354,28 +618,3 @@ func (ep *EventProvider) filterLogs(
 	}
 	return res, true
 }
-
-// splitBlockRanges splits a block range into smaller ranges of at most
-// "limit" blocks. Some RPC providers have a limit on the number of blocks
-// that can be fetched in a single request and this method is used to
-// keep the number of blocks in each request below that limit.
-func splitBlockRanges(from, to, limit *bn.IntNumber) [][2]*bn.IntNumber {
-	if from.Cmp(to) > 0 {
-		return nil
-	}
-	if to.Sub(from).Cmp(limit) <= 0 {
-		return [][2]*bn.IntNumber{{from, to}}
-	}
-	var ranges [][2]*bn.IntNumber
-	rangeFrom := from
-	rangeTo := from
-	for rangeTo.Cmp(to) < 0 {
-		rangeTo = rangeFrom.Add(limit).Sub(bn.Int(1))
-		if rangeTo.Cmp(to) > 0 {
-			rangeTo = to
-		}
-		ranges = append(ranges, [2]*bn.IntNumber{rangeFrom, rangeTo})
-		rangeFrom = rangeTo.Add(bn.Int(1))
-	}
-	return ranges
-}
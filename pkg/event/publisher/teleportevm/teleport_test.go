@@ -29,6 +29,7 @@ import (
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum"
 	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/mocks"
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/publisher/checkpoint"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/errutil"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/ptrutil"
@@ -161,6 +162,187 @@ func Test_teleportEventProvider_PrefetchEventsRoutine(t *testing.T) {
 	waitForEvents(ctx, t, ep, 2)
 }
 
+func Test_teleportEventProvider_Reorg(t *testing.T) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFunc()
+
+	var (
+		orphanedHash  = types.MustHashFromHex("0x01", types.PadLeft)
+		canonicalHash = types.MustHashFromHex("0x02", types.PadLeft)
+		newHash       = types.MustHashFromHex("0x03", types.PadLeft)
+		txHash        = types.MustHashFromHex("0x66e8ab5a41d4b109c7f6ea5303e3c292771e57fb0b93a8474ca6f72e53eac0e8", types.PadNone)
+	)
+
+	// The checkpoint points to the block 99 that is going to be orphaned.
+	checkpoints := checkpoint.NewMemoryStore()
+	require.NoError(t, checkpoints.Set(teleportTestAddress.String(), checkpoint.State{
+		Blocks: []checkpoint.Block{{Number: 99, Hash: orphanedHash}},
+		Events: []checkpoint.Event{{
+			Block: checkpoint.Block{Number: 99, Hash: orphanedHash},
+			Type:  TeleportEventType,
+			ID:    []byte("orphaned"),
+			Index: []byte("index"),
+		}},
+	}))
+
+	cli := &mocks.Client{}
+	ep, err := New(Config{
+		Client:             cli,
+		Addresses:          []types.Address{teleportTestAddress},
+		Interval:           100 * time.Millisecond,
+		BlockLimit:         10,
+		BlockConfirmations: 1,
+		Checkpoints:        checkpoints,
+		Logger:             null.New(),
+	})
+	require.NoError(t, err)
+	ep.disablePrefetchEventsRoutine = true
+
+	logs := []types.Log{{
+		TransactionIndex: ptrutil.Ptr(uint64(1)),
+		Data:             teleportTestGUID,
+		TransactionHash:  &txHash,
+		BlockNumber:      big.NewInt(99),
+		BlockHash:        &canonicalHash,
+		Address:          teleportTestAddress,
+	}}
+	blockCall := func(number uint64, block *types.Block) {
+		cli.On("Block", mock.Anything).Run(func(args mock.Arguments) {
+			assert.Equal(t, number, ethereum.BlockNumberFromContext(args.Get(0).(context.Context)).Uint64())
+		}).Return(block, nil).Once()
+	}
+
+	cli.On("BlockNumber", ctx).Return(big.NewInt(105), nil)
+
+	// The parent of the block 100 does not match the checkpoint, and the
+	// block 99 was replaced, so the event from it must be retracted and
+	// the block 99 must be processed again.
+	blockCall(100, &types.Block{ParentHash: canonicalHash})
+	blockCall(99, &types.Block{Hash: canonicalHash})
+	cli.On("FilterLogs", ctx, mock.Anything).Return(logs, nil).Once().Run(func(args mock.Arguments) {
+		fq := args.Get(1).(types.FilterLogsQuery)
+		assert.Equal(t, uint64(99), fq.FromBlock.Big().Uint64())
+		assert.Equal(t, uint64(104), fq.ToBlock.Big().Uint64())
+	})
+	blockCall(104, &types.Block{Hash: newHash})
+	blockCall(99, &types.Block{Hash: canonicalHash})
+
+	require.NoError(t, ep.Start(ctx))
+
+	retraction := <-ep.Events()
+	assert.True(t, retraction.IsRetraction())
+	assert.Equal(t, []byte("orphaned"), retraction.ID)
+	assert.Equal(t, []byte("index"), retraction.Index)
+
+	evt := <-ep.Events()
+	assert.False(t, evt.IsRetraction())
+	assert.Equal(t, txHash.Bytes(), evt.Index)
+
+	waitFor(t, func() bool {
+		state, err := checkpoints.Get(teleportTestAddress.String())
+		require.NoError(t, err)
+		last, _ := state.Last()
+		return last.Number == 104 && last.Hash == newHash
+	})
+	state, err := checkpoints.Get(teleportTestAddress.String())
+	require.NoError(t, err)
+	require.Len(t, state.Events, 1)
+	assert.Equal(t, canonicalHash, state.Events[0].Block.Hash)
+
+	// The hash of the block with the event must be kept in the checkpoint.
+	assert.Equal(t, []checkpoint.Block{{Number: 99, Hash: canonicalHash}, {Number: 104, Hash: newHash}}, state.Blocks)
+}
+
+func Test_teleportEventProvider_ReorgDuringRange(t *testing.T) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFunc()
+
+	var (
+		orphanedHash  = types.MustHashFromHex("0x01", types.PadLeft)
+		canonicalHash = types.MustHashFromHex("0x02", types.PadLeft)
+		lastHash      = types.MustHashFromHex("0x03", types.PadLeft)
+		txHash        = types.MustHashFromHex("0x66e8ab5a41d4b109c7f6ea5303e3c292771e57fb0b93a8474ca6f72e53eac0e8", types.PadNone)
+	)
+
+	checkpoints := checkpoint.NewMemoryStore()
+	require.NoError(t, checkpoints.Set(teleportTestAddress.String(), checkpoint.State{
+		Blocks: []checkpoint.Block{{Number: 100, Hash: canonicalHash}},
+	}))
+
+	cli := &mocks.Client{}
+	ep, err := New(Config{
+		Client:             cli,
+		Addresses:          []types.Address{teleportTestAddress},
+		Interval:           100 * time.Millisecond,
+		BlockLimit:         10,
+		BlockConfirmations: 1,
+		Checkpoints:        checkpoints,
+		Logger:             null.New(),
+	})
+	require.NoError(t, err)
+	ep.disablePrefetchEventsRoutine = true
+
+	newLogs := func(hash types.Hash) []types.Log {
+		return []types.Log{{
+			TransactionIndex: ptrutil.Ptr(uint64(1)),
+			Data:             teleportTestGUID,
+			TransactionHash:  &txHash,
+			BlockNumber:      big.NewInt(102),
+			BlockHash:        &hash,
+			Address:          teleportTestAddress,
+		}}
+	}
+	blockCall := func(number uint64, block *types.Block) {
+		cli.On("Block", mock.Anything).Run(func(args mock.Arguments) {
+			assert.Equal(t, number, ethereum.BlockNumberFromContext(args.Get(0).(context.Context)).Uint64())
+		}).Return(block, nil).Once()
+	}
+
+	cli.On("BlockNumber", ctx).Return(big.NewInt(105), nil)
+
+	// The log is fetched from the block that is orphaned before the block
+	// hashes are verified, so the event must not be sent and the range
+	// must be processed again.
+	blockCall(101, &types.Block{ParentHash: canonicalHash})
+	cli.On("FilterLogs", ctx, mock.Anything).Return(newLogs(orphanedHash), nil).Once()
+	blockCall(104, &types.Block{Hash: lastHash})
+	blockCall(102, &types.Block{Hash: canonicalHash})
+
+	// Second attempt:
+	blockCall(101, &types.Block{ParentHash: canonicalHash})
+	cli.On("FilterLogs", ctx, mock.Anything).Return(newLogs(canonicalHash), nil).Once()
+	blockCall(104, &types.Block{Hash: lastHash})
+	blockCall(102, &types.Block{Hash: canonicalHash})
+
+	require.NoError(t, ep.Start(ctx))
+
+	// The event must be sent only after the second attempt.
+	evt := <-ep.Events()
+	assert.False(t, evt.IsRetraction())
+	cli.AssertExpectations(t)
+
+	waitFor(t, func() bool {
+		state, err := checkpoints.Get(teleportTestAddress.String())
+		require.NoError(t, err)
+		last, _ := state.Last()
+		return last.Number == 104
+	})
+	state, err := checkpoints.Get(teleportTestAddress.String())
+	require.NoError(t, err)
+	require.Len(t, state.Events, 1)
+	assert.Equal(t, canonicalHash, state.Events[0].Block.Hash)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Fail(t, "condition not met")
+}
+
 func waitForEvents(ctx context.Context, t *testing.T, ep *EventProvider, expectedEvents int) {
 	events := 0
 loop:
@@ -0,0 +1,341 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package archive provides tools to export events from a store.Storage to
+// a file and to import them back.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+// Format is the format of the archive file.
+type Format string
+
+const (
+	// FormatJSON is a JSON-lines format, one event per line.
+	FormatJSON Format = "jsonl"
+
+	// FormatProtobuf is a stream of events encoded using protobuf, each
+	// prefixed with its size encoded as a varint.
+	FormatProtobuf Format = "protobuf"
+)
+
+// maxProtobufMessageSize is the maximum size of a single protobuf encoded
+// event.
+const maxProtobufMessageSize = 16 << 20
+
+// Writer writes events to an archive.
+type Writer interface {
+	Write(evt *messages.Event) error
+}
+
+// Reader reads events from an archive. The Read method returns io.EOF if
+// there are no more events.
+type Reader interface {
+	Read() (*messages.Event, error)
+}
+
+// NewWriter returns a new Writer for the given format.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatProtobuf:
+		return &protobufWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("archive: unsupported format: %s", format)
+	}
+}
+
+// NewReader returns a new Reader for the given format.
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonReader{dec: json.NewDecoder(r)}, nil
+	case FormatProtobuf:
+		return &protobufReader{r: bufio.NewReader(r)}, nil
+	default:
+		return nil, fmt.Errorf("archive: unsupported format: %s", format)
+	}
+}
+
+// Export writes events matching the query to the writer and returns the
+// number of written events.
+//
+// If the storage implements the store.Querier interface, it is used to
+// find events. Otherwise, the type and index of the query must be set.
+func Export(ctx context.Context, s store.Storage, q store.Query, w Writer) (int, error) {
+	var (
+		evts []*messages.Event
+		err  error
+	)
+	if querier, ok := s.(store.Querier); ok {
+		evts, err = querier.Query(ctx, q)
+	} else {
+		if q.Type == "" || q.Index == nil {
+			return 0, errors.New("archive: storage does not support range queries, type and index must be specified")
+		}
+		evts, err = s.Get(ctx, q.Type, q.Index)
+		if err == nil {
+			evts = filterEvents(evts, q)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	for n, evt := range evts {
+		if err := w.Write(evt); err != nil {
+			return n, err
+		}
+	}
+	return len(evts), nil
+}
+
+// Import reads all events from the reader, verifies their signatures and
+// adds them to the storage. If fn is not nil, it is called for every
+// imported event.
+//
+// Because authors of events are not stored in the archive, an event is
+// stored once for every signature, under the address of the signer and with
+// only that signature attached, the same way it would be stored if it was
+// received from the signer. Retractions remove the retracted event of every
+// signer instead of being stored, as the EventStore does for retractions
+// received from the transport. Import fails if the event is not signed or if
+// any of its signatures does not match the signer.
+func Import(ctx context.Context, s store.Storage, r Reader, fn func(evt *messages.Event) error) (int, error) {
+	n := 0
+	for {
+		evt, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if len(evt.Signatures) == 0 {
+			return n, fmt.Errorf("archive: event %x is not signed", evt.ID)
+		}
+		for name, sig := range evt.Signatures {
+			if err := verifySignature(evt, sig); err != nil {
+				return n, fmt.Errorf("archive: invalid %s signature of event %x: %w", name, evt.ID, err)
+			}
+		}
+		for name, sig := range evt.Signatures {
+			if evt.IsRetraction() {
+				if err := s.Remove(ctx, sig.Signer, evt.Type, evt.Index, evt.ID); err != nil {
+					return n, err
+				}
+				continue
+			}
+			signed := evt.Copy()
+			signed.Signatures = map[string]messages.EventSignature{name: sig}
+			if _, err := s.Add(ctx, sig.Signer, signed); err != nil {
+				return n, err
+			}
+		}
+		if fn != nil {
+			if err := fn(evt); err != nil {
+				return n, err
+			}
+		}
+		n++
+	}
+}
+
+// verifySignature verifies that the signature was created by its signer.
+// Events are signed using the Ethereum signature of the "hash" data field,
+// and retractions using the hash returned by the RetractionHash method.
+func verifySignature(evt *messages.Event, sig messages.EventSignature) error {
+	var hash []byte
+	if evt.IsRetraction() {
+		hash = evt.RetractionHash().Bytes()
+	} else {
+		var ok bool
+		if hash, ok = evt.Data["hash"]; !ok {
+			return errors.New("missing hash field")
+		}
+	}
+	s, err := types.SignatureFromBytes(sig.Signature)
+	if err != nil {
+		return err
+	}
+	addr, err := crypto.ECRecoverer.RecoverMessage(hash, s)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(addr.Bytes(), sig.Signer) {
+		return fmt.Errorf("signature was created by %s, not by %x", addr, sig.Signer)
+	}
+	return nil
+}
+
+func filterEvents(evts []*messages.Event, q store.Query) []*messages.Event {
+	var res []*messages.Event
+	for _, evt := range evts {
+		if q.Match(evt) {
+			res = append(res, evt)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].EventDate.Before(res[j].EventDate)
+	})
+	if q.Limit > 0 && len(res) > q.Limit {
+		res = res[:q.Limit]
+	}
+	return res
+}
+
+// jsonEvent is the JSON representation of an event. Binary values are hex
+// encoded.
+type jsonEvent struct {
+	Type        string                   `json:"type"`
+	ID          string                   `json:"id"`
+	Index       string                   `json:"index"`
+	EventDate   time.Time                `json:"event_date"`
+	MessageDate time.Time                `json:"message_date"`
+	Data        map[string]string        `json:"data"`
+	Signatures  map[string]jsonSignature `json:"signatures"`
+}
+
+type jsonSignature struct {
+	Signer    string `json:"signer"`
+	Signature string `json:"signature"`
+}
+
+type jsonWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonWriter) Write(evt *messages.Event) error {
+	j := jsonEvent{
+		Type:        evt.Type,
+		ID:          hex.EncodeToString(evt.ID),
+		Index:       hex.EncodeToString(evt.Index),
+		EventDate:   evt.EventDate.UTC(),
+		MessageDate: evt.MessageDate.UTC(),
+		Data:        make(map[string]string, len(evt.Data)),
+		Signatures:  make(map[string]jsonSignature, len(evt.Signatures)),
+	}
+	for k, v := range evt.Data {
+		j.Data[k] = hex.EncodeToString(v)
+	}
+	for k, v := range evt.Signatures {
+		j.Signatures[k] = jsonSignature{
+			Signer:    hex.EncodeToString(v.Signer),
+			Signature: hex.EncodeToString(v.Signature),
+		}
+	}
+	return w.enc.Encode(j)
+}
+
+type jsonReader struct {
+	dec *json.Decoder
+}
+
+func (r *jsonReader) Read() (*messages.Event, error) {
+	var j jsonEvent
+	if err := r.dec.Decode(&j); err != nil {
+		return nil, err
+	}
+	var err error
+	evt := &messages.Event{
+		Type:        j.Type,
+		EventDate:   j.EventDate,
+		MessageDate: j.MessageDate,
+		Data:        make(map[string][]byte, len(j.Data)),
+		Signatures:  make(map[string]messages.EventSignature, len(j.Signatures)),
+	}
+	if evt.ID, err = hex.DecodeString(j.ID); err != nil {
+		return nil, fmt.Errorf("archive: invalid event ID: %w", err)
+	}
+	if evt.Index, err = hex.DecodeString(j.Index); err != nil {
+		return nil, fmt.Errorf("archive: invalid event index: %w", err)
+	}
+	for k, v := range j.Data {
+		if evt.Data[k], err = hex.DecodeString(v); err != nil {
+			return nil, fmt.Errorf("archive: invalid event data: %w", err)
+		}
+	}
+	for k, v := range j.Signatures {
+		var s messages.EventSignature
+		if s.Signer, err = hex.DecodeString(v.Signer); err != nil {
+			return nil, fmt.Errorf("archive: invalid signer: %w", err)
+		}
+		if s.Signature, err = hex.DecodeString(v.Signature); err != nil {
+			return nil, fmt.Errorf("archive: invalid signature: %w", err)
+		}
+		evt.Signatures[k] = s
+	}
+	return evt, nil
+}
+
+type protobufWriter struct {
+	w io.Writer
+}
+
+func (w *protobufWriter) Write(evt *messages.Event) error {
+	b, err := evt.MarshallBinary()
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.Write(binary.AppendUvarint(nil, uint64(len(b)))); err != nil {
+		return err
+	}
+	_, err = w.w.Write(b)
+	return err
+}
+
+type protobufReader struct {
+	r *bufio.Reader
+}
+
+func (r *protobufReader) Read() (*messages.Event, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxProtobufMessageSize {
+		return nil, fmt.Errorf("archive: event size %d exceeds the limit", size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	evt := &messages.Event{}
+	if err := evt.UnmarshallBinary(b); err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
@@ -0,0 +1,260 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package archive
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+// getOnlyStorage hides the Query method of the underlying storage.
+type getOnlyStorage struct {
+	store.Storage
+}
+
+var (
+	testKey1 = wallet.NewRandomKey()
+	testKey2 = wallet.NewRandomKey()
+)
+
+func testSignature(t *testing.T, key wallet.Key, hash []byte) messages.EventSignature {
+	s, err := key.SignMessage(hash)
+	require.NoError(t, err)
+	return messages.EventSignature{Signer: key.Address().Bytes(), Signature: s.Bytes()}
+}
+
+func testEvents(t *testing.T) []*messages.Event {
+	now := time.Unix(time.Now().Unix(), 0)
+	return []*messages.Event{
+		{
+			Type:        "test",
+			ID:          []byte("id1"),
+			Index:       []byte("idx1"),
+			EventDate:   now.Add(-2 * time.Minute),
+			MessageDate: now,
+			Data:        map[string][]byte{"hash": []byte("hash1")},
+			Signatures:  map[string]messages.EventSignature{"ethereum": testSignature(t, testKey1, []byte("hash1"))},
+		},
+		{
+			Type:        "test",
+			ID:          []byte("id2"),
+			Index:       []byte("idx2"),
+			EventDate:   now.Add(-time.Minute),
+			MessageDate: now,
+			Data:        map[string][]byte{"hash": []byte("hash2")},
+			Signatures:  map[string]messages.EventSignature{"ethereum": testSignature(t, testKey2, []byte("hash2"))},
+		},
+	}
+}
+
+func TestArchive_roundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatProtobuf} {
+		t.Run(string(format), func(t *testing.T) {
+			ctx := context.Background()
+			src := store.NewMemoryStorage(time.Hour)
+			evts := testEvents(t)
+			for _, evt := range evts {
+				_, err := src.Add(ctx, []byte("author"), evt)
+				require.NoError(t, err)
+			}
+
+			buf := &bytes.Buffer{}
+			w, err := NewWriter(buf, format)
+			require.NoError(t, err)
+			n, err := Export(ctx, src, store.Query{Type: "test"}, w)
+			require.NoError(t, err)
+			assert.Equal(t, 2, n)
+
+			dst := store.NewMemoryStorage(time.Hour)
+			r, err := NewReader(buf, format)
+			require.NoError(t, err)
+			var imported []*messages.Event
+			n, err = Import(ctx, dst, r, func(evt *messages.Event) error {
+				imported = append(imported, evt)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 2, n)
+
+			for i, evt := range evts {
+				assert.Equal(t, evt.ID, imported[i].ID)
+				assert.Equal(t, evt.Index, imported[i].Index)
+				assert.Equal(t, evt.EventDate.Unix(), imported[i].EventDate.Unix())
+				assert.Equal(t, evt.MessageDate.Unix(), imported[i].MessageDate.Unix())
+				assert.Equal(t, evt.Data, imported[i].Data)
+				assert.Equal(t, evt.Signatures, imported[i].Signatures)
+
+				// Events must be stored under the signer's address:
+				es, err := dst.Get(ctx, evt.Type, evt.Index)
+				require.NoError(t, err)
+				require.Len(t, es, 1)
+				assert.Equal(t, evt.Signatures, es[0].Signatures)
+			}
+		})
+	}
+}
+
+func TestExport_withoutQuerier(t *testing.T) {
+	ctx := context.Background()
+	mem := store.NewMemoryStorage(time.Hour)
+	evts := testEvents(t)
+	for _, evt := range evts {
+		_, err := mem.Add(ctx, []byte("author"), evt)
+		require.NoError(t, err)
+	}
+	s := getOnlyStorage{Storage: mem}
+	w, err := NewWriter(&bytes.Buffer{}, FormatJSON)
+	require.NoError(t, err)
+
+	// Type and index are required if the storage does not support queries.
+	_, err = Export(ctx, s, store.Query{Type: "test"}, w)
+	assert.Error(t, err)
+
+	n, err := Export(ctx, s, store.Query{Type: "test", Index: []byte("idx1")}, w)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = Export(ctx, s, store.Query{Type: "test", Index: []byte("idx1"), From: evts[1].EventDate}, w)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestImport_multipleSigners(t *testing.T) {
+	ctx := context.Background()
+	evt := testEvents(t)[0]
+	evt.Signatures["other"] = testSignature(t, testKey2, evt.Data["hash"])
+
+	dst := store.NewMemoryStorage(time.Hour)
+	n, err := Import(ctx, dst, &sliceReader{evts: []*messages.Event{evt}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// The event must be stored separately for every signer, with only the
+	// signer's signature attached:
+	es, err := dst.Get(ctx, evt.Type, evt.Index)
+	require.NoError(t, err)
+	require.Len(t, es, 2)
+	for _, e := range es {
+		require.Len(t, e.Signatures, 1)
+		for name, sig := range e.Signatures {
+			assert.Equal(t, evt.Signatures[name], sig)
+		}
+	}
+}
+
+func TestImport_retraction(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(t)
+	evt := evts[0]
+	evt.Signatures["other"] = testSignature(t, testKey2, evt.Data["hash"])
+	retraction := messages.NewEventRetraction(evt.Type, evt.ID, evt.Index)
+	retraction.Signatures = map[string]messages.EventSignature{
+		"ethereum": testSignature(t, testKey1, retraction.RetractionHash().Bytes()),
+	}
+
+	dst := store.NewMemoryStorage(time.Hour)
+	n, err := Import(ctx, dst, &sliceReader{evts: []*messages.Event{evt, evts[1], retraction}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// Only the event of the retracting signer must be removed, the
+	// retraction itself must not be stored:
+	es, err := dst.Get(ctx, evt.Type, evt.Index)
+	require.NoError(t, err)
+	require.Len(t, es, 1)
+	assert.Contains(t, es[0].Signatures, "other")
+	assert.False(t, es[0].IsRetraction())
+
+	// Other events are not affected:
+	es, err = dst.Get(ctx, evts[1].Type, evts[1].Index)
+	require.NoError(t, err)
+	assert.Len(t, es, 1)
+}
+
+func TestImport_invalidSignature(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		modify func(evt *messages.Event)
+	}{
+		{
+			name:   "not signed",
+			modify: func(evt *messages.Event) { evt.Signatures = nil },
+		},
+		{
+			name: "different signer",
+			modify: func(evt *messages.Event) {
+				sig := evt.Signatures["ethereum"]
+				sig.Signer = testKey2.Address().Bytes()
+				evt.Signatures["ethereum"] = sig
+			},
+		},
+		{
+			name:   "modified data",
+			modify: func(evt *messages.Event) { evt.Data["hash"] = []byte("other") },
+		},
+		{
+			name:   "missing hash",
+			modify: func(evt *messages.Event) { delete(evt.Data, "hash") },
+		},
+		{
+			name: "malformed signature",
+			modify: func(evt *messages.Event) {
+				sig := evt.Signatures["ethereum"]
+				sig.Signature = []byte("sig")
+				evt.Signatures["ethereum"] = sig
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt := testEvents(t)[0]
+			tt.modify(evt)
+
+			dst := store.NewMemoryStorage(time.Hour)
+			n, err := Import(ctx, dst, &sliceReader{evts: []*messages.Event{evt}}, nil)
+			assert.Error(t, err)
+			assert.Equal(t, 0, n)
+
+			es, err := dst.Get(ctx, evt.Type, evt.Index)
+			require.NoError(t, err)
+			assert.Empty(t, es)
+		})
+	}
+}
+
+type sliceReader struct {
+	evts []*messages.Event
+}
+
+func (r *sliceReader) Read() (*messages.Event, error) {
+	if len(r.evts) == 0 {
+		return nil, io.EOF
+	}
+	evt := r.evts[0]
+	r.evts = r.evts[1:]
+	return evt, nil
+}
@@ -18,6 +18,7 @@ package store
 import (
 	"context"
 	"crypto/sha256"
+	"sort"
 	"sync"
 	"time"
 
@@ -79,6 +80,43 @@ func (m *MemoryStorage) Get(_ context.Context, typ string, idx []byte) ([]*messa
 	return nil, nil
 }
 
+// Query implements the store.Querier interface.
+func (m *MemoryStorage) Query(_ context.Context, q Query) ([]*messages.Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var evts []*messages.Event
+	for _, idx := range m.index {
+		for _, evt := range idx {
+			if q.Match(evt) {
+				evts = append(evts, evt)
+			}
+		}
+	}
+	sort.Slice(evts, func(i, j int) bool {
+		return evts[i].EventDate.Before(evts[j].EventDate)
+	})
+	if q.Limit > 0 && len(evts) > q.Limit {
+		evts = evts[:q.Limit]
+	}
+	return evts, nil
+}
+
+// Remove implements the store.Storage interface.
+func (m *MemoryStorage) Remove(_ context.Context, author []byte, typ string, idx []byte, id []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hi := hashIndex(typ, idx)
+	hu := hashUnique(author, id)
+	if _, ok := m.index[hi]; !ok {
+		return nil
+	}
+	delete(m.index[hi], hu)
+	if len(m.index[hi]) == 0 {
+		delete(m.index, hi)
+	}
+	return nil
+}
+
 // Garbage Collector removes expired messages.
 func (m *MemoryStorage) gc() {
 	m.gccount++
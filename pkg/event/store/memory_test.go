@@ -117,6 +117,33 @@ func TestMemory_Add_replacePreviousEvent(t *testing.T) {
 	assert.ElementsMatch(t, []*messages.Event{e2}, es)
 }
 
+func TestMemory_Remove(t *testing.T) {
+	m := NewMemoryStorage(time.Minute)
+	e1 := &messages.Event{
+		Type:        "test",
+		ID:          []byte("test"),
+		Index:       []byte("idx"),
+		MessageDate: time.Now(),
+		EventDate:   time.Now(),
+		Data:        map[string][]byte{"test": []byte("test")},
+		Signatures:  map[string]messages.EventSignature{},
+	}
+
+	_, err := m.Add(context.Background(), []byte("author1"), e1)
+	assert.NoError(t, err)
+
+	// Events published by other authors must not be removed.
+	assert.NoError(t, m.Remove(context.Background(), []byte("author2"), "test", []byte("idx"), []byte("test")))
+	es, err := m.Get(context.Background(), "test", []byte("idx"))
+	assert.NoError(t, err)
+	assert.Len(t, es, 1)
+
+	assert.NoError(t, m.Remove(context.Background(), []byte("author1"), "test", []byte("idx"), []byte("test")))
+	es, err = m.Get(context.Background(), "test", []byte("idx"))
+	assert.NoError(t, err)
+	assert.Len(t, es, 0)
+}
+
 func TestMemory_gc(t *testing.T) {
 	m := NewMemoryStorage(time.Minute)
 	_, err := m.Add(context.Background(), []byte("author"), &messages.Event{
@@ -168,3 +195,42 @@ func TestMemory_gc_allExpired(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, es, 0)
 }
+
+func TestMemory_Query(t *testing.T) {
+	m := NewMemoryStorage(time.Hour)
+	now := time.Now()
+	e1 := &messages.Event{
+		Type:       "test",
+		ID:         []byte("e1"),
+		Index:      []byte("idx1"),
+		EventDate:  now.Add(-2 * time.Minute),
+		Signatures: map[string]messages.EventSignature{"ethereum": {Signer: []byte("signer1")}},
+	}
+	e2 := &messages.Event{
+		Type:       "test",
+		ID:         []byte("e2"),
+		Index:      []byte("idx2"),
+		EventDate:  now.Add(-time.Minute),
+		Signatures: map[string]messages.EventSignature{"ethereum": {Signer: []byte("signer2")}},
+	}
+	_, err := m.Add(context.Background(), []byte("author"), e2)
+	assert.NoError(t, err)
+	_, err = m.Add(context.Background(), []byte("author"), e1)
+	assert.NoError(t, err)
+
+	es, err := m.Query(context.Background(), Query{Type: "test"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*messages.Event{e1, e2}, es)
+
+	es, err = m.Query(context.Background(), Query{Signer: []byte("signer2")})
+	assert.NoError(t, err)
+	assert.Equal(t, []*messages.Event{e2}, es)
+
+	es, err = m.Query(context.Background(), Query{To: e2.EventDate})
+	assert.NoError(t, err)
+	assert.Equal(t, []*messages.Event{e1}, es)
+
+	es, err = m.Query(context.Background(), Query{Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*messages.Event{e1}, es)
+}
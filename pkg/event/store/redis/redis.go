@@ -235,6 +235,33 @@ func (r *Storage) Get(ctx context.Context, typ string, idx []byte) ([]*messages.
 	return evts, err
 }
 
+// Remove implements the store.Storage interface.
+func (r *Storage) Remove(ctx context.Context, author []byte, typ string, idx []byte, id []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := evtKey(typ, idx, author, id)
+	// The transaction is used for the same reason as in the Add method, the
+	// memory usage must be decreased only if the key is actually removed.
+	return r.redisWatch(ctx, func(tx *redis.Tx) error {
+		prevValCmd := r.client.Get(ctx, key)
+		switch prevValCmd.Err() {
+		case nil: // No error, the key exists.
+			prevEvt := &messages.Event{}
+			if err := prevEvt.UnmarshallBinary([]byte(prevValCmd.Val())); err != nil {
+				return fmt.Errorf("redis: failed to unmarshal event: %w", err)
+			}
+			if err := r.incrMemUsage(ctx, tx, author, -len(prevValCmd.Val()), prevEvt.EventDate); err != nil {
+				return err
+			}
+			tx.Del(ctx, key)
+		case redis.Nil: // The key does not exist.
+		default:
+			return cmdError{cmd: prevValCmd}
+		}
+		return nil
+	}, key)
+}
+
 // getAvailMem returns the available memory for the given author.
 //
 // Finds all the memory usage keys for given author and sums them up. The exact
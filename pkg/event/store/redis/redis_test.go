@@ -140,6 +140,41 @@ func TestRedis_Add_replacePreviousEvent(t *testing.T) {
 	assert.ElementsMatch(t, eventsToByteSlices([]*messages.Event{e2}), eventsToByteSlices(es))
 }
 
+func TestRedis_Remove(t *testing.T) {
+	ok, cfg := getConfig()
+	if !ok {
+		t.Skip()
+		return
+	}
+	typ := strconv.Itoa(rand.Int())
+	author := strconv.Itoa(rand.Int())
+	r, err := New(cfg)
+	require.NoError(t, err)
+	e1 := &messages.Event{
+		Type:        typ,
+		ID:          []byte("test"),
+		Index:       []byte("idx"),
+		MessageDate: time.Now(),
+		EventDate:   time.Now(),
+		Data:        map[string][]byte{"test": []byte("test")},
+		Signatures:  map[string]messages.EventSignature{},
+	}
+
+	_, err = r.Add(context.Background(), []byte(author), e1)
+	assert.NoError(t, err)
+
+	// Events published by other authors must not be removed.
+	assert.NoError(t, r.Remove(context.Background(), []byte(author+"2"), typ, []byte("idx"), []byte("test")))
+	es, err := r.Get(context.Background(), typ, []byte("idx"))
+	assert.NoError(t, err)
+	assert.Len(t, es, 1)
+
+	assert.NoError(t, r.Remove(context.Background(), []byte(author), typ, []byte("idx"), []byte("test")))
+	es, err = r.Get(context.Background(), typ, []byte("idx"))
+	assert.NoError(t, err)
+	assert.Len(t, es, 0)
+}
+
 func TestRedis_memoryLimit(t *testing.T) {
 	ok, cfg := getConfig()
 	cfg.MemoryLimit = 60 // 60 is enough for one message
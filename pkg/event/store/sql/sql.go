@@ -0,0 +1,414 @@
+//  Copyright (C) 2020 Maker Ecosystem Growth Holdings, INC.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	// Database drivers:
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+const (
+	DriverSQLite   = "sqlite3"
+	DriverPostgres = "postgres"
+)
+
+// sqliteDriverName is the name under which the pure-Go SQLite driver is
+// registered. The "sqlite3" name is kept in the configuration for
+// compatibility with existing configs.
+const sqliteDriverName = "sqlite"
+
+// defaultCleanupInterval is the default interval between removals of
+// expired events.
+const defaultCleanupInterval = time.Minute
+
+// schema is the database schema. It must be compatible with both SQLite and
+// PostgreSQL. Binary values are stored as hex encoded strings and dates are
+// stored as UNIX timestamps.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS events (
+		type         TEXT   NOT NULL,
+		idx          TEXT   NOT NULL,
+		id           TEXT   NOT NULL,
+		author       TEXT   NOT NULL,
+		event_date   BIGINT NOT NULL,
+		message_date BIGINT NOT NULL,
+		data         TEXT   NOT NULL,
+		PRIMARY KEY (type, idx, id, author)
+	)`,
+	`CREATE INDEX IF NOT EXISTS events_event_date ON events (event_date)`,
+	`CREATE TABLE IF NOT EXISTS signatures (
+		type      TEXT NOT NULL,
+		idx       TEXT NOT NULL,
+		id        TEXT NOT NULL,
+		author    TEXT NOT NULL,
+		name      TEXT NOT NULL,
+		signer    TEXT NOT NULL,
+		signature TEXT NOT NULL,
+		PRIMARY KEY (type, idx, id, author, name)
+	)`,
+	`CREATE INDEX IF NOT EXISTS signatures_signer ON signatures (signer)`,
+}
+
+// Storage provides storage mechanism for store.EventStore.
+// It uses an SQL database to store events. SQLite and PostgreSQL databases
+// are supported.
+type Storage struct {
+	mu sync.Mutex
+
+	db              *sql.DB
+	ttl             time.Duration
+	typeTTL         map[string]time.Duration
+	cleanupInterval time.Duration
+	lastCleanup     time.Time
+}
+
+// Config is the configuration for the Storage.
+type Config struct {
+	// Driver is the name of the database driver, either "sqlite3" or
+	// "postgres".
+	Driver string
+	// DSN is the data source name, e.g. a path to the SQLite database file
+	// or a PostgreSQL connection string.
+	DSN string
+	// TTL specifies how long messages should be kept in storage.
+	TTL time.Duration
+	// TypeTTL overrides the TTL for specific event types.
+	TypeTTL map[string]time.Duration
+	// CleanupInterval specifies how often expired events are removed from
+	// the database. Defaults to one minute.
+	CleanupInterval time.Duration
+}
+
+// New returns a new instance of Storage. It creates the database schema if
+// it does not exist.
+func New(cfg Config) (*Storage, error) {
+	if cfg.Driver != DriverSQLite && cfg.Driver != DriverPostgres {
+		return nil, fmt.Errorf("sql: unsupported driver: %s", cfg.Driver)
+	}
+	if cfg.TTL <= 0 {
+		return nil, errors.New("sql: TTL must be greater than zero")
+	}
+	if cfg.CleanupInterval == 0 {
+		cfg.CleanupInterval = defaultCleanupInterval
+	}
+	driverName := cfg.Driver
+	if driverName == DriverSQLite {
+		driverName = sqliteDriverName
+	}
+	db, err := sql.Open(driverName, cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Driver == DriverSQLite {
+		// SQLite does not support concurrent writes, and each connection to
+		// an in-memory database opens a separate database.
+		db.SetMaxOpenConns(1)
+	}
+	for _, q := range schema {
+		if _, err := db.Exec(q); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("sql: unable to create the schema: %w", err)
+		}
+	}
+	return &Storage{
+		db:              db,
+		ttl:             cfg.TTL,
+		typeTTL:         cfg.TypeTTL,
+		cleanupInterval: cfg.CleanupInterval,
+		lastCleanup:     time.Now(),
+	}, nil
+}
+
+// Ping checks if the database is available.
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Close closes the database.
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+// Add implements the store.Storage interface.
+func (s *Storage) Add(ctx context.Context, author []byte, evt *messages.Event) (isNew bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.cleanup(ctx); err != nil {
+		return false, err
+	}
+	data, err := json.Marshal(evt.Data)
+	if err != nil {
+		return false, err
+	}
+	key := []any{evt.Type, hex.EncodeToString(evt.Index), hex.EncodeToString(evt.ID), hex.EncodeToString(author)}
+	err = s.tx(ctx, func(tx *sql.Tx) error {
+		var messageDate int64
+		err := tx.QueryRowContext(
+			ctx,
+			`SELECT message_date FROM events WHERE type = $1 AND idx = $2 AND id = $3 AND author = $4`,
+			key...,
+		).Scan(&messageDate)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			isNew = true
+			_, err = tx.ExecContext(
+				ctx,
+				`INSERT INTO events (type, idx, id, author, event_date, message_date, data) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				append(key, evt.EventDate.Unix(), evt.MessageDate.UnixNano(), string(data))...,
+			)
+		case err != nil:
+			return err
+		case messageDate < evt.MessageDate.UnixNano():
+			_, err = tx.ExecContext(
+				ctx,
+				`UPDATE events SET event_date = $1, message_date = $2, data = $3 WHERE type = $4 AND idx = $5 AND id = $6 AND author = $7`,
+				append([]any{evt.EventDate.Unix(), evt.MessageDate.UnixNano(), string(data)}, key...)...,
+			)
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(
+				ctx,
+				`DELETE FROM signatures WHERE type = $1 AND idx = $2 AND id = $3 AND author = $4`,
+				key...,
+			)
+		default:
+			// Stored message is newer or the same.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for name, sig := range evt.Signatures {
+			_, err = tx.ExecContext(
+				ctx,
+				`INSERT INTO signatures (type, idx, id, author, name, signer, signature) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				append(key, name, hex.EncodeToString(sig.Signer), hex.EncodeToString(sig.Signature))...,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return isNew, err
+}
+
+// Get implements the store.Storage interface.
+func (s *Storage) Get(ctx context.Context, typ string, idx []byte) ([]*messages.Event, error) {
+	return s.Query(ctx, store.Query{Type: typ, Index: idx})
+}
+
+// Remove implements the store.Storage interface.
+func (s *Storage) Remove(ctx context.Context, author []byte, typ string, idx []byte, id []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := []any{typ, hex.EncodeToString(idx), hex.EncodeToString(id), hex.EncodeToString(author)}
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(
+			ctx,
+			`DELETE FROM events WHERE type = $1 AND idx = $2 AND id = $3 AND author = $4`,
+			key...,
+		); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			`DELETE FROM signatures WHERE type = $1 AND idx = $2 AND id = $3 AND author = $4`,
+			key...,
+		)
+		return err
+	})
+}
+
+// Query implements the store.Querier interface. Expired events are not
+// returned.
+func (s *Storage) Query(ctx context.Context, q store.Query) ([]*messages.Event, error) {
+	var (
+		where []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if q.Type != "" {
+		where = append(where, "e.type = "+arg(q.Type))
+	}
+	if q.Index != nil {
+		where = append(where, "e.idx = "+arg(hex.EncodeToString(q.Index)))
+	}
+	if !q.From.IsZero() {
+		where = append(where, "e.event_date >= "+arg(q.From.Unix()))
+	}
+	if !q.To.IsZero() {
+		where = append(where, "e.event_date < "+arg(q.To.Unix()))
+	}
+	if q.Signer != nil {
+		where = append(where, `EXISTS (
+			SELECT 1 FROM signatures s
+			WHERE s.type = e.type AND s.idx = e.idx AND s.id = e.id AND s.author = e.author AND s.signer = `+arg(hex.EncodeToString(q.Signer))+`
+		)`)
+	}
+	events := `SELECT * FROM events e`
+	if len(where) > 0 {
+		events += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	events += ` ORDER BY e.event_date, e.message_date, e.type, e.idx, e.id, e.author`
+	if q.Limit > 0 {
+		events += ` LIMIT ` + arg(q.Limit)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.type, e.idx, e.id, e.author, e.event_date, e.message_date, e.data, s.name, s.signer, s.signature
+		FROM (`+events+`) e
+		LEFT JOIN signatures s ON s.type = e.type AND s.idx = e.idx AND s.id = e.id AND s.author = e.author
+		ORDER BY e.event_date, e.message_date, e.type, e.idx, e.id, e.author`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var (
+		evts    []*messages.Event
+		lastKey string
+	)
+	for rows.Next() {
+		var (
+			typ, idx, id, author string
+			eventDate, msgDate   int64
+			data                 string
+			name, signer, sig    sql.NullString
+		)
+		if err := rows.Scan(&typ, &idx, &id, &author, &eventDate, &msgDate, &data, &name, &signer, &sig); err != nil {
+			return nil, err
+		}
+		key := typ + "/" + idx + "/" + id + "/" + author
+		if key != lastKey {
+			lastKey = key
+			evt := &messages.Event{
+				Type:        typ,
+				EventDate:   time.Unix(eventDate, 0),
+				MessageDate: time.Unix(0, msgDate),
+				Signatures:  map[string]messages.EventSignature{},
+			}
+			if evt.Index, err = hex.DecodeString(idx); err != nil {
+				return nil, err
+			}
+			if evt.ID, err = hex.DecodeString(id); err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal([]byte(data), &evt.Data); err != nil {
+				return nil, err
+			}
+			evts = append(evts, evt)
+		}
+		if name.Valid {
+			var es messages.EventSignature
+			if es.Signer, err = hex.DecodeString(signer.String); err != nil {
+				return nil, err
+			}
+			if es.Signature, err = hex.DecodeString(sig.String); err != nil {
+				return nil, err
+			}
+			evts[len(evts)-1].Signatures[name.String] = es
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var res []*messages.Event
+	for _, evt := range evts {
+		if !s.isExpired(evt.Type, evt.EventDate) {
+			res = append(res, evt)
+		}
+	}
+	return res, nil
+}
+
+// cleanup removes expired events. To reduce the database load, it does
+// nothing if it was called less than the cleanup interval ago.
+func (s *Storage) cleanup(ctx context.Context) error {
+	if time.Since(s.lastCleanup) < s.cleanupInterval {
+		return nil
+	}
+	s.lastCleanup = time.Now()
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		var (
+			types []string
+			args  = []any{time.Now().Add(-s.ttl).Unix()}
+		)
+		for typ, ttl := range s.typeTTL {
+			if _, err := tx.ExecContext(
+				ctx,
+				`DELETE FROM events WHERE type = $1 AND event_date < $2`,
+				typ, time.Now().Add(-ttl).Unix(),
+			); err != nil {
+				return err
+			}
+			args = append(args, typ)
+			types = append(types, fmt.Sprintf("$%d", len(args)))
+		}
+		query := `DELETE FROM events WHERE event_date < $1`
+		if len(types) > 0 {
+			query += ` AND type NOT IN (` + strings.Join(types, ", ") + `)`
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			DELETE FROM signatures WHERE NOT EXISTS (
+				SELECT 1 FROM events e
+				WHERE e.type = signatures.type AND e.idx = signatures.idx AND e.id = signatures.id AND e.author = signatures.author
+			)`,
+		)
+		return err
+	})
+}
+
+func (s *Storage) isExpired(typ string, eventDate time.Time) bool {
+	ttl, ok := s.typeTTL[typ]
+	if !ok {
+		ttl = s.ttl
+	}
+	return time.Since(eventDate) > ttl
+}
+
+func (s *Storage) tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,199 @@
+//  Copyright (C) 2020 Maker Ecosystem Growth Holdings, INC.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sql
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/event/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+func newTestStorage(t *testing.T, cfg Config) *Storage {
+	cfg.Driver = DriverSQLite
+	if cfg.DSN == "" {
+		cfg.DSN = ":memory:"
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = time.Hour
+	}
+	s, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func testEvent(id, idx string, eventDate time.Time, signer string) *messages.Event {
+	return &messages.Event{
+		Type:        "test",
+		ID:          []byte(id),
+		Index:       []byte(idx),
+		EventDate:   eventDate,
+		MessageDate: eventDate,
+		Data:        map[string][]byte{"test": []byte(id)},
+		Signatures: map[string]messages.EventSignature{
+			"ethereum": {Signer: []byte(signer), Signature: []byte("sig")},
+		},
+	}
+}
+
+func TestStorage_Add(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, Config{DSN: filepath.Join(t.TempDir(), "events.db")})
+	now := time.Unix(time.Now().Unix(), 0)
+	e1 := testEvent("test", "idx", now, "signer1")
+	e2 := testEvent("test2", "idx", now, "signer2")
+	e3 := testEvent("test2", "idx2", now, "signer3")
+
+	isNew, err := s.Add(ctx, []byte("author1"), e1)
+	assert.True(t, isNew)
+	assert.NoError(t, err)
+
+	isNew, err = s.Add(ctx, []byte("author2"), e2)
+	assert.True(t, isNew)
+	assert.NoError(t, err)
+
+	isNew, err = s.Add(ctx, []byte("author3"), e3)
+	assert.True(t, isNew)
+	assert.NoError(t, err)
+
+	es, err := s.Get(ctx, "test", []byte("idx"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*messages.Event{e1, e2}, es)
+
+	es, err = s.Get(ctx, "test", []byte("unknown"))
+	assert.NoError(t, err)
+	assert.Len(t, es, 0)
+}
+
+func TestStorage_Add_replacePreviousEvent(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, Config{})
+	now := time.Unix(time.Now().Unix(), 0)
+	e1 := testEvent("test", "idx", now.Add(-time.Second), "signer1")
+	e2 := testEvent("test", "idx", now, "signer1")
+	e2.Signatures["other"] = messages.EventSignature{Signer: []byte("signer1"), Signature: []byte("sig2")}
+
+	isNew, err := s.Add(ctx, []byte("author1"), e1)
+	assert.True(t, isNew)
+	assert.NoError(t, err)
+
+	// Replace if newer.
+	isNew, err = s.Add(ctx, []byte("author1"), e2)
+	assert.False(t, isNew)
+	assert.NoError(t, err)
+
+	es, err := s.Get(ctx, "test", []byte("idx"))
+	assert.NoError(t, err)
+	assert.Equal(t, []*messages.Event{e2}, es)
+
+	// Keep previous if older.
+	isNew, err = s.Add(ctx, []byte("author1"), e1)
+	assert.False(t, isNew)
+	assert.NoError(t, err)
+
+	es, err = s.Get(ctx, "test", []byte("idx"))
+	assert.NoError(t, err)
+	assert.Equal(t, []*messages.Event{e2}, es)
+}
+
+func TestStorage_Remove(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, Config{})
+	_, err := s.Add(ctx, []byte("author1"), testEvent("test", "idx", time.Now(), "signer1"))
+	require.NoError(t, err)
+
+	// Events published by other authors must not be removed.
+	assert.NoError(t, s.Remove(ctx, []byte("author2"), "test", []byte("idx"), []byte("test")))
+	es, err := s.Get(ctx, "test", []byte("idx"))
+	assert.NoError(t, err)
+	assert.Len(t, es, 1)
+
+	assert.NoError(t, s.Remove(ctx, []byte("author1"), "test", []byte("idx"), []byte("test")))
+	es, err = s.Get(ctx, "test", []byte("idx"))
+	assert.NoError(t, err)
+	assert.Len(t, es, 0)
+}
+
+func TestStorage_Query(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, Config{})
+	now := time.Unix(time.Now().Unix(), 0)
+	e1 := testEvent("e1", "idx1", now.Add(-3*time.Minute), "signer1")
+	e2 := testEvent("e2", "idx1", now.Add(-2*time.Minute), "signer2")
+	e3 := testEvent("e3", "idx2", now.Add(-1*time.Minute), "signer1")
+	for _, e := range []*messages.Event{e3, e1, e2} {
+		_, err := s.Add(ctx, []byte("author"), e)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		query store.Query
+		want  []*messages.Event
+	}{
+		{query: store.Query{}, want: []*messages.Event{e1, e2, e3}},
+		{query: store.Query{Index: []byte("idx1")}, want: []*messages.Event{e1, e2}},
+		{query: store.Query{Signer: []byte("signer1")}, want: []*messages.Event{e1, e3}},
+		{query: store.Query{From: e2.EventDate}, want: []*messages.Event{e2, e3}},
+		{query: store.Query{To: e2.EventDate}, want: []*messages.Event{e1}},
+		{query: store.Query{From: e1.EventDate, To: e3.EventDate, Signer: []byte("signer2")}, want: []*messages.Event{e2}},
+		{query: store.Query{Limit: 2}, want: []*messages.Event{e1, e2}},
+		{query: store.Query{Type: "other"}, want: nil},
+	}
+	for _, tt := range tests {
+		es, err := s.Query(ctx, tt.query)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, es)
+	}
+}
+
+func TestStorage_retention(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, Config{
+		TTL:             time.Hour,
+		TypeTTL:         map[string]time.Duration{"short": time.Minute},
+		CleanupInterval: time.Nanosecond,
+	})
+	now := time.Unix(time.Now().Unix(), 0)
+	old := testEvent("old", "idx", now.Add(-2*time.Hour), "signer")
+	recent := testEvent("recent", "idx", now.Add(-30*time.Minute), "signer")
+	short := testEvent("short", "idx", now.Add(-30*time.Minute), "signer")
+	short.Type = "short"
+	for _, e := range []*messages.Event{old, recent, short} {
+		_, err := s.Add(ctx, []byte("author"), e)
+		require.NoError(t, err)
+	}
+
+	// Expired events must not be returned, even before they are removed.
+	es, err := s.Query(ctx, store.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, []*messages.Event{recent}, es)
+
+	// Next Add must remove expired events from the database.
+	_, err = s.Add(ctx, []byte("author"), testEvent("new", "idx", now, "signer"))
+	require.NoError(t, err)
+	var events, signatures int
+	require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM events`).Scan(&events))
+	require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM signatures`).Scan(&signatures))
+	assert.Equal(t, 2, events)
+	assert.Equal(t, 2, signatures)
+}
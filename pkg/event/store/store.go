@@ -16,9 +16,15 @@
 package store
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"errors"
+	"sync"
+	"time"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
@@ -28,6 +34,14 @@ import (
 
 const LoggerTag = "EVENT_STORE"
 
+// subscriptionBufferSize is the size of the channel returned by the
+// Subscribe method.
+const subscriptionBufferSize = 128
+
+// ErrQueryNotSupported is returned by the Query method if the storage does
+// not implement the Querier interface.
+var ErrQueryNotSupported = errors.New("storage does not support queries")
+
 // EventStore listens for event messages using the transport and stores
 // them for later use.
 type EventStore struct {
@@ -37,6 +51,9 @@ type EventStore struct {
 	transport  transport.Transport
 	log        log.Logger
 	waitCh     chan error
+
+	mu   sync.Mutex
+	subs map[chan *messages.Event]struct{}
 }
 
 // Config is the configuration for the EventStore.
@@ -66,6 +83,59 @@ type Storage interface {
 	// Get returns messages form the store for the given type and index. If the
 	// message does not exist, nil will be returned. The method is thread-safe.
 	Get(ctx context.Context, typ string, idx []byte) ([]*messages.Event, error)
+
+	// Remove removes the event with the given type, index and ID published
+	// by the given author. Removing an event that does not exist is not an
+	// error. The method is thread-safe.
+	Remove(ctx context.Context, author []byte, typ string, idx []byte, id []byte) error
+}
+
+// Querier is implemented by storages that support range queries.
+type Querier interface {
+	// Query returns events that match the given query, ordered by the event
+	// date. The method is thread-safe.
+	Query(ctx context.Context, q Query) ([]*messages.Event, error)
+}
+
+// Query is a filter for the Querier interface. Empty fields are ignored.
+type Query struct {
+	// Type is the type of the events.
+	Type string
+	// Index is the search index of the events.
+	Index []byte
+	// Signer is the address of the signer of the events.
+	Signer []byte
+	// From is the inclusive lower bound of the event date.
+	From time.Time
+	// To is the exclusive upper bound of the event date.
+	To time.Time
+	// Limit is the maximum number of events to return.
+	Limit int
+}
+
+// Match returns true if the event matches the query. The limit is ignored.
+func (q Query) Match(evt *messages.Event) bool {
+	if q.Type != "" && q.Type != evt.Type {
+		return false
+	}
+	if q.Index != nil && !bytes.Equal(q.Index, evt.Index) {
+		return false
+	}
+	if !q.From.IsZero() && evt.EventDate.Unix() < q.From.Unix() {
+		return false
+	}
+	if !q.To.IsZero() && evt.EventDate.Unix() >= q.To.Unix() {
+		return false
+	}
+	if q.Signer != nil {
+		for _, s := range evt.Signatures {
+			if bytes.Equal(s.Signer, q.Signer) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
 }
 
 // New returns a new instance of the EventStore struct.
@@ -85,6 +155,7 @@ func New(cfg Config) (*EventStore, error) {
 		transport:  cfg.Transport,
 		log:        cfg.Logger.WithField("tag", LoggerTag),
 		waitCh:     make(chan error),
+		subs:       make(map[chan *messages.Event]struct{}),
 	}, nil
 }
 
@@ -112,6 +183,55 @@ func (e *EventStore) Events(ctx context.Context, typ string, idx []byte) ([]*mes
 	return e.storage.Get(ctx, typ, idx)
 }
 
+// Query returns events that match the given query. It returns
+// ErrQueryNotSupported if the storage does not implement the Querier
+// interface. The method is thread-safe.
+func (e *EventStore) Query(ctx context.Context, q Query) ([]*messages.Event, error) {
+	querier, ok := e.storage.(Querier)
+	if !ok {
+		return nil, ErrQueryNotSupported
+	}
+	return querier.Query(ctx, q)
+}
+
+// Subscribe returns a channel that receives every event that was added to the
+// storage for the first time by its author, which means either a new event
+// or a new signature for an already known event. The channel is closed when
+// the given context is canceled. If the subscriber does not read events fast
+// enough, events are dropped.
+func (e *EventStore) Subscribe(ctx context.Context) <-chan *messages.Event {
+	ch := make(chan *messages.Event, subscriptionBufferSize)
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	e.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		e.mu.Lock()
+		delete(e.subs, ch)
+		close(ch)
+		e.mu.Unlock()
+	}()
+	return ch
+}
+
+func (e *EventStore) notify(evt *messages.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subs {
+		select {
+		case ch <- evt:
+		default:
+			e.log.
+				WithFields(log.Fields{
+					"id":    hex.EncodeToString(evt.ID),
+					"type":  evt.Type,
+					"index": hex.EncodeToString(evt.Index),
+				}).
+				Warn("Subscriber is too slow, event dropped")
+		}
+	}
+}
+
 func (e *EventStore) eventCollectorRoutine() {
 	msgCh := e.transport.Messages(messages.EventV1MessageName)
 	for {
@@ -131,6 +251,31 @@ func (e *EventStore) eventCollectorRoutine() {
 			if !e.isEventSupported(evt) {
 				continue
 			}
+			if evt.IsRetraction() {
+				if err := verifyRetraction(msg.Author, evt); err != nil {
+					e.log.
+						WithError(err).
+						WithFields(log.Fields{
+							"id":   hex.EncodeToString(evt.ID),
+							"type": evt.Type,
+							"from": msg.Author,
+						}).
+						Warn("Invalid event retraction")
+					continue
+				}
+				e.log.
+					WithFields(log.Fields{
+						"id":    hex.EncodeToString(evt.ID),
+						"type":  evt.Type,
+						"index": hex.EncodeToString(evt.Index),
+						"from":  msg.Author,
+					}).
+					Info("Event retracted")
+				if err := e.storage.Remove(e.ctx, msg.Author, evt.Type, evt.Index, evt.ID); err != nil {
+					e.log.WithError(err).Error("Unable to remove the retracted event")
+				}
+				continue
+			}
 			isNew, err := e.storage.Add(e.ctx, msg.Author, evt)
 			e.log.
 				WithFields(log.Fields{
@@ -149,8 +294,34 @@ func (e *EventStore) eventCollectorRoutine() {
 				e.log.WithError(err).Error("Unable to store the event")
 				continue
 			}
+			if isNew {
+				e.notify(evt)
+			}
+		}
+	}
+}
+
+// verifyRetraction verifies that the retraction is signed by its author.
+func verifyRetraction(author []byte, evt *messages.Event) error {
+	hash := evt.RetractionHash()
+	for _, s := range evt.Signatures {
+		if !bytes.Equal(s.Signer, author) {
+			continue
+		}
+		sig, err := types.SignatureFromBytes(s.Signature)
+		if err != nil {
+			return err
+		}
+		addr, err := crypto.ECRecoverer.RecoverMessage(hash.Bytes(), sig)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(addr.Bytes(), author) {
+			return errors.New("retraction signature does not match the author")
 		}
+		return nil
 	}
+	return errors.New("retraction is not signed by the author")
 }
 
 func (e *EventStore) isEventSupported(evt *messages.Event) bool {
@@ -20,6 +20,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/defiweb/go-eth/wallet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -44,7 +45,8 @@ func (s *storeTest) Get(ctx context.Context, typ string, idx []byte) ([]*message
 
 func TestEventStore(t *testing.T) {
 	ctx, cancelFunc := context.WithCancel(context.Background())
-	tra := local.New([]byte("test"), 1, map[string]transport.Message{messages.EventV1MessageName: (*messages.Event)(nil)})
+	key := wallet.NewRandomKey()
+	tra := local.New(key.Address().Bytes(), 1, map[string]transport.Message{messages.EventV1MessageName: (*messages.Event)(nil)})
 
 	mem := NewMemoryStorage(time.Minute)
 	evs, err := New(Config{
@@ -66,6 +68,9 @@ func TestEventStore(t *testing.T) {
 	// Wait for services to start,
 	time.Sleep(100 * time.Millisecond)
 
+	subCtx, subCancel := context.WithCancel(ctx)
+	subCh := evs.Subscribe(subCtx)
+
 	event := &messages.Event{
 		Type:        "test",
 		ID:          []byte("test"),
@@ -93,4 +98,38 @@ func TestEventStore(t *testing.T) {
 	assert.Equal(t, event.MessageDate.Unix(), events[0].MessageDate.Unix())
 	assert.Equal(t, event.Data, events[0].Data)
 	assert.Equal(t, event.Signatures, events[0].Signatures)
+
+	// Subscribers must be notified about new events only once.
+	select {
+	case evt := <-subCh:
+		assert.Equal(t, event.ID, evt.ID)
+	case <-time.After(time.Second):
+		t.Fatal("subscriber was not notified")
+	}
+	require.NoError(t, tra.Broadcast(messages.EventV1MessageName, event))
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, subCh, 0)
+	subCancel()
+	assert.Eventually(t, func() bool {
+		_, ok := <-subCh
+		return !ok
+	}, 1*time.Second, 100*time.Millisecond)
+
+	// Unsigned retraction must be ignored.
+	retraction := messages.NewEventRetraction(event.Type, event.ID, event.Index)
+	require.NoError(t, tra.Broadcast(messages.EventV1MessageName, retraction))
+	time.Sleep(100 * time.Millisecond)
+	events, err = evs.Events(context.Background(), "test", []byte("idx"))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	// Retraction signed by the author must remove the event from the storage.
+	sig, err := key.SignMessage(retraction.RetractionHash().Bytes())
+	require.NoError(t, err)
+	retraction.Signatures["ethereum"] = messages.EventSignature{Signer: key.Address().Bytes(), Signature: sig.Bytes()}
+	require.NoError(t, tra.Broadcast(messages.EventV1MessageName, retraction))
+	assert.Eventually(t, func() bool {
+		events, _ := evs.Events(context.Background(), "test", []byte("idx"))
+		return len(events) == 0
+	}, 1*time.Second, 100*time.Millisecond)
 }
@@ -3,9 +3,13 @@ package feed
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/datapoint"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/timeutil"
 
@@ -18,6 +22,7 @@ const LoggerTag = "FEED"
 // Feed is a service which periodically fetches data points and then sends them to
 // the network using transport layer.
 type Feed struct {
+	mu     sync.RWMutex
 	ctx    context.Context
 	waitCh chan error
 	log    log.Logger
@@ -27,6 +32,7 @@ type Feed struct {
 	signers      []datapoint.Signer
 	transport    transport.Transport
 	interval     *timeutil.Ticker
+	health       *health.Reporter
 }
 
 // Config is the configuration for the Feed.
@@ -50,6 +56,10 @@ type Config struct {
 	// Logger is a current logger interface used by the Feed.
 	// If nil, null logger will be used.
 	Logger log.Logger
+
+	// Health is the registry in which the Feed reports its status under
+	// the "feed" name. If nil, the status is not reported.
+	Health *health.Registry
 }
 
 // New creates a new instance of the Feed.
@@ -71,6 +81,7 @@ func New(cfg Config) (*Feed, error) {
 		signers:      cfg.Signers,
 		transport:    cfg.Transport,
 		interval:     cfg.Interval,
+		health:       cfg.Health.Register("feed"),
 	}
 	return g, nil
 }
@@ -88,6 +99,7 @@ func (f *Feed) Start(ctx context.Context) error {
 	f.interval.Start(f.ctx)
 	go f.broadcasterRoutine()
 	go f.contextCancelHandler()
+	f.health.Ready()
 	return nil
 }
 
@@ -96,15 +108,37 @@ func (f *Feed) Wait() <-chan error {
 	return f.waitCh
 }
 
+// SetDataProvider replaces the data provider. It may be called while the
+// feed is running, the new provider is used from the next tick.
+func (f *Feed) SetDataProvider(dataProvider datapoint.Provider) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dataProvider = dataProvider
+}
+
+// SetDataModels replaces the list of data models handled by the feed. It
+// may be called while the feed is running, the new list is used from the
+// next tick.
+func (f *Feed) SetDataModels(dataModels []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dataModels = dataModels
+}
+
 // broadcast sends data point to the network.
-func (f *Feed) broadcast(model string, point datapoint.Point) {
+func (f *Feed) broadcast(ctx context.Context, model string, point datapoint.Point) {
+	ctx, span := tracing.Start(ctx, "feed.Feed.broadcast", tracing.WithAttributes(map[string]any{
+		"model": model,
+		"age":   time.Since(point.Time),
+	}))
+	defer span.End()
 	found := false
 	for _, signer := range f.signers {
-		if !signer.Supports(f.ctx, point) {
+		if !signer.Supports(ctx, point) {
 			continue
 		}
 		found = true
-		sig, err := signer.Sign(f.ctx, model, point)
+		sig, err := signer.Sign(ctx, model, point)
 		if err != nil {
 			f.log.
 				WithError(err).
@@ -115,8 +149,11 @@ func (f *Feed) broadcast(model string, point datapoint.Point) {
 			Model:     model,
 			Value:     point,
 			Signature: *sig,
+			Meta:      map[string]string{},
 		}
+		tracing.Inject(ctx, msg.Meta)
 		if err := f.transport.Broadcast(messages.DataPointV1MessageName, msg); err != nil {
+			span.RecordError(err)
 			f.log.
 				WithError(err).
 				WithField("dataPoint", point).
@@ -139,28 +176,43 @@ func (f *Feed) broadcasterRoutine() {
 		case <-f.ctx.Done():
 			return
 		case <-f.interval.TickCh():
-			// Fetch all data points from the provider to update them
-			// at once.
-			_, err := f.dataProvider.DataPoints(f.ctx, f.dataModels...)
-			if err != nil {
-				f.log.
-					WithError(err).
-					Error("Unable to update data points")
-				continue
-			}
-
-			// Send data points to the network.
-			for _, model := range f.dataModels {
-				point, err := f.dataProvider.DataPoint(f.ctx, model)
-				if err != nil {
-					f.log.
-						WithError(err).
-						Error("Unable to get data points")
-					continue
-				}
-				f.broadcast(model, point)
-			}
+			f.tick()
+		}
+	}
+}
+
+// tick fetches data points and sends them to the network.
+func (f *Feed) tick() {
+	ctx, span := tracing.Start(f.ctx, "feed.Feed.tick")
+	defer span.End()
+
+	f.mu.RLock()
+	dataProvider, dataModels := f.dataProvider, f.dataModels
+	f.mu.RUnlock()
+
+	// Fetch all data points from the provider to update them
+	// at once.
+	_, err := dataProvider.DataPoints(ctx, dataModels...)
+	if err != nil {
+		span.RecordError(err)
+		f.health.Failure(err)
+		f.log.
+			WithError(err).
+			Error("Unable to update data points")
+		return
+	}
+	f.health.Success()
+
+	// Send data points to the network.
+	for _, model := range dataModels {
+		point, err := dataProvider.DataPoint(ctx, model)
+		if err != nil {
+			f.log.
+				WithError(err).
+				Error("Unable to get data points")
+			continue
 		}
+		f.broadcast(ctx, model, point)
 	}
 }
 
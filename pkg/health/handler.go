@@ -0,0 +1,62 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Response is the response returned by the liveness and readiness
+// handlers.
+type Response struct {
+	Live       bool     `json:"live"`
+	Ready      bool     `json:"ready"`
+	Components []Status `json:"components"`
+}
+
+// LivenessHandler returns an HTTP handler that responds with the 200 status
+// code if all components in the registry are live, otherwise it responds
+// with the 503 status code. The response body contains statuses of all
+// components.
+func LivenessHandler(r *Registry) http.Handler {
+	return handler(r, func(res Response) bool { return res.Live })
+}
+
+// ReadinessHandler returns an HTTP handler that responds with the 200
+// status code if all components in the registry are live and ready,
+// otherwise it responds with the 503 status code. The response body
+// contains statuses of all components.
+func ReadinessHandler(r *Registry) http.Handler {
+	return handler(r, func(res Response) bool { return res.Ready })
+}
+
+func handler(r *Registry, ok func(Response) bool) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		res := Response{Live: true, Ready: true, Components: r.Statuses()}
+		for _, s := range res.Components {
+			res.Live = res.Live && s.Live
+			res.Ready = res.Ready && s.Live && s.Ready
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		if ok(res) {
+			rw.WriteHeader(http.StatusOK)
+		} else {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(rw).Encode(res)
+	})
+}
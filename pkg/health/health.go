@@ -0,0 +1,244 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package health provides a registry in which services report their
+// health. The registry is used to implement liveness and readiness probes.
+//
+// A Registry is created by the application and passed to every component,
+// which obtains a Reporter using the Registry.Register method and reports
+// its status using the Reporter methods. The aggregated status of all
+// components is exposed by the handlers returned by LivenessHandler and
+// ReadinessHandler.
+package health
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is a status of a single component.
+type Status struct {
+	// Name is the name of the component.
+	Name string `json:"name"`
+
+	// Live is false if the component is not running and cannot recover
+	// without restarting the process.
+	Live bool `json:"live"`
+
+	// Ready is true if the component is ready to work.
+	Ready bool `json:"ready"`
+
+	// Degraded is true if the component works, but the last operation
+	// failed or there were no successful operations for too long.
+	Degraded bool `json:"degraded"`
+
+	// Reason describes why the component is not ready or degraded.
+	Reason string `json:"reason,omitempty"`
+
+	// LastSuccess is the time of the last successful operation.
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+
+	// UpdatedAt is the time of the last status update.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Registry contains reporters of all components.
+type Registry struct {
+	mu        sync.RWMutex
+	reporters map[string]*Reporter
+}
+
+// NewRegistry returns a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{reporters: make(map[string]*Reporter)}
+}
+
+// Register returns a reporter with the given name. If the reporter does not
+// exist, it is created. A new reporter is live, but not ready.
+//
+// If the registry is nil, a reporter that does not belong to any registry
+// is returned, so components may be used without health reporting.
+func (r *Registry) Register(name string) *Reporter {
+	if r == nil {
+		return newReporter(name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rep, ok := r.reporters[name]; ok {
+		return rep
+	}
+	rep := newReporter(name)
+	r.reporters[name] = rep
+	return rep
+}
+
+// Unregister removes the reporter with the given name.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reporters, name)
+}
+
+// Statuses returns statuses of all components sorted by name.
+func (r *Registry) Statuses() []Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	statuses := make([]Status, 0, len(r.reporters))
+	for _, rep := range r.reporters {
+		statuses = append(statuses, rep.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// Live returns true if all components are live.
+func (r *Registry) Live() bool {
+	for _, s := range r.Statuses() {
+		if !s.Live {
+			return false
+		}
+	}
+	return true
+}
+
+// Ready returns true if all components are live and ready.
+func (r *Registry) Ready() bool {
+	for _, s := range r.Statuses() {
+		if !s.Live || !s.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// Reporter reports the status of a single component.
+type Reporter struct {
+	mu     sync.RWMutex
+	status Status
+	maxAge time.Duration
+}
+
+func newReporter(name string) *Reporter {
+	return &Reporter{status: Status{
+		Name:      name,
+		Live:      true,
+		Reason:    "starting",
+		UpdatedAt: time.Now(),
+	}}
+}
+
+// SetMaxAge sets the maximum time between successful operations. If there
+// is no successful operation within this time, the component is reported
+// as degraded. Zero disables the check.
+func (r *Reporter) SetMaxAge(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxAge = d
+}
+
+// Ready marks the component as live and ready.
+func (r *Reporter) Ready() {
+	r.update(func(s *Status) {
+		s.Live = true
+		s.Ready = true
+		if !s.Degraded {
+			s.Reason = ""
+		}
+	})
+}
+
+// NotReady marks the component as not ready.
+func (r *Reporter) NotReady(reason string) {
+	r.update(func(s *Status) {
+		s.Ready = false
+		s.Reason = reason
+	})
+}
+
+// Down marks the component as not live and not ready.
+func (r *Reporter) Down(reason string) {
+	r.update(func(s *Status) {
+		s.Live = false
+		s.Ready = false
+		s.Reason = reason
+	})
+}
+
+// Success records a successful operation. It clears the degraded state.
+func (r *Reporter) Success() {
+	r.update(func(s *Status) {
+		now := time.Now()
+		s.LastSuccess = &now
+		if s.Degraded {
+			s.Degraded = false
+			s.Reason = ""
+		}
+	})
+}
+
+// Failure records a failed operation and marks the component as degraded.
+// A nil error is ignored.
+func (r *Reporter) Failure(err error) {
+	if err == nil {
+		return
+	}
+	r.update(func(s *Status) {
+		s.Degraded = true
+		s.Reason = err.Error()
+	})
+}
+
+// Report records a successful operation if err is nil, otherwise it records
+// a failed operation.
+func (r *Reporter) Report(err error) {
+	if err != nil {
+		r.Failure(err)
+		return
+	}
+	r.Success()
+}
+
+// Status returns the current status of the component.
+func (r *Reporter) Status() Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s := r.status
+	if s.LastSuccess != nil {
+		t := *s.LastSuccess
+		s.LastSuccess = &t
+	}
+	if r.maxAge > 0 && s.Ready && !s.Degraded {
+		since := s.UpdatedAt
+		if s.LastSuccess != nil {
+			since = *s.LastSuccess
+		}
+		if time.Since(since) > r.maxAge {
+			s.Degraded = true
+			s.Reason = fmt.Sprintf("no successful operation in the last %s", r.maxAge)
+		}
+	}
+	return s
+}
+
+func (r *Reporter) update(fn func(s *Status)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.status)
+	r.status.UpdatedAt = time.Now()
+}
@@ -0,0 +1,210 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	a := r.Register("a")
+	assert.Same(t, a, r.Register("a"))
+
+	s := a.Status()
+	assert.Equal(t, "a", s.Name)
+	assert.True(t, s.Live)
+	assert.False(t, s.Ready)
+	assert.Equal(t, "starting", s.Reason)
+	assert.Nil(t, s.LastSuccess)
+
+	r.Register("b")
+	statuses := r.Statuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "a", statuses[0].Name)
+	assert.Equal(t, "b", statuses[1].Name)
+
+	r.Unregister("b")
+	assert.Len(t, r.Statuses(), 1)
+}
+
+func TestRegistry_RegisterNil(t *testing.T) {
+	var r *Registry
+	a := r.Register("a")
+	require.NotNil(t, a)
+	assert.NotSame(t, a, r.Register("a"))
+
+	a.Ready()
+	assert.True(t, a.Status().Ready)
+}
+
+func TestRegistry_LiveReady(t *testing.T) {
+	r := NewRegistry()
+	assert.True(t, r.Live())
+	assert.True(t, r.Ready())
+
+	a := r.Register("a")
+	b := r.Register("b")
+	assert.True(t, r.Live())
+	assert.False(t, r.Ready())
+
+	a.Ready()
+	b.Ready()
+	assert.True(t, r.Live())
+	assert.True(t, r.Ready())
+
+	b.NotReady("restarting")
+	assert.True(t, r.Live())
+	assert.False(t, r.Ready())
+
+	b.Down("crashed")
+	assert.False(t, r.Live())
+	assert.False(t, r.Ready())
+}
+
+func TestReporter(t *testing.T) {
+	r := NewRegistry().Register("a")
+	r.Ready()
+	assert.True(t, r.Status().Ready)
+	assert.Empty(t, r.Status().Reason)
+
+	r.Failure(errors.New("fetch failed"))
+	s := r.Status()
+	assert.True(t, s.Ready)
+	assert.True(t, s.Degraded)
+	assert.Equal(t, "fetch failed", s.Reason)
+	assert.Nil(t, s.LastSuccess)
+
+	r.Failure(nil)
+	assert.True(t, r.Status().Degraded)
+
+	r.Report(nil)
+	s = r.Status()
+	assert.False(t, s.Degraded)
+	assert.Empty(t, s.Reason)
+	require.NotNil(t, s.LastSuccess)
+
+	r.Report(errors.New("broadcast failed"))
+	assert.True(t, r.Status().Degraded)
+	assert.Equal(t, "broadcast failed", r.Status().Reason)
+
+	r.Down("stopped")
+	s = r.Status()
+	assert.False(t, s.Live)
+	assert.False(t, s.Ready)
+	assert.Equal(t, "stopped", s.Reason)
+}
+
+func TestReporter_MaxAge(t *testing.T) {
+	r := NewRegistry().Register("a")
+	r.SetMaxAge(10 * time.Millisecond)
+	r.Ready()
+	r.Success()
+	assert.False(t, r.Status().Degraded)
+
+	time.Sleep(20 * time.Millisecond)
+	s := r.Status()
+	assert.True(t, s.Degraded)
+	assert.Contains(t, s.Reason, "no successful operation")
+
+	r.Success()
+	assert.False(t, r.Status().Degraded)
+}
+
+func TestHandlers(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    func(*Registry) http.Handler
+		setup      func(a, b *Reporter)
+		wantStatus int
+		wantLive   bool
+		wantReady  bool
+	}{
+		{
+			name:       "liveness/ready",
+			handler:    LivenessHandler,
+			setup:      func(a, b *Reporter) { a.Ready(); b.Ready() },
+			wantStatus: http.StatusOK,
+			wantLive:   true,
+			wantReady:  true,
+		},
+		{
+			name:       "liveness/not-ready",
+			handler:    LivenessHandler,
+			setup:      func(a, b *Reporter) { a.Ready() },
+			wantStatus: http.StatusOK,
+			wantLive:   true,
+			wantReady:  false,
+		},
+		{
+			name:       "liveness/down",
+			handler:    LivenessHandler,
+			setup:      func(a, b *Reporter) { a.Ready(); b.Down("crashed") },
+			wantStatus: http.StatusServiceUnavailable,
+			wantLive:   false,
+			wantReady:  false,
+		},
+		{
+			name:       "readiness/ready",
+			handler:    ReadinessHandler,
+			setup:      func(a, b *Reporter) { a.Ready(); b.Ready() },
+			wantStatus: http.StatusOK,
+			wantLive:   true,
+			wantReady:  true,
+		},
+		{
+			name:       "readiness/not-ready",
+			handler:    ReadinessHandler,
+			setup:      func(a, b *Reporter) { a.Ready() },
+			wantStatus: http.StatusServiceUnavailable,
+			wantLive:   true,
+			wantReady:  false,
+		},
+		{
+			name:       "readiness/degraded",
+			handler:    ReadinessHandler,
+			setup:      func(a, b *Reporter) { a.Ready(); b.Ready(); b.Failure(errors.New("err")) },
+			wantStatus: http.StatusOK,
+			wantLive:   true,
+			wantReady:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.setup(r.Register("a"), r.Register("b"))
+
+			rec := httptest.NewRecorder()
+			tt.handler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var res Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, tt.wantLive, res.Live)
+			assert.Equal(t, tt.wantReady, res.Ready)
+			assert.Len(t, res.Components, 2)
+		})
+	}
+}
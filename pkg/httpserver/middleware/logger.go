@@ -37,7 +37,7 @@ func (l *Logger) Handle(next http.Handler) http.Handler {
 		t := time.Now()
 		e := l.Log
 		if l.Log.Level() >= log.Debug {
-			rw = newRecorder(rw)
+			rw = newRecorder(rw, r)
 			e = e.WithField("request", string(readRequest(r)))
 		}
 		defer func() {
@@ -16,6 +16,7 @@
 package middleware
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -81,3 +82,35 @@ func TestLogger_DebugLevel(t *testing.T) {
 	assert.NotEmpty(t, recordedLogFields[0]["duration"])
 	assert.NotEmpty(t, recordedLogFields[0]["remoteAddr"])
 }
+
+func TestLogger_DebugLevelStreaming(t *testing.T) {
+	var recordedLogFields []log.Fields
+	l := callback.New(log.Debug, func(level log.Level, fields log.Fields, msg string) {
+		recordedLogFields = append(recordedLogFields, fields)
+	})
+
+	// Body of server-sent events must not be recorded.
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	h := (&Logger{Log: l}).Handle(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte("data: event\n\n"))
+	}))
+	h.ServeHTTP(w, r)
+	require.Len(t, recordedLogFields, 1)
+	assert.Equal(t, "", recordedLogFields[0]["response"])
+	assert.Equal(t, "data: event\n\n", w.Body.String())
+
+	// Large bodies must be truncated.
+	r = httptest.NewRequest("GET", "/", nil)
+	w = httptest.NewRecorder()
+	h = (&Logger{Log: l}).Handle(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		for i := 0; i < 3; i++ {
+			writer.Write(bytes.Repeat([]byte("a"), maxRecordedBodySize/2))
+		}
+	}))
+	h.ServeHTTP(w, r)
+	require.Len(t, recordedLogFields, 2)
+	assert.Len(t, recordedLogFields[1]["response"], maxRecordedBodySize)
+	assert.Equal(t, 3*maxRecordedBodySize/2, w.Body.Len())
+}
@@ -16,27 +16,40 @@
 package middleware
 
 import (
+	"bufio"
 	"bytes"
 	"io"
+	"net"
 	"net/http"
+	"strings"
 )
 
+// maxRecordedBodySize is the maximum number of bytes of the response body
+// recorded by the recorder.
+const maxRecordedBodySize = 64 * 1024
+
 // recorder implements the http.ResponseWriter interface. It passes all calls
 // to the underlying ResponseWriter and records a copies of values for a later
 // inspection.
+//
+// Only the first maxRecordedBodySize bytes of the body are recorded. The body
+// of streaming responses, like server-sent events or upgraded connections,
+// is not recorded at all.
 type recorder struct {
-	rw      http.ResponseWriter // rw is an underlying ResponseWriter.
-	code    int                 // code is the HTTP status code
-	headers http.Header         // headers is the list of HTTP headers
-	body    *bytes.Buffer       // body is the HTTP response body
+	rw       http.ResponseWriter // rw is an underlying ResponseWriter.
+	code     int                 // code is the HTTP status code
+	headers  http.Header         // headers is the list of HTTP headers
+	body     *bytes.Buffer       // body is the HTTP response body
+	skipBody bool                // skipBody disables recording of the body
 }
 
-func newRecorder(rw http.ResponseWriter) *recorder {
+func newRecorder(rw http.ResponseWriter, r *http.Request) *recorder {
 	return &recorder{
-		rw:      rw,
-		headers: make(http.Header),
-		body:    new(bytes.Buffer),
-		code:    http.StatusOK,
+		rw:       rw,
+		headers:  make(http.Header),
+		body:     new(bytes.Buffer),
+		code:     http.StatusOK,
+		skipBody: isStreaming(r),
 	}
 }
 
@@ -45,7 +58,13 @@ func (r *recorder) Header() http.Header {
 }
 
 func (r *recorder) Write(buf []byte) (int, error) {
-	r.body.Write(buf)
+	if !r.skipBody {
+		if n := maxRecordedBodySize - r.body.Len(); n < len(buf) {
+			r.body.Write(buf[:n])
+		} else {
+			r.body.Write(buf)
+		}
+	}
 	return r.rw.Write(buf)
 }
 
@@ -54,6 +73,29 @@ func (r *recorder) WriteHeader(code int) {
 	r.rw.WriteHeader(code)
 }
 
+// Flush implements the http.Flusher interface.
+func (r *recorder) Flush() {
+	_ = http.NewResponseController(r.rw).Flush()
+}
+
+// Hijack implements the http.Hijacker interface.
+func (r *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.skipBody = true
+	return http.NewResponseController(r.rw).Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter. It is used by the
+// http.ResponseController.
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.rw
+}
+
+// isStreaming returns true if the response to the given request is expected
+// to be a stream, e.g. server-sent events or a WebSocket connection.
+func isStreaming(r *http.Request) bool {
+	return r.Header.Get("Upgrade") != "" || strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
 func readRequest(r *http.Request) []byte {
 	b, _ := io.ReadAll(r.Body)
 	r.Body = io.NopCloser(bytes.NewReader(b))
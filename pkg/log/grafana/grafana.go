@@ -0,0 +1,109 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric"
+)
+
+// Config is the configuration for the Grafana sink.
+type Config struct {
+	// Graphite server endpoint.
+	GraphiteEndpoint string
+
+	// Graphite API key.
+	GraphiteAPIKey string
+
+	// HTTPClient used to send metrics to Grafana Cloud.
+	HTTPClient *http.Client
+}
+
+// Sink is a metric.Sink that sends metric points to Grafana Cloud using
+// the Graphite endpoint.
+type Sink struct {
+	graphiteEndpoint string
+	graphiteAPIKey   string
+	httpClient       *http.Client
+}
+
+type metricJSON struct {
+	Name     string   `json:"name"`
+	Interval uint     `json:"interval"`
+	Value    float64  `json:"value"`
+	Time     int64    `json:"time"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// New creates a new Grafana sink.
+func New(cfg Config) (*Sink, error) {
+	if cfg.GraphiteEndpoint == "" {
+		return nil, errors.New("graphite endpoint must not be empty")
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return &Sink{
+		graphiteEndpoint: cfg.GraphiteEndpoint,
+		graphiteAPIKey:   cfg.GraphiteAPIKey,
+		httpClient:       cfg.HTTPClient,
+	}, nil
+}
+
+// Push implements the metric.Sink interface.
+func (s *Sink) Push(ctx context.Context, points []metric.Point) error {
+	metrics := make([]metricJSON, len(points))
+	for i, p := range points {
+		var tags []string
+		for _, t := range p.Tags {
+			tags = append(tags, t.Name+"="+t.Value)
+		}
+		metrics[i] = metricJSON{
+			Name:     p.Name,
+			Interval: p.Interval,
+			Value:    p.Value,
+			Time:     p.Time,
+			Tags:     tags,
+		}
+	}
+	reqBody, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("unable to marshal metric points: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.graphiteEndpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.graphiteAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send metrics: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.ReadAll(res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+	return nil
+}
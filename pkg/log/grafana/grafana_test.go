@@ -0,0 +1,60 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric"
+)
+
+type RoundTripFunc func(req *http.Request) *http.Response
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func TestSink_Push(t *testing.T) {
+	called := false
+	s, err := New(Config{
+		GraphiteEndpoint: "https://example.com/metrics",
+		GraphiteAPIKey:   "test",
+		HTTPClient: &http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			called = true
+
+			// Verify URL and auth key:
+			assert.Equal(t, "https://example.com/metrics", req.URL.String())
+			assert.Equal(t, "Bearer test", req.Header.Get("Authorization"))
+
+			// Verify data:
+			var data []metricJSON
+			body, _ := io.ReadAll(req.Body)
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Len(t, data, 1)
+			assert.Equal(t, "test", data[0].Name)
+			assert.Equal(t, uint(60), data[0].Interval)
+			assert.Equal(t, 1.5, data[0].Value)
+			assert.Equal(t, int64(1600000000), data[0].Time)
+			assert.Equal(t, []string{"tag1=a", "tag2=b"}, data[0].Tags)
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}
+		})},
+	})
+	require.NoError(t, err)
+
+	err = s.Push(context.Background(), []metric.Point{{
+		Name:     "test",
+		Value:    1.5,
+		Time:     1600000000,
+		Interval: 60,
+		Tags:     []metric.Tag{{Name: "tag1", Value: "a"}, {Name: "tag2", Value: "b"}},
+	}})
+	require.NoError(t, err)
+	assert.True(t, called)
+}
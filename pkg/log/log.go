@@ -96,6 +96,12 @@ type Logger interface {
 	Panic(args ...interface{})
 }
 
+// LevelSetter is implemented by loggers whose log level can be changed
+// at runtime.
+type LevelSetter interface {
+	SetLevel(level Level)
+}
+
 // LoggerService is a logger that needs to be started to be used.
 type LoggerService interface {
 	Logger
@@ -16,30 +16,38 @@
 package logrus
 
 import (
+	"sync/atomic"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 )
 
 // New creates a new logger that uses Logrus for logging.
+//
+// If the given logger is a *logrus.Logger, the returned logger implements
+// the log.LevelSetter interface. The log level is shared between the
+// returned logger and all loggers derived from it.
 func New(logrusLogger logrus.FieldLogger) log.Logger {
-	lvl := log.Debug
+	lvl := &level{}
+	lvl.lvl.Store(uint32(log.Debug))
 	if l, ok := logrusLogger.(*logrus.Logger); ok {
+		lvl.base = l
 		switch l.Level {
 		case logrus.PanicLevel:
-			lvl = log.Panic
+			lvl.lvl.Store(uint32(log.Panic))
 		case logrus.FatalLevel:
-			lvl = log.Panic
+			lvl.lvl.Store(uint32(log.Panic))
 		case logrus.ErrorLevel:
-			lvl = log.Error
+			lvl.lvl.Store(uint32(log.Error))
 		case logrus.WarnLevel:
-			lvl = log.Warn
+			lvl.lvl.Store(uint32(log.Warn))
 		case logrus.InfoLevel:
-			lvl = log.Info
+			lvl.lvl.Store(uint32(log.Info))
 		case logrus.DebugLevel:
-			lvl = log.Debug
+			lvl.lvl.Store(uint32(log.Debug))
 		case logrus.TraceLevel:
-			lvl = log.Debug
+			lvl.lvl.Store(uint32(log.Debug))
 		}
 	}
 	return &logger{log: logrusLogger, lvl: lvl}
@@ -47,12 +55,39 @@ func New(logrusLogger logrus.FieldLogger) log.Logger {
 
 type logger struct {
 	log logrus.FieldLogger
-	lvl log.Level
+	lvl *level
+}
+
+// level is a log level shared between derived loggers.
+type level struct {
+	lvl  atomic.Uint32
+	base *logrus.Logger
 }
 
 // Level implements new log.Logger interface.
 func (l *logger) Level() log.Level {
-	return l.lvl
+	return log.Level(l.lvl.lvl.Load())
+}
+
+// SetLevel implements the log.LevelSetter interface. It changes the level
+// of the underlying Logrus logger. If the logger was not created from
+// a *logrus.Logger, only the value returned by the Level method is changed.
+func (l *logger) SetLevel(lvl log.Level) {
+	if l.lvl.base != nil {
+		switch lvl {
+		case log.Panic:
+			l.lvl.base.SetLevel(logrus.PanicLevel)
+		case log.Error:
+			l.lvl.base.SetLevel(logrus.ErrorLevel)
+		case log.Warn:
+			l.lvl.base.SetLevel(logrus.WarnLevel)
+		case log.Info:
+			l.lvl.base.SetLevel(logrus.InfoLevel)
+		case log.Debug:
+			l.lvl.base.SetLevel(logrus.DebugLevel)
+		}
+	}
+	l.lvl.lvl.Store(uint32(lvl))
 }
 
 // WithField implements new log.Logger interface.
@@ -13,15 +13,11 @@
 //  You should have received a copy of the GNU Affero General Public License
 //  along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
-package grafana
+package metric
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -29,39 +25,65 @@ import (
 	"sync"
 	"time"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/dump"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/interpolate"
-
-	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 )
 
-const LoggerTag = "GRAFANA"
+const LoggerTag = "LOG_METRIC"
+
+// Sink is a destination to which metric points are sent.
+type Sink interface {
+	// Push sends metric points to the sink. The method is called from
+	// a single goroutine, so it does not have to be thread-safe.
+	Push(ctx context.Context, points []Point) error
+}
+
+// Point is a single metric point.
+type Point struct {
+	// Name is the name of the metric.
+	Name string
+
+	// Value is the metric value.
+	Value float64
+
+	// Time is the start of the interval to which the point belongs, as
+	// a Unix timestamp.
+	Time int64
+
+	// Interval is the interval, in seconds, in which points are aggregated.
+	Interval uint
+
+	// Tags is a list of metric tags.
+	Tags []Tag
+}
+
+// Tag is a metric tag.
+type Tag struct {
+	Name  string
+	Value string
+}
 
-// Config is the configuration for the Grafana logger.
+// Config is the configuration for the metric logger.
 type Config struct {
 	// Metrics is a list of metric definitions.
 	Metrics []Metric
 
-	// Interval specifies how often logs should be sent to the Grafana Cloud
-	// server. Logs with the same name in that interval will be replaced with
-	// never ones.
+	// Interval specifies how often metric points should be pushed to
+	// the sinks. Points with the same name in that interval are merged
+	// according to the Metric.OnDuplicate setting.
 	Interval uint
 
-	// Graphite server endpoint.
-	GraphiteEndpoint string
+	// Sinks is a list of sinks to which metric points are pushed.
+	Sinks []Sink
 
-	// Graphite API key.
-	GraphiteAPIKey string
-
-	// HTTPClient used to send metrics to Grafana Cloud.
-	HTTPClient *http.Client
-
-	// Logger used to log errors related to this logger, such as connection errors.
+	// Logger used to log errors related to this logger, such as connection
+	// errors.
 	Logger log.Logger
 }
 
-// Metric describes one Grafana metric.
+// Metric describes one metric.
 type Metric struct {
 	// MatchMessage is a regexp that must match the log message.
 	MatchMessage *regexp.Regexp
@@ -96,14 +118,17 @@ type Metric struct {
 	parsedTags map[string][]interpolate.Parsed
 }
 
-// New creates a new logger that can extract parameters from log messages and
-// send them to Grafana Cloud using the Graphite endpoint. It starts
-// a background goroutine that will be sending metrics to the Grafana Cloud
-// server as often as described in Config.Interval parameter.
+// New creates a new logger that extracts metrics from log messages and
+// pushes them to the given sinks. Metrics are pushed by a background
+// goroutine, as often as described in the Config.Interval parameter, after
+// the logger is started.
 func New(level log.Level, cfg Config) (log.Logger, error) {
 	if cfg.Logger == nil {
 		cfg.Logger = null.New()
 	}
+	if cfg.Interval == 0 {
+		return nil, fmt.Errorf("interval must be greater than zero")
+	}
 	// Parse names and tags in advance to improve performance.
 	for n := range cfg.Metrics {
 		m := &cfg.Metrics[n]
@@ -117,14 +142,12 @@ func New(level log.Level, cfg Config) (log.Logger, error) {
 	}
 	l := &logger{
 		shared: &shared{
-			waitCh:           make(chan error),
-			metrics:          cfg.Metrics,
-			logger:           cfg.Logger.WithField("tag", LoggerTag),
-			interval:         cfg.Interval,
-			graphiteEndpoint: cfg.GraphiteEndpoint,
-			graphiteAPIKey:   cfg.GraphiteAPIKey,
-			httpClient:       cfg.HTTPClient,
-			metricPoints:     make(map[metricKey]metricValue, 0),
+			waitCh:       make(chan error),
+			metrics:      cfg.Metrics,
+			sinks:        cfg.Sinks,
+			logger:       cfg.Logger.WithField("tag", LoggerTag),
+			interval:     cfg.Interval,
+			metricPoints: make(map[metricKey]metricValue, 0),
 		},
 		level:  level,
 		fields: log.Fields{},
@@ -143,13 +166,11 @@ type shared struct {
 	ctx    context.Context
 	waitCh chan error
 
-	logger           log.Logger
-	metrics          []Metric
-	interval         uint
-	graphiteEndpoint string
-	graphiteAPIKey   string
-	httpClient       *http.Client
-	metricPoints     map[metricKey]metricValue
+	logger       log.Logger
+	metrics      []Metric
+	sinks        []Sink
+	interval     uint
+	metricPoints map[metricKey]metricValue
 }
 
 type OnDuplicate int
@@ -169,15 +190,7 @@ type metricKey struct {
 
 type metricValue struct {
 	value float64
-	tags  []string
-}
-
-type metricJSON struct {
-	Name     string   `json:"name"`
-	Interval uint     `json:"interval"`
-	Value    float64  `json:"value"`
-	Time     int64    `json:"time"`
-	Tags     []string `json:"tags,omitempty"`
+	tags  []Tag
 }
 
 // Level implements the log.Logger interface.
@@ -323,7 +336,7 @@ func (c *logger) collect(msg string, fields log.Fields) {
 						Warn("Invalid path in the tag definition")
 					continue
 				}
-				mv.tags = append(mv.tags, t+"="+rt)
+				mv.tags = append(mv.tags, Tag{Name: t, Value: rt})
 			}
 		}
 		if len(metric.Value) > 0 {
@@ -393,7 +406,7 @@ func (c *logger) pushRoutine() {
 	}
 }
 
-// pushMetrics pushes metrics to the Grafana Cloud server.
+// pushMetrics pushes metrics to all sinks.
 func (c *logger) pushMetrics() {
 	var once sync.Once
 	c.mu.Lock()
@@ -401,54 +414,36 @@ func (c *logger) pushMetrics() {
 	if len(c.metricPoints) == 0 {
 		return
 	}
-	var metrics []metricJSON
+	points := make([]Point, 0, len(c.metricPoints))
 	for k, v := range c.metricPoints {
-		metrics = append(metrics, metricJSON{
+		points = append(points, Point{
 			Name:     k.name,
-			Interval: c.interval,
 			Value:    v.value,
 			Time:     k.time,
+			Interval: c.interval,
 			Tags:     v.tags,
 		})
 		delete(c.metricPoints, k)
 	}
 	// After this line, the mutex must be unlocked, otherwise calling
-	// the logger will cause the code to block until the request to
-	// Grafana is complete.
+	// the logger will cause the code to block until points are pushed
+	// to all sinks.
 	once.Do(c.mu.Unlock)
-	reqBody, err := json.Marshal(metrics)
-	if err != nil {
-		c.logger.
-			WithError(err).
-			Warn("Unable to marshall metric points")
-		return
+	ctx := c.ctx
+	if ctx == nil || ctx.Err() != nil {
+		// Metrics are also pushed after the context is canceled and
+		// before the application panics.
+		ctx = context.Background()
 	}
 	c.logger.
-		WithField("metrics", len(metrics)).
+		WithField("metrics", len(points)).
 		Info("Pushing metrics")
-	req, err := http.NewRequest("POST", c.graphiteEndpoint, bytes.NewReader(reqBody))
-	if err != nil {
-		c.logger.
-			WithError(err).
-			Warn("Invalid request")
-		return
-	}
-	req.Header.Set("Authorization", "Bearer "+c.graphiteAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	res, err := c.httpClient.Do(req)
-	if err != nil {
-		c.logger.
-			WithError(err).
-			Warn("Invalid request")
-		return
-	}
-	_, _ = io.ReadAll(res.Body)
-	err = res.Body.Close()
-	if err != nil {
-		c.logger.
-			WithError(err).
-			Warn("Unable to close request body")
-		return
+	for _, s := range c.sinks {
+		if err := s.Push(ctx, points); err != nil {
+			c.logger.
+				WithError(err).
+				Warn("Unable to push metrics")
+		}
 	}
 }
 
@@ -482,7 +477,7 @@ func match(metric Metric, msg string, fields reflect.Value) bool {
 	return metric.MatchMessage == nil || metric.MatchMessage.MatchString(msg)
 }
 
-// replaceVars replaces vars provided as ${field} with values from log fields.
+// replaceVars replaces vars provided as %{field} with values from log fields.
 func replaceVars(s interpolate.Parsed, fields reflect.Value) (string, bool) {
 	valid := true
 	return s.Interpolate(func(v interpolate.Variable) string {
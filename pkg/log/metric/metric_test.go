@@ -1,12 +1,9 @@
-package grafana
+package metric
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"math"
-	"net/http"
 	"reflect"
 	"regexp"
 	"sort"
@@ -21,10 +18,11 @@ import (
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 )
 
-type RoundTripFunc func(req *http.Request) *http.Response
+type sinkFunc func(points []Point)
 
-func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
-	return f(req), nil
+func (f sinkFunc) Push(_ context.Context, points []Point) error {
+	f(points)
+	return nil
 }
 
 func TestLogger(t *testing.T) {
@@ -290,35 +288,30 @@ func TestLogger(t *testing.T) {
 			ctx, ctxCancel := context.WithCancel(context.Background())
 			defer ctxCancel()
 			r := int32(0)
-			l, _ := New(log.Debug, Config{
-				Metrics:          tt.metrics,
-				Interval:         1,
-				GraphiteEndpoint: "https://example.com/metrics",
-				GraphiteAPIKey:   "test",
-				HTTPClient: &http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			l, err := New(log.Debug, Config{
+				Metrics:  tt.metrics,
+				Interval: 1,
+				Sinks: []Sink{sinkFunc(func(points []Point) {
 					defer atomic.StoreInt32(&r, 1)
 
-					// Verify URL and auth key:
-					assert.Equal(t, "https://example.com/metrics", req.URL.String())
-					assert.Equal(t, "Bearer test", req.Header.Get("Authorization"))
-
-					// Verify data:
-					var data []metricJSON
-					body, _ := io.ReadAll(req.Body)
-					require.NoError(t, json.Unmarshal(body, &data))
-					require.Len(t, data, len(tt.want))
-					sort.Slice(data, func(i, j int) bool { return data[i].Name < data[j].Name })
+					require.Len(t, points, len(tt.want))
+					sort.Slice(points, func(i, j int) bool { return points[i].Name < points[j].Name })
 					for n, w := range tt.want {
-						assert.Equal(t, w.name, data[n].Name)
-						assert.Equal(t, uint(1), data[n].Interval)
-						assert.Equal(t, w.value, data[n].Value)
-						assert.Greater(t, data[n].Time, int64(0))
+						var tags []string
+						for _, tag := range points[n].Tags {
+							tags = append(tags, tag.Name+"="+tag.Value)
+						}
+						sort.Strings(tags)
+						assert.Equal(t, w.name, points[n].Name)
+						assert.Equal(t, uint(1), points[n].Interval)
+						assert.Equal(t, w.value, points[n].Value)
+						assert.Equal(t, w.tags, tags)
+						assert.Greater(t, points[n].Time, int64(0))
 					}
-
-					return nil
 				})},
 				Logger: null.New(),
 			})
+			require.NoError(t, err)
 			if l, ok := l.(log.LoggerService); ok {
 				require.NoError(t, l.Start(ctx))
 			}
@@ -0,0 +1,189 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package openmetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric"
+)
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Config is the configuration for the OpenMetrics text file sink.
+type Config struct {
+	// Path is the path to the file to which metrics are written. The file
+	// is replaced atomically on every push, so it can be read by tools like
+	// the node_exporter textfile collector.
+	Path string
+}
+
+// Sink is a metric.Sink that writes the latest value of every metric to
+// a file in the OpenMetrics text format.
+type Sink struct {
+	mu     sync.Mutex
+	path   string
+	series map[string]series
+}
+
+type series struct {
+	name   string
+	labels string
+	value  float64
+}
+
+// New creates a new OpenMetrics text file sink.
+func New(cfg Config) (*Sink, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("path must not be empty")
+	}
+	return &Sink{path: cfg.Path, series: map[string]series{}}, nil
+}
+
+// Push implements the metric.Sink interface.
+func (s *Sink) Push(_ context.Context, points []metric.Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range points {
+		ser := series{name: MetricName(p.Name), labels: labels(p.Tags), value: p.Value}
+		s.series[ser.name+ser.labels] = ser
+	}
+	return s.write()
+}
+
+// write writes all series to a temporary file and then renames it to
+// the target path.
+func (s *Sink) write() error {
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("unable to create metrics file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if err := encode(f, s.sorted()); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write metrics file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write metrics file: %w", err)
+	}
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		return fmt.Errorf("unable to replace metrics file: %w", err)
+	}
+	return nil
+}
+
+func (s *Sink) sorted() []series {
+	list := make([]series, 0, len(s.series))
+	for _, ser := range s.series {
+		list = append(list, ser)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].name != list[j].name {
+			return list[i].name < list[j].name
+		}
+		return list[i].labels < list[j].labels
+	})
+	return list
+}
+
+// encode writes the given series, sorted by name, in the OpenMetrics text
+// format.
+func encode(w io.Writer, list []series) error {
+	var b strings.Builder
+	last := ""
+	for _, ser := range list {
+		if ser.name != last {
+			fmt.Fprintf(&b, "# TYPE %s gauge\n", ser.name)
+			last = ser.name
+		}
+		fmt.Fprintf(&b, "%s%s %s\n", ser.name, ser.labels, strconv.FormatFloat(ser.value, 'g', -1, 64))
+	}
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MetricName converts the given name to a valid OpenMetrics metric name
+// by replacing invalid characters with underscores.
+func MetricName(name string) string {
+	return sanitize(name, true)
+}
+
+// LabelName converts the given name to a valid OpenMetrics label name
+// by replacing invalid characters with underscores.
+func LabelName(name string) string {
+	return sanitize(name, false)
+}
+
+// Labels merges tags into a map of label names and values. Values of tags
+// with the same name are joined with a comma.
+func Labels(tags []metric.Tag) map[string]string {
+	m := map[string]string{}
+	for _, t := range tags {
+		n := LabelName(t.Name)
+		if v, ok := m[n]; ok {
+			m[n] = v + "," + t.Value
+			continue
+		}
+		m[n] = t.Value
+	}
+	return m
+}
+
+func labels(tags []metric.Tag) string {
+	m := Labels(tags)
+	if len(m) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString("{")
+	for i, n := range names {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", n, labelValueReplacer.Replace(m[n]))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func sanitize(name string, colon bool) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		case c == ':' && colon:
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,53 @@
+package openmetrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric"
+)
+
+func TestSink_Push(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.prom")
+	s, err := New(Config{Path: path})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Push(context.Background(), []metric.Point{
+		{Name: "price.ETHUSD", Value: 1800.5, Tags: []metric.Tag{{Name: "origin", Value: "binance"}}},
+		{Name: "price.ETHUSD", Value: 1801, Tags: []metric.Tag{{Name: "origin", Value: "kraken"}}},
+		{Name: "errors", Value: 1},
+	}))
+	require.NoError(t, s.Push(context.Background(), []metric.Point{
+		{Name: "errors", Value: 2},
+	}))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# TYPE errors gauge
+errors 2
+# TYPE price_ETHUSD gauge
+price_ETHUSD{origin="binance"} 1800.5
+price_ETHUSD{origin="kraken"} 1801
+# EOF
+`, string(b))
+}
+
+func TestLabels(t *testing.T) {
+	assert.Equal(t, map[string]string{"a_b": "x,y", "c": `"`}, Labels([]metric.Tag{
+		{Name: "a.b", Value: "x"},
+		{Name: "a.b", Value: "y"},
+		{Name: "c", Value: `"`},
+	}))
+	assert.Equal(t, `{c="\""}`, labels([]metric.Tag{{Name: "c", Value: `"`}}))
+}
+
+func TestMetricName(t *testing.T) {
+	assert.Equal(t, "a_b:c", MetricName("a.b:c"))
+	assert.Equal(t, "_a", MetricName("1a"))
+	assert.Equal(t, "a_b_c", LabelName("a.b:c"))
+}
@@ -0,0 +1,145 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric/openmetrics"
+)
+
+// Kind is the kind of Prometheus metrics created by the sink.
+type Kind int
+
+const (
+	// Gauge sets the gauge to the value of the metric point.
+	Gauge Kind = iota
+
+	// Counter adds the value of the metric point to the counter. Points
+	// with negative values are ignored.
+	Counter
+)
+
+// Config is the configuration for the Prometheus sink.
+type Config struct {
+	// Registerer is the registry in which metrics are registered.
+	Registerer prometheus.Registerer
+
+	// Kind is the kind of metrics created by the sink.
+	Kind Kind
+}
+
+// Sink is a metric.Sink that exposes metric points as Prometheus gauges or
+// counters. Metrics are created on the first push of a point with a given
+// name. All points with the same name must use the same set of tags.
+type Sink struct {
+	mu         sync.Mutex
+	registerer prometheus.Registerer
+	kind       Kind
+	gauges     map[string]*prometheus.GaugeVec
+	counters   map[string]*prometheus.CounterVec
+	labels     map[string][]string
+}
+
+// New creates a new Prometheus sink.
+func New(cfg Config) (*Sink, error) {
+	if cfg.Registerer == nil {
+		return nil, errors.New("registerer must not be nil")
+	}
+	return &Sink{
+		registerer: cfg.Registerer,
+		kind:       cfg.Kind,
+		gauges:     map[string]*prometheus.GaugeVec{},
+		counters:   map[string]*prometheus.CounterVec{},
+		labels:     map[string][]string{},
+	}, nil
+}
+
+// Push implements the metric.Sink interface.
+func (s *Sink) Push(_ context.Context, points []metric.Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	for _, p := range points {
+		if pErr := s.push(p); pErr != nil {
+			err = multierror.Append(err, pErr)
+		}
+	}
+	return err
+}
+
+func (s *Sink) push(p metric.Point) error {
+	name := openmetrics.MetricName(p.Name)
+	labels := openmetrics.Labels(p.Tags)
+	names, ok := s.labels[name]
+	if !ok {
+		for n := range labels {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if err := s.register(name, names); err != nil {
+			return err
+		}
+		s.labels[name] = names
+	}
+	if len(names) != len(labels) {
+		return fmt.Errorf("metric %s: inconsistent label names", name)
+	}
+	values := make([]string, len(names))
+	for i, n := range names {
+		v, ok := labels[n]
+		if !ok {
+			return fmt.Errorf("metric %s: inconsistent label names", name)
+		}
+		values[i] = v
+	}
+	switch s.kind {
+	case Counter:
+		if p.Value < 0 {
+			return fmt.Errorf("metric %s: counter cannot decrease", name)
+		}
+		s.counters[name].WithLabelValues(values...).Add(p.Value)
+	default:
+		s.gauges[name].WithLabelValues(values...).Set(p.Value)
+	}
+	return nil
+}
+
+func (s *Sink) register(name string, labels []string) error {
+	var c prometheus.Collector
+	switch s.kind {
+	case Counter:
+		v := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: "Metric extracted from logs."}, labels)
+		s.counters[name] = v
+		c = v
+	default:
+		v := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: "Metric extracted from logs."}, labels)
+		s.gauges[name] = v
+		c = v
+	}
+	if err := s.registerer.Register(c); err != nil {
+		return fmt.Errorf("metric %s: %w", name, err)
+	}
+	return nil
+}
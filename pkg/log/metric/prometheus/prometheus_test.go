@@ -0,0 +1,61 @@
+package prometheus
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric"
+)
+
+func TestSink_Gauge(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	s, err := New(Config{Registerer: reg, Kind: Gauge})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Push(context.Background(), []metric.Point{
+		{Name: "price.ETHUSD", Value: 1800, Tags: []metric.Tag{{Name: "origin", Value: "binance"}}},
+	}))
+	require.NoError(t, s.Push(context.Background(), []metric.Point{
+		{Name: "price.ETHUSD", Value: 1801, Tags: []metric.Tag{{Name: "origin", Value: "binance"}}},
+	}))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP price_ETHUSD Metric extracted from logs.
+# TYPE price_ETHUSD gauge
+price_ETHUSD{origin="binance"} 1801
+`), "price_ETHUSD"))
+}
+
+func TestSink_Counter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	s, err := New(Config{Registerer: reg, Kind: Counter})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Push(context.Background(), []metric.Point{{Name: "errors", Value: 2}}))
+	require.NoError(t, s.Push(context.Background(), []metric.Point{{Name: "errors", Value: 3}}))
+	assert.Error(t, s.Push(context.Background(), []metric.Point{{Name: "errors", Value: -1}}))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP errors Metric extracted from logs.
+# TYPE errors counter
+errors 5
+`), "errors"))
+}
+
+func TestSink_InconsistentLabels(t *testing.T) {
+	s, err := New(Config{Registerer: prometheus.NewRegistry()})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Push(context.Background(), []metric.Point{
+		{Name: "a", Value: 1, Tags: []metric.Tag{{Name: "x", Value: "1"}}},
+	}))
+	assert.Error(t, s.Push(context.Background(), []metric.Point{
+		{Name: "a", Value: 1, Tags: []metric.Tag{{Name: "y", Value: "1"}}},
+	}))
+}
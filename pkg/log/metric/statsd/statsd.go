@@ -0,0 +1,132 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package statsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric"
+)
+
+// maxPacketSize is the maximum size of a UDP packet. It is small enough
+// to avoid fragmentation on most networks.
+const maxPacketSize = 1432
+
+// Config is the configuration for the StatsD sink.
+type Config struct {
+	// Address is the UDP address of the StatsD server, e.g.
+	// "127.0.0.1:8125".
+	Address string
+
+	// Prefix is an optional prefix added to all metric names.
+	Prefix string
+}
+
+// Sink is a metric.Sink that sends metric points as gauges to a StatsD
+// server over UDP. Tags are sent using the DogStatsD extension.
+type Sink struct {
+	address string
+	prefix  string
+}
+
+// New creates a new StatsD sink.
+func New(cfg Config) (*Sink, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("address must not be empty")
+	}
+	if _, err := net.ResolveUDPAddr("udp", cfg.Address); err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+	return &Sink{address: cfg.Address, prefix: cfg.Prefix}, nil
+}
+
+// Push implements the metric.Sink interface.
+func (s *Sink) Push(ctx context.Context, points []metric.Point) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", s.address)
+	if err != nil {
+		return fmt.Errorf("unable to connect to StatsD server: %w", err)
+	}
+	defer conn.Close()
+	for _, packet := range packets(s.lines(points)) {
+		if _, err := conn.Write(packet); err != nil {
+			return fmt.Errorf("unable to send metrics to StatsD server: %w", err)
+		}
+	}
+	return nil
+}
+
+// lines returns metric points encoded in the StatsD line format.
+func (s *Sink) lines(points []metric.Point) []string {
+	lines := make([]string, 0, len(points))
+	for _, p := range points {
+		var b strings.Builder
+		b.WriteString(sanitize(s.prefix + p.Name))
+		b.WriteString(":")
+		b.WriteString(strconv.FormatFloat(p.Value, 'f', -1, 64))
+		b.WriteString("|g")
+		if len(p.Tags) > 0 {
+			tags := make([]string, len(p.Tags))
+			for i, t := range p.Tags {
+				tags[i] = sanitize(t.Name) + ":" + sanitize(t.Value)
+			}
+			sort.Strings(tags)
+			b.WriteString("|#")
+			b.WriteString(strings.Join(tags, ","))
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// packets groups lines into packets that do not exceed maxPacketSize.
+func packets(lines []string) [][]byte {
+	var (
+		res [][]byte
+		cur []byte
+	)
+	for _, l := range lines {
+		if len(cur) > 0 && len(cur)+len(l)+1 > maxPacketSize {
+			res = append(res, cur)
+			cur = nil
+		}
+		if len(cur) > 0 {
+			cur = append(cur, '\n')
+		}
+		cur = append(cur, l...)
+	}
+	if len(cur) > 0 {
+		res = append(res, cur)
+	}
+	return res
+}
+
+// sanitize replaces characters that have a special meaning in the StatsD
+// line format.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', ',', '#', '@', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
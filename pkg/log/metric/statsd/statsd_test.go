@@ -0,0 +1,44 @@
+package statsd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/metric"
+)
+
+func TestSink_Push(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := New(Config{Address: conn.LocalAddr().String(), Prefix: "oracle."})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Push(context.Background(), []metric.Point{
+		{Name: "price", Value: 1800.5, Tags: []metric.Tag{{Name: "pair", Value: "ETH/USD"}, {Name: "origin", Value: "a:b"}}},
+		{Name: "errors", Value: 1},
+	}))
+
+	buf := make([]byte, maxPacketSize)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"oracle.price:1800.5|g|#origin:a_b,pair:ETH/USD",
+		"oracle.errors:1|g",
+	}, strings.Split(string(buf[:n]), "\n"))
+}
+
+func TestPackets(t *testing.T) {
+	line := strings.Repeat("a", maxPacketSize/2-1)
+	assert.Len(t, packets([]string{line, line, line}), 2)
+	assert.Len(t, packets([]string{"a", "b"}), 1)
+	assert.Len(t, packets(nil), 0)
+}
@@ -0,0 +1,183 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package redact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+)
+
+// RedactFunc returns the given string with sensitive data removed.
+type RedactFunc func(s string) string
+
+// New returns a logger that removes sensitive data from log messages, string
+// fields and errors using the given function before passing them to the
+// given logger.
+//
+// If the given logger implements the log.LoggerService interface, the
+// returned logger implements it too.
+func New(logger log.Logger, redact RedactFunc) log.Logger {
+	l := &redactLogger{logger: logger, redact: redact}
+	if srv, ok := logger.(log.LoggerService); ok {
+		return &redactService{redactLogger: l, srv: srv}
+	}
+	return l
+}
+
+type redactLogger struct {
+	logger log.Logger
+	redact RedactFunc
+}
+
+type redactService struct {
+	*redactLogger
+	srv log.LoggerService
+}
+
+// Start implements the supervisor.Service interface.
+func (s *redactService) Start(ctx context.Context) error {
+	return s.srv.Start(ctx)
+}
+
+// Wait implements the supervisor.Service interface.
+func (s *redactService) Wait() <-chan error {
+	return s.srv.Wait()
+}
+
+// Level implements the log.Logger interface.
+func (l *redactLogger) Level() log.Level {
+	return l.logger.Level()
+}
+
+// WithField implements the log.Logger interface.
+func (l *redactLogger) WithField(key string, value any) log.Logger {
+	return New(l.logger.WithField(key, l.value(value)), l.redact)
+}
+
+// WithFields implements the log.Logger interface.
+func (l *redactLogger) WithFields(fields log.Fields) log.Logger {
+	f := make(log.Fields, len(fields))
+	for k, v := range fields {
+		f[k] = l.value(v)
+	}
+	return New(l.logger.WithFields(f), l.redact)
+}
+
+// WithError implements the log.Logger interface.
+func (l *redactLogger) WithError(err error) log.Logger {
+	return New(l.logger.WithError(l.error(err)), l.redact)
+}
+
+// Debugf implements the log.Logger interface.
+func (l *redactLogger) Debugf(format string, args ...any) {
+	if l.logger.Level() < log.Debug {
+		return
+	}
+	l.logger.Debug(l.redact(fmt.Sprintf(format, args...)))
+}
+
+// Infof implements the log.Logger interface.
+func (l *redactLogger) Infof(format string, args ...any) {
+	if l.logger.Level() < log.Info {
+		return
+	}
+	l.logger.Info(l.redact(fmt.Sprintf(format, args...)))
+}
+
+// Warnf implements the log.Logger interface.
+func (l *redactLogger) Warnf(format string, args ...any) {
+	if l.logger.Level() < log.Warn {
+		return
+	}
+	l.logger.Warn(l.redact(fmt.Sprintf(format, args...)))
+}
+
+// Errorf implements the log.Logger interface.
+func (l *redactLogger) Errorf(format string, args ...any) {
+	if l.logger.Level() < log.Error {
+		return
+	}
+	l.logger.Error(l.redact(fmt.Sprintf(format, args...)))
+}
+
+// Panicf implements the log.Logger interface.
+func (l *redactLogger) Panicf(format string, args ...any) {
+	l.logger.Panic(l.redact(fmt.Sprintf(format, args...)))
+}
+
+// Debug implements the log.Logger interface.
+func (l *redactLogger) Debug(args ...any) {
+	if l.logger.Level() < log.Debug {
+		return
+	}
+	l.logger.Debug(l.redact(fmt.Sprint(args...)))
+}
+
+// Info implements the log.Logger interface.
+func (l *redactLogger) Info(args ...any) {
+	if l.logger.Level() < log.Info {
+		return
+	}
+	l.logger.Info(l.redact(fmt.Sprint(args...)))
+}
+
+// Warn implements the log.Logger interface.
+func (l *redactLogger) Warn(args ...any) {
+	if l.logger.Level() < log.Warn {
+		return
+	}
+	l.logger.Warn(l.redact(fmt.Sprint(args...)))
+}
+
+// Error implements the log.Logger interface.
+func (l *redactLogger) Error(args ...any) {
+	if l.logger.Level() < log.Error {
+		return
+	}
+	l.logger.Error(l.redact(fmt.Sprint(args...)))
+}
+
+// Panic implements the log.Logger interface.
+func (l *redactLogger) Panic(args ...any) {
+	l.logger.Panic(l.redact(fmt.Sprint(args...)))
+}
+
+func (l *redactLogger) value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return l.redact(v)
+	case error:
+		return l.error(v)
+	}
+	return v
+}
+
+// error returns the error with a redacted message. If the message does not
+// contain sensitive data, the original error is returned, so that fields of
+// errors implementing the log.ErrorWithFields interface are preserved.
+func (l *redactLogger) error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if r := l.redact(msg); r != msg {
+		return errors.New(r)
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package redact
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/callback"
+)
+
+func TestLogger(t *testing.T) {
+	var (
+		msgs   []string
+		fields []log.Fields
+	)
+	base := callback.New(log.Info, func(_ log.Level, f log.Fields, msg string) {
+		msgs = append(msgs, msg)
+		fields = append(fields, f)
+	})
+	logger := New(base, func(s string) string {
+		return strings.ReplaceAll(s, "secret", "(redacted)")
+	})
+
+	logger.
+		WithField("key", "secret").
+		WithFields(log.Fields{"num": 1, "other": "no secret here"}).
+		WithError(errors.New("invalid secret")).
+		Infof("using %s", "secret")
+	logger.Warn("the ", "secret")
+	logger.Debug("secret") // Below the log level.
+
+	assert.Equal(t, []string{"using (redacted)", "the (redacted)"}, msgs)
+	assert.Equal(t, log.Fields{
+		"key":   "(redacted)",
+		"num":   1,
+		"other": "no (redacted) here",
+		"err":   "invalid (redacted)",
+	}, fields[0])
+}
@@ -0,0 +1,47 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+)
+
+const LoggerTag = "METRICS"
+
+// Handler returns an HTTP handler that exposes metrics from the Registry
+// in the Prometheus text format. Errors are logged using the given logger.
+func Handler(logger log.Logger) http.Handler {
+	if logger == nil {
+		logger = null.New()
+	}
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		ErrorLog: errorLogger{log: logger.WithField("tag", LoggerTag)},
+	})
+}
+
+// errorLogger adapts log.Logger to the promhttp.Logger interface.
+type errorLogger struct {
+	log log.Logger
+}
+
+func (l errorLogger) Println(v ...any) {
+	l.log.Error(v...)
+}
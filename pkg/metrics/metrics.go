@@ -0,0 +1,133 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package metrics provides Prometheus metrics that are exported by all
+// services of the suite.
+//
+// Metrics are registered in the Registry. They can be exposed using
+// the HTTP handler returned by Handler, which is served by the admin
+// server.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "oracle"
+
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Registry is the registry in which all metrics are registered.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// OriginFetches counts price fetches from origins.
+	OriginFetches = newCounterVec(
+		"origin_fetches_total",
+		"Number of price fetches from origins.",
+		"origin", "status",
+	)
+
+	// OriginFetchDuration measures the time it takes to fetch prices from
+	// an origin.
+	OriginFetchDuration = newHistogramVec(
+		"origin_fetch_duration_seconds",
+		"Time it takes to fetch prices from an origin.",
+		"origin",
+	)
+
+	// Broadcasts counts messages broadcast using a transport.
+	Broadcasts = newCounterVec(
+		"transport_broadcasts_total",
+		"Number of messages broadcast using a transport.",
+		"transport", "topic", "status",
+	)
+
+	// MessagesReceived counts messages received from a transport.
+	MessagesReceived = newCounterVec(
+		"transport_messages_received_total",
+		"Number of messages received from a transport.",
+		"transport", "topic",
+	)
+
+	// MessagesRejected counts messages rejected by a transport.
+	MessagesRejected = newCounterVec(
+		"transport_messages_rejected_total",
+		"Number of messages rejected by a transport.",
+		"transport", "topic", "reason",
+	)
+
+	// Pokes counts attempts to update oracle contracts.
+	Pokes = newCounterVec(
+		"relay_pokes_total",
+		"Number of attempts to update oracle contracts.",
+		"pair", "status",
+	)
+
+	// RPCCalls counts calls to Ethereum RPC endpoints.
+	RPCCalls = newCounterVec(
+		"rpc_calls_total",
+		"Number of calls to Ethereum RPC endpoints.",
+		"endpoint", "method", "status",
+	)
+
+	// RPCCallDuration measures the duration of calls to Ethereum RPC
+	// endpoints.
+	RPCCallDuration = newHistogramVec(
+		"rpc_call_duration_seconds",
+		"Duration of calls to Ethereum RPC endpoints.",
+		"endpoint", "method",
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Status returns the value of the status label for the given error.
+func Status(err error) string {
+	if err != nil {
+		return StatusError
+	}
+	return StatusSuccess
+}
+
+func newCounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	Registry.MustRegister(c)
+	return c
+}
+
+func newHistogramVec(name, help string, labels ...string) *prometheus.HistogramVec {
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+		Buckets:   prometheus.DefBuckets,
+	}, labels)
+	Registry.MustRegister(h)
+	return h
+}
@@ -18,13 +18,18 @@ package feed
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/defiweb/go-eth/wallet"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/median"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider/marshal"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/timeutil"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
@@ -37,6 +42,7 @@ const LoggerTag = "FEED"
 // Feed is a service which periodically fetches prices and then sends them to
 // the Oracle network using transport layer.
 type Feed struct {
+	mu     sync.RWMutex
 	ctx    context.Context
 	waitCh chan error
 
@@ -45,6 +51,7 @@ type Feed struct {
 	transport     transport.Transport
 	interval      *timeutil.Ticker
 	pairs         []provider.Pair
+	health        *health.Reporter
 	log           log.Logger
 }
 
@@ -68,6 +75,10 @@ type Config struct {
 
 	// Logger is a current logger interface used by the Feed.
 	Logger log.Logger
+
+	// Health is the registry in which the Feed reports its status under
+	// the "price_feed" name. If nil, the status is not reported.
+	Health *health.Registry
 }
 
 // New creates a new instance of the Feed.
@@ -95,6 +106,7 @@ func New(cfg Config) (*Feed, error) {
 		transport:     cfg.Transport,
 		interval:      cfg.Interval,
 		pairs:         pairs,
+		health:        cfg.Health.Register("price_feed"),
 		log:           cfg.Logger.WithField("tag", LoggerTag),
 	}
 	return g, nil
@@ -113,6 +125,7 @@ func (g *Feed) Start(ctx context.Context) error {
 	g.interval.Start(g.ctx)
 	go g.broadcasterRoutine()
 	go g.contextCancelHandler()
+	g.health.Ready()
 	return nil
 }
 
@@ -121,16 +134,42 @@ func (g *Feed) Wait() <-chan error {
 	return g.waitCh
 }
 
+// SetPriceProvider replaces the price provider. It may be called while the
+// feed is running, the new provider is used from the next tick.
+func (g *Feed) SetPriceProvider(priceProvider provider.Provider) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.priceProvider = priceProvider
+}
+
+// SetPairs replaces the list of pairs for which prices are sent. It may be
+// called while the feed is running, the new list is used from the next tick.
+func (g *Feed) SetPairs(pairs []provider.Pair) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pairs = pairs
+}
+
 // broadcast sends price for single pair to the network. This method uses
 // current price from the Provider, so it must be updated beforehand.
-func (g *Feed) broadcast(pair provider.Pair) error {
-	var err error
+func (g *Feed) broadcast(pair provider.Pair) (err error) {
+	ctx, span := tracing.Start(g.ctx, "price.Feed.broadcast", tracing.WithAttributes(map[string]any{
+		"assetPair": pair.String(),
+	}))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 
 	// Create price.
-	tick, err := g.priceProvider.Price(pair)
+	g.mu.RLock()
+	priceProvider := g.priceProvider
+	g.mu.RUnlock()
+	tick, err := priceProvider.Price(pair)
 	if err != nil {
 		return err
 	}
+	span.SetAttribute("age", time.Since(tick.Time))
 	if tick.Error != "" {
 		return errors.New(tick.Error)
 	}
@@ -148,6 +187,7 @@ func (g *Feed) broadcast(pair provider.Pair) error {
 	if err != nil {
 		return err
 	}
+	tracing.Inject(ctx, msg.Meta)
 	if err := g.transport.Broadcast(messages.PriceV0MessageName, msg.AsV0()); err != nil {
 		return err
 	}
@@ -164,8 +204,13 @@ func (g *Feed) broadcasterRoutine() {
 			return
 		case <-g.interval.TickCh():
 			// Send prices to the network.
-			for _, pair := range g.pairs {
+			g.mu.RLock()
+			pairs := g.pairs
+			g.mu.RUnlock()
+			var failed error
+			for _, pair := range pairs {
 				if err := g.broadcast(pair); err != nil {
+					failed = fmt.Errorf("%s: %w", pair, err)
 					g.log.
 						WithField("assetPair", pair).
 						WithError(err).
@@ -176,6 +221,7 @@ func (g *Feed) broadcasterRoutine() {
 					WithField("assetPair", pair).
 					Info("Price broadcast")
 			}
+			g.health.Report(failed)
 		}
 	}
 }
@@ -194,5 +240,6 @@ func toPriceMessage(price *median.Price, provider *provider.Price) (*messages.Pr
 	return &messages.Price{
 		Price: price,
 		Trace: trace,
+		Meta:  map[string]string{},
 	}, nil
 }
@@ -18,6 +18,8 @@ package graph
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider"
@@ -33,11 +35,14 @@ const LoggerTag = "ASYNC_GOFER"
 // but allows updating prices asynchronously.
 type AsyncProvider struct {
 	*Provider
-	ctx    context.Context
-	waitCh chan error
-	feed   *feed.Feed
-	nodes  []nodes.Node
-	log    log.Logger
+	mu        sync.Mutex
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	waitCh    chan error
+	err       error
+	feed      *feed.Feed
+	nodes     []nodes.Node
+	log       log.Logger
 }
 
 // NewAsyncProvider returns a new AsyncGofer instance.
@@ -55,6 +60,19 @@ func NewAsyncProvider(
 	}, nil
 }
 
+// Clone returns a new, not started AsyncProvider that updates prices in
+// the same graphs. It allows restarting price updates after the provider
+// fails, while other services still use the previous instance to read
+// prices.
+func (a *AsyncProvider) Clone() *AsyncProvider {
+	return &AsyncProvider{
+		Provider: a.Provider,
+		waitCh:   make(chan error),
+		feed:     a.feed,
+		log:      a.log,
+	}
+}
+
 // Start starts asynchronous price updater.
 func (a *AsyncProvider) Start(ctx context.Context) error {
 	if a.ctx != nil {
@@ -64,7 +82,7 @@ func (a *AsyncProvider) Start(ctx context.Context) error {
 		return errors.New("context must not be nil")
 	}
 	a.log.Debug("Starting")
-	a.ctx = ctx
+	a.ctx, a.ctxCancel = context.WithCancel(ctx)
 
 	// To ensure that broken origins do not affect the fetching of prices from
 	// other origins, all nodes are grouped by origin, and a separate goroutine
@@ -79,8 +97,8 @@ func (a *AsyncProvider) Start(ctx context.Context) error {
 			}
 		}, graph)
 	}
-	for _, ns := range originNodes {
-		ns := ns
+	for origin, ns := range originNodes {
+		origin, ns := origin, ns
 		ttl := gcdTTL(ns)
 		if ttl < time.Second {
 			ttl = time.Second
@@ -95,12 +113,19 @@ func (a *AsyncProvider) Start(ctx context.Context) error {
 			}
 		}
 		go func() {
+			// A panic stops all workers and is reported as an error, so
+			// the provider can be restarted by the supervisor.
+			defer func() {
+				if r := recover(); r != nil {
+					a.fail(fmt.Errorf("price updates for origin %s panicked: %v", origin, r))
+				}
+			}()
 			ticker := time.NewTicker(ttl)
+			defer ticker.Stop()
 			feed()
 			for {
 				select {
 				case <-a.ctx.Done():
-					ticker.Stop()
 					return
 				case <-ticker.C:
 					feed()
@@ -118,10 +143,26 @@ func (a *AsyncProvider) Wait() <-chan error {
 	return a.waitCh
 }
 
+// fail stops the provider with the given error.
+func (a *AsyncProvider) fail(err error) {
+	a.mu.Lock()
+	if a.err == nil {
+		a.err = err
+	}
+	a.mu.Unlock()
+	a.ctxCancel()
+}
+
 func (a *AsyncProvider) contextCancelHandler() {
 	defer func() { close(a.waitCh) }()
 	defer a.log.Debug("Stopped")
 	<-a.ctx.Done()
+	a.mu.Lock()
+	err := a.err
+	a.mu.Unlock()
+	if err != nil {
+		a.waitCh <- err
+	}
 }
 
 // gcdTTL returns the greatest common divisor of nodes minTTLs.
@@ -1,15 +1,60 @@
 package graph
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider"
+	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider/graph/feed"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider/graph/nodes"
+	"github.com/chronicleprotocol/oracle-suite/pkg/price/provider/origins"
 )
 
+type panicNode struct {
+	*nodes.OriginNode
+}
+
+func (n panicNode) Price() nodes.OriginPrice {
+	panic("test")
+}
+
+func TestAsyncProvider_Panic(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+
+	p := provider.Pair{Base: "A", Quote: "B"}
+	root := nodes.NewMedianAggregatorNode(p, 1)
+	root.AddChild(panicNode{nodes.NewOriginNode(nodes.OriginPair{Origin: "a", Pair: p}, time.Second, time.Minute)})
+	ap, err := NewAsyncProvider(
+		map[provider.Pair]nodes.Node{p: root},
+		feed.NewFeed(origins.NewSet(nil), null.New()),
+		null.New(),
+	)
+	require.NoError(t, err)
+	require.NoError(t, ap.Start(ctx))
+
+	// A panic in a worker is reported as an error:
+	select {
+	case err := <-ap.Wait():
+		assert.ErrorContains(t, err, "price updates for origin a panicked")
+	case <-time.After(time.Second):
+		require.Fail(t, "provider did not fail")
+	}
+
+	// A clone uses the same graphs:
+	clone := ap.Clone()
+	assert.Same(t, ap.Provider, clone.Provider)
+	require.NoError(t, clone.Start(ctx))
+	ctxCancel()
+	for range clone.Wait() {
+	}
+}
+
 func Test_gcdTTL(t *testing.T) {
 	p := provider.Pair{Base: "A", Quote: "B"}
 	root := nodes.NewMedianAggregatorNode(p, 1)
@@ -23,6 +23,8 @@ import (
 
 	"github.com/defiweb/go-eth/types"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/metrics"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/query"
 )
 
@@ -201,7 +203,8 @@ func fetchResultListWithErrors(pairs []Pair, err error) []FetchResult {
 }
 
 type Set struct {
-	list map[string]Handler
+	list   map[string]Handler
+	health *health.Registry
 }
 
 func NewSet(list map[string]Handler) *Set {
@@ -212,6 +215,12 @@ func (e *Set) SetHandler(name string, handler Handler) {
 	e.list[name] = handler
 }
 
+// SetHealth sets the registry in which the status of every origin is
+// reported under the "origin.<name>" name.
+func (e *Set) SetHealth(r *health.Registry) {
+	e.health = r
+}
+
 func (e *Set) Handlers() map[string]Handler {
 	c := map[string]Handler{}
 	for k, v := range e.list {
@@ -252,7 +261,19 @@ func (e *Set) Fetch(originPairs map[string][]Pair) map[string][]FetchResult {
 				)
 				mu.Unlock()
 			} else {
+				t := time.Now()
 				resp := handler.Fetch(pairs)
+				metrics.OriginFetchDuration.WithLabelValues(origin).Observe(time.Since(t).Seconds())
+				var failed error
+				for _, fr := range resp {
+					metrics.OriginFetches.WithLabelValues(origin, metrics.Status(fr.Error)).Inc()
+					if fr.Error != nil {
+						failed = fr.Error
+					}
+				}
+				h := e.health.Register("origin." + origin)
+				h.Ready()
+				h.Report(failed)
 				mu.Lock()
 				frs[origin] = append(frs[origin], resp...)
 				mu.Unlock()
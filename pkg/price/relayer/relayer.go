@@ -30,10 +30,13 @@ import (
 	"github.com/defiweb/go-eth/crypto"
 	"github.com/defiweb/go-eth/types"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/metrics"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/median"
 	"github.com/chronicleprotocol/oracle-suite/pkg/price/store"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/timeutil"
 )
@@ -52,6 +55,7 @@ type Relayer struct {
 	pairs   map[string]*Pair
 	log     log.Logger
 	recover crypto.Recoverer
+	health  *health.Reporter
 }
 
 // Config is the configuration for Relayer.
@@ -70,6 +74,10 @@ type Config struct {
 	// Logger is a current logger interface used by the Relayer.
 	Logger log.Logger
 
+	// Health is the registry in which the Relayer reports its status under
+	// the "relayer" name. If nil, the status is not reported.
+	Health *health.Registry
+
 	// Recoverer provides a method to recover the public key from a signature.
 	// The default is crypto.ECRecoverer.
 	Recoverer crypto.Recoverer
@@ -102,6 +110,9 @@ type Pair struct {
 	// TODO(mdobak): Instead of updating the list periodically, we should
 	//               listen for events from the Medianizer contract.
 	FeedAddressesUpdateTicker *timeutil.Ticker
+
+	// cancel stops the FeedAddresses update routine.
+	cancel context.CancelFunc
 }
 
 func New(cfg Config) (*Relayer, error) {
@@ -121,6 +132,7 @@ func New(cfg Config) (*Relayer, error) {
 		pairs:   make(map[string]*Pair, len(cfg.Pairs)),
 		log:     cfg.Logger.WithField("tag", LoggerTag),
 		recover: cfg.Recoverer,
+		health:  cfg.Health.Register("relayer"),
 	}
 	for _, p := range cfg.Pairs {
 		r.pairs[p.AssetPair] = p
@@ -139,15 +151,14 @@ func (s *Relayer) Start(ctx context.Context) error {
 	s.log.Debug("Starting")
 	s.ctx = ctx
 	for _, p := range s.pairs {
-		if err := s.syncFeedAddresses(p); err != nil {
+		if err := s.startPair(p); err != nil {
 			return err
 		}
-		p.FeedAddressesUpdateTicker.Start(ctx)
-		go s.syncFeedAddressesRoutine(p)
 	}
 	s.ticker.Start(s.ctx)
 	go s.relayerRoutine()
 	go s.contextCancelHandler()
+	s.health.Ready()
 	return nil
 }
 
@@ -156,6 +167,72 @@ func (s *Relayer) Wait() <-chan error {
 	return s.waitCh
 }
 
+// SetPairs replaces the list of pairs handled by the Relayer. It may be
+// called while the Relayer is running. In that case, feed addresses of the
+// new pairs are synchronized before they replace the current ones, and if
+// that fails, the current pairs are kept.
+func (s *Relayer) SetPairs(pairs []*Pair) error {
+	commit, err := s.PreparePairs(pairs)
+	if err != nil {
+		return err
+	}
+	commit()
+	return nil
+}
+
+// PreparePairs prepares the replacement of the list of pairs handled by the
+// Relayer without modifying the current pairs. If the Relayer is running,
+// feed addresses of the new pairs are synchronized. It returns a function
+// that replaces the current pairs with the prepared ones.
+func (s *Relayer) PreparePairs(pairs []*Pair) (func(), error) {
+	if s.ctx != nil {
+		for _, p := range pairs {
+			if err := s.syncFeedAddresses(p); err != nil {
+				return nil, fmt.Errorf("unable to sync feed addresses of %s: %w", p.AssetPair, err)
+			}
+		}
+	}
+	return func() {
+		m := make(map[string]*Pair, len(pairs))
+		for _, p := range pairs {
+			m[p.AssetPair] = p
+		}
+		if s.ctx != nil {
+			for _, p := range pairs {
+				s.runPair(p)
+			}
+		}
+		s.mu.Lock()
+		old := s.pairs
+		s.pairs = m
+		s.mu.Unlock()
+		for _, p := range old {
+			if p.cancel != nil {
+				p.cancel()
+			}
+		}
+	}, nil
+}
+
+// startPair synchronizes feed addresses of the pair and starts the routine
+// that keeps them up to date.
+func (s *Relayer) startPair(p *Pair) error {
+	if err := s.syncFeedAddresses(p); err != nil {
+		return err
+	}
+	s.runPair(p)
+	return nil
+}
+
+// runPair starts the routine that keeps feed addresses of the pair up to
+// date.
+func (s *Relayer) runPair(p *Pair) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	p.cancel = cancel
+	p.FeedAddressesUpdateTicker.Start(ctx)
+	go s.syncFeedAddressesRoutine(ctx, p)
+}
+
 // relay tries to update an Oracle contract for given pair.
 // In returns a transaction hash if the update was successful.
 // If update is not required, it returns nil.
@@ -233,8 +310,33 @@ func (s *Relayer) relay(assetPair string) (*types.Hash, error) {
 			return nil, fmt.Errorf("not enough prices to achieve quorum: %d/%d", len(prices), oracleQuorum)
 		}
 
+		// Link the update to the traces of the prices used in it.
+		var (
+			links  []tracing.SpanContext
+			oldest time.Time
+		)
+		for _, price := range prices {
+			if sc, ok := tracing.FromMeta(price.Meta); ok {
+				links = append(links, sc)
+			}
+			if oldest.IsZero() || price.Price.Age.Before(oldest) {
+				oldest = price.Price.Age
+			}
+		}
+		ctx, span := tracing.Start(s.ctx, "relayer.Relayer.poke", tracing.WithLinks(links...), tracing.WithAttributes(map[string]any{
+			"assetPair": assetPair,
+			"prices":    len(prices),
+			"expired":   isExpired,
+			"stale":     isStale,
+			"maxAge":    time.Since(oldest),
+		}))
+		defer span.End()
+
 		// Send *actual* transaction.
-		return pair.Median.Poke(s.ctx, toOraclePrices(&prices), true)
+		tx, err := pair.Median.Poke(ctx, toOraclePrices(&prices), true)
+		metrics.Pokes.WithLabelValues(assetPair, metrics.Status(err)).Inc()
+		span.RecordError(err)
+		return tx, err
 	}
 
 	// There is no need to update the price.
@@ -262,11 +364,19 @@ func (s *Relayer) relayerRoutine() {
 		case <-s.ctx.Done():
 			return
 		case <-s.ticker.TickCh():
+			s.mu.Lock()
+			assetPairs := make([]string, 0, len(s.pairs))
 			for assetPair := range s.pairs {
+				assetPairs = append(assetPairs, assetPair)
+			}
+			s.mu.Unlock()
+			var failed error
+			for _, assetPair := range assetPairs {
 				tx, err := s.relay(assetPair)
 
 				// Print log in case of an error.
 				if err != nil {
+					failed = fmt.Errorf("%s: %w", assetPair, err)
 					s.log.
 						WithField("assetPair", assetPair).
 						WithError(err).
@@ -290,14 +400,15 @@ func (s *Relayer) relayerRoutine() {
 						Info("Oracle updated")
 				}
 			}
+			s.health.Report(failed)
 		}
 	}
 }
 
-func (s *Relayer) syncFeedAddressesRoutine(p *Pair) {
+func (s *Relayer) syncFeedAddressesRoutine(ctx context.Context, p *Pair) {
 	for {
 		select {
-		case <-s.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-p.FeedAddressesUpdateTicker.TickCh():
 			if err := s.syncFeedAddresses(p); err != nil {
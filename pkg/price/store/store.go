@@ -19,12 +19,17 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/defiweb/go-eth/crypto"
 	"github.com/defiweb/go-eth/types"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
 )
@@ -38,13 +43,15 @@ var ErrUnknownFeed = errors.New("messages from feed are not allowed")
 
 // PriceStore contains a list of prices.
 type PriceStore struct {
+	mu        sync.RWMutex
 	ctx       context.Context
 	storage   Storage
 	transport transport.Transport
 	pairs     []string
-	feeds     []string
+	feeds     *keyset.Allowlist
 	log       log.Logger
 	recover   crypto.Recoverer
+	health    *health.Reporter
 	waitCh    chan error
 }
 
@@ -63,10 +70,18 @@ type Config struct {
 	// Feeds is the list of feeds which are supported by the store.
 	Feeds []types.Address
 
+	// Allowlist is an optional allowlist of feeds that can be updated at
+	// runtime. If set, addresses from Feeds are added to it.
+	Allowlist *keyset.Allowlist
+
 	// Logger is a current logger interface used by the PriceStore.
 	// The Logger is required to monitor asynchronous processes.
 	Logger log.Logger
 
+	// Health is the registry in which the PriceStore reports its status under
+	// the "price_store" name. If nil, the status is not reported.
+	Health *health.Registry
+
 	// Recoverer provides a method to recover the public key from a signature.
 	// The default is crypto.ECREcoverer.
 	Recoverer crypto.Recoverer
@@ -108,17 +123,18 @@ func New(cfg Config) (*PriceStore, error) {
 	if cfg.Recoverer == nil {
 		cfg.Recoverer = crypto.ECRecoverer
 	}
-	feeds := make([]string, len(cfg.Feeds))
-	for i, feed := range cfg.Feeds {
-		feeds[i] = feed.String()
+	if cfg.Allowlist == nil {
+		cfg.Allowlist = keyset.NewAllowlist(nil)
 	}
+	cfg.Allowlist.Add(cfg.Feeds...)
 	return &PriceStore{
 		storage:   cfg.Storage,
 		transport: cfg.Transport,
 		pairs:     cfg.Pairs,
-		feeds:     feeds,
+		feeds:     cfg.Allowlist,
 		log:       cfg.Logger.WithField("tag", LoggerTag),
 		recover:   cfg.Recoverer,
+		health:    cfg.Health.Register("price_store"),
 		waitCh:    make(chan error),
 	}, nil
 }
@@ -135,6 +151,7 @@ func (p *PriceStore) Start(ctx context.Context) error {
 	p.ctx = ctx
 	go p.priceCollectorRoutine()
 	go p.contextCancelHandler()
+	p.health.Ready()
 	return nil
 }
 
@@ -169,7 +186,7 @@ func (p *PriceStore) collectPrice(price *messages.Price) error {
 	if err != nil {
 		return ErrInvalidSignature
 	}
-	if !p.isFeedSupported(from.String()) {
+	if !p.feeds.Allowed(*from) {
 		return ErrUnknownFeed
 	}
 	if !p.isPairSupported(price.Price.Wat) {
@@ -181,7 +198,25 @@ func (p *PriceStore) collectPrice(price *messages.Price) error {
 	return p.Add(p.ctx, *from, price)
 }
 
+// SetPairs replaces the list of asset pairs which are supported by the
+// store. It may be called while the store is running. Prices of removed
+// pairs that were already collected are kept in the storage.
+func (p *PriceStore) SetPairs(pairs []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pairs = pairs
+}
+
+// SetFeeds replaces the list of feeds which are allowed to send prices to
+// the store. It may be called while the store is running. Prices from
+// removed feeds are still accepted for keyset.DefaultOverlap.
+func (p *PriceStore) SetFeeds(feeds []types.Address) {
+	p.feeds.Update(feeds, 0)
+}
+
 func (p *PriceStore) isPairSupported(pair string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	for _, a := range p.pairs {
 		if a == pair {
 			return true
@@ -189,14 +224,6 @@ func (p *PriceStore) isPairSupported(pair string) bool {
 	}
 	return false
 }
-func (p *PriceStore) isFeedSupported(feed string) bool {
-	for _, a := range p.feeds {
-		if a == feed {
-			return true
-		}
-	}
-	return false
-}
 
 func (p *PriceStore) priceCollectorRoutine() {
 	priceV0Ch := p.transport.Messages(messages.PriceV0MessageName)
@@ -227,7 +254,17 @@ func (p *PriceStore) handlePriceMessage(msg transport.ReceivedMessage) {
 			Error("Unexpected value returned from the transport layer")
 		return
 	}
+	_, span := tracing.Start(
+		tracing.Extract(p.ctx, price.Meta),
+		"price.PriceStore.collectPrice",
+		tracing.WithAttributes(map[string]any{
+			"assetPair": price.Price.Wat,
+			"age":       time.Since(price.Price.Age),
+		}),
+	)
 	err := p.collectPrice(price)
+	span.RecordError(err)
+	span.End()
 	if err != nil {
 		p.log.
 			WithError(err).
@@ -236,6 +273,7 @@ func (p *PriceStore) handlePriceMessage(msg transport.ReceivedMessage) {
 			WithFields(msg.Fields()).
 			Warn("Price rejected")
 	} else {
+		p.health.Success()
 		p.log.
 			WithFields(price.Price.Fields(p.recover)).
 			WithField("version", price.Version).
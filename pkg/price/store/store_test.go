@@ -90,6 +90,20 @@ func TestStore(t *testing.T) {
 	assert.Contains(t, toOraclePrices(xxxyyy), testutil.PriceXXXYYY2.Price)
 }
 
+func TestStore_SetFeeds(t *testing.T) {
+	ps, err := New(Config{
+		Storage:   NewMemoryStorage(),
+		Transport: local.New([]byte("test"), 0, nil),
+		Feeds:     []types.Address{testutil.Address1},
+	})
+	require.NoError(t, err)
+	ps.SetFeeds([]types.Address{testutil.Address2})
+
+	// The removed feed is still allowed during the overlap window:
+	assert.True(t, ps.feeds.Allowed(testutil.Address1))
+	assert.True(t, ps.feeds.Allowed(testutil.Address2))
+}
+
 func toOraclePrices(ps []*messages.Price) []*median.Price {
 	var r []*median.Price
 	for _, p := range ps {
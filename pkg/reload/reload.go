@@ -0,0 +1,132 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package reload provides a service that reloads the configuration of
+// a running process when the process receives the SIGHUP signal or when
+// a reload is requested using the Reload method, e.g. by the admin API.
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+)
+
+const LoggerTag = "RELOAD"
+
+// ErrNotSupported is returned by services that do not support reloading
+// the configuration, e.g. because the Reloader is not configured.
+var ErrNotSupported = errors.New("configuration reload is not supported")
+
+// Config is the configuration for the Reloader.
+type Config struct {
+	// Reload loads the configuration and applies it to running services.
+	// If the configuration cannot be applied, it must return an error and
+	// leave running services unchanged.
+	Reload func() error
+
+	// Signals is a list of signals that trigger the reload. If empty,
+	// SIGHUP is used.
+	Signals []os.Signal
+
+	// Logger is used to log results of reloads. If nil, null logger is used.
+	Logger log.Logger
+}
+
+// Reloader is a service that reloads the configuration when the process
+// receives one of the configured signals. Only one reload is performed
+// at a time.
+type Reloader struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	waitCh  chan error
+	reload  func() error
+	signals []os.Signal
+	log     log.Logger
+}
+
+// New returns a new instance of Reloader.
+func New(cfg Config) (*Reloader, error) {
+	if cfg.Reload == nil {
+		return nil, errors.New("reload function must not be nil")
+	}
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = []os.Signal{syscall.SIGHUP}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = null.New()
+	}
+	return &Reloader{
+		waitCh:  make(chan error),
+		reload:  cfg.Reload,
+		signals: cfg.Signals,
+		log:     cfg.Logger.WithField("tag", LoggerTag),
+	}, nil
+}
+
+// Start implements the supervisor.Service interface.
+func (r *Reloader) Start(ctx context.Context) error {
+	if r.ctx != nil {
+		return errors.New("service can be started only once")
+	}
+	if ctx == nil {
+		return errors.New("context must not be nil")
+	}
+	r.log.Debug("Starting")
+	r.ctx = ctx
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, r.signals...)
+	go r.signalRoutine(sigCh)
+	return nil
+}
+
+// Wait implements the supervisor.Service interface.
+func (r *Reloader) Wait() <-chan error {
+	return r.waitCh
+}
+
+// Reload reloads the configuration and returns the result. Concurrent
+// calls are serialized.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.log.Info("Reloading configuration")
+	if err := r.reload(); err != nil {
+		r.log.WithError(err).Error("Unable to reload configuration")
+		return err
+	}
+	r.log.Info("Configuration reloaded")
+	return nil
+}
+
+func (r *Reloader) signalRoutine(sigCh chan os.Signal) {
+	defer func() { close(r.waitCh) }()
+	defer r.log.Debug("Stopped")
+	defer signal.Stop(sigCh)
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-sigCh:
+			_ = r.Reload()
+		}
+	}
+}
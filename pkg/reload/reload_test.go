@@ -0,0 +1,77 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	r, err := New(Config{Reload: func() error {
+		calls++
+		if calls > 1 {
+			return errors.New("invalid config")
+		}
+		return nil
+	}})
+	require.NoError(t, err)
+	require.NoError(t, r.Start(ctx))
+
+	require.NoError(t, r.Reload())
+	require.EqualError(t, r.Reload(), "invalid config")
+	assert.Equal(t, 2, calls)
+
+	cancel()
+	<-r.Wait()
+}
+
+func TestReloader_Signal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloadCh := make(chan struct{}, 1)
+	r, err := New(Config{
+		Reload: func() error {
+			reloadCh <- struct{}{}
+			return nil
+		},
+		Signals: []os.Signal{syscall.SIGUSR1},
+	})
+	require.NoError(t, err)
+	require.NoError(t, r.Start(ctx))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case <-reloadCh:
+	case <-time.After(time.Second):
+		t.Fatal("reload was not triggered by the signal")
+	}
+
+	cancel()
+	<-r.Wait()
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"reflect"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/metrics"
 	"github.com/chronicleprotocol/oracle-suite/pkg/rpcsplitter/types"
 )
 
@@ -533,6 +535,9 @@ func (s *server) call(
 				if r := recover(); r != nil {
 					err = fmt.Errorf("panic: %s", r)
 				}
+				endpoint := endpointLabel(n)
+				metrics.RPCCalls.WithLabelValues(endpoint, method, metrics.Status(err)).Inc()
+				metrics.RPCCallDuration.WithLabelValues(endpoint, method).Observe(time.Since(t).Seconds())
 				switch {
 				case err != nil:
 					s.log.
@@ -588,6 +593,16 @@ func (s *server) call(
 	}
 }
 
+// endpointLabel returns the value of the endpoint label for the caller with
+// the given name. Because endpoint URLs may contain API keys, only the host
+// name is used.
+func endpointLabel(name string) string {
+	if u, err := url.Parse(name); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return name
+}
+
 // removeTrailingNilArgs removes trailing nil parameters from the params
 // slice. Some RPC servers do not like null parameters and will return a
 // "bad request" error if they occur.
@@ -0,0 +1,268 @@
+//  Copyright (C) 2020 Maker Ecosystem Growth Holdings, INC.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package starknet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// eventsChunkSize is the maximum number of events fetched in a single
+// starknet_getEvents request.
+const eventsChunkSize = 1000
+
+// BlockID identifies a block in the JSON-RPC API. It is either a block
+// number or one of the "latest" or "pending" tags.
+type BlockID struct {
+	tag    string
+	number uint64
+}
+
+var (
+	LatestBlock  = BlockID{tag: "latest"}
+	PendingBlock = BlockID{tag: "pending"}
+)
+
+// BlockIDFromNumber returns a BlockID for the given block number.
+func BlockIDFromNumber(number uint64) BlockID {
+	return BlockID{number: number}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (b BlockID) MarshalJSON() ([]byte, error) {
+	if b.tag != "" {
+		return json.Marshal(b.tag)
+	}
+	return json.Marshal(struct {
+		BlockNumber uint64 `json:"block_number"`
+	}{BlockNumber: b.number})
+}
+
+// EventFilter is a filter for the starknet_getEvents method.
+type EventFilter struct {
+	FromBlock BlockID   `json:"from_block"`
+	ToBlock   BlockID   `json:"to_block"`
+	Address   *Felt     `json:"address,omitempty"`
+	Keys      [][]*Felt `json:"keys,omitempty"`
+}
+
+// EmittedEvent is an event returned by the starknet_getEvents method.
+type EmittedEvent struct {
+	Event
+	BlockHash       *Felt  `json:"block_hash"`
+	BlockNumber     uint64 `json:"block_number"`
+	TransactionHash *Felt  `json:"transaction_hash"`
+}
+
+// RPC is a client for the Starknet JSON-RPC API.
+//
+// It implements the same methods as the Sequencer, so it can be used
+// instead of the deprecated feeder gateway. Because the JSON-RPC API does
+// not return blocks with receipts, blocks are assembled from the block
+// header and events emitted in that block. Receipts in returned blocks
+// contain only transaction hashes, indexes and events.
+type RPC struct {
+	endpoint   string
+	httpClient http.Client
+	id         uint64
+}
+
+// NewRPC returns a new instance of the RPC client.
+func NewRPC(endpoint string, httpClient http.Client) *RPC {
+	return &RPC{endpoint: endpoint, httpClient: httpClient}
+}
+
+// BlockNumber returns the number of the latest accepted block.
+func (r *RPC) BlockNumber(ctx context.Context) (uint64, error) {
+	var res uint64
+	if err := r.call(ctx, &res, "starknet_blockNumber"); err != nil {
+		return 0, err
+	}
+	return res, nil
+}
+
+// GetPendingBlock returns the pending block.
+func (r *RPC) GetPendingBlock(ctx context.Context) (*Block, error) {
+	return r.getBlock(ctx, PendingBlock)
+}
+
+// GetLatestBlock returns the latest accepted block.
+func (r *RPC) GetLatestBlock(ctx context.Context) (*Block, error) {
+	return r.getBlock(ctx, LatestBlock)
+}
+
+// GetBlockByNumber returns the block with the given number.
+func (r *RPC) GetBlockByNumber(ctx context.Context, blockNumber uint64) (*Block, error) {
+	return r.getBlock(ctx, BlockIDFromNumber(blockNumber))
+}
+
+// GetEvents returns all events matching the given filter. It follows
+// continuation tokens until all events are fetched.
+func (r *RPC) GetEvents(ctx context.Context, filter EventFilter) ([]*EmittedEvent, error) {
+	var events []*EmittedEvent
+	var token string
+	for {
+		var res struct {
+			Events            []*EmittedEvent `json:"events"`
+			ContinuationToken string          `json:"continuation_token"`
+		}
+		req := struct {
+			EventFilter
+			ChunkSize         int    `json:"chunk_size"`
+			ContinuationToken string `json:"continuation_token,omitempty"`
+		}{
+			EventFilter:       filter,
+			ChunkSize:         eventsChunkSize,
+			ContinuationToken: token,
+		}
+		if err := r.call(ctx, &res, "starknet_getEvents", req); err != nil {
+			return nil, err
+		}
+		events = append(events, res.Events...)
+		if res.ContinuationToken == "" {
+			return events, nil
+		}
+		token = res.ContinuationToken
+	}
+}
+
+// rpcBlock is a block returned by the starknet_getBlockWithTxHashes method.
+type rpcBlock struct {
+	Status           string  `json:"status"`
+	BlockHash        *Felt   `json:"block_hash"`
+	ParentHash       *Felt   `json:"parent_hash"`
+	BlockNumber      uint64  `json:"block_number"`
+	NewRoot          string  `json:"new_root"`
+	Timestamp        int64   `json:"timestamp"`
+	SequencerAddress string  `json:"sequencer_address"`
+	Transactions     []*Felt `json:"transactions"`
+}
+
+func (r *RPC) getBlock(ctx context.Context, id BlockID) (*Block, error) {
+	var b rpcBlock
+	if err := r.call(ctx, &b, "starknet_getBlockWithTxHashes", id); err != nil {
+		return nil, err
+	}
+	block := &Block{
+		BlockHash:        b.BlockHash,
+		ParentBlockHash:  b.ParentHash,
+		BlockNumber:      b.BlockNumber,
+		StateRoot:        b.NewRoot,
+		Status:           b.Status,
+		Timestamp:        b.Timestamp,
+		SequencerAddress: b.SequencerAddress,
+	}
+	eventsID := BlockIDFromNumber(b.BlockNumber)
+	if id == PendingBlock {
+		// The pending block has no number and its status is not always
+		// reported, so it is set explicitly to match the feeder gateway.
+		block.Status = "PENDING"
+		eventsID = PendingBlock
+	}
+	receipts := map[string]*TransactionReceipt{}
+	for i, txHash := range b.Transactions {
+		receipt := &TransactionReceipt{TransactionIndex: i, TransactionHash: txHash}
+		receipts[txHash.String()] = receipt
+		block.Transactions = append(block.Transactions, &Transaction{TransactionHash: txHash})
+		block.TransactionReceipts = append(block.TransactionReceipts, receipt)
+	}
+	events, err := r.GetEvents(ctx, EventFilter{FromBlock: eventsID, ToBlock: eventsID})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		if e.TransactionHash == nil {
+			continue
+		}
+		receipt, ok := receipts[e.TransactionHash.String()]
+		if !ok {
+			// New transactions may be added to the pending block between
+			// the requests.
+			receipt = &TransactionReceipt{
+				TransactionIndex: len(block.TransactionReceipts),
+				TransactionHash:  e.TransactionHash,
+			}
+			receipts[e.TransactionHash.String()] = receipt
+			block.Transactions = append(block.Transactions, &Transaction{TransactionHash: e.TransactionHash})
+			block.TransactionReceipts = append(block.TransactionReceipts, receipt)
+		}
+		evt := e.Event
+		receipt.Events = append(receipt.Events, &evt)
+	}
+	return block, nil
+}
+
+// rpcError is an error returned by the JSON-RPC API.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (r *RPC) call(ctx context.Context, result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	reqBody, err := json.Marshal(struct {
+		JSONRPC string `json:"jsonrpc"`
+		ID      uint64 `json:"id"`
+		Method  string `json:"method"`
+		Params  []any  `json:"params"`
+	}{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&r.id, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return Error{Err: err}
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", r.endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return Error{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return Error{Err: err}
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return HTTPError{StatusCode: res.StatusCode}
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Error{Err: err}
+	}
+	var rpcRes struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(body, &rpcRes); err != nil {
+		return Error{Err: err}
+	}
+	if rpcRes.Error != nil {
+		return Error{Err: fmt.Errorf("%s: %d %s", method, rpcRes.Error.Code, rpcRes.Error.Message)}
+	}
+	if err := json.Unmarshal(rpcRes.Result, result); err != nil {
+		return Error{Err: err}
+	}
+	return nil
+}
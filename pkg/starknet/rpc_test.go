@@ -0,0 +1,169 @@
+//  Copyright (C) 2020 Maker Ecosystem Growth Holdings, INC.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rpcRequest struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func newRPCStub(t *testing.T, handler func(req rpcRequest) (any, *rpcError)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		res, rpcErr := handler(req)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  res,
+			"error":   rpcErr,
+		})
+	}))
+}
+
+func Test_BlockID_Marshal(t *testing.T) {
+	tests := []struct {
+		arg  BlockID
+		want string
+	}{
+		{arg: LatestBlock, want: `"latest"`},
+		{arg: PendingBlock, want: `"pending"`},
+		{arg: BlockIDFromNumber(42), want: `{"block_number":42}`},
+	}
+	for _, tt := range tests {
+		j, err := json.Marshal(tt.arg)
+		require.NoError(t, err)
+		assert.JSONEq(t, tt.want, string(j))
+	}
+}
+
+func TestRPC_GetBlockByNumber(t *testing.T) {
+	var eventsCalls int
+	srv := newRPCStub(t, func(req rpcRequest) (any, *rpcError) {
+		switch req.Method {
+		case "starknet_getBlockWithTxHashes":
+			assert.JSONEq(t, `{"block_number":10}`, string(req.Params[0]))
+			return map[string]any{
+				"status":       "ACCEPTED_ON_L2",
+				"block_hash":   "0xb10c",
+				"parent_hash":  "0xb10b",
+				"block_number": 10,
+				"timestamp":    1650000000,
+				"transactions": []string{"0x1", "0x2"},
+			}, nil
+		case "starknet_getEvents":
+			eventsCalls++
+			var filter struct {
+				FromBlock         json.RawMessage `json:"from_block"`
+				ToBlock           json.RawMessage `json:"to_block"`
+				ContinuationToken string          `json:"continuation_token"`
+			}
+			require.NoError(t, json.Unmarshal(req.Params[0], &filter))
+			assert.JSONEq(t, `{"block_number":10}`, string(filter.FromBlock))
+			assert.JSONEq(t, `{"block_number":10}`, string(filter.ToBlock))
+			if filter.ContinuationToken == "" {
+				return map[string]any{
+					"events": []map[string]any{
+						{"from_address": "0xa", "keys": []string{"0x11"}, "data": []string{"0xd1"}, "transaction_hash": "0x2"},
+					},
+					"continuation_token": "next",
+				}, nil
+			}
+			return map[string]any{
+				"events": []map[string]any{
+					{"from_address": "0xa", "keys": []string{"0x12"}, "data": []string{"0xd2"}, "transaction_hash": "0x2"},
+				},
+			}, nil
+		}
+		return nil, &rpcError{Code: -32601, Message: "method not found"}
+	})
+	defer srv.Close()
+
+	block, err := NewRPC(srv.URL, http.Client{}).GetBlockByNumber(context.Background(), 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, eventsCalls)
+	assert.Equal(t, uint64(10), block.BlockNumber)
+	assert.Equal(t, HexToFelt("0xb10c"), block.BlockHash)
+	assert.Equal(t, HexToFelt("0xb10b"), block.ParentBlockHash)
+	assert.Equal(t, int64(1650000000), block.Timestamp)
+	require.Len(t, block.TransactionReceipts, 2)
+	assert.Equal(t, HexToFelt("0x1"), block.TransactionReceipts[0].TransactionHash)
+	assert.Empty(t, block.TransactionReceipts[0].Events)
+	assert.Equal(t, 1, block.TransactionReceipts[1].TransactionIndex)
+	require.Len(t, block.TransactionReceipts[1].Events, 2)
+	assert.Equal(t, HexToFelt("0xa"), block.TransactionReceipts[1].Events[0].FromAddress)
+	assert.Equal(t, []*Felt{HexToFelt("0xd2")}, block.TransactionReceipts[1].Events[1].Data)
+}
+
+func TestRPC_GetPendingBlock(t *testing.T) {
+	srv := newRPCStub(t, func(req rpcRequest) (any, *rpcError) {
+		switch req.Method {
+		case "starknet_getBlockWithTxHashes":
+			assert.JSONEq(t, `"pending"`, string(req.Params[0]))
+			return map[string]any{
+				"parent_hash":  "0xb10c",
+				"timestamp":    1650000000,
+				"transactions": []string{"0x1"},
+			}, nil
+		case "starknet_getEvents":
+			return map[string]any{
+				"events": []map[string]any{
+					{"from_address": "0xa", "keys": []string{"0x13"}, "data": []string{}, "transaction_hash": "0x3"},
+				},
+			}, nil
+		}
+		return nil, &rpcError{Code: -32601, Message: "method not found"}
+	})
+	defer srv.Close()
+
+	block, err := NewRPC(srv.URL, http.Client{}).GetPendingBlock(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "PENDING", block.Status)
+	require.Len(t, block.TransactionReceipts, 2)
+	assert.Equal(t, HexToFelt("0x3"), block.TransactionReceipts[1].TransactionHash)
+	assert.Len(t, block.TransactionReceipts[1].Events, 1)
+}
+
+func TestRPC_Errors(t *testing.T) {
+	srv := newRPCStub(t, func(req rpcRequest) (any, *rpcError) {
+		return nil, &rpcError{Code: 24, Message: "Block not found"}
+	})
+	defer srv.Close()
+
+	_, err := NewRPC(srv.URL, http.Client{}).GetLatestBlock(context.Background())
+	assert.IsType(t, Error{}, err)
+
+	srv429 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv429.Close()
+
+	_, err = NewRPC(srv429.URL, http.Client{}).GetLatestBlock(context.Background())
+	assert.Equal(t, HTTPError{StatusCode: http.StatusTooManyRequests}, err)
+}
@@ -18,14 +18,27 @@ package supervisor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 )
 
 const LoggerTag = "SUPERVISOR"
 
+const (
+	defaultBackoffInitial    = time.Second
+	defaultBackoffMax        = time.Minute
+	defaultBackoffMultiplier = 2
+)
+
 // Service that could be managed by Supervisor.
 type Service interface {
 	// Start starts the service.
@@ -37,15 +50,96 @@ type Service interface {
 	Wait() <-chan error
 }
 
+// RestartPolicy describes what the Supervisor does when a service stops.
+type RestartPolicy int
+
+const (
+	// RestartNever never restarts the service. If the service fails, all
+	// other services are stopped. This is the default policy.
+	RestartNever RestartPolicy = iota
+
+	// RestartOnFailure restarts the service if it fails. If the service
+	// fails more than MaxRetries times in a row, all other services are
+	// stopped. If the service stops without an error, it is not restarted.
+	RestartOnFailure
+
+	// RestartAlways restarts the service whenever it stops, until the
+	// Supervisor is stopped.
+	RestartAlways
+)
+
+// Backoff describes an exponential backoff between service restarts.
+type Backoff struct {
+	// Initial is the delay before the first restart.
+	// If zero, 1 second is used.
+	Initial time.Duration
+
+	// Max is the maximum delay between restarts. If a service runs longer
+	// than Max, the number of consecutive failures is reset.
+	// If zero, 1 minute is used.
+	Max time.Duration
+
+	// Multiplier is the factor by which the delay grows after every
+	// consecutive restart. If zero, 2 is used.
+	Multiplier float64
+}
+
+// ServiceConfig is the configuration of a service managed by Supervisor.
+type ServiceConfig struct {
+	// Service is the service to start.
+	Service Service
+
+	// Name is the name of the service used in logs and in the DependsOn
+	// field. If empty, the name of the service type is used.
+	Name string
+
+	// RestartPolicy is the restart policy of the service.
+	RestartPolicy RestartPolicy
+
+	// Factory creates a new instance of the service when the service is
+	// restarted. Because services can be started only once, it is required
+	// for restart policies other than RestartNever.
+	//
+	// Other services that hold a reference to the previous instance are
+	// not updated, so the restart policies should be used only for services
+	// that other services do not depend on directly, or that are used
+	// through a wrapper that forwards calls to the running instance, like
+	// the restartable transport.
+	Factory func() (Service, error)
+
+	// MaxRetries is the maximum number of consecutive restarts for the
+	// RestartOnFailure policy. If zero, the number of restarts is unlimited.
+	MaxRetries int
+
+	// Backoff is the backoff between restarts.
+	Backoff Backoff
+
+	// DependsOn is a list of names of services that must be started before
+	// this service.
+	DependsOn []string
+}
+
 // Supervisor manages long-running services that implement the Service
-// interface. If any of the managed services fail, all other services are
-// stopped. This ensures that all services are running or none.
+// interface. By default, if any of the managed services fail, all other
+// services are stopped. This ensures that all services are running or none.
+// This behavior can be changed per service using the restart policies, see
+// WatchService.
 type Supervisor struct {
+	mu        sync.Mutex
+	wg        sync.WaitGroup
 	ctx       context.Context
 	ctxCancel context.CancelFunc
 	waitCh    chan error
-	services  []Service
+	services  []*managedService
+	errs      []error
 	log       log.Logger
+	health    *health.Registry
+}
+
+type managedService struct {
+	ServiceConfig
+	service Service
+	health  *health.Reporter
 }
 
 // New returns a new instance of *Supervisor.
@@ -59,17 +153,63 @@ func New(logger log.Logger) *Supervisor {
 	}
 }
 
+// SetHealth sets the registry in which the supervisor reports the status of
+// every watched service under the "service.<name>" name. It must be invoked
+// before the Start method. If the registry is not set, statuses are not
+// reported.
+func (s *Supervisor) SetHealth(r *health.Registry) {
+	s.health = r
+}
+
 // Watch add one or more services to a supervisor. Services must be added
-// before invoking the Start method, otherwise it panics.
+// before invoking the Start method, otherwise it panics. Services are
+// never restarted.
 func (s *Supervisor) Watch(services ...Service) {
+	for _, srv := range services {
+		s.WatchService(ServiceConfig{Service: srv})
+	}
+}
+
+// WatchService adds one or more services with the given configuration to
+// a supervisor. Services must be added before invoking the Start method,
+// otherwise it panics.
+func (s *Supervisor) WatchService(cfgs ...ServiceConfig) {
+	for _, cfg := range cfgs {
+		s.watchService(cfg)
+	}
+}
+
+func (s *Supervisor) watchService(cfg ServiceConfig) {
 	if s.ctx != nil {
 		s.log.Panic("supervisor was already started")
 	}
-	s.services = append(s.services, services...)
+	if cfg.Service == nil {
+		s.log.Panic("service must not be nil")
+	}
+	if cfg.RestartPolicy != RestartNever && cfg.Factory == nil {
+		s.log.Panic("service factory is required for restart policies")
+	}
+	if cfg.Name == "" {
+		cfg.Name = serviceName(cfg.Service)
+	}
+	if cfg.Backoff.Initial == 0 {
+		cfg.Backoff.Initial = defaultBackoffInitial
+	}
+	if cfg.Backoff.Max == 0 {
+		cfg.Backoff.Max = defaultBackoffMax
+	}
+	if cfg.Backoff.Multiplier == 0 {
+		cfg.Backoff.Multiplier = defaultBackoffMultiplier
+	}
+	s.services = append(s.services, &managedService{
+		ServiceConfig: cfg,
+		service:       cfg.Service,
+	})
 }
 
-// Start starts all watched services. It can be invoked only once, otherwise
-// it panics.
+// Start starts all watched services. Services are started in the order in
+// which they were added, but after the services they depend on. It can be
+// invoked only once, otherwise it panics.
 func (s *Supervisor) Start(ctx context.Context) error {
 	if s.ctx != nil {
 		return errors.New("service can be started only once")
@@ -77,77 +217,245 @@ func (s *Supervisor) Start(ctx context.Context) error {
 	if ctx == nil {
 		return errors.New("context must not be nil")
 	}
+	services, err := startOrder(s.services)
+	if err != nil {
+		return err
+	}
+	for _, ms := range services {
+		ms.health = s.health.Register("service." + ms.Name)
+	}
 	s.ctx, s.ctxCancel = context.WithCancel(ctx)
-	for _, srv := range s.services {
+	for _, ms := range services {
 		s.log.
-			WithField("service", serviceName(srv)).
+			WithField("service", ms.Name).
 			Debug("Starting service")
-		if err := srv.Start(s.ctx); err != nil {
+		if err := ms.service.Start(s.ctx); err != nil {
+			ms.health.Down(err.Error())
 			s.ctxCancel()
 			close(s.waitCh)
 			return err
 		}
+		ms.health.Ready()
+	}
+	s.wg.Add(len(services))
+	for _, ms := range services {
+		go s.serviceMonitor(ms)
 	}
-	go s.serviceMonitor()
+	go s.waitRoutine()
 	return nil
 }
 
 // Wait returns a channel that is blocked until at least one service is
 // running. When all services are stopped, the channel will be closed.
 // If an error occurs in any of the services, it will be sent to the
-// channel before closing it. If multiple service crash, only the first
-// error is returned.
+// channel before closing it. If multiple errors occur, they are
+// aggregated into a single multierror.
 func (s *Supervisor) Wait() <-chan error {
 	return s.waitCh
 }
 
-func (s *Supervisor) serviceMonitor() {
-	var err error
-	// In this loop, a select is created (using reflection) that waits until
-	// at least one service has completed its work. This is reported by
-	// closing the channel returned by the Wait() or returning an error from
-	// the same channel (see the Service interface). The service is then
-	// removed from the s.service list and the loop is executed again until
-	// no service remains.
-	for len(s.services) > 0 {
-		// Wait for first stopped service:
-		c := make([]reflect.SelectCase, len(s.services))
-		for i, srv := range s.services {
-			c[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(srv.Wait())}
+// serviceMonitor waits until the service stops and restarts it according
+// to its restart policy.
+func (s *Supervisor) serviceMonitor(ms *managedService) {
+	defer s.wg.Done()
+	failures := 0
+	for {
+		startedAt := time.Now()
+		err := s.waitService(ms)
+		if s.ctx.Err() != nil || !shouldRestart(ms.RestartPolicy, err) {
+			if err == nil {
+				ms.health.NotReady("stopped")
+			}
+			s.log.
+				WithField("service", ms.Name).
+				Debug("Service stopped")
+			return
 		}
-		n, v, ok := reflect.Select(c)
-		name := serviceName(s.services[n])
 
-		// If service failed, cancel the context to stop the others:
-		if !v.IsNil() {
+		// Reset the number of failures if the service was running for
+		// a long time.
+		if time.Since(startedAt) >= ms.Backoff.Max {
+			failures = 0
+		}
+		for {
+			failures++
+			if ms.RestartPolicy == RestartOnFailure && ms.MaxRetries > 0 && failures > ms.MaxRetries {
+				err = fmt.Errorf("service %s failed after %d restarts: %w", ms.Name, ms.MaxRetries, err)
+				ms.health.Down(err.Error())
+				s.fail(err)
+				return
+			}
+			if err != nil {
+				ms.health.NotReady("restarting: " + err.Error())
+			} else {
+				ms.health.NotReady("restarting")
+			}
+			delay := ms.Backoff.delay(failures)
 			s.log.
-				WithError(v.Interface().(error)).
-				WithField("service", name).
-				Error("Service crashed")
-			if err == nil {
-				err = v.Interface().(error) // TODO(mdobak): Consider using multierror.
+				WithFields(log.Fields{
+					"service": ms.Name,
+					"attempt": failures,
+					"delay":   delay.String(),
+				}).
+				Warn("Restarting service")
+			t := time.NewTimer(delay)
+			select {
+			case <-s.ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
 			}
-			s.ctxCancel()
-			continue
+			if err = s.restartService(ms); err == nil {
+				ms.health.Ready()
+				break
+			}
+			s.log.
+				WithError(err).
+				WithField("service", ms.Name).
+				Error("Unable to restart service")
 		}
+	}
+}
 
+// waitService waits until the current instance of the service stops and
+// returns errors reported by the service. If the service is not restarted
+// on failure, the first error stops all other services.
+func (s *Supervisor) waitService(ms *managedService) error {
+	var errs []error
+	for err := range ms.service.Wait() {
+		if err == nil {
+			continue
+		}
 		s.log.
-			WithField("service", name).
-			Debug("Service stopped")
-
-		// Remove service from list if channel is closed:
-		// TODO(mdobak): If service is not removed from the list, there is no need
-		//               rebuild select cases above.
-		if !ok {
-			s.services = append(s.services[:n], s.services[n+1:]...)
+			WithError(err).
+			WithField("service", ms.Name).
+			Error("Service crashed")
+		if ms.RestartPolicy == RestartNever {
+			ms.health.Down(err.Error())
+			s.fail(err)
 		}
+		errs = append(errs, err)
+	}
+	return joinErrors(errs)
+}
+
+// restartService creates and starts a new instance of the service.
+func (s *Supervisor) restartService(ms *managedService) error {
+	srv, err := ms.Factory()
+	if err != nil {
+		return err
 	}
+	if srv == nil {
+		return errors.New("service factory returned nil")
+	}
+	if err := srv.Start(s.ctx); err != nil {
+		return err
+	}
+	ms.service = srv
+	return nil
+}
+
+// fail records the error and stops all services.
+func (s *Supervisor) fail(err error) {
+	s.mu.Lock()
+	s.errs = append(s.errs, err)
+	s.mu.Unlock()
+	s.ctxCancel()
+}
+
+// waitRoutine closes the wait channel when all services are stopped.
+func (s *Supervisor) waitRoutine() {
+	s.wg.Wait()
+	s.ctxCancel()
+	s.mu.Lock()
+	err := joinErrors(s.errs)
+	s.mu.Unlock()
 	if err != nil {
 		s.waitCh <- err
 	}
 	close(s.waitCh)
 }
 
+// joinErrors returns nil for no errors, the error itself for a single
+// error and a multierror otherwise.
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return &multierror.Error{Errors: errs}
+	}
+}
+
+// delay returns the delay before the given restart attempt.
+func (b Backoff) delay(attempt int) time.Duration {
+	d := float64(b.Initial) * math.Pow(b.Multiplier, float64(attempt-1))
+	if d > float64(b.Max) || math.IsInf(d, 0) || math.IsNaN(d) {
+		return b.Max
+	}
+	return time.Duration(d)
+}
+
+func shouldRestart(p RestartPolicy, err error) bool {
+	switch p {
+	case RestartOnFailure:
+		return err != nil
+	case RestartAlways:
+		return true
+	default:
+		return false
+	}
+}
+
+// startOrder returns services sorted in such a way that every service is
+// placed after the services it depends on. Otherwise, the order in which
+// services were added is preserved.
+func startOrder(services []*managedService) ([]*managedService, error) {
+	byName := make(map[string][]*managedService)
+	for _, ms := range services {
+		byName[ms.Name] = append(byName[ms.Name], ms)
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*managedService]int)
+	order := make([]*managedService, 0, len(services))
+	var visit func(ms *managedService) error
+	visit = func(ms *managedService) error {
+		switch state[ms] {
+		case visiting:
+			return fmt.Errorf("circular dependency of service %s", ms.Name)
+		case visited:
+			return nil
+		}
+		state[ms] = visiting
+		for _, dep := range ms.DependsOn {
+			deps, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf("service %s depends on unknown service %s", ms.Name, dep)
+			}
+			for _, d := range deps {
+				if err := visit(d); err != nil {
+					return err
+				}
+			}
+		}
+		state[ms] = visited
+		order = append(order, ms)
+		return nil
+	}
+	for _, ms := range services {
+		if err := visit(ms); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
 func serviceName(s interface{}) string {
 	return reflect.Indirect(reflect.ValueOf(s)).Type().String()
 }
@@ -22,8 +22,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 )
 
 type service struct {
@@ -176,7 +179,12 @@ func TestSupervisor_MultipleErrors(t *testing.T) {
 
 	select {
 	case err := <-s.Wait():
-		require.Equal(t, "err", err.Error())
+		var merr *multierror.Error
+		require.ErrorAs(t, err, &merr)
+		require.Len(t, merr.Errors, 3)
+		for _, e := range merr.Errors {
+			require.Equal(t, "err", e.Error())
+		}
 	default:
 		require.Fail(t, "Wait() channel should not be blocked")
 	}
@@ -185,3 +193,279 @@ func TestSupervisor_MultipleErrors(t *testing.T) {
 	assert.False(t, s2.Started())
 	assert.False(t, s3.Started())
 }
+
+// crashingService is a service that can be stopped with an error.
+type crashingService struct {
+	waitCh chan error
+	stopCh chan error
+}
+
+func newCrashingService() *crashingService {
+	return &crashingService{
+		waitCh: make(chan error),
+		stopCh: make(chan error),
+	}
+}
+
+func (s *crashingService) Start(ctx context.Context) error {
+	go func() {
+		defer close(s.waitCh)
+		select {
+		case <-ctx.Done():
+		case err := <-s.stopCh:
+			if err != nil {
+				s.waitCh <- err
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *crashingService) Wait() <-chan error {
+	return s.waitCh
+}
+
+// Stop stops the service with the given error.
+func (s *crashingService) Stop(err error) {
+	s.stopCh <- err
+}
+
+// factory returns a service factory that sends created services to the
+// returned channel.
+func factory() (func() (Service, error), chan *crashingService) {
+	ch := make(chan *crashingService, 10)
+	return func() (Service, error) {
+		srv := newCrashingService()
+		ch <- srv
+		return srv, nil
+	}, ch
+}
+
+func waitForService(t *testing.T, ch chan *crashingService) *crashingService {
+	select {
+	case srv := <-ch:
+		return srv
+	case <-time.After(time.Second):
+		require.Fail(t, "service was not restarted")
+		return nil
+	}
+}
+
+func TestSupervisor_RestartOnFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := New(nil)
+
+	f, ch := factory()
+	s1 := newCrashingService()
+	s2 := &service{waitCh: make(chan error)}
+	s.WatchService(ServiceConfig{
+		Service:       s1,
+		RestartPolicy: RestartOnFailure,
+		Factory:       f,
+		Backoff:       Backoff{Initial: time.Millisecond},
+	})
+	s.Watch(s2)
+
+	require.NoError(t, s.Start(ctx))
+
+	// Service is restarted after a failure and other services keep running.
+	s1.Stop(errors.New("err"))
+	s1 = waitForService(t, ch)
+	s1.Stop(errors.New("err"))
+	s1 = waitForService(t, ch)
+	assert.True(t, s2.Started())
+
+	// Service is not restarted if it stops without an error.
+	s1.Stop(nil)
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, ch, 0)
+	assert.True(t, s2.Started())
+
+	cancel()
+	select {
+	case err, ok := <-s.Wait():
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		require.Fail(t, "Wait() channel should not be blocked")
+	}
+}
+
+func TestSupervisor_Health(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := New(nil)
+	r := health.NewRegistry()
+	s.SetHealth(r)
+
+	f, ch := factory()
+	s1 := newCrashingService()
+	s.WatchService(ServiceConfig{
+		Service:       s1,
+		Name:          "crashing",
+		RestartPolicy: RestartOnFailure,
+		Factory:       f,
+		Backoff:       Backoff{Initial: time.Millisecond},
+	})
+
+	require.NoError(t, s.Start(ctx))
+	statuses := r.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "service.crashing", statuses[0].Name)
+	assert.True(t, statuses[0].Ready)
+
+	// Service is reported as ready again after it is restarted.
+	s1.Stop(errors.New("err"))
+	waitForService(t, ch)
+	assert.Eventually(t, r.Ready, time.Second, time.Millisecond)
+}
+
+func TestSupervisor_MaxRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := New(nil)
+
+	f, ch := factory()
+	s1 := newCrashingService()
+	s2 := &service{waitCh: make(chan error)}
+	s.WatchService(ServiceConfig{
+		Service:       s1,
+		Name:          "s1",
+		RestartPolicy: RestartOnFailure,
+		Factory:       f,
+		MaxRetries:    2,
+		Backoff:       Backoff{Initial: time.Millisecond},
+	})
+	s.Watch(s2)
+
+	require.NoError(t, s.Start(ctx))
+
+	s1.Stop(errors.New("err"))
+	s1 = waitForService(t, ch)
+	s1.Stop(errors.New("err"))
+	s1 = waitForService(t, ch)
+	s1.Stop(errors.New("err"))
+
+	// After reaching the retry limit, all services are stopped.
+	select {
+	case err := <-s.Wait():
+		require.EqualError(t, err, "service s1 failed after 2 restarts: err")
+	case <-time.After(time.Second):
+		require.Fail(t, "Wait() channel should not be blocked")
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, s2.Started())
+}
+
+func TestSupervisor_RestartAlways(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := New(nil)
+
+	f, ch := factory()
+	s1 := newCrashingService()
+	s.WatchService(ServiceConfig{
+		Service:       s1,
+		RestartPolicy: RestartAlways,
+		Factory:       f,
+		Backoff:       Backoff{Initial: time.Millisecond},
+	})
+
+	require.NoError(t, s.Start(ctx))
+
+	s1.Stop(nil)
+	s1 = waitForService(t, ch)
+	s1.Stop(errors.New("err"))
+	waitForService(t, ch)
+
+	cancel()
+	select {
+	case err := <-s.Wait():
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "Wait() channel should not be blocked")
+	}
+}
+
+func TestSupervisor_RestartWithoutFactory(t *testing.T) {
+	s := New(nil)
+	assert.Panics(t, func() {
+		s.WatchService(ServiceConfig{
+			Service:       newCrashingService(),
+			RestartPolicy: RestartAlways,
+		})
+	})
+}
+
+// orderedService records the order in which services are started.
+type orderedService struct {
+	*service
+	name  string
+	order *[]string
+}
+
+func (s *orderedService) Start(ctx context.Context) error {
+	*s.order = append(*s.order, s.name)
+	return s.service.Start(ctx)
+}
+
+func TestSupervisor_DependsOn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := New(nil)
+
+	var order []string
+	newService := func(name string) Service {
+		return &orderedService{service: &service{waitCh: make(chan error)}, name: name, order: &order}
+	}
+	s.WatchService(ServiceConfig{Service: newService("a"), Name: "a", DependsOn: []string{"c"}})
+	s.WatchService(ServiceConfig{Service: newService("b"), Name: "b"})
+	s.WatchService(ServiceConfig{Service: newService("c"), Name: "c", DependsOn: []string{"b"}})
+	s.WatchService(ServiceConfig{Service: newService("d"), Name: "d"})
+
+	require.NoError(t, s.Start(ctx))
+	assert.Equal(t, []string{"b", "c", "a", "d"}, order)
+}
+
+func TestSupervisor_DependsOnErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		services []ServiceConfig
+		wantErr  string
+	}{
+		{
+			name: "unknown",
+			services: []ServiceConfig{
+				{Service: newCrashingService(), Name: "a", DependsOn: []string{"b"}},
+			},
+			wantErr: "service a depends on unknown service b",
+		},
+		{
+			name: "circular",
+			services: []ServiceConfig{
+				{Service: newCrashingService(), Name: "a", DependsOn: []string{"b"}},
+				{Service: newCrashingService(), Name: "b", DependsOn: []string{"a"}},
+			},
+			wantErr: "circular dependency of service a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(nil)
+			for _, cfg := range tt.services {
+				s.WatchService(cfg)
+			}
+			assert.EqualError(t, s.Start(context.Background()), tt.wantErr)
+		})
+	}
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 10 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, b.delay(1))
+	assert.Equal(t, 2*time.Second, b.delay(2))
+	assert.Equal(t, 8*time.Second, b.delay(4))
+	assert.Equal(t, 10*time.Second, b.delay(5))
+	assert.Equal(t, 10*time.Second, b.delay(1000))
+}
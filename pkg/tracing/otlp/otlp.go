@@ -0,0 +1,356 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package otlp provides a tracing exporter that sends spans to an
+// OpenTelemetry collector using the OTLP/HTTP protocol with JSON encoding.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing"
+)
+
+const LoggerTag = "OTLP"
+
+const (
+	defaultInterval     = 5 * time.Second
+	defaultMaxQueueSize = 2048
+	shutdownTimeout     = 5 * time.Second
+)
+
+// OTLP span kinds and status codes.
+const (
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+// Exporter is a service that batches finished spans and periodically sends
+// them to an OTLP/HTTP endpoint. While the service is running, it is used as
+// the global exporter of the tracing package.
+type Exporter struct {
+	ctx    context.Context
+	waitCh chan error
+	log    log.Logger
+
+	mu      sync.Mutex
+	queue   []tracing.SpanData
+	dropped int
+
+	endpoint     string
+	serviceName  string
+	headers      map[string]string
+	interval     time.Duration
+	maxQueueSize int
+	client       *http.Client
+}
+
+// Config is the configuration for the Exporter.
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP traces endpoint, e.g.
+	// "http://localhost:4318/v1/traces".
+	Endpoint string
+
+	// ServiceName is the name of the service reported in the resource
+	// attributes.
+	ServiceName string
+
+	// Headers are additional HTTP headers sent with every request.
+	Headers map[string]string
+
+	// Interval is the interval between sending batches of spans.
+	// If zero, 5 seconds is used.
+	Interval time.Duration
+
+	// MaxQueueSize is the maximum number of spans waiting to be sent.
+	// Spans exceeding this limit are dropped. If zero, 2048 is used.
+	MaxQueueSize int
+
+	// HTTPClient is the HTTP client used to send spans.
+	// If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// Logger is a current logger interface used by the Exporter.
+	// If nil, null logger will be used.
+	Logger log.Logger
+}
+
+// New creates a new Exporter.
+func New(cfg Config) (*Exporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New("endpoint must be an HTTP or HTTPS URL")
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.MaxQueueSize == 0 {
+		cfg.MaxQueueSize = defaultMaxQueueSize
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = null.New()
+	}
+	return &Exporter{
+		waitCh:       make(chan error),
+		log:          cfg.Logger.WithField("tag", LoggerTag),
+		endpoint:     cfg.Endpoint,
+		serviceName:  cfg.ServiceName,
+		headers:      cfg.Headers,
+		interval:     cfg.Interval,
+		maxQueueSize: cfg.MaxQueueSize,
+		client:       cfg.HTTPClient,
+	}, nil
+}
+
+// Start implements the supervisor.Service interface.
+func (e *Exporter) Start(ctx context.Context) error {
+	if e.ctx != nil {
+		return errors.New("service can be started only once")
+	}
+	if ctx == nil {
+		return errors.New("context must not be nil")
+	}
+	e.log.Info("Starting")
+	e.ctx = ctx
+	tracing.SetExporter(e)
+	go e.exportRoutine()
+	return nil
+}
+
+// Wait implements the supervisor.Service interface.
+func (e *Exporter) Wait() <-chan error {
+	return e.waitCh
+}
+
+// ExportSpan implements the tracing.Exporter interface.
+func (e *Exporter) ExportSpan(span tracing.SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.queue) >= e.maxQueueSize {
+		e.dropped++
+		return
+	}
+	e.queue = append(e.queue, span)
+}
+
+// Flush sends all queued spans.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	spans, dropped := e.queue, e.dropped
+	e.queue, e.dropped = nil, 0
+	e.mu.Unlock()
+	if dropped > 0 {
+		e.log.
+			WithField("dropped", dropped).
+			Warn("Span queue is full, spans were dropped")
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return nil
+}
+
+func (e *Exporter) exportRoutine() {
+	defer func() { close(e.waitCh) }()
+	defer e.log.Info("Stopped")
+	t := time.NewTicker(e.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			tracing.SetExporter(nil)
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			if err := e.Flush(ctx); err != nil {
+				e.log.WithError(err).Warn("Unable to export spans")
+			}
+			cancel()
+			return
+		case <-t.C:
+			if err := e.Flush(e.ctx); err != nil {
+				e.log.WithError(err).Warn("Unable to export spans")
+			}
+		}
+	}
+}
+
+func (e *Exporter) request(spans []tracing.SpanData) exportRequest {
+	s := make([]spanJSON, 0, len(spans))
+	for _, span := range spans {
+		sj := spanJSON{
+			TraceID:           span.SpanContext.TraceID.String(),
+			SpanID:            span.SpanContext.SpanID.String(),
+			Name:              span.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        attributes(span.Attributes),
+		}
+		if span.Parent.IsValid() {
+			sj.ParentSpanID = span.Parent.SpanID.String()
+		}
+		for _, l := range span.Links {
+			sj.Links = append(sj.Links, linkJSON{
+				TraceID: l.TraceID.String(),
+				SpanID:  l.SpanID.String(),
+			})
+		}
+		if span.Err != nil {
+			sj.Status = &statusJSON{Code: statusCodeError, Message: span.Err.Error()}
+		}
+		s = append(s, sj)
+	}
+	return exportRequest{
+		ResourceSpans: []resourceSpansJSON{{
+			Resource: resourceJSON{
+				Attributes: attributes(map[string]any{"service.name": e.serviceName}),
+			},
+			ScopeSpans: []scopeSpansJSON{{
+				Scope: scopeJSON{Name: "github.com/chronicleprotocol/oracle-suite"},
+				Spans: s,
+			}},
+		}},
+	}
+}
+
+func attributes(m map[string]any) []attributeJSON {
+	attrs := make([]attributeJSON, 0, len(m))
+	for k, v := range m {
+		attrs = append(attrs, attributeJSON{Key: k, Value: attributeValue(v)})
+	}
+	return attrs
+}
+
+func attributeValue(v any) valueJSON {
+	switch v := v.(type) {
+	case string:
+		return valueJSON{StringValue: &v}
+	case bool:
+		return valueJSON{BoolValue: &v}
+	case int:
+		s := strconv.FormatInt(int64(v), 10)
+		return valueJSON{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return valueJSON{IntValue: &s}
+	case uint64:
+		s := strconv.FormatUint(v, 10)
+		return valueJSON{IntValue: &s}
+	case float64:
+		return valueJSON{DoubleValue: &v}
+	case time.Duration:
+		f := v.Seconds()
+		return valueJSON{DoubleValue: &f}
+	case fmt.Stringer:
+		s := v.String()
+		return valueJSON{StringValue: &s}
+	default:
+		s := fmt.Sprint(v)
+		return valueJSON{StringValue: &s}
+	}
+}
+
+type exportRequest struct {
+	ResourceSpans []resourceSpansJSON `json:"resourceSpans"`
+}
+
+type resourceSpansJSON struct {
+	Resource   resourceJSON     `json:"resource"`
+	ScopeSpans []scopeSpansJSON `json:"scopeSpans"`
+}
+
+type resourceJSON struct {
+	Attributes []attributeJSON `json:"attributes"`
+}
+
+type scopeSpansJSON struct {
+	Scope scopeJSON  `json:"scope"`
+	Spans []spanJSON `json:"spans"`
+}
+
+type scopeJSON struct {
+	Name string `json:"name"`
+}
+
+type spanJSON struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []attributeJSON `json:"attributes,omitempty"`
+	Links             []linkJSON      `json:"links,omitempty"`
+	Status            *statusJSON     `json:"status,omitempty"`
+}
+
+type linkJSON struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+}
+
+type statusJSON struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type attributeJSON struct {
+	Key   string    `json:"key"`
+	Value valueJSON `json:"value"`
+}
+
+type valueJSON struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
@@ -0,0 +1,148 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/tracing"
+)
+
+func TestExporter(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+
+	reqCh := make(chan *http.Request, 1)
+	bodyCh := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		reqCh <- r
+		bodyCh <- body
+	}))
+	defer srv.Close()
+
+	e, err := New(Config{
+		Endpoint:    srv.URL + "/v1/traces",
+		ServiceName: "test",
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		Interval:    time.Hour,
+	})
+	require.NoError(t, err)
+	require.NoError(t, e.Start(ctx))
+
+	// Exporter must be registered as the global exporter.
+	spanCtx, parent := tracing.Start(context.Background(), "parent")
+	_, child := tracing.Start(spanCtx, "child", tracing.WithAttributes(map[string]any{
+		"str":      "value",
+		"int":      1,
+		"bool":     true,
+		"float":    1.5,
+		"duration": 2 * time.Second,
+	}))
+	child.RecordError(errors.New("error"))
+	child.End()
+	parent.End()
+
+	require.NoError(t, e.Flush(ctx))
+	req := <-reqCh
+	body := <-bodyCh
+	assert.Equal(t, "/v1/traces", req.URL.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+	var got exportRequest
+	require.NoError(t, json.Unmarshal(body, &got))
+	require.Len(t, got.ResourceSpans, 1)
+	require.Len(t, got.ResourceSpans[0].Resource.Attributes, 1)
+	assert.Equal(t, "service.name", got.ResourceSpans[0].Resource.Attributes[0].Key)
+	assert.Equal(t, "test", *got.ResourceSpans[0].Resource.Attributes[0].Value.StringValue)
+	require.Len(t, got.ResourceSpans[0].ScopeSpans, 1)
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().TraceID.String(), spans[0].TraceID)
+	assert.Equal(t, parent.SpanContext().SpanID.String(), spans[0].ParentSpanID)
+	assert.Equal(t, statusCodeError, spans[0].Status.Code)
+	assert.Equal(t, "error", spans[0].Status.Message)
+	assert.Len(t, spans[0].Attributes, 5)
+	for _, a := range spans[0].Attributes {
+		switch a.Key {
+		case "str":
+			assert.Equal(t, "value", *a.Value.StringValue)
+		case "int":
+			assert.Equal(t, "1", *a.Value.IntValue)
+		case "bool":
+			assert.True(t, *a.Value.BoolValue)
+		case "float":
+			assert.Equal(t, 1.5, *a.Value.DoubleValue)
+		case "duration":
+			assert.Equal(t, 2.0, *a.Value.DoubleValue)
+		}
+	}
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Nil(t, spans[1].Status)
+
+	// Nothing to send:
+	require.NoError(t, e.Flush(ctx))
+	assert.Len(t, reqCh, 0)
+
+	// Exporter must be unregistered after the context is canceled.
+	ctxCancel()
+	<-e.Wait()
+	_, span := tracing.Start(context.Background(), "span")
+	assert.Nil(t, span)
+}
+
+func TestExporter_QueueLimit(t *testing.T) {
+	e, err := New(Config{
+		Endpoint:     "http://localhost:4318/v1/traces",
+		MaxQueueSize: 2,
+	})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		e.ExportSpan(tracing.SpanData{Name: "span"})
+	}
+	assert.Len(t, e.queue, 2)
+	assert.Equal(t, 1, e.dropped)
+}
+
+func TestExporter_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	e, err := New(Config{Endpoint: srv.URL})
+	require.NoError(t, err)
+	e.ExportSpan(tracing.SpanData{Name: "span"})
+	assert.Error(t, e.Flush(context.Background()))
+}
+
+func TestNew_InvalidEndpoint(t *testing.T) {
+	_, err := New(Config{Endpoint: "localhost:4318"})
+	assert.Error(t, err)
+}
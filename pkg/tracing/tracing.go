@@ -0,0 +1,330 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package tracing provides distributed tracing of data points as they flow
+// through the suite, from fetching them from origins, through broadcasting
+// them using a transport, to updating an Oracle contract.
+//
+// Spans are created using the Start function. The span context is
+// propagated between services in the message metadata using the W3C Trace
+// Context format, see Inject and Extract. Finished spans are passed to the
+// exporter set using SetExporter. If no exporter is set, tracing is disabled
+// and Start returns a nil span. All Span methods are safe to call on a nil
+// span.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceparentKey is the metadata key under which the span context is
+// propagated.
+const TraceparentKey = "traceparent"
+
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// TraceID is a unique identifier of a trace.
+type TraceID [16]byte
+
+// IsValid returns true if the trace ID is not zero.
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// String returns the hex representation of the trace ID.
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID is a unique identifier of a span within a trace.
+type SpanID [8]byte
+
+// IsValid returns true if the span ID is not zero.
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// String returns the hex representation of the span ID.
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext identifies a span.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+
+	// Remote is true if the span context was extracted from a message
+	// received from another service.
+	Remote bool
+}
+
+// IsValid returns true if both trace ID and span ID are not zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent returns the span context encoded as a W3C traceparent value.
+func (sc SpanContext) Traceparent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-01"
+}
+
+// ParseTraceparent parses a W3C traceparent value.
+func ParseTraceparent(s string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(s, "-")
+	if len(parts) < 4 {
+		return sc, ErrInvalidTraceparent
+	}
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || len(version) != 1 || version[0] == 0xff {
+		return sc, ErrInvalidTraceparent
+	}
+	if version[0] == 0 && len(parts) != 4 {
+		return sc, ErrInvalidTraceparent
+	}
+	if len(parts[1]) != 2*len(sc.TraceID) || len(parts[2]) != 2*len(sc.SpanID) || len(parts[3]) != 2 {
+		return sc, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	if _, err := hex.DecodeString(parts[3]); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	if !sc.IsValid() {
+		return sc, ErrInvalidTraceparent
+	}
+	sc.Remote = true
+	return sc, nil
+}
+
+// SpanData contains data of a finished span.
+type SpanData struct {
+	Name        string
+	SpanContext SpanContext
+	Parent      SpanContext
+	Links       []SpanContext
+	StartTime   time.Time
+	EndTime     time.Time
+	Attributes  map[string]any
+	Err         error
+}
+
+// Exporter exports finished spans.
+type Exporter interface {
+	// ExportSpan is called when a span ends. It must not block.
+	ExportSpan(span SpanData)
+}
+
+var (
+	exporterMu sync.RWMutex
+	exporter   Exporter
+)
+
+// SetExporter sets the exporter for finished spans. Setting a nil exporter
+// disables tracing.
+func SetExporter(e Exporter) {
+	exporterMu.Lock()
+	defer exporterMu.Unlock()
+	exporter = e
+}
+
+func currentExporter() Exporter {
+	exporterMu.RLock()
+	defer exporterMu.RUnlock()
+	return exporter
+}
+
+// Span represents a single operation within a trace.
+type Span struct {
+	mu       sync.Mutex
+	data     SpanData
+	ended    bool
+	exporter Exporter
+}
+
+// StartOption is an option for the Start function.
+type StartOption func(*Span)
+
+// WithLinks links the span to other spans. It is used when a span depends
+// on multiple spans, possibly from different traces, e.g. an Oracle update
+// that uses prices from multiple feeds.
+func WithLinks(links ...SpanContext) StartOption {
+	return func(s *Span) {
+		for _, l := range links {
+			if l.IsValid() {
+				s.data.Links = append(s.data.Links, l)
+			}
+		}
+	}
+}
+
+// WithAttributes sets span attributes.
+func WithAttributes(attrs map[string]any) StartOption {
+	return func(s *Span) {
+		for k, v := range attrs {
+			s.data.Attributes[k] = v
+		}
+	}
+}
+
+// Start starts a new span. If the context contains a span context, the new
+// span is its child, otherwise a new trace is started. The returned context
+// contains the context of the new span.
+//
+// If tracing is disabled, the context is returned unchanged along with a
+// nil span.
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	e := currentExporter()
+	if e == nil {
+		return ctx, nil
+	}
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{TraceID: parent.TraceID, SpanID: newSpanID()}
+	if !parent.IsValid() {
+		parent = SpanContext{}
+		sc.TraceID = newTraceID()
+	}
+	s := &Span{
+		data: SpanData{
+			Name:        name,
+			SpanContext: sc,
+			Parent:      parent,
+			StartTime:   time.Now(),
+			Attributes:  make(map[string]any),
+		},
+		exporter: e,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return ContextWithSpanContext(ctx, sc), s
+}
+
+// SpanContext returns the span context. It returns an empty span context
+// for a nil span.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetAttribute sets a span attribute.
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+// RecordError marks the span as failed. A nil error is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err
+}
+
+// End finishes the span and passes it to the exporter. Subsequent calls
+// have no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+	s.exporter.ExportSpan(data)
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of the context with the given span
+// context.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context stored in the context.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// Inject stores the span context from the context in the metadata map.
+// The map is not modified if the context does not contain a valid span
+// context.
+func Inject(ctx context.Context, meta map[string]string) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() || meta == nil {
+		return
+	}
+	meta[TraceparentKey] = sc.Traceparent()
+}
+
+// Extract returns a copy of the context with the span context stored in the
+// metadata map. If the map does not contain a valid span context, the
+// context is returned unchanged.
+func Extract(ctx context.Context, meta map[string]string) context.Context {
+	sc, ok := FromMeta(meta)
+	if !ok {
+		return ctx
+	}
+	return ContextWithSpanContext(ctx, sc)
+}
+
+// FromMeta returns the span context stored in the metadata map.
+func FromMeta(meta map[string]string) (SpanContext, bool) {
+	tp, ok := meta[TraceparentKey]
+	if !ok {
+		return SpanContext{}, false
+	}
+	sc, err := ParseTraceparent(tp)
+	if err != nil {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+func newTraceID() (id TraceID) {
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func newSpanID() (id SpanID) {
+	_, _ = rand.Read(id[:])
+	return id
+}
@@ -0,0 +1,147 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *testExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		traceparent string
+		wantErr     bool
+	}{
+		{traceparent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+		{traceparent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00"},
+		{traceparent: "01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-future"},
+		{traceparent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra", wantErr: true},
+		{traceparent: "ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", wantErr: true},
+		{traceparent: "00-00000000000000000000000000000000-b7ad6b7169203331-01", wantErr: true},
+		{traceparent: "00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01", wantErr: true},
+		{traceparent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b71692033-01", wantErr: true},
+		{traceparent: "00-zzf7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", wantErr: true},
+		{traceparent: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.traceparent, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.traceparent)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, sc.IsValid())
+			assert.True(t, sc.Remote)
+			assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", sc.TraceID.String())
+			assert.Equal(t, "b7ad6b7169203331", sc.SpanID.String())
+		})
+	}
+}
+
+func TestStart_Disabled(t *testing.T) {
+	SetExporter(nil)
+	ctx, span := Start(context.Background(), "test")
+	assert.Nil(t, span)
+	assert.False(t, SpanContextFromContext(ctx).IsValid())
+
+	// Methods must be safe to call on a nil span.
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("error"))
+	span.End()
+	assert.False(t, span.SpanContext().IsValid())
+}
+
+func TestStart(t *testing.T) {
+	e := &testExporter{}
+	SetExporter(e)
+	defer SetExporter(nil)
+
+	ctx, root := Start(context.Background(), "root", WithAttributes(map[string]any{"a": 1}))
+	_, child := Start(ctx, "child")
+	child.RecordError(errors.New("error"))
+	child.End()
+	child.End() // second call must be ignored
+	root.SetAttribute("b", "2")
+	root.End()
+
+	require.Len(t, e.spans, 2)
+	assert.Equal(t, "child", e.spans[0].Name)
+	assert.Equal(t, "root", e.spans[1].Name)
+	assert.Equal(t, root.SpanContext().TraceID, e.spans[0].SpanContext.TraceID)
+	assert.Equal(t, root.SpanContext(), e.spans[0].Parent)
+	assert.False(t, e.spans[1].Parent.IsValid())
+	assert.EqualError(t, e.spans[0].Err, "error")
+	assert.Equal(t, map[string]any{"a": 1, "b": "2"}, e.spans[1].Attributes)
+	assert.False(t, e.spans[1].EndTime.Before(e.spans[1].StartTime))
+}
+
+func TestInjectExtract(t *testing.T) {
+	e := &testExporter{}
+	SetExporter(e)
+	defer SetExporter(nil)
+
+	// Sender:
+	ctx, span := Start(context.Background(), "send")
+	meta := map[string]string{}
+	Inject(ctx, meta)
+	span.End()
+	require.Contains(t, meta, TraceparentKey)
+
+	// Receiver:
+	ctx, span = Start(Extract(context.Background(), meta), "receive")
+	span.End()
+	require.Len(t, e.spans, 2)
+	assert.Equal(t, e.spans[0].SpanContext.TraceID, e.spans[1].SpanContext.TraceID)
+	assert.Equal(t, e.spans[0].SpanContext.SpanID, e.spans[1].Parent.SpanID)
+	assert.True(t, e.spans[1].Parent.Remote)
+	assert.Equal(t, span.SpanContext(), SpanContextFromContext(ctx))
+
+	// Invalid metadata:
+	assert.Equal(t, context.Background(), Extract(context.Background(), map[string]string{TraceparentKey: "invalid"}))
+	assert.Equal(t, context.Background(), Extract(context.Background(), nil))
+}
+
+func TestWithLinks(t *testing.T) {
+	e := &testExporter{}
+	SetExporter(e)
+	defer SetExporter(nil)
+
+	_, a := Start(context.Background(), "a")
+	_, b := Start(context.Background(), "b")
+	_, c := Start(context.Background(), "c", WithLinks(a.SpanContext(), SpanContext{}, b.SpanContext()))
+	c.End()
+
+	require.Len(t, e.spans, 1)
+	assert.Equal(t, []SpanContext{a.SpanContext(), b.SpanContext()}, e.spans[0].Links)
+	assert.NotEqual(t, a.SpanContext().TraceID, b.SpanContext().TraceID)
+}
@@ -18,8 +18,10 @@ package chain
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/dedup"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/chanutil"
 )
 
@@ -29,6 +31,10 @@ type Chain struct {
 	ctx    context.Context
 	waitCh <-chan error
 	ts     []transport.Transport
+
+	// Deduplication settings, used only if dedupSize is greater than zero.
+	dedupSize int
+	dedupTTL  time.Duration
 }
 
 // New creates a new Chain instance.
@@ -44,6 +50,17 @@ func New(ts ...transport.Transport) *Chain {
 	}
 }
 
+// NewDeduplicated creates a new Chain instance that delivers each message
+// only once, even if it is received from multiple transports. Each channel
+// returned by the Messages method uses its own deduplication cache that
+// holds up to size messages for the ttl duration.
+func NewDeduplicated(size int, ttl time.Duration, ts ...transport.Transport) *Chain {
+	c := New(ts...)
+	c.dedupSize = size
+	c.dedupTTL = ttl
+	return c
+}
+
 // Broadcast implements the transport.Transport interface.
 func (m *Chain) Broadcast(topic string, message transport.Message) error {
 	var err error
@@ -62,7 +79,10 @@ func (m *Chain) Messages(topic string) <-chan transport.ReceivedMessage {
 		_ = fi.Add(t.Messages(topic))
 	}
 	fi.AutoClose()
-	return fi.Chan()
+	if m.dedupSize <= 0 {
+		return fi.Chan()
+	}
+	return deduplicate(topic, fi.Chan(), dedup.NewCache(m.dedupSize, m.dedupTTL))
 }
 
 // Start implements the transport.Transport interface.
@@ -86,3 +106,23 @@ func (m *Chain) Start(ctx context.Context) error {
 func (m *Chain) Wait() <-chan error {
 	return m.waitCh
 }
+
+// deduplicate forwards messages from the given channel to the returned
+// channel, dropping messages that were already seen. Messages with errors
+// are always forwarded.
+func deduplicate(topic string, ch <-chan transport.ReceivedMessage, c *dedup.Cache) <-chan transport.ReceivedMessage {
+	out := make(chan transport.ReceivedMessage)
+	go func() {
+		defer close(out)
+		for msg := range ch {
+			if msg.Error == nil && msg.Message != nil {
+				bin, err := msg.Message.MarshallBinary()
+				if err == nil && c.Seen(topic, msg.Author, bin) {
+					continue
+				}
+			}
+			out <- msg
+		}
+	}()
+	return out
+}
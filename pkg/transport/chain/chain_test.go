@@ -96,3 +96,39 @@ func TestChain_Wait(t *testing.T) {
 	_, ok := <-l.Wait()
 	assert.False(t, ok)
 }
+
+func TestChain_Deduplicated(t *testing.T) {
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer ctxCancel()
+
+	l1 := local.New([]byte("test"), 1, map[string]transport.Message{"foo": (*testMsg)(nil)})
+	l2 := local.New([]byte("test"), 1, map[string]transport.Message{"foo": (*testMsg)(nil)})
+
+	l := NewDeduplicated(10, time.Minute, l1, l2)
+	_ = l.Start(ctx)
+
+	m := l.Messages("foo")
+
+	// Both transports receive the same message, but it should be delivered
+	// only once.
+	assert.NoError(t, l.Broadcast("foo", &testMsg{Val: "bar"}))
+	assert.NoError(t, l.Broadcast("foo", &testMsg{Val: "baz"}))
+
+	var vals []string
+	for len(vals) < 2 {
+		select {
+		case msg := <-m:
+			vals = append(vals, msg.Message.(*testMsg).Val)
+		case <-ctx.Done():
+			t.Fatal("timeout")
+		}
+	}
+	assert.ElementsMatch(t, []string{"bar", "baz"}, vals)
+
+	// No more messages should be received.
+	select {
+	case msg := <-m:
+		t.Fatalf("unexpected message: %v", msg.Message)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
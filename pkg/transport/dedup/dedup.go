@@ -0,0 +1,101 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package dedup
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+)
+
+// Cache is a bounded cache of recently seen messages. It is used by
+// transports to deliver each logical message only once, even if the same
+// message is relayed by multiple producers, received from multiple
+// transports, or re-sent after a restart.
+//
+// Messages are identified by the hash of the topic, the message author and
+// the binary representation of the message. When the cache is full, the
+// oldest entries are evicted. Entries older than the TTL are treated as not
+// seen. The implementation is thread-safe.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[types.Hash]*list.Element
+	order   *list.List // Entries ordered by the time they were added.
+}
+
+type entry struct {
+	key  types.Hash
+	time time.Time
+}
+
+// NewCache creates a new Cache that holds up to size entries. If ttl is
+// zero, entries are kept until evicted.
+func NewCache(size int, ttl time.Duration) *Cache {
+	return &Cache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[types.Hash]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// Seen reports whether a message with the given topic, author and data was
+// already seen. If not, the message is added to the cache.
+func (c *Cache) Seen(topic string, author []byte, data []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	key := crypto.Keccak256([]byte(topic), author, data)
+	c.evictExpired(now)
+	if _, ok := c.entries[key]; ok {
+		return true
+	}
+	for c.size > 0 && c.order.Len() >= c.size {
+		c.remove(c.order.Front())
+	}
+	c.entries[key] = c.order.PushBack(&entry{key: key, time: now})
+	return false
+}
+
+// Len returns the number of entries in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// evictExpired removes entries that are older than the TTL.
+func (c *Cache) evictExpired(now time.Time) {
+	if c.ttl == 0 {
+		return
+	}
+	for e := c.order.Front(); e != nil; e = c.order.Front() {
+		if now.Sub(e.Value.(*entry).time) < c.ttl {
+			return
+		}
+		c.remove(e)
+	}
+}
+
+func (c *Cache) remove(e *list.Element) {
+	delete(c.entries, e.Value.(*entry).key)
+	c.order.Remove(e)
+}
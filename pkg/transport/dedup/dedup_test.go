@@ -0,0 +1,51 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Seen(t *testing.T) {
+	c := NewCache(2, 0)
+
+	assert.False(t, c.Seen("topic", []byte("author"), []byte("a")))
+	assert.True(t, c.Seen("topic", []byte("author"), []byte("a")))
+
+	// Different topic, author or data is a different message.
+	assert.False(t, c.Seen("other", []byte("author"), []byte("a")))
+	assert.False(t, c.Seen("topic", []byte("other"), []byte("a")))
+	assert.Equal(t, 2, c.Len())
+
+	// The oldest entries must be evicted when the cache is full.
+	assert.False(t, c.Seen("topic", []byte("author"), []byte("a")))
+	assert.True(t, c.Seen("topic", []byte("other"), []byte("a")))
+}
+
+func TestCache_TTL(t *testing.T) {
+	c := NewCache(10, 50*time.Millisecond)
+
+	assert.False(t, c.Seen("topic", []byte("author"), []byte("a")))
+	assert.True(t, c.Seen("topic", []byte("author"), []byte("a")))
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.False(t, c.Seen("topic", []byte("author"), []byte("a")))
+	assert.Equal(t, 1, c.Len())
+}
@@ -0,0 +1,303 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package libp2p
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
+	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver/middleware"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+)
+
+// adminAPI provides an HTTP API to inspect peers and to ban or unban peers
+// and feeds at runtime.
+//
+// Endpoints:
+//
+//	GET  /peers  - list of connected peers with their scores and topics
+//	GET  /banned - list of banned peer IDs and feed addresses
+//	POST /ban    - ban a peer ID or a feed address
+//	POST /unban  - unban a peer ID or a feed address
+//	GET  /feeds  - list of allowed feed addresses
+//	POST /feeds  - replace the list of allowed feed addresses
+//	GET  /keys   - list of message signing keys, if a key set is used
+//	POST /rotate - rotate the message signing key, if a key set is used
+//
+// The ban and unban endpoints expect a JSON body with either the "peer_id"
+// or the "addr" field. The feeds endpoint expects a JSON body with the
+// "feeds" field and an optional "overlap" field, the number of seconds
+// during which removed feeds are still allowed. The rotate endpoint expects
+// a JSON body with the "addr" field of the key to activate and an optional
+// "overlap" field. The admin API does not provide any authentication, so
+// it should be served only on a local interface. Until the transport is
+// started, all endpoints respond with the 503 status code.
+type adminAPI struct {
+	p   *P2P
+	log log.Logger
+}
+
+type jsonPeer struct {
+	ID     string   `json:"id"`
+	Addrs  []string `json:"addrs"`
+	Score  float64  `json:"score"`
+	Topics []string `json:"topics"`
+	Banned bool     `json:"banned"`
+}
+
+type jsonBanned struct {
+	Peers []string        `json:"peers"`
+	Addrs []types.Address `json:"addrs"`
+}
+
+type jsonFeed struct {
+	Addr         types.Address `json:"addr"`
+	RetiringTill *time.Time    `json:"retiring_till,omitempty"`
+}
+
+type jsonFeedsRequest struct {
+	Feeds   []types.Address `json:"feeds"`
+	Overlap uint64          `json:"overlap"`
+}
+
+type jsonKey struct {
+	Addr         types.Address `json:"addr"`
+	Active       bool          `json:"active"`
+	RetiringTill *time.Time    `json:"retiring_till,omitempty"`
+}
+
+type jsonRotateRequest struct {
+	Addr    types.Address `json:"addr"`
+	Overlap uint64        `json:"overlap"`
+}
+
+type jsonBanRequest struct {
+	PeerID string         `json:"peer_id"`
+	Addr   *types.Address `json:"addr"`
+}
+
+func newAdminHandler(p *P2P, logger log.Logger) http.Handler {
+	api := &adminAPI{p: p, log: logger}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/peers", api.peersHandler)
+	mux.HandleFunc("/banned", api.bannedHandler)
+	mux.HandleFunc("/ban", api.banHandler(true))
+	mux.HandleFunc("/unban", api.banHandler(false))
+	mux.HandleFunc("/feeds", api.feedsHandler)
+	mux.HandleFunc("/keys", api.keysHandler)
+	mux.HandleFunc("/rotate", api.rotateHandler)
+	started := http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if !p.started.Load() {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		mux.ServeHTTP(res, req)
+	})
+	return (&middleware.Logger{Log: logger}).Handle(started)
+}
+
+func (a *adminAPI) peersHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var (
+		host   = a.p.node.Host()
+		ps     = a.p.node.PubSub()
+		scores = a.p.reputation.Scores()
+		peers  = make([]jsonPeer, 0)
+	)
+	if host == nil {
+		res.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	for _, id := range host.Network().Peers() {
+		jp := jsonPeer{
+			ID:     id.String(),
+			Addrs:  []string{},
+			Topics: []string{},
+			Score:  scores[id].Score,
+			Banned: a.p.reputation.IsPeerBanned(id),
+		}
+		for _, maddr := range host.Peerstore().Addrs(id) {
+			jp.Addrs = append(jp.Addrs, maddr.String())
+		}
+		if ps != nil {
+			for _, topic := range ps.GetTopics() {
+				for _, tp := range ps.ListPeers(topic) {
+					if tp == id {
+						jp.Topics = append(jp.Topics, topic)
+						break
+					}
+				}
+			}
+			sort.Strings(jp.Topics)
+		}
+		peers = append(peers, jp)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+	writeJSON(res, peers)
+}
+
+func (a *adminAPI) bannedHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	banned := jsonBanned{Peers: []string{}, Addrs: a.p.reputation.BannedAddrs()}
+	for _, id := range a.p.reputation.BannedPeers() {
+		banned.Peers = append(banned.Peers, id.String())
+	}
+	if banned.Addrs == nil {
+		banned.Addrs = []types.Address{}
+	}
+	writeJSON(res, banned)
+}
+
+func (a *adminAPI) banHandler(ban bool) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			res.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var br jsonBanRequest
+		if err := json.NewDecoder(req.Body).Decode(&br); err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var err error
+		switch {
+		case br.PeerID != "" && br.Addr == nil:
+			var id peer.ID
+			id, err = peer.Decode(br.PeerID)
+			if err != nil {
+				http.Error(res, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if ban {
+				err = a.p.BanPeer(id)
+			} else {
+				err = a.p.reputation.UnbanPeer(id)
+			}
+		case br.PeerID == "" && br.Addr != nil:
+			if ban {
+				err = a.p.reputation.BanAddr(*br.Addr)
+			} else {
+				err = a.p.reputation.UnbanAddr(*br.Addr)
+			}
+		default:
+			err = errors.New("either peer_id or addr must be provided")
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			a.log.WithError(err).Error("Unable to update ban list")
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+	}
+}
+
+func (a *adminAPI) feedsHandler(res http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		feeds := make([]jsonFeed, 0)
+		for _, e := range a.p.feeds.Entries() {
+			f := jsonFeed{Addr: e.Address}
+			if !e.RetiringTill.IsZero() {
+				f.RetiringTill = &e.RetiringTill
+			}
+			feeds = append(feeds, f)
+		}
+		writeJSON(res, feeds)
+	case http.MethodPost:
+		var fr jsonFeedsRequest
+		if err := json.NewDecoder(req.Body).Decode(&fr); err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		a.p.feeds.Update(fr.Feeds, time.Duration(fr.Overlap)*time.Second)
+		a.log.
+			WithField("feeds", fr.Feeds).
+			WithField("overlap", fr.Overlap).
+			Info("Feeds updated")
+		res.WriteHeader(http.StatusOK)
+	default:
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *adminAPI) keysHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	ks, ok := keyset.FromKey(a.p.signer)
+	if !ok {
+		http.Error(res, "key set is not configured", http.StatusNotFound)
+		return
+	}
+	active := ks.Active().Address()
+	retiring, till := ks.Retiring()
+	keys := make([]jsonKey, 0)
+	for _, addr := range ks.Keys() {
+		k := jsonKey{Addr: addr, Active: addr == active}
+		if retiring != nil && retiring.Address() == addr {
+			k.RetiringTill = &till
+		}
+		keys = append(keys, k)
+	}
+	writeJSON(res, keys)
+}
+
+func (a *adminAPI) rotateHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	ks, ok := keyset.FromKey(a.p.signer)
+	if !ok {
+		http.Error(res, "key set is not configured", http.StatusNotFound)
+		return
+	}
+	var rr jsonRotateRequest
+	if err := json.NewDecoder(req.Body).Decode(&rr); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ks.Rotate(rr.Addr, time.Duration(rr.Overlap)*time.Second); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.log.
+		WithField("addr", rr.Addr.String()).
+		Info("Message signing key rotated")
+	res.WriteHeader(http.StatusOK)
+}
+
+func writeJSON(res http.ResponseWriter, v any) {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(res).Encode(v)
+}
@@ -62,6 +62,7 @@ type Node struct {
 	messageHandlerSet     *sets.MessageHandlerSet
 	subs                  map[string]*Subscription
 	tsLog                 tsLogger
+	peerScoreHandlers     []func(map[peer.ID]*pubsub.PeerScoreSnapshot)
 	disablePubSub         bool
 	closed                bool
 
@@ -0,0 +1,73 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package internal
+
+import (
+	"github.com/libp2p/go-libp2p/core/control"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// Banlist blocks connections to and from peers for which the isBanned
+// function returns true. Unlike the Denylist, the list of banned peers
+// may change at runtime. Connections that are already established are not
+// affected, use the ClosePeer method to close them.
+func Banlist(isBanned func(peer.ID) bool) Options {
+	return func(n *Node) error {
+		n.AddConnectionGater(&banlistConnGater{isBanned: isBanned})
+		return nil
+	}
+}
+
+// ClosePeer closes all connections to the given peer.
+func (n *Node) ClosePeer(pid peer.ID) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.host == nil {
+		return nil
+	}
+	return n.host.Network().ClosePeer(pid)
+}
+
+type banlistConnGater struct {
+	isBanned func(peer.ID) bool
+}
+
+// InterceptAddrDial implements the connmgr.ConnectionGater interface.
+func (f *banlistConnGater) InterceptAddrDial(pid peer.ID, _ multiaddr.Multiaddr) bool {
+	return !f.isBanned(pid)
+}
+
+// InterceptPeerDial implements the connmgr.ConnectionGater interface.
+func (f *banlistConnGater) InterceptPeerDial(pid peer.ID) bool {
+	return !f.isBanned(pid)
+}
+
+// InterceptAccept implements the connmgr.ConnectionGater interface.
+func (f *banlistConnGater) InterceptAccept(network.ConnMultiaddrs) bool {
+	return true
+}
+
+// InterceptSecured implements the connmgr.ConnectionGater interface.
+func (f *banlistConnGater) InterceptSecured(_ network.Direction, pid peer.ID, _ network.ConnMultiaddrs) bool {
+	return !f.isBanned(pid)
+}
+
+// InterceptUpgraded implements the connmgr.ConnectionGater interface.
+func (f *banlistConnGater) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
@@ -0,0 +1,77 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package internal
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNode_Banlist(t *testing.T) {
+	// This test checks that banned peers can be changed at runtime.
+
+	peers, err := getNodeInfo(2)
+	require.NoError(t, err)
+
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+
+	var (
+		mu     sync.Mutex
+		banned = map[peer.ID]bool{}
+	)
+	n0, err := NewNode(
+		PeerPrivKey(peers[0].PrivKey),
+		ListenAddrs(peers[0].ListenAddrs),
+		Banlist(func(id peer.ID) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return banned[id]
+		}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, n0.Start(ctx))
+
+	n1, err := NewNode(
+		PeerPrivKey(peers[1].PrivKey),
+		ListenAddrs(peers[1].ListenAddrs),
+	)
+	require.NoError(t, err)
+	require.NoError(t, n1.Start(ctx))
+
+	// Peers are not banned, so they should be able to connect.
+	require.NoError(t, n0.Connect(peers[1].PeerAddrs[0]))
+	assert.Len(t, n0.Host().Network().Peers(), 1)
+
+	// After banning the peer and closing the connection, it must not be
+	// possible to connect again.
+	mu.Lock()
+	banned[peers[1].ID] = true
+	mu.Unlock()
+	require.NoError(t, n0.ClosePeer(peers[1].ID))
+	assert.Error(t, n0.Connect(peers[1].PeerAddrs[0]))
+
+	// Inbound connections from the banned peer must be rejected as well.
+	_ = n1.Connect(peers[0].PeerAddrs[0])
+	waitFor(t, func() bool {
+		return len(n0.Host().Network().ConnsToPeer(peers[1].ID)) == 0
+	})
+}
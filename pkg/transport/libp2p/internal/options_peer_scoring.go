@@ -41,6 +41,9 @@ func PeerScoring(
 						WithField("score", ps).
 						Debug("Peer score")
 				}
+				for _, h := range n.peerScoreHandlers {
+					h(m)
+				}
 			}, time.Minute),
 		)
 
@@ -74,3 +77,12 @@ func PeerScoring(
 		return nil
 	}
 }
+
+// PeerScoreHandler registers a function that is periodically called with
+// the current peer score snapshots. It requires the PeerScoring option.
+func PeerScoreHandler(h func(map[peer.ID]*pubsub.PeerScoreSnapshot)) Options {
+	return func(n *Node) error {
+		n.peerScoreHandlers = append(n.peerScoreHandlers, h)
+		return nil
+	}
+}
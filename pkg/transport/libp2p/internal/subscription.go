@@ -75,12 +75,12 @@ func newSubscription(node *Node, topic string) (*Subscription, error) {
 	return s, err
 }
 
-func (s *Subscription) Publish(msg []byte) error {
+func (s *Subscription) Publish(msg []byte, opts ...pubsub.PubOpt) error {
 	if msg == nil {
 		return ErrNilMessage
 	}
 	s.messageHandler.Published(s.topic.String(), msg)
-	return s.topic.Publish(s.ctx, msg)
+	return s.topic.Publish(s.ctx, msg, opts...)
 }
 
 func (s *Subscription) Next() chan *pubsub.Message {
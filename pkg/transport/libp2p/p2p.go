@@ -20,6 +20,8 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/defiweb/go-eth/types"
@@ -30,8 +32,11 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/multiformats/go-multiaddr"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/metrics"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/libp2p/crypto/ethkey"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/libp2p/internal"
@@ -78,12 +83,18 @@ var defaultListenAddrs = []string{"/ip4/0.0.0.0/tcp/0"}
 // P2P is the wrapper for the Node that implements the transport.Transport
 // interface.
 type P2P struct {
-	id        peer.ID
-	node      *internal.Node
-	mode      Mode
-	topics    map[string]transport.Message
-	msgCh     map[string]chan transport.ReceivedMessage
-	msgFanOut map[string]*chanutil.FanOut[transport.ReceivedMessage]
+	id         peer.ID
+	node       *internal.Node
+	mode       Mode
+	topics     map[string]transport.Message
+	msgCh      map[string]chan transport.ReceivedMessage
+	msgFanOut  map[string]*chanutil.FanOut[transport.ReceivedMessage]
+	reputation *Reputation
+	feeds      *keyset.Allowlist
+	signer     wallet.Key
+	started    atomic.Bool
+	admin      http.Handler
+	health     *health.Reporter
 }
 
 // Config is the configuration for the P2P transport.
@@ -126,18 +137,37 @@ type Config struct {
 	// these addresses will be accepted.
 	AuthorAllowlist []types.Address
 
+	// Feeds is an optional allowlist of message authors that can be updated
+	// at runtime, e.g. to rotate feed keys. If set, addresses from the
+	// AuthorAllowlist are added to it. If nil, a new allowlist is created
+	// from the AuthorAllowlist.
+	Feeds *keyset.Allowlist
+
 	// Discovery indicates whenever peer discovery should be enabled.
 	// If discovery is disabled, then DirectPeersAddrs must be used
 	// to connect to the network. Always enabled in bootstrap mode.
 	Discovery bool
 
 	// Signer used to verify price messages. Ignored in bootstrap mode.
+	//
+	// If the signer is a keyset.KeySet, messages are published using its
+	// active key, so the key can be rotated without restarting the node.
+	// In that case, the MessagePrivKey must be created from the same
+	// signer.
 	Signer wallet.Key
 
+	// ReputationFile is an optional path to a file in which peer scores and
+	// ban lists are persisted. If empty, they are kept only in memory.
+	ReputationFile string
+
 	// Logger is a custom logger instance. If not provided then null
 	// logger is used.
 	Logger log.Logger
 
+	// Health is the registry in which the transport reports its status under
+	// the "transport.libp2p" name. If nil, the status is not reported.
+	Health *health.Registry
+
 	// Application info:
 	AppName    string
 	AppVersion string
@@ -165,6 +195,10 @@ func New(cfg Config) (*P2P, error) {
 	if cfg.Logger == nil {
 		cfg.Logger = null.New()
 	}
+	if cfg.Feeds == nil {
+		cfg.Feeds = keyset.NewAllowlist(nil)
+	}
+	cfg.Feeds.Add(cfg.AuthorAllowlist...)
 
 	listenAddrs, err := strsToMaddrs(cfg.ListenAddrs)
 	if err != nil {
@@ -183,6 +217,11 @@ func New(cfg Config) (*P2P, error) {
 		return nil, fmt.Errorf("P2P transport error: unable to parse blockedAddrs: %w", err)
 	}
 
+	reputation, err := NewReputation(cfg.ReputationFile)
+	if err != nil {
+		return nil, fmt.Errorf("P2P transport error: unable to load reputation file: %w", err)
+	}
+
 	logger := cfg.Logger.WithField("tag", LoggerTag)
 	opts := []internal.Options{
 		internal.DialTimeout(connectionTimeout),
@@ -193,6 +232,7 @@ func New(cfg Config) (*P2P, error) {
 		internal.ListenAddrs(listenAddrs),
 		internal.DirectPeers(directPeersAddrs),
 		internal.Denylist(blockedAddrs),
+		internal.Banlist(reputation.IsPeerBanned),
 		internal.ConnectionLimit(
 			minConnections,
 			maxConnections,
@@ -225,10 +265,12 @@ func New(cfg Config) (*P2P, error) {
 		if err != nil {
 			return nil, fmt.Errorf("P2P transport error: invalid event topic scoring parameters: %w", err)
 		}
+		scoreParams := *peerScoreParams
+		scoreParams.AppSpecificScore = reputation.appSpecificScore
 		opts = append(opts,
 			internal.MessageLogger(),
 			internal.RateLimiter(rateLimiterConfig(cfg)),
-			internal.PeerScoring(peerScoreParams, thresholds, func(topic string) *pubsub.TopicScoreParams {
+			internal.PeerScoring(&scoreParams, thresholds, func(topic string) *pubsub.TopicScoreParams {
 				if topic == messages.PriceV0MessageName || topic == messages.PriceV1MessageName {
 					return priceTopicScoreParams
 				}
@@ -237,8 +279,13 @@ func New(cfg Config) (*P2P, error) {
 				}
 				return nil
 			}),
+			internal.PeerScoreHandler(func(m map[peer.ID]*pubsub.PeerScoreSnapshot) {
+				if err := reputation.updateScores(m); err != nil {
+					logger.WithError(err).Warn("Unable to persist peer scores")
+				}
+			}),
 			messageValidator(cfg.Topics, logger), // must be registered before any other validator
-			feedValidator(cfg.AuthorAllowlist, logger),
+			feedValidator(cfg.Feeds, reputation, logger),
 			// eventValidator(logger),
 			// priceValidator(logger, cryptoETH.ECRecoverer),
 		)
@@ -267,14 +314,20 @@ func New(cfg Config) (*P2P, error) {
 		return nil, fmt.Errorf("P2P transport error, unable to get public ID from private key: %w", err)
 	}
 
-	return &P2P{
-		id:        id,
-		node:      n,
-		mode:      cfg.Mode,
-		topics:    cfg.Topics,
-		msgCh:     map[string]chan transport.ReceivedMessage{},
-		msgFanOut: map[string]*chanutil.FanOut[transport.ReceivedMessage]{},
-	}, nil
+	p := &P2P{
+		id:         id,
+		node:       n,
+		mode:       cfg.Mode,
+		topics:     cfg.Topics,
+		msgCh:      map[string]chan transport.ReceivedMessage{},
+		msgFanOut:  map[string]*chanutil.FanOut[transport.ReceivedMessage]{},
+		reputation: reputation,
+		feeds:      cfg.Feeds,
+		signer:     cfg.Signer,
+		health:     cfg.Health.Register("transport." + TransportName),
+	}
+	p.admin = newAdminHandler(p, logger)
+	return p, nil
 }
 
 // Start implements the transport.Transport interface.
@@ -282,6 +335,7 @@ func (p *P2P) Start(ctx context.Context) error {
 	if err := p.node.Start(ctx); err != nil {
 		return fmt.Errorf("P2P transport error, unable to start node: %w", err)
 	}
+	p.started.Store(true)
 	if p.mode == ClientMode {
 		for topic := range p.topics {
 			msgCh := make(chan transport.ReceivedMessage)
@@ -292,6 +346,7 @@ func (p *P2P) Start(ctx context.Context) error {
 			}
 		}
 	}
+	p.health.Ready()
 	return nil
 }
 
@@ -300,8 +355,41 @@ func (p *P2P) Wait() <-chan error {
 	return p.node.Wait()
 }
 
+// AdminHandler returns an HTTP handler of the admin API that allows to
+// inspect peers and to ban or unban peers and feeds at runtime. The handler
+// should be served only on a local interface, see adminAPI for the list of
+// endpoints.
+func (p *P2P) AdminHandler() http.Handler {
+	return p.admin
+}
+
+// Reputation returns the peer reputation registry.
+func (p *P2P) Reputation() *Reputation {
+	return p.reputation
+}
+
+// Feeds returns the allowlist of message authors.
+func (p *P2P) Feeds() *keyset.Allowlist {
+	return p.feeds
+}
+
+// BanPeer bans the given peer ID and closes all connections to it.
+func (p *P2P) BanPeer(id peer.ID) error {
+	if err := p.reputation.BanPeer(id); err != nil {
+		return err
+	}
+	return p.node.ClosePeer(id)
+}
+
 // Broadcast implements the transport.Transport interface.
 func (p *P2P) Broadcast(topic string, message transport.Message) error {
+	err := p.broadcast(topic, message)
+	metrics.Broadcasts.WithLabelValues(TransportName, topic, metrics.Status(err)).Inc()
+	p.health.Report(err)
+	return err
+}
+
+func (p *P2P) broadcast(topic string, message transport.Message) error {
 	sub, err := p.node.Subscription(topic)
 	if err != nil {
 		return fmt.Errorf("P2P transport error, unable to get subscription for %s topic: %w", topic, err)
@@ -310,6 +398,15 @@ func (p *P2P) Broadcast(topic string, message transport.Message) error {
 	if err != nil {
 		return fmt.Errorf("P2P transport error, unable to marshall message: %w", err)
 	}
+	if ks, ok := keyset.FromKey(p.signer); ok {
+		// Publish using the currently active key rather than the key that
+		// was active when the node was started.
+		key := ks.Active()
+		return sub.Publish(data, pubsub.WithSecretKeyAndPeerId(
+			ethkey.NewPrivKey(key),
+			ethkey.AddressToPeerID(key.Address()),
+		))
+	}
 	return sub.Publish(data)
 }
 
@@ -337,6 +434,8 @@ func (p *P2P) messagesLoop(topic string, sub *internal.Subscription) {
 			return
 		}
 		if msg, ok := nodeMsg.ValidatorData.(transport.Message); ok {
+			metrics.MessagesReceived.WithLabelValues(TransportName, topic).Inc()
+			p.health.Success()
 			p.msgCh[topic] <- transport.ReceivedMessage{
 				Message: msg,
 				Author:  ethkey.PeerIDToAddress(nodeMsg.GetFrom()).Bytes(),
@@ -0,0 +1,293 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package libp2p
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// restoredScoreTTL is the time after which a score restored from the
+// reputation file no longer affects the peer score.
+const restoredScoreTTL = time.Hour
+
+// PeerScore is a snapshot of a peer score.
+type PeerScore struct {
+	// Score is the gossipsub score of the peer.
+	Score float64 `json:"score"`
+
+	// Topics is the list of topics for which the peer has a score.
+	Topics []string `json:"topics"`
+
+	// UpdatedAt is the time when the score was last updated.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Reputation keeps track of peer scores and banned peers and feeds. If the
+// path is not empty, scores and ban lists are persisted in a JSON file, so
+// they survive restarts.
+//
+// Gossipsub scores cannot be set directly, therefore negative scores
+// restored from the file are applied as an application specific score that
+// linearly decays to zero within an hour after the restart. Positive scores
+// are not restored, peers have to earn them again.
+type Reputation struct {
+	mu sync.RWMutex
+
+	path        string
+	scores      map[peer.ID]PeerScore
+	restored    map[peer.ID]float64
+	restoredAt  time.Time
+	bannedPeers map[peer.ID]struct{}
+	bannedAddrs map[types.Address]struct{}
+}
+
+// reputationFile is the JSON representation of the reputation file.
+type reputationFile struct {
+	Scores      map[string]PeerScore `json:"scores"`
+	BannedPeers []string             `json:"banned_peers"`
+	BannedAddrs []types.Address      `json:"banned_addrs"`
+}
+
+// NewReputation creates a new Reputation instance. If the path is not
+// empty and the file exists, the scores and ban lists are loaded from it.
+func NewReputation(path string) (*Reputation, error) {
+	r := &Reputation{
+		path:        path,
+		scores:      make(map[peer.ID]PeerScore),
+		restored:    make(map[peer.ID]float64),
+		restoredAt:  time.Now(),
+		bannedPeers: make(map[peer.ID]struct{}),
+		bannedAddrs: make(map[types.Address]struct{}),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Scores returns the last known scores of peers.
+func (r *Reputation) Scores() map[peer.ID]PeerScore {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scores := make(map[peer.ID]PeerScore, len(r.scores))
+	for id, s := range r.scores {
+		scores[id] = s
+	}
+	return scores
+}
+
+// BannedPeers returns the list of banned peer IDs.
+func (r *Reputation) BannedPeers() []peer.ID {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var ids []peer.ID
+	for id := range r.bannedPeers {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// BannedAddrs returns the list of banned feed addresses.
+func (r *Reputation) BannedAddrs() []types.Address {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var addrs []types.Address
+	for addr := range r.bannedAddrs {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].String() < addrs[j].String() })
+	return addrs
+}
+
+// BanPeer bans the given peer ID.
+func (r *Reputation) BanPeer(id peer.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bannedPeers[id] = struct{}{}
+	return r.save()
+}
+
+// UnbanPeer removes the given peer ID from the ban list.
+func (r *Reputation) UnbanPeer(id peer.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.bannedPeers, id)
+	return r.save()
+}
+
+// BanAddr bans messages signed by the given feed address.
+func (r *Reputation) BanAddr(addr types.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bannedAddrs[addr] = struct{}{}
+	return r.save()
+}
+
+// UnbanAddr removes the given feed address from the ban list.
+func (r *Reputation) UnbanAddr(addr types.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.bannedAddrs, addr)
+	return r.save()
+}
+
+// IsPeerBanned returns true if the given peer ID is banned.
+func (r *Reputation) IsPeerBanned(id peer.ID) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.bannedPeers[id]
+	return ok
+}
+
+// IsAddrBanned returns true if the given feed address is banned.
+func (r *Reputation) IsAddrBanned(addr types.Address) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.bannedAddrs[addr]
+	return ok
+}
+
+// appSpecificScore returns the application specific score for the given
+// peer. It is used to apply negative scores restored from the file.
+func (r *Reputation) appSpecificScore(id peer.ID) float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.restored[id]
+	if !ok {
+		return 0
+	}
+	elapsed := time.Since(r.restoredAt)
+	if elapsed >= restoredScoreTTL {
+		return 0
+	}
+	return s * (1 - float64(elapsed)/float64(restoredScoreTTL))
+}
+
+// updateScores updates the peer scores using the given snapshots and
+// persists them.
+func (r *Reputation) updateScores(snapshots map[peer.ID]*pubsub.PeerScoreSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for id, s := range snapshots {
+		var topics []string
+		for topic := range s.Topics {
+			topics = append(topics, topic)
+		}
+		sort.Strings(topics)
+		r.scores[id] = PeerScore{Score: s.Score, Topics: topics, UpdatedAt: now}
+	}
+	for id, s := range r.scores {
+		if now.Sub(s.UpdatedAt) > restoredScoreTTL {
+			delete(r.scores, id)
+		}
+	}
+	return r.save()
+}
+
+// load loads the reputation file. Must be called only in the constructor.
+func (r *Reputation) load() error {
+	if r.path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var f reputationFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	for idStr, s := range f.Scores {
+		id, err := peer.Decode(idStr)
+		if err != nil {
+			return err
+		}
+		if time.Since(s.UpdatedAt) > restoredScoreTTL {
+			continue
+		}
+		r.scores[id] = s
+		if s.Score < 0 {
+			r.restored[id] = s.Score
+		}
+	}
+	for _, idStr := range f.BannedPeers {
+		id, err := peer.Decode(idStr)
+		if err != nil {
+			return err
+		}
+		r.bannedPeers[id] = struct{}{}
+	}
+	for _, addr := range f.BannedAddrs {
+		r.bannedAddrs[addr] = struct{}{}
+	}
+	return nil
+}
+
+// save writes the reputation file. The file is written to a temporary file
+// first and then renamed to avoid partial writes. Caller must hold the lock.
+func (r *Reputation) save() error {
+	if r.path == "" {
+		return nil
+	}
+	f := reputationFile{
+		Scores:      make(map[string]PeerScore, len(r.scores)),
+		BannedPeers: []string{},
+		BannedAddrs: []types.Address{},
+	}
+	for id, s := range r.scores {
+		f.Scores[id.String()] = s
+	}
+	for id := range r.bannedPeers {
+		f.BannedPeers = append(f.BannedPeers, id.String())
+	}
+	for addr := range r.bannedAddrs {
+		f.BannedAddrs = append(f.BannedAddrs, addr)
+	}
+	sort.Strings(f.BannedPeers)
+	sort.Slice(f.BannedAddrs, func(i, j int) bool { return f.BannedAddrs[i].String() < f.BannedAddrs[j].String() })
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
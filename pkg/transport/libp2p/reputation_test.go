@@ -0,0 +1,82 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package libp2p
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/libp2p/crypto/ethkey"
+)
+
+func TestReputation(t *testing.T) {
+	var (
+		path  = filepath.Join(t.TempDir(), "reputation.json")
+		addr  = types.MustAddressFromHex("0x1234567890123456789012345678901234567890")
+		peer1 = ethkey.HexAddressToPeerID("0x2345678901234567890123456789012345678901")
+		peer2 = ethkey.HexAddressToPeerID("0x3456789012345678901234567890123456789012")
+	)
+
+	r, err := NewReputation(path)
+	require.NoError(t, err)
+
+	// Ban peers and feeds.
+	require.NoError(t, r.BanPeer(peer1))
+	require.NoError(t, r.BanAddr(addr))
+	assert.True(t, r.IsPeerBanned(peer1))
+	assert.False(t, r.IsPeerBanned(peer2))
+	assert.True(t, r.IsAddrBanned(addr))
+
+	// Update scores.
+	require.NoError(t, r.updateScores(map[peer.ID]*pubsub.PeerScoreSnapshot{
+		peer1: {Score: -100, Topics: map[string]*pubsub.TopicScoreSnapshot{"a": {}, "b": {}}},
+		peer2: {Score: 100},
+	}))
+	assert.Equal(t, -100.0, r.Scores()[peer1].Score)
+	assert.Equal(t, []string{"a", "b"}, r.Scores()[peer1].Topics)
+	assert.Equal(t, 0.0, r.appSpecificScore(peer1))
+
+	// Scores and ban lists must be restored from the file. Only negative
+	// scores are applied as an app specific score.
+	r, err = NewReputation(path)
+	require.NoError(t, err)
+	assert.Equal(t, []peer.ID{peer1}, r.BannedPeers())
+	assert.Equal(t, []types.Address{addr}, r.BannedAddrs())
+	assert.Equal(t, 100.0, r.Scores()[peer2].Score)
+	assert.InDelta(t, -100.0, r.appSpecificScore(peer1), 1)
+	assert.Equal(t, 0.0, r.appSpecificScore(peer2))
+
+	// Restored scores must decay to zero.
+	r.restoredAt = time.Now().Add(-restoredScoreTTL / 2)
+	assert.InDelta(t, -50.0, r.appSpecificScore(peer1), 1)
+	r.restoredAt = time.Now().Add(-restoredScoreTTL)
+	assert.Equal(t, 0.0, r.appSpecificScore(peer1))
+
+	// Unban peers and feeds.
+	require.NoError(t, r.UnbanPeer(peer1))
+	require.NoError(t, r.UnbanAddr(addr))
+	r, err = NewReputation(path)
+	require.NoError(t, err)
+	assert.Empty(t, r.BannedPeers())
+	assert.Empty(t, r.BannedAddrs())
+}
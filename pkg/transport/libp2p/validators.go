@@ -22,11 +22,12 @@ import (
 	"time"
 
 	"github.com/defiweb/go-eth/crypto"
-	"github.com/defiweb/go-eth/types"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/keyset"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/metrics"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/libp2p/crypto/ethkey"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/libp2p/internal"
@@ -50,6 +51,7 @@ func messageValidator(topics map[string]transport.Message, logger log.Logger) in
 						WithField("peerID", psMsg.GetFrom().String()).
 						WithField("peerAddr", feedAddr).
 						Warn("The message has been rejected, unable to unmarshall")
+					metrics.MessagesRejected.WithLabelValues(TransportName, topic, "invalid_message").Inc()
 					return pubsub.ValidationReject
 				}
 				psMsg.ValidatorData = msg
@@ -61,15 +63,24 @@ func messageValidator(topics map[string]transport.Message, logger log.Logger) in
 	}
 }
 
-func feedValidator(feeds []types.Address, logger log.Logger) internal.Options {
+func feedValidator(feeds *keyset.Allowlist, reputation *Reputation, logger log.Logger) internal.Options {
 	return func(n *internal.Node) error {
 		n.AddValidator(func(ctx context.Context, topic string, id peer.ID, psMsg *pubsub.Message) pubsub.ValidationResult {
 			from := ethkey.PeerIDToAddress(psMsg.GetFrom())
-			if !feedAllowed(from, feeds) {
+			if !feeds.Allowed(from) {
 				logger.
 					WithField("peerID", psMsg.GetFrom().String()).
 					WithField("peerAddr", from.String()).
 					Warn("Message ignored, feed is not allowed to send messages")
+				metrics.MessagesRejected.WithLabelValues(TransportName, topic, "feed_not_allowed").Inc()
+				return pubsub.ValidationIgnore
+			}
+			if reputation.IsAddrBanned(from) {
+				logger.
+					WithField("peerID", psMsg.GetFrom().String()).
+					WithField("peerAddr", from.String()).
+					Warn("Message ignored, feed is banned")
+				metrics.MessagesRejected.WithLabelValues(TransportName, topic, "feed_banned").Inc()
 				return pubsub.ValidationIgnore
 			}
 			return pubsub.ValidationAccept
@@ -78,15 +89,6 @@ func feedValidator(feeds []types.Address, logger log.Logger) internal.Options {
 	}
 }
 
-func feedAllowed(addr types.Address, feeds []types.Address) bool {
-	for _, f := range feeds {
-		if f == addr {
-			return true
-		}
-	}
-	return false
-}
-
 // eventValidator adds a validator for event messages.
 func eventValidator(logger log.Logger) internal.Options {
 	return func(n *internal.Node) error {
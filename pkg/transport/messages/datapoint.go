@@ -19,6 +19,10 @@ type DataPoint struct {
 	Value datapoint.Point
 
 	Signature types.Signature
+
+	// Meta contains additional metadata, such as the trace context.
+	// Metadata is not signed.
+	Meta map[string]string
 }
 
 // MarshallBinary implements the transport.Message interface.
@@ -31,6 +35,7 @@ func (d *DataPoint) MarshallBinary() ([]byte, error) {
 		Model:     d.Model,
 		Value:     value,
 		Signature: d.Signature.Bytes(),
+		Meta:      d.Meta,
 	})
 }
 
@@ -50,5 +55,6 @@ func (d *DataPoint) UnmarshallBinary(data []byte) error {
 	}
 	d.Model = msg.Model
 	d.Signature = sig
+	d.Meta = msg.Meta
 	return nil
 }
@@ -19,6 +19,8 @@ import (
 	"errors"
 	"time"
 
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages/pb"
@@ -30,6 +32,10 @@ const eventMessageMaxSize = 1 * 1024 * 1024 // 1MB
 
 var ErrEventMessageTooLarge = errors.New("event message too large")
 
+// eventRetractionPrefix is the prefix of the data used to calculate the
+// retraction hash.
+const eventRetractionPrefix = "event/retraction"
+
 type EventSignature struct {
 	Signer    []byte
 	Signature []byte
@@ -56,11 +62,50 @@ type Event struct {
 
 	// List of event signatures.
 	Signatures map[string]EventSignature
+
+	// Retracted is true if the message retracts a previously published event
+	// with the same type, ID and index. Retractions carry no data and are
+	// signed using the hash returned by RetractionHash.
+	Retracted bool
+}
+
+// NewEventRetraction returns an event that retracts a previously published
+// event with the given type, ID and index, e.g. because the block in which
+// the event was emitted was orphaned by a chain reorganization.
+func NewEventRetraction(typ string, id, index []byte) *Event {
+	now := time.Now()
+	return &Event{
+		Type:        typ,
+		ID:          id,
+		Index:       index,
+		EventDate:   now,
+		MessageDate: now,
+		Data:        map[string][]byte{},
+		Signatures:  map[string]EventSignature{},
+		Retracted:   true,
+	}
+}
+
+// IsRetraction returns true if the event retracts a previously published
+// event.
+func (e *Event) IsRetraction() bool {
+	return e.Retracted
+}
+
+// RetractionHash returns the hash that is signed to retract the event. It
+// commits to the type, ID and index of the retracted event.
+func (e *Event) RetractionHash() types.Hash {
+	return crypto.Keccak256(
+		[]byte(eventRetractionPrefix),
+		crypto.Keccak256([]byte(e.Type)).Bytes(),
+		crypto.Keccak256(e.ID).Bytes(),
+		crypto.Keccak256(e.Index).Bytes(),
+	)
 }
 
 // Copy returns a copy of the event.
 func (e *Event) Copy() *Event {
-	evt := &Event{Type: e.Type, EventDate: e.EventDate, MessageDate: e.MessageDate}
+	evt := &Event{Type: e.Type, EventDate: e.EventDate, MessageDate: e.MessageDate, Retracted: e.Retracted}
 	evt.ID = make([]byte, len(e.ID))
 	evt.Index = make([]byte, len(e.Index))
 	copy(evt.ID, e.ID)
@@ -99,6 +144,7 @@ func (e *Event) MarshallBinary() ([]byte, error) {
 		MessageTimestamp: e.MessageDate.Unix(),
 		Data:             e.Data,
 		Signatures:       signatures,
+		Retracted:        e.Retracted,
 	})
 	if err != nil {
 		return nil, err
@@ -132,5 +178,6 @@ func (e *Event) UnmarshallBinary(data []byte) error {
 	e.MessageDate = time.Unix(msg.MessageTimestamp, 0)
 	e.Data = msg.Data
 	e.Signatures = signatures
+	e.Retracted = msg.Retracted
 	return nil
 }
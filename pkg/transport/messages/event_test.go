@@ -25,6 +25,30 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestEvent_Retraction(t *testing.T) {
+	event := NewEventRetraction("test", []byte{10}, []byte{11})
+	assert.True(t, event.IsRetraction())
+	assert.Equal(t, "test", event.Type)
+	assert.Equal(t, []byte{10}, event.ID)
+	assert.Equal(t, []byte{11}, event.Index)
+
+	// Retraction must survive the binary encoding.
+	b, err := event.MarshallBinary()
+	require.NoError(t, err)
+	decoded := &Event{}
+	require.NoError(t, decoded.UnmarshallBinary(b))
+	assert.True(t, decoded.IsRetraction())
+	assert.Equal(t, event.RetractionHash(), decoded.RetractionHash())
+
+	// Retraction is a distinct field, not a data key.
+	assert.False(t, (&Event{Data: map[string][]byte{"retracted": {1}}}).IsRetraction())
+
+	// Retraction hash must commit to the type, ID and index.
+	assert.NotEqual(t, event.RetractionHash(), NewEventRetraction("test2", []byte{10}, []byte{11}).RetractionHash())
+	assert.NotEqual(t, event.RetractionHash(), NewEventRetraction("test", []byte{12}, []byte{11}).RetractionHash())
+	assert.NotEqual(t, event.RetractionHash(), NewEventRetraction("test", []byte{10}, []byte{12}).RetractionHash())
+}
+
 func TestEvent_Copy(t *testing.T) {
 	event := &Event{
 		Type:        "test",
@@ -44,6 +44,10 @@ type Price struct {
 	Trace   json.RawMessage `json:"trace"`
 	Version string          `json:"version,omitempty"` // TODO: this should move to some meta field e.g. `feedVersion`
 
+	// Meta contains additional metadata, such as the trace context.
+	// Metadata is not signed.
+	Meta map[string]string `json:"meta,omitempty"`
+
 	// messageVersion is the version of the message. The value 0 corresponds to
 	// the price/v0 and 1 to the price/v1 message. Both messages contain the
 	// same data but the price/v1 uses protobuf to encode the data. After full
@@ -74,6 +78,7 @@ func (p *Price) MarshallBinary() ([]byte, error) {
 			Vrs:     p.Price.Sig.Bytes(),
 			Trace:   p.Trace,
 			Version: p.Version,
+			Meta:    p.Meta,
 		}
 		if p.Price.Val != nil {
 			pbPrice.Val = p.Price.Val.Bytes()
@@ -128,6 +133,7 @@ func (p *Price) UnmarshallBinary(data []byte) error {
 		}
 		p.Trace = msg.Trace
 		p.Version = msg.Version
+		p.Meta = msg.Meta
 	case 0:
 		if err := p.Unmarshall(data); err != nil {
 			return err
@@ -174,5 +180,11 @@ func (p *Price) copy() *Price {
 		c.Trace = make([]byte, len(p.Trace))
 		copy(c.Trace, p.Trace)
 	}
+	if p.Meta != nil {
+		c.Meta = make(map[string]string, len(p.Meta))
+		for k, v := range p.Meta {
+			c.Meta[k] = v
+		}
+	}
 	return c
 }
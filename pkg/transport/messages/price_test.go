@@ -170,6 +170,26 @@ func TestPrice_Marshalling(t *testing.T) {
 			}).AsV1(),
 			wantErr: false,
 		},
+		// With metadata as V0:
+		{
+			price: (&Price{
+				messageVersion: 0,
+				Price:          &median.Price{},
+				Version:        "0.0.1",
+				Meta:           map[string]string{"traceparent": "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01"},
+			}).AsV0(),
+			wantErr: false,
+		},
+		// With metadata as V1:
+		{
+			price: (&Price{
+				messageVersion: 0,
+				Price:          &median.Price{},
+				Version:        "0.0.1",
+				Meta:           map[string]string{"traceparent": "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01"},
+			}).AsV1(),
+			wantErr: false,
+		},
 		// Too large message:
 		{
 			price: &Price{
@@ -220,6 +240,7 @@ func TestPrice_Marshalling(t *testing.T) {
 				assert.Equal(t, tt.price.Price.Age.Unix(), price.Price.Age.Unix())
 				assert.Equal(t, tt.price.Price.Sig.Bytes(), price.Price.Sig.Bytes())
 				assert.Equal(t, tt.price.Version, price.Version)
+				assert.Equal(t, tt.price.Meta, price.Meta)
 
 				if tt.price.messageVersion == 0 && tt.price.Trace == nil {
 					assert.Equal(t, json.RawMessage("null"), price.Trace)
@@ -0,0 +1,351 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package nats
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+	"github.com/nats-io/nats.go"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/metrics"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/chanutil"
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/maputil"
+	"github.com/chronicleprotocol/oracle-suite/pkg/util/sliceutil"
+)
+
+const (
+	LoggerTag     = "NATS"
+	TransportName = "nats"
+
+	// signatureHeader is the name of the NATS message header that contains
+	// the message signature.
+	signatureHeader = "Chronicle-Signature"
+
+	// defaultSubjectPrefix is the default prefix for NATS subjects.
+	defaultSubjectPrefix = "chronicle."
+
+	// messageChanSize is the size of the message channel. It is used to buffer
+	// messages before they are consumed.
+	messageChanSize = 10000
+)
+
+// NATS is a transport that uses a NATS message bus to send and receive
+// messages.
+//
+// Each topic is mapped to a NATS subject created by adding the subject prefix
+// to the topic name. Messages are published as the binary representation of
+// the transport message. Every message is signed by the publisher. The
+// signature is calculated for the concatenation of the topic name and the
+// message data and is stored as a hex encoded string in the
+// Chronicle-Signature message header.
+//
+// Received messages are accepted only if the signature is valid and the
+// author recovered from the signature is on the allowlist.
+type NATS struct {
+	mu     sync.RWMutex
+	ctx    context.Context
+	waitCh chan error
+
+	// State fields:
+	conn    *nats.Conn                                             // NATS connection.
+	subs    []*nats.Subscription                                   // Active subscriptions.
+	msgCh   map[string]chan transport.ReceivedMessage              // Channels for received messages.
+	msgChFO map[string]*chanutil.FanOut[transport.ReceivedMessage] // Fan-out channels for received messages.
+
+	// Configuration fields:
+	url           string
+	ownConn       bool
+	subjectPrefix string
+	topics        map[string]transport.Message
+	allowlist     []types.Address
+	signer        wallet.Key
+	log           log.Logger
+
+	// Internal fields:
+	recover crypto.Recoverer
+	health  *health.Reporter
+}
+
+// Config is a configuration of NATS.
+type Config struct {
+	// URL is the NATS server URL, e.g. nats://127.0.0.1:4222. Multiple
+	// servers may be provided as a comma separated list.
+	//
+	// Ignored if Conn is not nil.
+	//
+	// Cannot be empty if Conn is nil.
+	URL string
+
+	// Conn is an optional NATS connection. If provided, URL is ignored and
+	// the connection will not be closed when the transport is stopped.
+	Conn *nats.Conn
+
+	// SubjectPrefix is the prefix added to topic names to create NATS
+	// subjects. If empty, "chronicle." is used.
+	SubjectPrefix string
+
+	// Topics is a list of subscribed topics. A value of the map a type of
+	// message given as a nil pointer, e.g.: (*Message)(nil).
+	Topics map[string]transport.Message
+
+	// AuthorAllowlist is a list of allowed message authors. Only messages from
+	// these addresses will be accepted.
+	AuthorAllowlist []types.Address
+
+	// Signer used to sign messages.
+	// If not provided, message broadcast will not be available.
+	Signer wallet.Key
+
+	// Logger is a custom logger instance. If not provided then null
+	// logger is used.
+	Logger log.Logger
+
+	// Health is the registry in which the transport reports its status under
+	// the "transport.nats" name. If nil, the status is not reported.
+	Health *health.Registry
+}
+
+// New returns a new instance of NATS.
+func New(cfg Config) (*NATS, error) {
+	if cfg.Conn == nil && len(cfg.URL) == 0 {
+		return nil, errors.New("NATS server URL must be provided")
+	}
+	if cfg.SubjectPrefix == "" {
+		cfg.SubjectPrefix = defaultSubjectPrefix
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = null.New()
+	}
+	return &NATS{
+		waitCh:        make(chan error),
+		conn:          cfg.Conn,
+		msgCh:         make(map[string]chan transport.ReceivedMessage),
+		msgChFO:       make(map[string]*chanutil.FanOut[transport.ReceivedMessage]),
+		url:           cfg.URL,
+		ownConn:       cfg.Conn == nil,
+		subjectPrefix: cfg.SubjectPrefix,
+		topics:        maputil.Copy(cfg.Topics),
+		allowlist:     sliceutil.Copy(cfg.AuthorAllowlist),
+		signer:        cfg.Signer,
+		log:           cfg.Logger.WithField("tag", LoggerTag),
+		recover:       crypto.ECRecoverer,
+		health:        cfg.Health.Register("transport." + TransportName),
+	}, nil
+}
+
+// Start implements the supervisor.Supervisor interface.
+func (n *NATS) Start(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.ctx != nil {
+		return errors.New("service can be started only once")
+	}
+	if ctx == nil {
+		return errors.New("context must not be nil")
+	}
+	n.ctx = ctx
+	n.log.Debug("Starting")
+	if n.conn == nil {
+		conn, err := nats.Connect(
+			n.url,
+			nats.Name(TransportName),
+			nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+				if err != nil {
+					n.health.Failure(fmt.Errorf("disconnected from NATS server: %w", err))
+				}
+			}),
+			nats.ReconnectHandler(func(*nats.Conn) {
+				n.health.Success()
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("unable to connect to NATS server: %w", err)
+		}
+		n.conn = conn
+	}
+	for topic := range n.topics {
+		n.msgCh[topic] = make(chan transport.ReceivedMessage, messageChanSize)
+		n.msgChFO[topic] = chanutil.NewFanOut(n.msgCh[topic])
+		sub, err := n.conn.Subscribe(n.subject(topic), n.messageHandler(topic))
+		if err != nil {
+			return fmt.Errorf("unable to subscribe to topic %s: %w", topic, err)
+		}
+		n.subs = append(n.subs, sub)
+	}
+	go n.contextCancelHandler()
+	n.health.Ready()
+	return nil
+}
+
+// Wait implements the supervisor.Supervisor interface.
+func (n *NATS) Wait() <-chan error {
+	return n.waitCh
+}
+
+// Broadcast implements the transport.Transport interface.
+func (n *NATS) Broadcast(topic string, message transport.Message) error {
+	err := n.broadcast(topic, message)
+	metrics.Broadcasts.WithLabelValues(TransportName, topic, metrics.Status(err)).Inc()
+	n.health.Report(err)
+	return err
+}
+
+func (n *NATS) broadcast(topic string, message transport.Message) error {
+	if n.signer == nil {
+		return fmt.Errorf("unable to broadcast messages: signer is not set")
+	}
+	n.log.WithField("topic", topic).Debug("Broadcasting message")
+	bin, err := message.MarshallBinary()
+	if err != nil {
+		return err
+	}
+	sig, err := n.signer.SignMessage(signingData(topic, bin))
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(n.subject(topic))
+	msg.Data = bin
+	msg.Header.Set(signatureHeader, hex.EncodeToString(sig.Bytes()))
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.conn == nil {
+		return errors.New("transport is not started")
+	}
+	return n.conn.PublishMsg(msg)
+}
+
+// Messages implements the transport.Transport interface.
+func (n *NATS) Messages(topic string) <-chan transport.ReceivedMessage {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if ch, ok := n.msgChFO[topic]; ok {
+		return ch.Chan()
+	}
+	return nil
+}
+
+// subject returns the NATS subject for the given topic.
+func (n *NATS) subject(topic string) string {
+	return n.subjectPrefix + topic
+}
+
+// messageHandler returns a NATS message handler for the given topic. The
+// handler verifies the message signature, unmarshalls the message and sends
+// it to the msgCh channel.
+func (n *NATS) messageHandler(topic string) nats.MsgHandler {
+	typ := reflect.TypeOf(n.topics[topic]).Elem()
+	return func(natsMsg *nats.Msg) {
+		n.mu.RLock()
+		defer n.mu.RUnlock()
+		if n.ctx.Err() != nil {
+			return
+		}
+		fields := log.Fields{"topic": topic, "subject": natsMsg.Subject}
+
+		// Verify the message signature.
+		sig, err := hex.DecodeString(natsMsg.Header.Get(signatureHeader))
+		if err != nil || len(sig) != 65 {
+			n.log.
+				WithFields(fields).
+				Warn("Message rejected, missing or invalid signature")
+			metrics.MessagesRejected.WithLabelValues(TransportName, topic, "invalid_signature").Inc()
+			return
+		}
+		author, err := n.recover.RecoverMessage(signingData(topic, natsMsg.Data), types.MustSignatureFromBytes(sig))
+		if err != nil {
+			n.log.
+				WithFields(fields).
+				WithError(err).
+				Warn("Message rejected, invalid signature")
+			metrics.MessagesRejected.WithLabelValues(TransportName, topic, "invalid_signature").Inc()
+			return
+		}
+		fields["author"] = author.String()
+
+		// Verify if the feed is allowed to send messages.
+		if !sliceutil.Contains(n.allowlist, *author) {
+			n.log.
+				WithFields(fields).
+				Warn("Message ignored, feed is not allowed to send messages")
+			metrics.MessagesRejected.WithLabelValues(TransportName, topic, "feed_not_allowed").Inc()
+			return
+		}
+
+		// Unmarshall the message.
+		msg := reflect.New(typ).Interface().(transport.Message)
+		if err := msg.UnmarshallBinary(natsMsg.Data); err != nil {
+			n.log.
+				WithFields(fields).
+				WithError(err).
+				Warn("Message rejected, unable to unmarshall")
+			metrics.MessagesRejected.WithLabelValues(TransportName, topic, "invalid_message").Inc()
+			return
+		}
+
+		metrics.MessagesReceived.WithLabelValues(TransportName, topic).Inc()
+		n.health.Success()
+		// The read lock is held while sending, so the channel cannot be
+		// closed in the meantime. The send is abandoned when the context is
+		// canceled, so contextCancelHandler can acquire the lock even if
+		// nobody reads the channel.
+		select {
+		case n.msgCh[topic] <- transport.ReceivedMessage{
+			Message: msg,
+			Author:  author.Bytes(),
+			Meta:    transport.Meta{Transport: TransportName, Topic: topic},
+		}:
+		case <-n.ctx.Done():
+		}
+	}
+}
+
+// contextCancelHandler handles context cancellation.
+func (n *NATS) contextCancelHandler() {
+	defer func() { close(n.waitCh) }()
+	defer n.log.Debug("Stopped")
+	<-n.ctx.Done()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, sub := range n.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			n.log.WithError(err).Warn("Unable to unsubscribe")
+		}
+	}
+	if n.ownConn {
+		n.conn.Close()
+	}
+	for _, ch := range n.msgCh {
+		close(ch)
+	}
+}
+
+// signingData returns the data used to sign the message. The data is the
+// concatenation of the topic name and the message data.
+func signingData(topic string, data []byte) []byte {
+	return append([]byte(topic), data...)
+}
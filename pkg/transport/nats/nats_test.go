@@ -0,0 +1,214 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
+)
+
+type message struct {
+	data []byte
+}
+
+func (m *message) MarshallBinary() ([]byte, error) {
+	return m.data, nil
+}
+
+func (m *message) UnmarshallBinary(i []byte) error {
+	m.data = i
+	return nil
+}
+
+func runServer(t *testing.T) *server.Server {
+	srv, err := server.NewServer(&server.Options{
+		Host:   "127.0.0.1",
+		Port:   server.RANDOM_PORT,
+		NoLog:  true,
+		NoSigs: true,
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func TestNATS(t *testing.T) {
+	var (
+		key1 = wallet.NewRandomKey()
+		key2 = wallet.NewRandomKey()
+	)
+	tests := []struct {
+		name   string
+		signer wallet.Key
+		test   func(t *testing.T, p *NATS, ch <-chan transport.ReceivedMessage)
+	}{
+		{
+			name:   "valid message",
+			signer: key1,
+			test: func(t *testing.T, p *NATS, ch <-chan transport.ReceivedMessage) {
+				require.NoError(t, p.Broadcast("test", &message{data: []byte("data")}))
+				select {
+				case msg := <-ch:
+					assert.Equal(t, []byte("data"), msg.Message.(*message).data)
+					assert.Equal(t, key1.Address().Bytes(), msg.Author)
+					assert.Equal(t, TransportName, msg.Meta.Transport)
+				case <-time.After(time.Second):
+					t.Fatal("timeout")
+				}
+			},
+		},
+		{
+			name:   "author not allowed",
+			signer: key2,
+			test: func(t *testing.T, p *NATS, ch <-chan transport.ReceivedMessage) {
+				require.NoError(t, p.Broadcast("test", &message{data: []byte("data")}))
+				select {
+				case <-ch:
+					t.Fatal("unexpected message")
+				case <-time.After(100 * time.Millisecond):
+				}
+			},
+		},
+		{
+			name:   "unsigned message",
+			signer: key1,
+			test: func(t *testing.T, p *NATS, ch <-chan transport.ReceivedMessage) {
+				require.NoError(t, p.conn.Publish("chronicle.test", []byte("data")))
+				select {
+				case <-ch:
+					t.Fatal("unexpected message")
+				case <-time.After(100 * time.Millisecond):
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, ctxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer ctxCancel()
+
+			srv := runServer(t)
+
+			// Producer and consumer use separate connections.
+			prod, err := New(Config{
+				URL:    srv.ClientURL(),
+				Signer: tt.signer,
+			})
+			require.NoError(t, err)
+			cons, err := New(Config{
+				URL:             srv.ClientURL(),
+				Topics:          map[string]transport.Message{"test": (*message)(nil)},
+				AuthorAllowlist: []types.Address{key1.Address()},
+			})
+			require.NoError(t, err)
+			require.NoError(t, prod.Start(ctx))
+			require.NoError(t, cons.Start(ctx))
+
+			// Make sure that the subscription is registered on the server
+			// before messages are published.
+			require.NoError(t, cons.conn.Flush())
+
+			tt.test(t, prod, cons.Messages("test"))
+
+			ctxCancel()
+			<-prod.Wait()
+			<-cons.Wait()
+		})
+	}
+}
+
+func TestNATS_Conn(t *testing.T) {
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer ctxCancel()
+
+	srv := runServer(t)
+	conn, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	n, err := New(Config{Conn: conn, Topics: map[string]transport.Message{"test": (*message)(nil)}})
+	require.NoError(t, err)
+	require.NoError(t, n.Start(ctx))
+
+	// Channels must be closed after context is done, but a connection
+	// provided in the config must not be closed.
+	ch := n.Messages("test")
+	ctxCancel()
+	_, ok := <-ch
+	assert.False(t, ok)
+	<-n.Wait()
+	assert.False(t, conn.IsClosed())
+
+	// Broadcast without a signer must fail.
+	assert.Error(t, n.Broadcast("test", &message{data: []byte("data")}))
+}
+
+func TestNATS_StopWithFullChannel(t *testing.T) {
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer ctxCancel()
+
+	srv := runServer(t)
+	key := wallet.NewRandomKey()
+	prod, err := New(Config{URL: srv.ClientURL(), Signer: key})
+	require.NoError(t, err)
+	cons, err := New(Config{
+		URL:             srv.ClientURL(),
+		Topics:          map[string]transport.Message{"test": (*message)(nil)},
+		AuthorAllowlist: []types.Address{key.Address()},
+	})
+	require.NoError(t, err)
+	require.NoError(t, prod.Start(ctx))
+	require.NoError(t, cons.Start(ctx))
+	require.NoError(t, cons.conn.Flush())
+
+	// The subscriber does not read messages, so once the buffer is full,
+	// the handler blocks on sending the next one.
+	_ = cons.Messages("test")
+	for i := 0; i <= messageChanSize; i++ {
+		cons.msgCh["test"] <- transport.ReceivedMessage{}
+	}
+	require.NoError(t, prod.Broadcast("test", &message{data: []byte("data")}))
+	require.NoError(t, prod.conn.Flush())
+	require.Eventually(t, func() bool {
+		if cons.mu.TryLock() {
+			cons.mu.Unlock()
+			return false
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	// The transport must stop even though the handler cannot deliver the
+	// message.
+	ctxCancel()
+	select {
+	case <-cons.Wait():
+	case <-time.After(time.Second):
+		t.Fatal("transport did not stop")
+	}
+	<-prod.Wait()
+}
@@ -0,0 +1,216 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package restartable
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
+)
+
+// ErrNotRunning is returned by Broadcast if no instance of the transport
+// is running.
+var ErrNotRunning = errors.New("transport is not running")
+
+// Transport is a transport.Transport that forwards calls to the running
+// instance of another transport. It allows a supervisor to restart a failed
+// transport without affecting services that use it, because channels
+// returned by the Messages method stay open across restarts.
+//
+// Instances are started by services returned by the Instance method, which
+// should be added to the supervisor together with the Transport. The
+// Transport itself only closes the message channels once its context is
+// canceled.
+type Transport struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	ctx     context.Context
+	waitCh  chan error
+	doneCh  chan struct{}
+	closed  bool
+	current transport.Transport
+	stopCh  chan struct{} // Closed when the current instance is replaced.
+	subs    map[string][]chan transport.ReceivedMessage
+}
+
+// New returns a new Transport without a running instance.
+func New() *Transport {
+	return &Transport{
+		waitCh: make(chan error),
+		doneCh: make(chan struct{}),
+		subs:   make(map[string][]chan transport.ReceivedMessage),
+	}
+}
+
+// Instance returns a service that starts the given transport and makes it
+// the running instance. If the transport reports an error, the service
+// stops it, so it can be replaced by a new instance.
+func (t *Transport) Instance(tr transport.Transport) supervisor.Service {
+	return &instance{parent: t, transport: tr}
+}
+
+// Broadcast implements the transport.Transport interface.
+func (t *Transport) Broadcast(topic string, message transport.Message) error {
+	t.mu.Lock()
+	current := t.current
+	t.mu.Unlock()
+	if current == nil {
+		return ErrNotRunning
+	}
+	return current.Broadcast(topic, message)
+}
+
+// Messages implements the transport.Transport interface.
+func (t *Transport) Messages(topic string) <-chan transport.ReceivedMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch := make(chan transport.ReceivedMessage)
+	if t.closed {
+		close(ch)
+		return ch
+	}
+	t.subs[topic] = append(t.subs[topic], ch)
+	if t.current != nil {
+		t.forward(t.current.Messages(topic), ch, t.stopCh)
+	}
+	return ch
+}
+
+// Start implements the transport.Transport interface.
+func (t *Transport) Start(ctx context.Context) error {
+	if t.ctx != nil {
+		return errors.New("service can be started only once")
+	}
+	if ctx == nil {
+		return errors.New("context must not be nil")
+	}
+	t.ctx = ctx
+	go t.contextCancelHandler()
+	return nil
+}
+
+// Wait implements the transport.Transport interface.
+func (t *Transport) Wait() <-chan error {
+	return t.waitCh
+}
+
+// attach makes the given transport the running instance and forwards its
+// messages to the channels returned by the Messages method.
+func (t *Transport) attach(tr transport.Transport) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	if t.stopCh != nil {
+		close(t.stopCh)
+	}
+	t.current = tr
+	t.stopCh = make(chan struct{})
+	for topic, subs := range t.subs {
+		for _, ch := range subs {
+			t.forward(tr.Messages(topic), ch, t.stopCh)
+		}
+	}
+}
+
+// forward forwards messages until the source channel is closed, the
+// instance is replaced or the transport is stopped. It must be called with
+// the mutex locked.
+func (t *Transport) forward(from <-chan transport.ReceivedMessage, to chan transport.ReceivedMessage, stopCh chan struct{}) {
+	if from == nil {
+		return
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for {
+			select {
+			case <-t.doneCh:
+				return
+			case <-stopCh:
+				return
+			case msg, ok := <-from:
+				if !ok {
+					return
+				}
+				select {
+				case to <- msg:
+				case <-t.doneCh:
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (t *Transport) contextCancelHandler() {
+	defer func() { close(t.waitCh) }()
+	<-t.ctx.Done()
+	t.mu.Lock()
+	t.closed = true
+	t.current = nil
+	close(t.doneCh)
+	t.mu.Unlock()
+	t.wg.Wait()
+	for _, subs := range t.subs {
+		for _, ch := range subs {
+			close(ch)
+		}
+	}
+}
+
+// instance is a service that runs a single instance of a transport.
+type instance struct {
+	parent    *Transport
+	transport transport.Transport
+	waitCh    chan error
+}
+
+// Start implements the supervisor.Service interface.
+func (i *instance) Start(ctx context.Context) error {
+	if i.waitCh != nil {
+		return errors.New("service can be started only once")
+	}
+	ctx, ctxCancel := context.WithCancel(ctx)
+	if err := i.transport.Start(ctx); err != nil {
+		ctxCancel()
+		return err
+	}
+	i.waitCh = make(chan error)
+	i.parent.attach(i.transport)
+	go func() {
+		defer close(i.waitCh)
+		defer ctxCancel()
+		for err := range i.transport.Wait() {
+			if err != nil {
+				// Stop the failed instance, so it releases its resources
+				// before it is replaced.
+				ctxCancel()
+			}
+			i.waitCh <- err
+		}
+	}()
+	return nil
+}
+
+// Wait implements the supervisor.Service interface.
+func (i *instance) Wait() <-chan error {
+	return i.waitCh
+}
@@ -0,0 +1,153 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package restartable
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/supervisor"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/messages"
+)
+
+type testTransport struct {
+	mu         sync.Mutex
+	ctx        context.Context
+	waitCh     chan error
+	msgCh      chan transport.ReceivedMessage
+	broadcasts int
+}
+
+func newTestTransport() *testTransport {
+	return &testTransport{
+		waitCh: make(chan error, 1),
+		msgCh:  make(chan transport.ReceivedMessage, 1),
+	}
+}
+
+func (t *testTransport) Start(ctx context.Context) error {
+	t.ctx = ctx
+	go func() {
+		<-ctx.Done()
+		close(t.waitCh)
+	}()
+	return nil
+}
+
+func (t *testTransport) Wait() <-chan error {
+	return t.waitCh
+}
+
+func (t *testTransport) Broadcast(_ string, _ transport.Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.broadcasts++
+	return nil
+}
+
+func (t *testTransport) Messages(_ string) <-chan transport.ReceivedMessage {
+	return t.msgCh
+}
+
+func TestTransport(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+
+	rt := New()
+	require.NoError(t, rt.Start(ctx))
+	msgs := rt.Messages("test")
+	assert.ErrorIs(t, rt.Broadcast("test", &messages.Event{}), ErrNotRunning)
+
+	tt := newTestTransport()
+	require.NoError(t, rt.Instance(tt).Start(ctx))
+	require.NoError(t, rt.Broadcast("test", &messages.Event{}))
+	assert.Equal(t, 1, tt.broadcasts)
+
+	tt.msgCh <- transport.ReceivedMessage{Author: []byte("a")}
+	assert.Equal(t, []byte("a"), (<-msgs).Author)
+
+	// Message channels are closed when the transport is stopped:
+	ctxCancel()
+	for range msgs {
+	}
+	<-rt.Wait()
+}
+
+func TestTransport_InstanceFailure(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+
+	rt := New()
+	require.NoError(t, rt.Start(ctx))
+	tt := newTestTransport()
+	srv := rt.Instance(tt)
+	require.NoError(t, srv.Start(ctx))
+
+	// A failed instance is stopped:
+	tt.waitCh <- errors.New("test")
+	assert.EqualError(t, <-srv.Wait(), "test")
+	_, ok := <-srv.Wait()
+	assert.False(t, ok)
+	assert.Error(t, tt.ctx.Err())
+	assert.NoError(t, ctx.Err())
+}
+
+func TestTransport_Supervisor(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+
+	rt := New()
+	first := newTestTransport()
+	second := newTestTransport()
+	s := supervisor.New(nil)
+	s.WatchService(supervisor.ServiceConfig{Service: rt, Name: "transport"})
+	s.WatchService(supervisor.ServiceConfig{
+		Service:       rt.Instance(first),
+		Name:          "transport_instance",
+		RestartPolicy: supervisor.RestartOnFailure,
+		Factory: func() (supervisor.Service, error) {
+			return rt.Instance(second), nil
+		},
+		Backoff:   supervisor.Backoff{Initial: time.Millisecond},
+		DependsOn: []string{"transport"},
+	})
+	require.NoError(t, s.Start(ctx))
+	msgs := rt.Messages("test")
+
+	// After the first instance fails, messages from the second instance are
+	// delivered to the same channel:
+	first.waitCh <- errors.New("test")
+	second.msgCh <- transport.ReceivedMessage{Author: []byte("b")}
+	select {
+	case msg := <-msgs:
+		assert.Equal(t, []byte("b"), msg.Author)
+	case <-time.After(time.Second):
+		require.Fail(t, "message was not forwarded")
+	}
+	assert.Error(t, first.ctx.Err())
+	require.NoError(t, rt.Broadcast("test", &messages.Event{}))
+	assert.Equal(t, 1, second.broadcasts)
+
+	ctxCancel()
+	assert.NoError(t, <-s.Wait())
+}
@@ -2,16 +2,34 @@ package webapi
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/defiweb/go-eth/abi"
+	"github.com/defiweb/go-eth/crypto"
 	"github.com/defiweb/go-eth/rpc"
 	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
 )
 
+// maxRegistryDocumentSize is the maximum size of the registry document
+// fetched by HTTPAddressBook.
+const maxRegistryDocumentSize = 1 << 20
+
 // AddressBook provides a list of addresses to which the messages should be
 // sent.
+//
+// An address may be prefixed with the Ethereum address of the consumer
+// followed by "@", e.g. "0x1234...@consumer.onion:8080". In that case, the
+// delivery receipt must be signed by that address.
 type AddressBook interface {
 	Consumers(ctx context.Context) ([]string, error)
 }
@@ -130,3 +148,219 @@ func (c *EthereumAddressBook) fetchConsumers(ctx context.Context) ([]string, err
 }
 
 var consumersMethod = abi.MustParseMethod("function list() returns (string[])")
+
+// RegistryDocument is a signed list of consumers served by an HTTP registry
+// and used by the HTTPAddressBook.
+//
+// The document is encoded as JSON:
+//
+//	{
+//	  "consumers": ["abc.onion:8080", "def.onion:8080"],
+//	  "timestamp": 1672531200,
+//	  "signature": "0x..."
+//	}
+//
+// The signature is created by signing the timestamp encoded as a 10-base
+// string followed by consumer addresses, each prefixed with a new line
+// character.
+type RegistryDocument struct {
+	// Consumers is the list of consumer addresses.
+	Consumers []string `json:"consumers"`
+
+	// Timestamp is the UNIX timestamp in seconds at which the document was
+	// signed. Documents older than the last accepted one, or older than the
+	// maximum age of the HTTPAddressBook, are rejected.
+	Timestamp int64 `json:"timestamp"`
+
+	// Signature is the signature of the document.
+	Signature types.Signature `json:"signature"`
+}
+
+// Sign signs the document using the given signer.
+func (d *RegistryDocument) Sign(signer wallet.Key) error {
+	sig, err := signer.SignMessage(d.signingData())
+	if err != nil {
+		return err
+	}
+	d.Signature = *sig
+	return nil
+}
+
+// Verify verifies the document signature and returns the signer address.
+func (d *RegistryDocument) Verify(recover crypto.Recoverer) (*types.Address, error) {
+	return recover.RecoverMessage(d.signingData(), d.Signature)
+}
+
+func (d *RegistryDocument) signingData() []byte {
+	var signingData []byte
+	signingData = append(signingData, strconv.FormatInt(d.Timestamp, 10)...)
+	for _, addr := range d.Consumers {
+		signingData = append(signingData, '\n')
+		signingData = append(signingData, addr...)
+	}
+	return signingData
+}
+
+// HTTPAddressBook is an AddressBook implementation that fetches the list of
+// consumers from an HTTP registry. The registry must serve a RegistryDocument
+// signed by a known key.
+type HTTPAddressBook struct {
+	mu sync.Mutex
+
+	client    *http.Client  // HTTP client.
+	url       string        // URL of the registry document.
+	signer    types.Address // Address of the document signer.
+	cache     []string      // Cached list of addresses.
+	cacheTime time.Time     // Time when the cache was last updated.
+	cacheTTL  time.Duration // How long the cache should be valid.
+	maxAge    time.Duration // Maximum age of the document.
+	lastTime  int64         // Timestamp of the last accepted document.
+	recover   crypto.Recoverer
+}
+
+// NewHTTPAddressBook creates a new instance of HTTPAddressBook.
+// The signer parameter is the address of the key that signs the registry
+// document. The cacheTTL parameter specifies how long the list of addresses
+// should be cached before it is fetched again from the registry.
+//
+// The maxAge parameter specifies the maximum age of the document based on
+// its timestamp. Because the timestamp of the last accepted document is
+// not persisted, the maximum age prevents replaying an old document after
+// a restart. If zero, the age is not checked.
+func NewHTTPAddressBook(
+	client *http.Client,
+	url string,
+	signer types.Address,
+	cacheTTL time.Duration,
+	maxAge time.Duration,
+) *HTTPAddressBook {
+	return &HTTPAddressBook{
+		client:   client,
+		url:      url,
+		signer:   signer,
+		cacheTTL: cacheTTL,
+		maxAge:   maxAge,
+		recover:  crypto.ECRecoverer,
+	}
+}
+
+// Consumers implements the AddressBook interface.
+func (c *HTTPAddressBook) Consumers(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cache == nil || c.cacheTime.Add(c.cacheTTL).Before(time.Now()) {
+		addrs, err := c.fetchConsumers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.cache = addrs
+		c.cacheTime = time.Now()
+	}
+	return c.cache, nil
+}
+
+func (c *HTTPAddressBook) fetchConsumers(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry responded with status %d", res.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxRegistryDocumentSize))
+	if err != nil {
+		return nil, err
+	}
+	var doc RegistryDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid registry document: %w", err)
+	}
+	addr, err := doc.Verify(c.recover)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry document signature: %w", err)
+	}
+	if *addr != c.signer {
+		return nil, fmt.Errorf("registry document signed by unknown key %s", addr)
+	}
+	if doc.Timestamp < c.lastTime {
+		return nil, errors.New("registry document is older than the last accepted one")
+	}
+	if c.maxAge > 0 && time.Unix(doc.Timestamp, 0).Add(c.maxAge).Before(time.Now()) {
+		return nil, fmt.Errorf("registry document is older than %s", c.maxAge)
+	}
+	c.lastTime = doc.Timestamp
+	if doc.Consumers == nil {
+		return []string{}, nil
+	}
+	return doc.Consumers, nil
+}
+
+// TXTResolver resolves DNS TXT records. It is implemented by net.Resolver.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DNSAddressBook is an AddressBook implementation that reads the list of
+// consumers from DNS TXT records of a domain. Each record may contain
+// multiple addresses separated by whitespace or commas.
+//
+// DNS responses are not authenticated, but because all messages are signed
+// by producers, a spoofed response can only affect the message delivery.
+type DNSAddressBook struct {
+	mu sync.Mutex
+
+	resolver  TXTResolver   // DNS resolver.
+	domain    string        // Domain with TXT records.
+	cache     []string      // Cached list of addresses.
+	cacheTime time.Time     // Time when the cache was last updated.
+	cacheTTL  time.Duration // How long the cache should be valid.
+}
+
+// NewDNSAddressBook creates a new instance of DNSAddressBook.
+// The cacheTTL parameter specifies how long the list of addresses should be
+// cached before the TXT records are resolved again.
+func NewDNSAddressBook(resolver TXTResolver, domain string, cacheTTL time.Duration) *DNSAddressBook {
+	return &DNSAddressBook{
+		resolver: resolver,
+		domain:   domain,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Consumers implements the AddressBook interface.
+func (c *DNSAddressBook) Consumers(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cache == nil || c.cacheTime.Add(c.cacheTTL).Before(time.Now()) {
+		addrs, err := c.fetchConsumers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.cache = addrs
+		c.cacheTime = time.Now()
+	}
+	return c.cache, nil
+}
+
+func (c *DNSAddressBook) fetchConsumers(ctx context.Context) ([]string, error) {
+	records, err := c.resolver.LookupTXT(ctx, c.domain)
+	if err != nil {
+		return nil, err
+	}
+	addrs := []string{}
+	for _, record := range records {
+		for _, addr := range strings.FieldsFunc(record, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		}) {
+			addrs = append(addrs, addr)
+		}
+	}
+	// The order of TXT records is not guaranteed.
+	sort.Strings(addrs)
+	return addrs, nil
+}
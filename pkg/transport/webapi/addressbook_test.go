@@ -2,13 +2,18 @@ package webapi
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/defiweb/go-eth/abi"
 	"github.com/defiweb/go-eth/hexutil"
 	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -178,6 +183,116 @@ func TestEthereumAddressBook_Cache(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestHTTPAddressBook_Consumers(t *testing.T) {
+	var (
+		key      = wallet.NewRandomKey()
+		otherKey = wallet.NewRandomKey()
+		tm       = time.Now().Unix()
+	)
+	tests := []struct {
+		signer  wallet.Key
+		docs    []RegistryDocument
+		want    []string
+		wantErr bool
+	}{
+		{
+			signer: key,
+			docs:   []RegistryDocument{{Consumers: []string{"domain1.example", "domain2.example"}, Timestamp: tm}},
+			want:   []string{"domain1.example", "domain2.example"},
+		},
+		{
+			signer: key,
+			docs:   []RegistryDocument{{Timestamp: tm}},
+			want:   []string{},
+		},
+		{
+			// Document signed by unknown key.
+			signer:  otherKey,
+			docs:    []RegistryDocument{{Consumers: []string{"domain1.example"}, Timestamp: tm}},
+			wantErr: true,
+		},
+		{
+			// Older document must be rejected.
+			signer: key,
+			docs: []RegistryDocument{
+				{Consumers: []string{"domain1.example"}, Timestamp: tm},
+				{Consumers: []string{"domain2.example"}, Timestamp: tm - 1},
+			},
+			wantErr: true,
+		},
+		{
+			// Document older than the maximum age must be rejected.
+			signer:  key,
+			docs:    []RegistryDocument{{Consumers: []string{"domain1.example"}, Timestamp: tm - 2*3600}},
+			wantErr: true,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			var docs [][]byte
+			for _, doc := range tt.docs {
+				require.NoError(t, doc.Sign(tt.signer))
+				docs = append(docs, errutil.Must(json.Marshal(doc)))
+			}
+			srv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				_, _ = res.Write(docs[0])
+				docs = docs[1:]
+			}))
+			defer srv.Close()
+
+			// Cache TTL is zero, so every call fetches the document.
+			book := NewHTTPAddressBook(srv.Client(), srv.URL, key.Address(), 0, time.Hour)
+			var (
+				consumers []string
+				err       error
+			)
+			for range tt.docs {
+				consumers, err = book.Consumers(context.Background())
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, consumers)
+		})
+	}
+}
+
+type txtResolver struct {
+	records map[string][]string
+	calls   int
+}
+
+func (r *txtResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	r.calls++
+	records, ok := r.records[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return records, nil
+}
+
+func TestDNSAddressBook_Consumers(t *testing.T) {
+	resolver := &txtResolver{records: map[string][]string{
+		"consumers.example": {"domain2.example, domain3.example", "domain1.example"},
+	}}
+
+	book := NewDNSAddressBook(resolver, "consumers.example", time.Minute)
+	consumers, err := book.Consumers(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"domain1.example", "domain2.example", "domain3.example"}, consumers)
+
+	// The result must be cached.
+	_, err = book.Consumers(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, resolver.calls)
+
+	// Unknown domain.
+	_, err = NewDNSAddressBook(resolver, "unknown.example", time.Minute).Consumers(context.Background())
+	assert.Error(t, err)
+}
+
 func encodeAddresses(addresses []string) []byte {
 	return errutil.Must(abi.EncodeValues(consumersMethod.Outputs(), addresses))
 }
@@ -0,0 +1,127 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package webapi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/defiweb/go-eth/crypto"
+	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
+)
+
+// Receipt is a signed acknowledgement returned by a consumer in the response
+// to the consume request. It proves that the consumer accepted a message pack.
+type Receipt struct {
+	// BatchHash is the Keccak256 hash of the protobuf-encoded message pack
+	// before compression.
+	BatchHash types.Hash `json:"hash"`
+
+	// Timestamp is the time at which the consumer accepted the message pack.
+	// It is encoded as a UNIX timestamp in seconds.
+	Timestamp int64 `json:"timestamp"`
+
+	// Consumer is the address of the consumer that signed the receipt.
+	Consumer types.Address `json:"consumer"`
+
+	// Signature is the signature of the receipt created by the consumer.
+	Signature types.Signature `json:"signature"`
+}
+
+// DeliveryStatus describes the result of the last delivery of a message
+// pack to a consumer.
+type DeliveryStatus struct {
+	// LastAttempt is the time of the last delivery attempt.
+	LastAttempt time.Time
+
+	// LastSuccess is the time of the last successful delivery.
+	LastSuccess time.Time
+
+	// Attempts is the number of attempts made to deliver the last message
+	// pack.
+	Attempts int
+
+	// Receipt is the receipt returned by the consumer for the last
+	// successfully delivered message pack. It is nil if the consumer did not
+	// return a receipt.
+	Receipt *Receipt
+
+	// Error is the error of the last delivery attempt, nil if the last
+	// attempt was successful.
+	Error error
+}
+
+// signReceipt creates a receipt for the given batch hash signed using the
+// given signer.
+func signReceipt(batchHash types.Hash, tm time.Time, signer wallet.Key) (*Receipt, error) {
+	r := &Receipt{
+		BatchHash: batchHash,
+		Timestamp: tm.Unix(),
+		Consumer:  signer.Address(),
+	}
+	sig, err := signer.SignMessage(receiptSigningData(r))
+	if err != nil {
+		return nil, err
+	}
+	r.Signature = *sig
+	return r, nil
+}
+
+// verifyReceipt verifies that the receipt is signed by the consumer listed
+// in the receipt, that it commits to the given batch hash, and that its
+// timestamp is within the maxClockSkew from the now time. If consumer is
+// not nil, the receipt must be issued by that consumer.
+func verifyReceipt(
+	r *Receipt,
+	batchHash types.Hash,
+	consumer *types.Address,
+	now time.Time,
+	maxClockSkew time.Duration,
+	recover crypto.Recoverer,
+) error {
+	if r.BatchHash != batchHash {
+		return errors.New("receipt batch hash does not match")
+	}
+	if consumer != nil && r.Consumer != *consumer {
+		return fmt.Errorf("receipt consumer %s does not match expected consumer %s", r.Consumer, consumer)
+	}
+	tm := time.Unix(r.Timestamp, 0)
+	if tm.Before(now.Add(-maxClockSkew)) || tm.After(now.Add(maxClockSkew)) {
+		return errors.New("receipt timestamp is outside of the allowed clock skew")
+	}
+	addr, err := recover.RecoverMessage(receiptSigningData(r), r.Signature)
+	if err != nil {
+		return err
+	}
+	if *addr != r.Consumer {
+		return fmt.Errorf("receipt signer %s does not match consumer %s", addr, r.Consumer)
+	}
+	return nil
+}
+
+// receiptSigningData returns the data used to sign the given receipt.
+// The data is the concatenation of the batch hash, the timestamp encoded as
+// a big-endian 64-bit integer and the consumer address.
+func receiptSigningData(r *Receipt) []byte {
+	var signingData []byte
+	signingData = append(signingData, r.BatchHash.Bytes()...)
+	signingData = binary.BigEndian.AppendUint64(signingData, uint64(r.Timestamp))
+	signingData = append(signingData, r.Consumer.Bytes()...)
+	return signingData
+}
@@ -0,0 +1,93 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package webapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/mocks"
+)
+
+func Test_signReceipt(t *testing.T) {
+	var (
+		tm        = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		hash      = types.MustHashFromHex("0x0102030405060708091011121314151617181920212223242526272829303132", types.PadNone)
+		address1  = types.MustAddressFromHex("0x1234567890123456789012345678901234567890")
+		address2  = types.MustAddressFromHex("0x2345678901234567890123456789012345678901")
+		expData   = append(append(hash.Bytes(), 0, 0, 0, 0, 0x5e, 0x0b, 0xe1, 0x00), address1.Bytes()...)
+		signer    = &mocks.Key{}
+		recoverer = &mocks.Recoverer{}
+	)
+
+	// Mocks:
+	signer.On("Address").Return(address1)
+	signer.On("SignMessage", expData).Return(&fakeSignature, nil)
+	recoverer.On("RecoverMessage", expData, fakeSignature).Return(&address1, nil)
+
+	// Sign receipt:
+	r, err := signReceipt(hash, tm, signer)
+	require.NoError(t, err)
+	assert.Equal(t, hash, r.BatchHash)
+	assert.Equal(t, tm.Unix(), r.Timestamp)
+	assert.Equal(t, address1, r.Consumer)
+
+	// Valid receipt:
+	require.NoError(t, verifyReceipt(r, hash, nil, tm, time.Second, recoverer))
+
+	// Expected consumer:
+	require.NoError(t, verifyReceipt(r, hash, &address1, tm, time.Second, recoverer))
+	assert.Error(t, verifyReceipt(r, hash, &address2, tm, time.Second, recoverer))
+
+	// Different batch hash:
+	assert.Error(t, verifyReceipt(r, types.Hash{}, nil, tm, time.Second, recoverer))
+
+	// Timestamp outside of the clock skew:
+	assert.Error(t, verifyReceipt(r, hash, nil, tm.Add(time.Minute), time.Second, recoverer))
+	assert.Error(t, verifyReceipt(r, hash, nil, tm.Add(-time.Minute), time.Second, recoverer))
+
+	// Signer does not match the consumer:
+	r.Consumer = address2
+	recoverer.On("RecoverMessage", receiptSigningData(r), fakeSignature).Return(&address1, nil)
+	assert.Error(t, verifyReceipt(r, hash, nil, tm, time.Second, recoverer))
+}
+
+func Test_parseConsumer(t *testing.T) {
+	address := types.MustAddressFromHex("0x1234567890123456789012345678901234567890")
+	tests := []struct {
+		entry    string
+		addr     string
+		consumer *types.Address
+	}{
+		{entry: "localhost:8080", addr: "localhost:8080"},
+		{entry: "http://localhost:8080", addr: "http://localhost:8080"},
+		{entry: "0x1234567890123456789012345678901234567890@localhost:8080", addr: "localhost:8080", consumer: &address},
+		{entry: "0x1234567890123456789012345678901234567890@http://localhost:8080", addr: "http://localhost:8080", consumer: &address},
+		{entry: "0xzz34567890123456789012345678901234567890@localhost:8080", addr: "0xzz34567890123456789012345678901234567890@localhost:8080"},
+		{entry: "user@localhost:8080", addr: "user@localhost:8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			addr, consumer := parseConsumer(tt.entry)
+			assert.Equal(t, tt.addr, addr)
+			assert.Equal(t, tt.consumer, consumer)
+		})
+	}
+}
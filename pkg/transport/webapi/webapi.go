@@ -21,6 +21,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -38,10 +39,13 @@ import (
 	"github.com/defiweb/go-eth/wallet"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/chronicleprotocol/oracle-suite/pkg/health"
 	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log"
 	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
+	"github.com/chronicleprotocol/oracle-suite/pkg/metrics"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
+	"github.com/chronicleprotocol/oracle-suite/pkg/transport/dedup"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/webapi/pb"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/chanutil"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/maputil"
@@ -67,6 +71,21 @@ const (
 	// defaultTimeout is the default timeout for HTTP requests, both for
 	// client and server.
 	defaultTimeout = 60 * time.Second
+
+	// defaultDedupCacheSize is the default number of messages stored in the
+	// deduplication cache.
+	defaultDedupCacheSize = 100000
+
+	// defaultDedupTTL is the default time for which a message is remembered
+	// by the deduplication cache.
+	defaultDedupTTL = time.Hour
+
+	// defaultRetryInterval is the default interval between delivery retries.
+	defaultRetryInterval = time.Second
+
+	// maxReceiptSize is the maximum size of the receipt returned by the
+	// consumer.
+	maxReceiptSize = 4096
 )
 
 // WebAPI is transport that uses HTTP API to send and receive messages.
@@ -131,6 +150,19 @@ const (
 // The HTTP server returns HTTP 200 OK response if the request is valid.
 // Otherwise, it returns 429 Too Many Requests response if producer sends
 // messages too often or 400 Bad Request response for any other error.
+//
+// If the consumer has a signer, the 200 OK response contains a JSON encoded
+// receipt (see Receipt) that commits to the Keccak256 hash of the
+// uncompressed message pack, the time of acceptance and the consumer
+// address. The producer verifies the receipt and records the delivery status
+// for each consumer (see DeliveryStatus). Deliveries that failed due to
+// network errors or server errors are retried as long as the consumer would
+// still accept the request, that is, within the maxClockSkew from the
+// timestamp used to sign the URL.
+//
+// Consumers deliver each message only once. Messages with the same topic,
+// author and content that were already received, e.g. re-sent by a producer
+// after a restart, are dropped (see dedup.Cache).
 type WebAPI struct {
 	mu     sync.RWMutex
 	ctx    context.Context
@@ -138,24 +170,35 @@ type WebAPI struct {
 
 	// State fields:
 	messagePack *pb.MessagePack                                        // Message pack to be sent on next flush.
-	lastReqs    map[types.Address]time.Time                            // Last timestamp received from each producer.
+	lastReqs    map[types.Address]lastRequest                          // Last request received from each producer.
 	msgCh       map[string]chan transport.ReceivedMessage              // Channels for received messages.
 	msgChFO     map[string]*chanutil.FanOut[transport.ReceivedMessage] // Fan-out channels for received messages.
 
+	// Request state fields:
+	lastReqsMu sync.Mutex
+
+	// Delivery state fields:
+	deliveryMu sync.Mutex
+	deliveries map[string]DeliveryStatus // Delivery status for each consumer.
+	retryQueue []*delivery               // Deliveries to be retried.
+
 	// Configuration fields:
-	addressBook  AddressBook
-	topics       map[string]transport.Message
-	allowlist    []types.Address
-	flushTicker  *timeutil.Ticker
-	signer       wallet.Key
-	client       *http.Client
-	server       *httpserver.HTTPServer
-	rand         io.Reader
-	maxClockSkew time.Duration
-	log          log.Logger
+	addressBook   AddressBook
+	topics        map[string]transport.Message
+	allowlist     []types.Address
+	flushTicker   *timeutil.Ticker
+	signer        wallet.Key
+	dedup         *dedup.Cache
+	client        *http.Client
+	server        *httpserver.HTTPServer
+	rand          io.Reader
+	maxClockSkew  time.Duration
+	retryInterval time.Duration
+	log           log.Logger
 
 	// Internal fields:
 	recover crypto.Recoverer
+	health  *health.Reporter
 }
 
 // Config is a configuration of WebAPI.
@@ -169,7 +212,8 @@ type Config struct {
 	ListenAddr string
 
 	// AddressBook provides the list of message consumers. All produced messages
-	// will be sent only to consumers from the address book.
+	// will be sent only to consumers from the address book. Consumers may be
+	// bound to an Ethereum address, see AddressBook.
 	//
 	// Cannot be nil.
 	AddressBook AddressBook
@@ -206,6 +250,11 @@ type Config struct {
 	// messages. If provided, Timeout is ignored.
 	Client *http.Client
 
+	// DedupCache is an optional cache used to drop duplicated messages. It
+	// may be shared between multiple transports. If not provided, a new cache
+	// that remembers up to 100000 messages for 1 hour will be used.
+	DedupCache *dedup.Cache
+
 	// Rand is an optional random number generator. If not provided, Reader
 	// from crypto/rand package will be used.
 	Rand io.Reader
@@ -214,9 +263,18 @@ type Config struct {
 	// and the producer. If not provided, default value will be used (10 seconds).
 	MaxClockSkew time.Duration
 
+	// RetryInterval is the interval between retries of failed deliveries.
+	// Failed deliveries are retried only within the MaxClockSkew window.
+	// If not provided, default value will be used (1 second).
+	RetryInterval time.Duration
+
 	// Logger is a custom logger instance. If not provided then null
 	// logger is used.
 	Logger log.Logger
+
+	// Health is the registry in which the transport reports its status under
+	// the "transport.webapi" name. If nil, the status is not reported.
+	Health *health.Registry
 }
 
 // New returns a new instance of WebAPI.
@@ -239,6 +297,12 @@ func New(cfg Config) (*WebAPI, error) {
 	if cfg.MaxClockSkew == 0 {
 		cfg.MaxClockSkew = defaultMaxClockSkew
 	}
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = defaultRetryInterval
+	}
+	if cfg.DedupCache == nil {
+		cfg.DedupCache = dedup.NewCache(defaultDedupCacheSize, defaultDedupTTL)
+	}
 	if cfg.Rand == nil {
 		cfg.Rand = rand.Reader
 	}
@@ -260,21 +324,25 @@ func New(cfg Config) (*WebAPI, error) {
 		client = &http.Client{Timeout: cfg.Timeout}
 	}
 	w := &WebAPI{
-		waitCh:       make(chan error),
-		topics:       maputil.Copy(cfg.Topics),
-		allowlist:    sliceutil.Copy(cfg.AuthorAllowlist),
-		addressBook:  cfg.AddressBook,
-		flushTicker:  cfg.FlushTicker,
-		client:       client,
-		server:       server,
-		signer:       cfg.Signer,
-		lastReqs:     make(map[types.Address]time.Time),
-		msgCh:        make(map[string]chan transport.ReceivedMessage),
-		msgChFO:      make(map[string]*chanutil.FanOut[transport.ReceivedMessage]),
-		maxClockSkew: cfg.MaxClockSkew,
-		rand:         cfg.Rand,
-		log:          cfg.Logger.WithField("tag", LoggerTag),
-		recover:      crypto.ECRecoverer,
+		waitCh:        make(chan error),
+		topics:        maputil.Copy(cfg.Topics),
+		allowlist:     sliceutil.Copy(cfg.AuthorAllowlist),
+		addressBook:   cfg.AddressBook,
+		flushTicker:   cfg.FlushTicker,
+		client:        client,
+		server:        server,
+		signer:        cfg.Signer,
+		dedup:         cfg.DedupCache,
+		lastReqs:      make(map[types.Address]lastRequest),
+		msgCh:         make(map[string]chan transport.ReceivedMessage),
+		msgChFO:       make(map[string]*chanutil.FanOut[transport.ReceivedMessage]),
+		deliveries:    make(map[string]DeliveryStatus),
+		maxClockSkew:  cfg.MaxClockSkew,
+		retryInterval: cfg.RetryInterval,
+		rand:          cfg.Rand,
+		log:           cfg.Logger.WithField("tag", LoggerTag),
+		recover:       crypto.ECRecoverer,
+		health:        cfg.Health.Register("transport." + TransportName),
 	}
 	w.server.SetHandler(http.HandlerFunc(w.consumeHandler))
 	return w, nil
@@ -299,7 +367,9 @@ func (w *WebAPI) Start(ctx context.Context) error {
 	}
 	w.flushTicker.Start(ctx)
 	go w.flushRoutine(ctx)
+	go w.retryRoutine(ctx)
 	go w.contextCancelHandler()
+	w.health.Ready()
 	return nil
 }
 
@@ -310,6 +380,13 @@ func (w *WebAPI) Wait() <-chan error {
 
 // Broadcast implements the transport.Transport interface.
 func (w *WebAPI) Broadcast(topic string, message transport.Message) error {
+	err := w.broadcast(topic, message)
+	metrics.Broadcasts.WithLabelValues(TransportName, topic, metrics.Status(err)).Inc()
+	w.health.Report(err)
+	return err
+}
+
+func (w *WebAPI) broadcast(topic string, message transport.Message) error {
 	if w.signer == nil {
 		return fmt.Errorf("unable to broadcast messages: signer is not set")
 	}
@@ -342,6 +419,24 @@ func (w *WebAPI) Messages(topic string) <-chan transport.ReceivedMessage {
 	return nil
 }
 
+// DeliveryStatus returns the delivery status for each consumer to which
+// messages were sent. The map key is the consumer address as used in the
+// request URL.
+func (w *WebAPI) DeliveryStatus() map[string]DeliveryStatus {
+	w.deliveryMu.Lock()
+	defer w.deliveryMu.Unlock()
+	return maputil.Copy(w.deliveries)
+}
+
+// SetAddressBook replaces the address book used to find consumers. It may
+// be called while the transport is running, the new address book is used
+// on the next flush.
+func (w *WebAPI) SetAddressBook(addressBook AddressBook) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.addressBook = addressBook
+}
+
 // flushMessages sends the current batch of messages to the consumers.
 // The batch is cleared after the messages are sent.
 func (w *WebAPI) flushMessages(ctx context.Context, t time.Time) error {
@@ -360,6 +455,7 @@ func (w *WebAPI) flushMessages(ctx context.Context, t time.Time) error {
 	if err != nil {
 		return err
 	}
+	hash := crypto.Keccak256(bin)
 	bin, err = gzipCompress(bin)
 	if err != nil {
 		return err
@@ -368,24 +464,108 @@ func (w *WebAPI) flushMessages(ctx context.Context, t time.Time) error {
 	if err != nil {
 		return err
 	}
-	// Consumer addresses may omit protocol scheme, so we add it here.
-	for n, addr := range cons {
+	for _, entry := range cons {
+		addr, consumer := parseConsumer(entry)
+		// Consumer addresses may omit protocol scheme, so we add it here.
 		if !strings.Contains(addr, "://") {
 			// Data transmitted over the WebAPI protocol is signed, hence
 			// there is no need to use HTTPS.
-			cons[n] = "http://" + addr
+			addr = "http://" + addr
 		}
-	}
-	for _, addr := range cons {
-		go w.doHTTPRequest(ctx, addr, bin, t)
+		go w.deliver(ctx, &delivery{addr: addr, consumer: consumer, data: bin, hash: hash, time: t})
 	}
 	return nil
 }
 
+// parseConsumer splits an address book entry into the consumer address and
+// the optional Ethereum address of the consumer. The Ethereum address may
+// precede the consumer address and be separated by "@", e.g.
+// "0x1234...@consumer.onion:8080". If it is given, receipts must be signed
+// by that address.
+func parseConsumer(entry string) (string, *types.Address) {
+	const prefixLen = 2 + 2*types.AddressLength
+	if len(entry) <= prefixLen || entry[prefixLen] != '@' || !strings.HasPrefix(entry, "0x") {
+		return entry, nil
+	}
+	consumer, err := types.AddressFromHex(entry[:prefixLen])
+	if err != nil {
+		return entry, nil
+	}
+	return entry[prefixLen+1:], &consumer
+}
+
+// lastRequest is the last request received from a producer.
+type lastRequest struct {
+	timestamp time.Time  // Time used for the URL signature.
+	batchHash types.Hash // Hash of the uncompressed MessagePack.
+}
+
+// delivery is a single delivery of a message pack to a consumer.
+type delivery struct {
+	addr     string         // Consumer address.
+	consumer *types.Address // Expected receipt signer, may be nil.
+	data     []byte         // Gzipped protobuf-encoded MessagePack.
+	hash     types.Hash     // Hash of the uncompressed MessagePack.
+	time     time.Time      // Time used for the URL signature.
+	attempts int            // Number of delivery attempts.
+}
+
+// deliver sends the message pack to the consumer and records the delivery
+// status. If the delivery fails with a retryable error, it is added to the
+// retry queue.
+func (w *WebAPI) deliver(ctx context.Context, d *delivery) {
+	d.attempts++
+	receipt, retry, err := w.doHTTPRequest(ctx, d.addr, d.consumer, d.data, d.hash, d.time)
+	now := time.Now()
+
+	w.deliveryMu.Lock()
+	defer w.deliveryMu.Unlock()
+	status := w.deliveries[d.addr]
+	status.LastAttempt = now
+	status.Attempts = d.attempts
+	status.Error = err
+	if err == nil {
+		status.LastSuccess = now
+		status.Receipt = receipt
+	}
+	w.deliveries[d.addr] = status
+	if err == nil {
+		return
+	}
+	w.log.
+		WithError(err).
+		WithFields(log.Fields{"addr": d.addr, "attempts": d.attempts}).
+		Error("Failed to deliver messages to consumer")
+
+	// The consumer rejects requests with a timestamp older than
+	// flushInterval + maxClockSkew, so retrying within the maxClockSkew
+	// window guarantees that the retry will not be rejected because of
+	// the timestamp. Retries reuse the timestamp of the first attempt, which
+	// the consumer accepts again for the same batch, in case the first
+	// attempt was accepted but the response was lost.
+	if retry && ctx.Err() == nil && now.Add(w.retryInterval).Before(d.time.Add(w.maxClockSkew)) {
+		w.retryQueue = append(w.retryQueue, d)
+	}
+}
+
 // doHTTPRequest sends a POST request to the given address with the given
-// data. The data must be gzipped protobuf-encoded MessagePack. The t parameter
-// is the time used for the URL signature.
-func (w *WebAPI) doHTTPRequest(ctx context.Context, addr string, data []byte, t time.Time) {
+// data. The data must be gzipped protobuf-encoded MessagePack and hash is the
+// hash of the uncompressed MessagePack. The t parameter is the time used for
+// the URL signature. If consumer is not nil, the receipt must be signed by
+// the consumer address.
+//
+// It returns the receipt returned by the consumer, or nil if the consumer
+// did not return a receipt, which is allowed only if consumer is nil. In
+// case of an error, the retry flag indicates whether the delivery may be
+// retried.
+func (w *WebAPI) doHTTPRequest(
+	ctx context.Context,
+	addr string,
+	consumer *types.Address,
+	data []byte,
+	hash types.Hash,
+	t time.Time,
+) (receipt *Receipt, retry bool, err error) {
 	w.log.WithField("addr", addr).Info("Sending messages to consumer")
 
 	// Sign the URL.
@@ -396,14 +576,13 @@ func (w *WebAPI) doHTTPRequest(ctx context.Context, addr string, data []byte, t
 		w.rand,
 	)
 	if err != nil {
-		w.log.WithError(err).WithField("addr", addr).Error("Failed to sign URL")
-		return
+		return nil, false, fmt.Errorf("failed to sign URL: %w", err)
 	}
 
 	// Prepare the request.
 	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
 	if err != nil {
-		w.log.WithField("addr", addr).WithError(err).Error("Failed to create request")
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/x-protobuf")
@@ -412,10 +591,38 @@ func (w *WebAPI) doHTTPRequest(ctx context.Context, addr string, data []byte, t
 	// Send the request.
 	res, err := w.client.Do(req)
 	if err != nil {
-		w.log.WithField("addr", addr).WithError(err).Error("Failed to send messages to consumer")
-		return
+		return nil, true, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer res.Body.Close()
+
+	// Verify the response.
+	if res.StatusCode >= http.StatusInternalServerError {
+		return nil, true, fmt.Errorf("consumer responded with status %d", res.StatusCode)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("consumer rejected messages with status %d", res.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxReceiptSize))
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(body) == 0 {
+		// A consumer bound to an address must return a receipt, otherwise
+		// it cannot be confirmed that it accepted the batch. Consumers
+		// without a bound address may not support receipts.
+		if consumer != nil {
+			return nil, true, errors.New("consumer did not return a receipt")
+		}
+		return nil, false, nil
+	}
+	receipt = &Receipt{}
+	if err := json.Unmarshal(body, receipt); err != nil {
+		return nil, false, fmt.Errorf("invalid receipt: %w", err)
+	}
+	if err := verifyReceipt(receipt, hash, consumer, time.Now(), w.maxClockSkew, w.recover); err != nil {
+		return nil, false, fmt.Errorf("invalid receipt: %w", err)
+	}
+	return receipt, false, nil
 }
 
 // consumeHandler handles incoming messages from consumers.
@@ -521,17 +728,6 @@ func (w *WebAPI) consumeHandler(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Message timestamp must be newer than the last received message by
-	// flushInterval - maxClockSkew.
-	if timestamp.Before(w.lastReqs[*requestAuthor].Add(w.flushTicker.Duration() - w.maxClockSkew)) {
-		w.log.
-			WithFields(fields).
-			Warn("Too many messages received in a short time")
-		res.WriteHeader(http.StatusTooManyRequests)
-		return
-	}
-	w.lastReqs[*requestAuthor] = timestamp
-
 	// Read the request body.
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
@@ -582,13 +778,42 @@ func (w *WebAPI) consumeHandler(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Send messages from the MessagePack to the msgCh channel.
+	// Message timestamp must be newer than the last received message by
+	// flushInterval - maxClockSkew. A request with the same timestamp as the
+	// last one is a retry, which is accepted only if it contains the same
+	// batch. The request is remembered in the same critical section, so
+	// concurrent requests of the same producer cannot both pass the check.
+	batchHash := crypto.Keccak256(body)
+	w.lastReqsMu.Lock()
+	lastReq := w.lastReqs[*requestAuthor]
+	retried := timestamp.Equal(lastReq.timestamp)
+	if (retried && batchHash != lastReq.batchHash) ||
+		(!retried && timestamp.Before(lastReq.timestamp.Add(w.flushTicker.Duration()-w.maxClockSkew))) {
+		w.lastReqsMu.Unlock()
+		w.log.
+			WithFields(fields).
+			Warn("Too many messages received in a short time")
+		res.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	w.lastReqs[*requestAuthor] = lastRequest{timestamp: timestamp, batchHash: batchHash}
+	w.lastReqsMu.Unlock()
+
+	// Send messages from the MessagePack to the msgCh channel. Messages
+	// of a retried batch are dropped by the deduplication cache.
 	for topic, msgs := range mp.Messages {
 		typ, ok := w.topics[topic]
 		if !ok {
 			continue // Ignore messages for unknown topics.
 		}
 		for _, bin := range msgs.Data {
+			if w.dedup.Seen(topic, requestAuthor.Bytes(), bin) {
+				w.log.
+					WithFields(fields).
+					WithField("topic", topic).
+					Debug("Duplicated message dropped")
+				continue
+			}
 			ref := reflect.TypeOf(typ).Elem()
 			msg := reflect.New(ref).Interface().(transport.Message)
 			if err := msg.UnmarshallBinary(bin); err != nil {
@@ -596,6 +821,7 @@ func (w *WebAPI) consumeHandler(res http.ResponseWriter, req *http.Request) {
 					WithFields(fields).
 					WithError(err).
 					Warn("Unable to unmarshal message")
+				metrics.MessagesRejected.WithLabelValues(TransportName, topic, "invalid_message").Inc()
 				continue
 			}
 
@@ -608,6 +834,8 @@ func (w *WebAPI) consumeHandler(res http.ResponseWriter, req *http.Request) {
 					Panic("Channel not initialized")
 			}
 
+			metrics.MessagesReceived.WithLabelValues(TransportName, topic).Inc()
+			w.health.Success()
 			w.msgCh[topic] <- transport.ReceivedMessage{
 				Message: msg,
 				Author:  requestAuthor.Bytes(),
@@ -615,6 +843,31 @@ func (w *WebAPI) consumeHandler(res http.ResponseWriter, req *http.Request) {
 			}
 		}
 	}
+
+	// Respond with a signed receipt. Without a signer, the consumer is
+	// not able to sign the receipt, so only the status code is returned.
+	if w.signer == nil {
+		return
+	}
+	receipt, err := signReceipt(batchHash, time.Now(), w.signer)
+	if err != nil {
+		w.log.
+			WithFields(fields).
+			WithError(err).
+			Error("Unable to sign receipt")
+		return
+	}
+	bin, err := json.Marshal(receipt)
+	if err != nil {
+		w.log.
+			WithFields(fields).
+			WithError(err).
+			Error("Unable to encode receipt")
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	_, _ = res.Write(bin)
 }
 
 // flushRoutine periodically sends the buffered messages to the
@@ -637,6 +890,29 @@ func (w *WebAPI) flushRoutine(ctx context.Context) {
 	}
 }
 
+// retryRoutine periodically retries failed deliveries from the retry queue.
+func (w *WebAPI) retryRoutine(ctx context.Context) {
+	if w.signer == nil {
+		return
+	}
+	t := time.NewTicker(w.retryInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			w.deliveryMu.Lock()
+			queue := w.retryQueue
+			w.retryQueue = nil
+			w.deliveryMu.Unlock()
+			for _, d := range queue {
+				go w.deliver(ctx, d)
+			}
+		}
+	}
+}
+
 // contextCancelHandler handles context cancellation.
 func (w *WebAPI) contextCancelHandler() {
 	defer func() { close(w.waitCh) }()
@@ -18,21 +18,30 @@ package webapi
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/defiweb/go-eth/types"
+	"github.com/defiweb/go-eth/wallet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/chronicleprotocol/oracle-suite/pkg/ethereum/mocks"
 	"github.com/chronicleprotocol/oracle-suite/pkg/httpserver"
 	logMocks "github.com/chronicleprotocol/oracle-suite/pkg/log/mocks"
+	"github.com/chronicleprotocol/oracle-suite/pkg/log/null"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport"
 	"github.com/chronicleprotocol/oracle-suite/pkg/transport/webapi/pb"
 	"github.com/chronicleprotocol/oracle-suite/pkg/util/timeutil"
@@ -64,6 +73,7 @@ func (a *addressBook) Consumers(ctx context.Context) ([]string, error) {
 func Test_WebAPI(t *testing.T) {
 	address1 := types.MustAddressFromHex("0x1234567890123456789012345678901234567890")
 	address2 := types.MustAddressFromHex("0x2345678901234567890123456789012345678901")
+	address3 := types.MustAddressFromHex("0x3456789012345678901234567890123456789012")
 
 	tests := []struct {
 		test func(T *testing.T, l *logMocks.Logger, s *mocks.Key, r *mocks.Recoverer, p, c *WebAPI)
@@ -93,6 +103,31 @@ func Test_WebAPI(t *testing.T) {
 				assert.Nil(t, msg.Error)
 			},
 		},
+		{
+			// Duplicated messages are delivered only once.
+			test: func(t *testing.T, l *logMocks.Logger, s *mocks.Key, r *mocks.Recoverer, p, c *WebAPI) {
+				tm := time.Now()
+				ch := c.Messages("test")
+
+				// Prepare mocks:
+				msgSig := []byte("testdatadatadata2")
+				urlSig := []byte(fmt.Sprintf("%d30313233343536373839616263646566", tm.Unix()))
+				s.On("SignMessage", msgSig).Return(&fakeSignature, nil).Once()
+				s.On("SignMessage", urlSig).Return(&fakeSignature, nil).Once()
+				r.On("RecoverMessage", msgSig, fakeSignature).Return(&address1, nil).Once()
+				r.On("RecoverMessage", urlSig, fakeSignature).Return(&address1, nil)
+
+				// Send messages:
+				require.NoError(t, p.Broadcast("test", &message{data: []byte("data")}))
+				require.NoError(t, p.Broadcast("test", &message{data: []byte("data")}))
+				require.NoError(t, p.Broadcast("test", &message{data: []byte("data2")}))
+				p.flushTicker.TickAt(tm)
+
+				// Wait for messages and verify:
+				assert.Equal(t, []byte("data"), (<-ch).Message.(*message).data)
+				assert.Equal(t, []byte("data2"), (<-ch).Message.(*message).data)
+			},
+		},
 		{
 			// Invalid URL signature.
 			test: func(t *testing.T, l *logMocks.Logger, s *mocks.Key, r *mocks.Recoverer, p, c *WebAPI) {
@@ -300,6 +335,64 @@ func Test_WebAPI(t *testing.T) {
 				}, time.Second, time.Millisecond*100)
 			},
 		},
+		{
+			// Consumer returns a signed receipt.
+			test: func(t *testing.T, l *logMocks.Logger, s *mocks.Key, r *mocks.Recoverer, p, c *WebAPI) {
+				tm := time.Now()
+				ch := c.Messages("test")
+
+				// Prepare mocks:
+				msgSig := []byte("testdata")
+				urlSig := []byte(fmt.Sprintf("%d30313233343536373839616263646566", tm.Unix()))
+				s.On("SignMessage", msgSig).Return(&fakeSignature, nil).Once()
+				s.On("SignMessage", urlSig).Return(&fakeSignature, nil).Once()
+				r.On("RecoverMessage", msgSig, fakeSignature).Return(&address1, nil).Once()
+				r.On("RecoverMessage", urlSig, fakeSignature).Return(&address1, nil)
+
+				// Send message:
+				require.NoError(t, p.Broadcast("test", &message{data: []byte("data")}))
+				p.flushTicker.TickAt(tm)
+				<-ch
+
+				// Wait for the delivery status and verify:
+				assert.Eventually(t, func() bool {
+					for _, status := range p.DeliveryStatus() {
+						return status.Receipt != nil &&
+							status.Receipt.Consumer == address3 &&
+							status.Attempts == 1 &&
+							status.Error == nil
+					}
+					return false
+				}, time.Second, time.Millisecond*100)
+			},
+		},
+		{
+			// Rejected delivery is recorded.
+			test: func(t *testing.T, l *logMocks.Logger, s *mocks.Key, r *mocks.Recoverer, p, c *WebAPI) {
+				tm := time.Now()
+
+				// Prepare mocks:
+				msgSig := []byte("testdata")
+				urlSig := []byte(fmt.Sprintf("%d30313233343536373839616263646566", tm.Unix()))
+				s.On("SignMessage", msgSig).Return(&fakeSignature, nil).Once()
+				s.On("SignMessage", urlSig).Return(&fakeSignature, nil).Once()
+				r.On("RecoverMessage", urlSig, fakeSignature).Return(&address2, nil).Once()
+
+				// Send message:
+				require.NoError(t, p.Broadcast("test", &message{data: []byte("data")}))
+				p.flushTicker.TickAt(tm)
+
+				// Wait for the delivery status and verify:
+				assert.Eventually(t, func() bool {
+					for _, status := range p.DeliveryStatus() {
+						return status.Receipt == nil &&
+							status.LastSuccess.IsZero() &&
+							status.Error != nil
+					}
+					return false
+				}, time.Second, time.Millisecond*100)
+			},
+		},
 		{
 			// Channel must be closed after context is done.
 			test: func(t *testing.T, l *logMocks.Logger, s *mocks.Key, r *mocks.Recoverer, p, c *WebAPI) {
@@ -322,11 +415,17 @@ func Test_WebAPI(t *testing.T) {
 			ab := &addressBook{addresses: []string{}}
 			signer := &mocks.Key{}
 			recoverer := &mocks.Recoverer{}
+			receiptSigner := &mocks.Key{}
+			receiptSigner.On("Address").Return(address3)
+			receiptSigner.On("SignMessage", mock.Anything).Return(&fakeSignature, nil)
+			receiptRecoverer := &mocks.Recoverer{}
+			receiptRecoverer.On("RecoverMessage", mock.Anything, fakeSignature).Return(&address3, nil)
 			logger := logMocks.New()
 			logger.Mock().On("WithError", mock.Anything).Return(logger)
 			logger.Mock().On("WithField", mock.Anything, mock.Anything).Return(logger)
 			logger.Mock().On("WithFields", mock.Anything).Return(logger)
 			logger.Mock().On("Warn", mock.Anything).Return()
+			logger.Mock().On("Error", mock.Anything).Return()
 			logger.Mock().On("Info", mock.Anything)
 			logger.Mock().On("Debug", mock.Anything)
 
@@ -349,7 +448,7 @@ func Test_WebAPI(t *testing.T) {
 				Topics:          map[string]transport.Message{"test": (*message)(nil)},
 				AuthorAllowlist: []types.Address{address1},
 				AddressBook:     ab,
-				Signer:          signer,
+				Signer:          receiptSigner,
 				Timeout:         0,
 				FlushTicker:     consTick,
 				Server:          consSrv,
@@ -358,7 +457,7 @@ func Test_WebAPI(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			prod.recover = recoverer
+			prod.recover = receiptRecoverer
 			cons.recover = recoverer
 
 			// Start transport.
@@ -450,3 +549,214 @@ func Test_signURL(t *testing.T) {
 	assert.Equal(t, &address, retAddress)
 	assert.Equal(t, tm.Unix(), retTime.Unix())
 }
+
+func Test_WebAPI_Retry(t *testing.T) {
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer ctxCancel()
+
+	prodKey := wallet.NewRandomKey()
+	consKey := wallet.NewRandomKey()
+	otherKey := wallet.NewRandomKey()
+
+	// Consumer:
+	consSrv := httpserver.New(&http.Server{Addr: "127.0.0.1:0"})
+	cons, err := New(Config{
+		Topics:          map[string]transport.Message{"test": (*message)(nil)},
+		AuthorAllowlist: []types.Address{prodKey.Address()},
+		AddressBook:     &addressBook{},
+		Signer:          consKey,
+		FlushTicker:     timeutil.NewTicker(60 * time.Second),
+		Server:          consSrv,
+		Logger:          null.New(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, cons.Start(ctx))
+	ch := cons.Messages("test")
+
+	// Proxy in front of the consumer that forwards the first request to the
+	// consumer, but responds with an error as if the response was lost.
+	consURL, err := url.Parse("http://" + consSrv.Addr().String())
+	require.NoError(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(consURL)
+	var requests atomic.Int32
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if requests.Add(1) == 1 {
+			proxy.ServeHTTP(httptest.NewRecorder(), req)
+			res.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		proxy.ServeHTTP(res, req)
+	}))
+	defer proxySrv.Close()
+	proxyAddr := strings.TrimPrefix(proxySrv.URL, "http://")
+
+	// Producers, the second one expects receipts from a different consumer:
+	newProducer := func(consumer string) *WebAPI {
+		prod, err := New(Config{
+			ListenAddr:    "127.0.0.1:0",
+			Topics:        map[string]transport.Message{"test": (*message)(nil)},
+			AddressBook:   &addressBook{addresses: []string{consumer}},
+			Signer:        prodKey,
+			FlushTicker:   timeutil.NewTicker(60 * time.Second),
+			RetryInterval: 50 * time.Millisecond,
+			Logger:        null.New(),
+		})
+		require.NoError(t, err)
+		require.NoError(t, prod.Start(ctx))
+		return prod
+	}
+	prod := newProducer(consKey.Address().String() + "@" + proxyAddr)
+
+	// The first attempt fails, the retry must be accepted by the consumer:
+	require.NoError(t, prod.Broadcast("test", &message{data: []byte("data")}))
+	prod.flushTicker.TickAt(time.Now())
+	assert.Eventually(t, func() bool {
+		for _, status := range prod.DeliveryStatus() {
+			return status.Receipt != nil &&
+				status.Receipt.Consumer == consKey.Address() &&
+				status.Attempts == 2 &&
+				status.Error == nil
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// Message must be delivered only once:
+	msg := <-ch
+	require.NoError(t, msg.Error)
+	assert.Equal(t, []byte("data"), msg.Message.(*message).data)
+	select {
+	case <-ch:
+		t.Fatal("message delivered twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Receipt from a different consumer must be rejected:
+	prod = newProducer(otherKey.Address().String() + "@" + proxyAddr)
+	require.NoError(t, prod.Broadcast("test", &message{data: []byte("data2")}))
+	prod.flushTicker.TickAt(time.Now().Add(time.Minute))
+	assert.Eventually(t, func() bool {
+		for _, status := range prod.DeliveryStatus() {
+			return status.Receipt == nil && status.Error != nil
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_WebAPI_EmptyResponse(t *testing.T) {
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer ctxCancel()
+
+	// Consumer that accepts messages but does not return a receipt:
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	newProducer := func(consumer string) *WebAPI {
+		prod, err := New(Config{
+			ListenAddr:    "127.0.0.1:0",
+			Topics:        map[string]transport.Message{"test": (*message)(nil)},
+			AddressBook:   &addressBook{addresses: []string{consumer}},
+			Signer:        wallet.NewRandomKey(),
+			FlushTicker:   timeutil.NewTicker(60 * time.Second),
+			RetryInterval: 50 * time.Millisecond,
+			Logger:        null.New(),
+		})
+		require.NoError(t, err)
+		require.NoError(t, prod.Start(ctx))
+		return prod
+	}
+
+	// Without a bound address, an empty response is a successful delivery:
+	prod := newProducer(addr)
+	require.NoError(t, prod.Broadcast("test", &message{data: []byte("data")}))
+	prod.flushTicker.TickAt(time.Now())
+	assert.Eventually(t, func() bool {
+		for _, status := range prod.DeliveryStatus() {
+			return status.Receipt == nil &&
+				!status.LastSuccess.IsZero() &&
+				status.Attempts == 1 &&
+				status.Error == nil
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	// With a bound address, the receipt is required and the delivery is
+	// retried:
+	requests.Store(0)
+	prod = newProducer(wallet.NewRandomKey().Address().String() + "@" + addr)
+	require.NoError(t, prod.Broadcast("test", &message{data: []byte("data")}))
+	prod.flushTicker.TickAt(time.Now())
+	assert.Eventually(t, func() bool {
+		for _, status := range prod.DeliveryStatus() {
+			return status.Receipt == nil &&
+				status.LastSuccess.IsZero() &&
+				status.Attempts > 1 &&
+				status.Error != nil
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+	assert.Greater(t, requests.Load(), int32(1))
+}
+
+func Test_WebAPI_ConcurrentRequests(t *testing.T) {
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer ctxCancel()
+
+	prodKey := wallet.NewRandomKey()
+	cons, err := New(Config{
+		ListenAddr:      "127.0.0.1:0",
+		Topics:          map[string]transport.Message{"test": (*message)(nil)},
+		AuthorAllowlist: []types.Address{prodKey.Address()},
+		AddressBook:     &addressBook{},
+		FlushTicker:     timeutil.NewTicker(60 * time.Second),
+		Logger:          null.New(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, cons.Start(ctx))
+
+	// Requests with the same timestamp but different batches. Only one of
+	// them may be accepted, the others are not retries of the first one.
+	const n = 20
+	tm := time.Now()
+	reqs := make([]*http.Request, n)
+	for i := range reqs {
+		mp := &pb.MessagePack{Messages: map[string]*pb.MessagePack_Messages{
+			"test": {Data: [][]byte{[]byte(fmt.Sprintf("data%d", i))}},
+		}}
+		require.NoError(t, signMessage(mp, prodKey))
+		bin, err := proto.Marshal(mp)
+		require.NoError(t, err)
+		bin, err = gzipCompress(bin)
+		require.NoError(t, err)
+		u, err := signURL("http://consumer"+consumePath, tm, prodKey, rand.Reader)
+		require.NoError(t, err)
+		reqs[i] = httptest.NewRequest(http.MethodPost, u, bytes.NewReader(bin))
+		reqs[i].Header.Set("Content-Type", "application/x-protobuf")
+		reqs[i].Header.Set("Content-Encoding", "gzip")
+	}
+	var (
+		wg       sync.WaitGroup
+		start    = make(chan struct{})
+		accepted atomic.Int32
+	)
+	for _, req := range reqs {
+		wg.Add(1)
+		go func(req *http.Request) {
+			defer wg.Done()
+			<-start
+			rec := httptest.NewRecorder()
+			cons.consumeHandler(rec, req)
+			if rec.Code == http.StatusOK {
+				accepted.Add(1)
+			}
+		}(req)
+	}
+	close(start)
+	wg.Wait()
+	assert.Equal(t, int32(1), accepted.Load())
+}
@@ -40,7 +40,7 @@ func Include(ctx *hcl.EvalContext, body hcl.Body, wd string, maxDeep int) (hcl.B
 	var bodies []hcl.Body
 	for _, pattern := range include {
 		// Find all files matching the glob pattern.
-		paths, err := glob(pattern)
+		paths, err := Glob(pattern, wd)
 		if err != nil {
 			return nil, hcl.Diagnostics{{
 				Severity: hcl.DiagError,
@@ -52,8 +52,6 @@ func Include(ctx *hcl.EvalContext, body hcl.Body, wd string, maxDeep int) (hcl.B
 
 		// Iterate over the files from the glob pattern.
 		for _, path := range paths {
-			path = relativePath(wd, path)
-
 			// Parse the file.
 			fileBody, diags := utilHCL.ParseFile(path, attr.Expr.Range().Ptr())
 			if diags.HasErrors() {
@@ -73,6 +71,20 @@ func Include(ctx *hcl.EvalContext, body hcl.Body, wd string, maxDeep int) (hcl.B
 	return hcl.MergeBodies([]hcl.Body{remain, hcl.MergeBodies(bodies)}), diags
 }
 
+// Glob returns the paths of files matching the given glob pattern, as they
+// are resolved by the "include" attribute. Relative paths are joined with
+// the given working directory.
+func Glob(pattern, wd string) ([]string, error) {
+	paths, err := glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for i, path := range paths {
+		paths[i] = relativePath(wd, path)
+	}
+	return paths, nil
+}
+
 // relativePath returns an absolute path for the given path relative to the
 // given working directory.
 func relativePath(wd, path string) string {
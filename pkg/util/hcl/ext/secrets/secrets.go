@@ -0,0 +1,188 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Redacted is the text that replaces secrets in redacted strings.
+const Redacted = "(redacted)"
+
+// MinRedactLength is the minimum length of a secret that is redacted
+// wherever it occurs in a string. Shorter secrets are redacted only if they
+// are the whole string, so that they do not redact unrelated text.
+const MinRedactLength = 8
+
+// Provider resolves secrets of a single scheme. The name is the part of
+// the secret reference after the scheme, e.g. for "env:API_KEY" the name is
+// "API_KEY".
+type Provider interface {
+	Secret(name string) (string, error)
+}
+
+// ProviderFunc is an adapter that allows using a function as a Provider.
+type ProviderFunc func(name string) (string, error)
+
+// Secret implements the Provider interface.
+func (f ProviderFunc) Secret(name string) (string, error) {
+	return f(name)
+}
+
+// Env is a provider that reads secrets from environment variables.
+var Env = ProviderFunc(func(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return v, nil
+})
+
+// File is a provider that reads secrets from files. A trailing newline is
+// removed from the file content.
+var File = ProviderFunc(func(name string) (string, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+})
+
+// Resolver resolves secret references in the "scheme:name" format using
+// registered providers. It remembers resolved secrets, so they can be
+// redacted from strings and values later.
+type Resolver struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+	secrets   map[string]struct{}
+}
+
+// NewResolver returns a new Resolver with the "env", "file" and "vault"
+// providers registered. The "vault" provider uses the VAULT_ADDR and
+// VAULT_TOKEN environment variables.
+func NewResolver() *Resolver {
+	r := &Resolver{
+		providers: map[string]Provider{},
+		secrets:   map[string]struct{}{},
+	}
+	r.Register("env", Env)
+	r.Register("file", File)
+	r.Register("vault", &Vault{})
+	return r
+}
+
+// Register registers a provider for the given scheme. It replaces the
+// provider previously registered for the same scheme.
+func (r *Resolver) Register(scheme string, p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[scheme] = p
+}
+
+// Resolve returns the secret for the given reference.
+func (r *Resolver) Resolve(ref string) (string, error) {
+	scheme, name, ok := strings.Cut(ref, ":")
+	if !ok || scheme == "" || name == "" {
+		return "", fmt.Errorf("invalid secret reference %q, expected scheme:name", ref)
+	}
+	r.mu.RLock()
+	p, ok := r.providers[scheme]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown secret provider %q", scheme)
+	}
+	secret, err := p.Secret(name)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve secret %q: %w", ref, err)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("secret %q is empty", ref)
+	}
+	r.mu.Lock()
+	r.secrets[secret] = struct{}{}
+	r.mu.Unlock()
+	return secret, nil
+}
+
+// Redact replaces resolved secrets in the given string with the Redacted
+// text. Secrets shorter than MinRedactLength are replaced only if they are
+// the whole string.
+func (r *Resolver) Redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.secrets) == 0 || s == "" {
+		return s
+	}
+	if _, ok := r.secrets[s]; ok {
+		return Redacted
+	}
+	// Longer secrets are replaced first, in case one secret contains another.
+	secrets := make([]string, 0, len(r.secrets))
+	for secret := range r.secrets {
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+	for _, secret := range secrets {
+		if len(secret) < MinRedactLength {
+			break
+		}
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	return s
+}
+
+// RedactValue replaces resolved secrets in all strings of the given value.
+func (r *Resolver) RedactValue(v cty.Value) cty.Value {
+	v, _ = cty.Transform(v, func(_ cty.Path, v cty.Value) (cty.Value, error) {
+		if v.IsKnown() && !v.IsNull() && v.Type() == cty.String {
+			return cty.StringVal(r.Redact(v.AsString())), nil
+		}
+		return v, nil
+	})
+	return v
+}
+
+// Func returns the "secret" function that resolves the secret reference
+// given as the argument, e.g. secret("env:API_KEY").
+func (r *Resolver) Func() function.Function {
+	return function.New(&function.Spec{
+		Description: "Returns the secret for the given reference.",
+		Params: []function.Parameter{
+			{
+				Name:        "ref",
+				Description: "The secret reference in the scheme:name format.",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			secret, err := r.Resolve(args[0].AsString())
+			if err != nil {
+				return cty.NilVal, function.NewArgError(0, err)
+			}
+			return cty.StringVal(secret), nil
+		},
+	})
+}
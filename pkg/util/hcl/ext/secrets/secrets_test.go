@@ -0,0 +1,153 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package secrets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	t.Setenv("SECRETS_TEST_KEY", "env-secret")
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte("file-secret\n"), 0600))
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "env:SECRETS_TEST_KEY", want: "env-secret"},
+		{ref: "file:" + path, want: "file-secret"},
+		{ref: "env:SECRETS_TEST_MISSING", wantErr: "environment variable SECRETS_TEST_MISSING is not set"},
+		{ref: "foo:bar", wantErr: `unknown secret provider "foo"`},
+		{ref: "SECRETS_TEST_KEY", wantErr: "expected scheme:name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			secret, err := NewResolver().Resolve(tt.ref)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, secret)
+		})
+	}
+}
+
+func TestResolver_Redact(t *testing.T) {
+	r := NewResolver()
+	r.Register("test", ProviderFunc(func(name string) (string, error) {
+		return name, nil
+	}))
+	assert.Equal(t, "key=abc", r.Redact("key=abc"))
+
+	_, err := r.Resolve("test:abc")
+	require.NoError(t, err)
+	_, err = r.Resolve("test:abcdefgh")
+	require.NoError(t, err)
+
+	// Short secrets are redacted only as whole strings:
+	assert.Equal(t, "key=(redacted) other=abc", r.Redact("key=abcdefgh other=abc"))
+	assert.Equal(t, "(redacted)", r.Redact("abc"))
+	assert.Equal(t,
+		cty.ObjectVal(map[string]cty.Value{
+			"key":  cty.StringVal("(redacted)"),
+			"list": cty.ListVal([]cty.Value{cty.StringVal("x-(redacted)"), cty.StringVal("x-abc")}),
+			"num":  cty.NumberIntVal(1),
+		}),
+		r.RedactValue(cty.ObjectVal(map[string]cty.Value{
+			"key":  cty.StringVal("abc"),
+			"list": cty.ListVal([]cty.Value{cty.StringVal("x-abcdefgh"), cty.StringVal("x-abc")}),
+			"num":  cty.NumberIntVal(1),
+		})),
+	)
+}
+
+func TestResolver_Func(t *testing.T) {
+	t.Setenv("SECRETS_TEST_KEY", "env-secret")
+	ctx := &hcl.EvalContext{
+		Functions: map[string]function.Function{"secret": NewResolver().Func()},
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(`"key=${secret("env:SECRETS_TEST_KEY")}"`), "test.hcl", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	val, diags := expr.Value(ctx)
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.Equal(t, "key=env-secret", val.AsString())
+
+	expr, diags = hclsyntax.ParseExpression([]byte(`secret("env:SECRETS_TEST_MISSING")`), "test.hcl", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	_, diags = expr.Value(ctx)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), "environment variable SECRETS_TEST_MISSING is not set")
+}
+
+func TestVault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/oracle":
+			_, _ = w.Write([]byte(`{"data":{"data":{"api_key":"v2-secret"},"metadata":{"version":1}}}`))
+		case "/v1/kv/oracle":
+			_, _ = w.Write([]byte(`{"data":{"value":"v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr string
+	}{
+		{name: "secret/data/oracle#api_key", token: "token", want: "v2-secret"},
+		{name: "kv/oracle", token: "token", want: "v1-secret"},
+		{name: "secret/data/oracle#missing", token: "token", wantErr: "key missing not found"},
+		{name: "secret/data/missing", token: "token", wantErr: "status 404"},
+		{name: "secret/data/oracle#api_key", token: "invalid", wantErr: "status 403"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Vault{Address: srv.URL, Token: tt.token}
+			secret, err := v.Secret(tt.name)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, secret)
+		})
+	}
+}
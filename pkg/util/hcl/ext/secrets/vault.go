@@ -0,0 +1,107 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const vaultDefaultKey = "value"
+
+// Vault is a provider that reads secrets from a Vault-compatible HTTP API
+// using the KV secrets engine. Secret names have the "path#key" format,
+// e.g. "secret/data/oracle#api_key". If the key is omitted, the "value" key
+// is used. Both version 1 and version 2 of the KV engine are supported.
+type Vault struct {
+	// Address is the address of the Vault server. If empty, the VAULT_ADDR
+	// environment variable is used.
+	Address string
+
+	// Token is the Vault token. If empty, the VAULT_TOKEN environment
+	// variable is used.
+	Token string
+
+	// Client is the HTTP client used to send requests. If nil, a client with
+	// a 10-second timeout is used.
+	Client *http.Client
+}
+
+// Secret implements the Provider interface.
+func (v *Vault) Secret(name string) (string, error) {
+	path, key, _ := strings.Cut(name, "#")
+	if key == "" {
+		key = vaultDefaultKey
+	}
+	addr := v.Address
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if addr == "" {
+		return "", fmt.Errorf("vault address is not set")
+	}
+	token := v.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid vault address: %w", err)
+	}
+	u = u.JoinPath("v1", path)
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for %s", res.StatusCode, path)
+	}
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("unable to decode vault response: %w", err)
+	}
+	data := body.Data
+	// The KV version 2 engine nests the secret data in another data object.
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	secret, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("key %s not found in vault secret %s", key, path)
+	}
+	return secret, nil
+}
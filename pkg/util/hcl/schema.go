@@ -0,0 +1,74 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"reflect"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Schema describes the HCL body that can be decoded into a struct by the
+// Decode function.
+type Schema struct {
+	Labels     []SchemaField // Labels of the block, in order.
+	Attributes []SchemaField // Attributes of the body.
+	Blocks     []SchemaField // Nested blocks of the body.
+	Remain     bool          // True if the struct accepts additional content.
+}
+
+// SchemaField describes a single label, attribute or block of the Schema.
+type SchemaField struct {
+	Name     string              // Name as defined in the hcl tag.
+	Optional bool                // True if the attribute or block is optional.
+	Multiple bool                // True if the block can be repeated.
+	Field    reflect.StructField // The struct field, Index is relative to the struct.
+	Type     reflect.Type        // Type of the attribute or the struct type of the block.
+}
+
+// StructSchema returns the schema of the HCL body that can be decoded into
+// the given struct type. Pointer types are dereferenced.
+func StructSchema(t reflect.Type) (*Schema, hcl.Diagnostics) {
+	meta, diags := getStructMeta(derefType(t))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	schema := &Schema{Remain: meta.Remain != nil}
+	for _, f := range meta.Labels {
+		schema.Labels = append(schema.Labels, schemaField(f))
+	}
+	for _, f := range meta.Attrs {
+		schema.Attributes = append(schema.Attributes, schemaField(f))
+	}
+	for _, f := range meta.Blocks {
+		schema.Blocks = append(schema.Blocks, schemaField(f))
+	}
+	return schema, nil
+}
+
+func schemaField(f structFieldMeta) SchemaField {
+	typ := f.Reflect.Type
+	if f.Type == fieldBlock {
+		typ = f.StructReflect
+	}
+	return SchemaField{
+		Name:     f.Name,
+		Optional: f.Optional,
+		Multiple: f.Multiple,
+		Field:    f.Reflect,
+		Type:     typ,
+	}
+}
@@ -0,0 +1,80 @@
+//  Copyright (C) 2021-2023 Chronicle Labs, Inc.
+//
+//  This program is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  This program is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructSchema(t *testing.T) {
+	type embedded struct {
+		Embedded string `hcl:"embedded,optional"`
+	}
+	type nested struct {
+		Name  string `hcl:",label"`
+		Value int    `hcl:"value"`
+	}
+	type config struct {
+		embedded
+		Attr   string            `hcl:"attr"`
+		Block  *nested           `hcl:"block,block,optional"`
+		Blocks []nested          `hcl:"blocks,block"`
+		Map    map[string]nested `hcl:"map,block"`
+		Remain hcl.Body          `hcl:",remain"`
+	}
+
+	schema, diags := StructSchema(reflect.TypeOf(&config{}))
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.True(t, schema.Remain)
+	assert.Empty(t, schema.Labels)
+
+	require.Len(t, schema.Attributes, 2)
+	assert.Equal(t, "embedded", schema.Attributes[0].Name)
+	assert.True(t, schema.Attributes[0].Optional)
+	assert.Equal(t, []int{0, 0}, schema.Attributes[0].Field.Index)
+	assert.Equal(t, "attr", schema.Attributes[1].Name)
+	assert.False(t, schema.Attributes[1].Optional)
+	assert.Equal(t, reflect.TypeOf(""), schema.Attributes[1].Type)
+
+	require.Len(t, schema.Blocks, 3)
+	for _, b := range schema.Blocks {
+		assert.Equal(t, reflect.TypeOf(nested{}), b.Type, b.Name)
+	}
+	assert.Equal(t, "block", schema.Blocks[0].Name)
+	assert.True(t, schema.Blocks[0].Optional)
+	assert.False(t, schema.Blocks[0].Multiple)
+	assert.True(t, schema.Blocks[1].Multiple)
+	assert.True(t, schema.Blocks[2].Multiple)
+
+	nestedSchema, diags := StructSchema(reflect.TypeOf(nested{}))
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.Len(t, nestedSchema.Labels, 1)
+	assert.Equal(t, "Name", nestedSchema.Labels[0].Name)
+}
+
+func TestStructSchema_Invalid(t *testing.T) {
+	type config struct {
+		A string `hcl:"a"`
+		B string `hcl:"a"`
+	}
+	_, diags := StructSchema(reflect.TypeOf(config{}))
+	assert.True(t, diags.HasErrors())
+}